@@ -3,7 +3,49 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=adminGroupDetailsRoleToggleGranted&groupID="+$(this).attr('groupID')+"&roleID="+$(this).attr('roleID')+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=adminGroupDetailsRoleToggleGranted&groupID="+$(this).attr('groupID')+"&roleID="+$(this).attr('roleID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
+			dataType: "json",
+			error: displayAjaxError,
+			success: displayResponse,
+			timeout: 10000,
+			type: "PUT",
+			url: "/admin/groups"
+		});
+	});
+	
+	$('a.admin-groupdetails-membershiprule-delete').click(function() {
+		$.ajax({
+			accepts: "application/json",
+			cache: false,
+			data: "command=adminGroupDetailsMembershipRuleDelete&groupID="+$(this).attr('groupID')+"&membershipRuleID="+$(this).attr('membershipRuleID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
+			dataType: "json",
+			error: displayAjaxError,
+			success: displayResponse,
+			timeout: 10000,
+			type: "PUT",
+			url: "/admin/groups"
+		});
+	});
+	
+	$('a.admin-groupdetails-subgroup-delete').click(function() {
+		$.ajax({
+			accepts: "application/json",
+			cache: false,
+			data: "command=adminGroupDetailsSubGroupDelete&groupID="+$(this).attr('groupID')+"&subGroupID="+$(this).attr('subGroupID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
+			dataType: "json",
+			error: displayAjaxError,
+			success: displayResponse,
+			timeout: 10000,
+			type: "PUT",
+			url: "/admin/groups"
+		});
+	});
+	
+	$('a.admin-groupdetails-manager-delete').click(function() {
+		$.ajax({
+			accepts: "application/json",
+			cache: false,
+			data: "command=adminGroupDetailsManagerDelete&groupID="+$(this).attr('groupID')+"&groupManagerID="+$(this).attr('groupManagerID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
@@ -17,7 +59,7 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=adminGroupDetailsRoleDelete&groupID="+$(this).attr('groupID')+"&roleID="+$(this).attr('roleID')+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=adminGroupDetailsRoleDelete&groupID="+$(this).attr('groupID')+"&roleID="+$(this).attr('roleID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
@@ -1,4 +1,16 @@
 $(document).ready(function(e) {
+	$('#adminRolesImplyRoleID').change(function() {
+		$('#adminRolesImplyVersion').val($(this).find('option:selected').attr('version'));
+	}).change();
+
+	$('#adminRolesSetApplicationRoleID').change(function() {
+		$('#adminRolesSetApplicationVersion').val($(this).find('option:selected').attr('version'));
+	}).change();
+
+	$('#adminRolesSetPolicyRoleID').change(function() {
+		$('#adminRolesSetPolicyVersion').val($(this).find('option:selected').attr('version'));
+	}).change();
+
 	$('a.admin-role-delete').click(function() {
 		$.ajax({
 			accepts: "application/json",
@@ -12,4 +24,18 @@ $(document).ready(function(e) {
 			url: "/admin/roles"
 		});
 	});
+
+	$('a.admin-role-remove-implied').click(function() {
+		$.ajax({
+			accepts: "application/json",
+			cache: false,
+			data: "command=adminRolesRemoveImplied&roleID="+$(this).attr('roleID')+"&version="+$(this).attr('version')+"&impliedRoleID="+$(this).attr('impliedRoleID')+"&csrfToken="+$(this).attr('csrfToken'),
+			dataType: "json",
+			error: displayAjaxError,
+			success: displayResponse,
+			timeout: 10000,
+			type: "PUT",
+			url: "/admin/roles"
+		});
+	});
 });
\ No newline at end of file
@@ -0,0 +1,15 @@
+$(document).ready(function(e) {
+	$('a.admin-trash-restore').click(function() {
+		$.ajax({
+			accepts: "application/json",
+			cache: false,
+			data: "command=adminTrashRestore&entryType="+$(this).attr('entryType')+"&entryID="+$(this).attr('entryID')+"&csrfToken="+$(this).attr('csrfToken'),
+			dataType: "json",
+			error: displayAjaxError,
+			success: displayResponse,
+			timeout: 10000,
+			type: "PUT",
+			url: "/admin/trash"
+		});
+	});
+});
\ No newline at end of file
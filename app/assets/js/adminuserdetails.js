@@ -3,7 +3,7 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=adminUserDetailsGroupDelete&userID="+$(this).attr('userID')+"&groupID="+$(this).attr('groupID')+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=adminUserDetailsGroupDelete&userID="+$(this).attr('userID')+"&groupID="+$(this).attr('groupID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
@@ -17,7 +17,7 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=adminUserDetailsRoleToggleGranted&userID="+$(this).attr('userID')+"&roleID="+$(this).attr('roleID')+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=adminUserDetailsRoleToggleGranted&userID="+$(this).attr('userID')+"&roleID="+$(this).attr('roleID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
@@ -31,7 +31,7 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=adminUserDetailsRoleDelete&userID="+$(this).attr('userID')+"&roleID="+$(this).attr('roleID')+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=adminUserDetailsRoleDelete&userID="+$(this).attr('userID')+"&roleID="+$(this).attr('roleID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
@@ -45,7 +45,7 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=adminUserDetailsAccountDelete&userID="+$(this).attr('userID')+"&accountID="+$(this).attr('accountID')+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=adminUserDetailsAccountDelete&userID="+$(this).attr('userID')+"&accountID="+$(this).attr('accountID')+"&version="+$(this).attr('version')+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
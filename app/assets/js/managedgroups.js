@@ -0,0 +1,15 @@
+$(document).ready(function(e) {
+	$('a.managedgroups-member-remove').click(function() {
+		$.ajax({
+			accepts: "application/json",
+			cache: false,
+			data: "command=managedGroupsRemoveMember&groupID="+$(this).attr('groupID')+"&userID="+$(this).attr('userID')+"&csrfToken="+$(this).attr('csrfToken'),
+			dataType: "json",
+			error: displayAjaxError,
+			success: displayResponse,
+			timeout: 10000,
+			type: "PUT",
+			url: "/managedgroups"
+		});
+	});
+});
\ No newline at end of file
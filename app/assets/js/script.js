@@ -34,6 +34,12 @@ function displayResponse(response) {
 		} else {
 			displayInfo("Something something info...");
 		}
+	} else if (response.status === 4) {
+		if (response.result !== null) {
+			displayError(response.result+' <a href="javascript:location.reload(true);">Reload</a>');
+		} else {
+			displayError('Entry has been modified in the meantime! <a href="javascript:location.reload(true);">Reload</a>');
+		}
 	} else {
 		displayError(response);
 	}
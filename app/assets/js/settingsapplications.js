@@ -16,7 +16,9 @@ $(document).ready(function(e) {
 	$('a.settings-application-edit-toggle').click(function() {
 		$('#settingsApplicationsEditApplicationName').val($(this).attr('applicationName'));
 		$('#settingsApplicationsEditApplicationCallback').val($(this).attr('applicationCallback'));
+		$('#settingsApplicationsEditApplicationRoleScopes').val($(this).attr('applicationRoleScopes'));
 		$('#settingsApplicationsEditApplicationID').val($(this).attr('applicationID'));
+		$('#settingsApplicationsEditApplicationVersion').val($(this).attr('applicationVersion'));
 		$('#settingsApplicationsEditApplication').collapse("show");
 	});
 
@@ -42,7 +44,7 @@ $(document).ready(function(e) {
 		$.ajax({
 			accepts: "application/json",
 			cache: false,
-			data: "command=settingsApplicationsEditApplicationResetSecret&applicationID="+$('#settingsApplicationsEditApplicationID').val()+"&csrfToken="+$(this).attr('csrfToken'),
+			data: "command=settingsApplicationsEditApplicationResetSecret&applicationID="+$('#settingsApplicationsEditApplicationID').val()+"&version="+$('#settingsApplicationsEditApplicationVersion').val()+"&csrfToken="+$(this).attr('csrfToken'),
 			dataType: "json",
 			error: displayAjaxError,
 			success: displayResponse,
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/morpheusxaut/eveauth/backup"
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/misc"
+)
+
+// runExport writes all data stored in the database to a JSON archive at the given path, returning the exit code for the application
+func runExport(db database.Connection, path string) int {
+	if len(path) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: eveauth [options] export <file>\n")
+		return 2
+	}
+
+	archive, err := backup.Export(context.Background(), db)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to export database: [%v]", err)
+		return 2
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to create archive: [%v]", err)
+		return 2
+	}
+	defer file.Close()
+
+	err = archive.Write(file)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to write archive: [%v]", err)
+		return 2
+	}
+
+	misc.Logger.Infof("Exported %d users, %d groups, %d roles and %d applications", len(archive.Users), len(archive.Groups), len(archive.Roles), len(archive.Applications))
+
+	return 0
+}
+
+// runImport reads a JSON archive from the given path and writes all of its entries to the database, returning the exit code for the application
+func runImport(db database.Connection, path string) int {
+	if len(path) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: eveauth [options] import <file>\n")
+		return 2
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to open archive: [%v]", err)
+		return 2
+	}
+	defer file.Close()
+
+	archive, err := backup.Read(file)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to read archive: [%v]", err)
+		return 2
+	}
+
+	err = backup.Import(context.Background(), db, archive)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to import archive: [%v]", err)
+		return 2
+	}
+
+	misc.Logger.Infof("Imported %d users, %d groups, %d roles and %d applications", len(archive.Users), len(archive.Groups), len(archive.Roles), len(archive.Applications))
+
+	return 0
+}
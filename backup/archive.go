@@ -0,0 +1,426 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// FormatVersion represents the version of the archive format written by Export
+const FormatVersion = 1
+
+// Archive represents a backend independent snapshot of all data stored in the database.
+// Entries reference each other using the IDs they had in the exported database
+type Archive struct {
+	// FormatVersion represents the version of the archive format
+	FormatVersion int `json:"formatVersion"`
+	// CreatedAt represents the time the archive was exported at
+	CreatedAt time.Time `json:"createdAt"`
+	// Alliances contains all alliances
+	Alliances []*models.Alliance `json:"alliances"`
+	// Corporations contains all corporations
+	Corporations []*models.Corporation `json:"corporations"`
+	// Roles contains all roles
+	Roles []*models.Role `json:"roles"`
+	// RoleImplications contains all implications between roles
+	RoleImplications []*models.RoleImplication `json:"roleImplications"`
+	// Groups contains all groups, their group roles and sub groups are stored separately
+	Groups []*models.Group `json:"groups"`
+	// GroupNestings contains all nestings between groups
+	GroupNestings []*models.GroupNesting `json:"groupNestings"`
+	// GroupRoles contains all group roles
+	GroupRoles []*RoleAssignment `json:"groupRoles"`
+	// MembershipRules contains the membership rules of all groups
+	MembershipRules []*models.MembershipRule `json:"membershipRules"`
+	// Users contains all users including their password hashes
+	Users []*User `json:"users"`
+	// UserRoles contains all user roles
+	UserRoles []*RoleAssignment `json:"userRoles"`
+	// Memberships contains all group memberships
+	Memberships []*Membership `json:"memberships"`
+	// GroupManagers contains all group manager assignments
+	GroupManagers []*models.GroupManager `json:"groupManagers"`
+	// ManagerActions contains all actions performed by group managers
+	ManagerActions []*models.ManagerAction `json:"managerActions"`
+	// GroupApplications contains all applications for group memberships
+	GroupApplications []*models.GroupApplication `json:"groupApplications"`
+	// Accounts contains all accounts, their characters are stored separately
+	Accounts []*models.Account `json:"accounts"`
+	// Characters contains all characters
+	Characters []*models.Character `json:"characters"`
+	// Applications contains all applications including their secrets
+	Applications []*Application `json:"applications"`
+	// LoginAttempts contains all login attempts
+	LoginAttempts []*models.LoginAttempt `json:"loginAttempts"`
+	// LoginAttemptSummaries contains the daily summaries of all pruned login attempts
+	LoginAttemptSummaries []*models.LoginAttemptSummary `json:"loginAttemptSummaries"`
+	// CSRFFailures contains all CSRF failures
+	CSRFFailures []*models.CSRFFailure `json:"csrfFailures"`
+}
+
+// User represents an archived user, including the password hash omitted by the regular JSON representation
+type User struct {
+	// ID represents the database ID of the user
+	ID int64 `json:"id"`
+	// Username represents the username of the user
+	Username string `json:"username"`
+	// Password represents the bcrypt-hashed password of the user
+	Password string `json:"password"`
+	// Email represents the email address of the user
+	Email string `json:"email"`
+	// VerifiedEmail indicates whether the user has verified their email address
+	VerifiedEmail bool `json:"verifiedEmail"`
+	// Active indicates whether the user is set as active
+	Active bool `json:"active"`
+}
+
+// Application represents an archived application, including the secret omitted by the regular JSON representation
+type Application struct {
+	// ID represents the database ID of the application
+	ID int64 `json:"id"`
+	// Name represents the name of the application
+	Name string `json:"name"`
+	// MaintainerID represents the ID of the user maintaining the application
+	MaintainerID int64 `json:"maintainerID"`
+	// Secret represents the secret used to authenticate the application
+	Secret string `json:"secret"`
+	// Callback represents the URL the application is redirected to after authentication
+	Callback string `json:"callback"`
+	// Active indicates whether the application is set as active
+	Active bool `json:"active"`
+	// RoleScopes represents the space-separated global roles the application may see
+	RoleScopes string `json:"roleScopes"`
+}
+
+// RoleAssignment represents an archived group or user role, referencing its role by ID
+type RoleAssignment struct {
+	// ID represents the database ID of the group or user role
+	ID int64 `json:"id"`
+	// OwnerID represents the ID of the group or user the role is assigned to
+	OwnerID int64 `json:"ownerID"`
+	// RoleID represents the ID of the assigned role
+	RoleID int64 `json:"roleID"`
+	// AutoAdded indicates whether the role was automatically added
+	AutoAdded bool `json:"autoAdded"`
+	// Granted indicates whether the role is granted or denied
+	Granted bool `json:"granted"`
+	// Validity represents the time window a user role is valid in, nil for group roles and user roles valid indefinitely
+	Validity *models.Validity `json:"validity,omitempty"`
+}
+
+// Membership represents an archived membership of a user in a group
+type Membership struct {
+	// UserID represents the ID of the member
+	UserID int64 `json:"userID"`
+	// GroupID represents the ID of the group
+	GroupID int64 `json:"groupID"`
+	// Validity represents the time window the membership is valid in, nil if it is valid indefinitely
+	Validity *models.Validity `json:"validity,omitempty"`
+	// AutoAdded indicates whether the membership has been added by a membership rule
+	AutoAdded bool `json:"autoAdded,omitempty"`
+}
+
+// Read decodes an archive from the given reader, returning an error if decoding failed or the archive format is not supported
+func Read(r io.Reader) (*Archive, error) {
+	var archive Archive
+
+	err := json.NewDecoder(r).Decode(&archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if archive.FormatVersion < 1 || archive.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("Unsupported archive format version #%d", archive.FormatVersion)
+	}
+
+	return &archive, nil
+}
+
+// Write encodes the archive to the given writer, returning an error if encoding failed
+func (archive *Archive) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+
+	return encoder.Encode(archive)
+}
+
+// Validate checks the referential integrity of the archive, returning an error describing the first duplicate ID or dangling reference found
+func (archive *Archive) Validate() error {
+	alliances := make(map[int64]bool)
+	for _, alliance := range archive.Alliances {
+		err := addID(alliances, "alliance", alliance.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	corporations := make(map[int64]bool)
+	for _, corporation := range archive.Corporations {
+		err := addID(corporations, "corporation", corporation.ID)
+		if err != nil {
+			return err
+		}
+
+		if corporation.AllianceID.Valid && !alliances[corporation.AllianceID.Int64] {
+			return fmt.Errorf("Corporation #%d references unknown alliance #%d", corporation.ID, corporation.AllianceID.Int64)
+		}
+	}
+
+	roles := make(map[int64]bool)
+	for _, role := range archive.Roles {
+		err := addID(roles, "role", role.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = models.ParseRolePolicy(role.Policy)
+		if err != nil {
+			return fmt.Errorf("Role #%d has an invalid policy: %v", role.ID, err)
+		}
+	}
+
+	roleImplications := make(map[int64]bool)
+	for _, roleImplication := range archive.RoleImplications {
+		err := addID(roleImplications, "role implication", roleImplication.ID)
+		if err != nil {
+			return err
+		}
+
+		if !roles[roleImplication.RoleID] {
+			return fmt.Errorf("Role implication #%d references unknown role #%d", roleImplication.ID, roleImplication.RoleID)
+		}
+
+		if !roles[roleImplication.ImpliedRoleID] {
+			return fmt.Errorf("Role implication #%d references unknown implied role #%d", roleImplication.ID, roleImplication.ImpliedRoleID)
+		}
+	}
+
+	groups := make(map[int64]bool)
+	for _, group := range archive.Groups {
+		err := addID(groups, "group", group.ID)
+		if err != nil {
+			return err
+		}
+
+		// Archives exported before groups had a visibility keep their groups hidden
+		if len(group.Visibility) > 0 {
+			_, err = models.ParseGroupVisibility(string(group.Visibility))
+			if err != nil {
+				return fmt.Errorf("Group #%d has unknown visibility %q", group.ID, group.Visibility)
+			}
+		}
+	}
+
+	groupNestings := make(map[int64]bool)
+	for _, groupNesting := range archive.GroupNestings {
+		err := addID(groupNestings, "group nesting", groupNesting.ID)
+		if err != nil {
+			return err
+		}
+
+		if !groups[groupNesting.GroupID] {
+			return fmt.Errorf("Group nesting #%d references unknown group #%d", groupNesting.ID, groupNesting.GroupID)
+		}
+
+		if !groups[groupNesting.SubGroupID] {
+			return fmt.Errorf("Group nesting #%d references unknown sub group #%d", groupNesting.ID, groupNesting.SubGroupID)
+		}
+	}
+
+	membershipRules := make(map[int64]bool)
+	for _, membershipRule := range archive.MembershipRules {
+		err := addID(membershipRules, "membership rule", membershipRule.ID)
+		if err != nil {
+			return err
+		}
+
+		if !groups[membershipRule.GroupID] {
+			return fmt.Errorf("Membership rule #%d references unknown group #%d", membershipRule.ID, membershipRule.GroupID)
+		}
+
+		switch membershipRule.Type {
+		case models.MembershipRuleTypeCorporation, models.MembershipRuleTypeDefaultCorporation:
+			if !corporations[membershipRule.TargetID] {
+				return fmt.Errorf("Membership rule #%d references unknown corporation #%d", membershipRule.ID, membershipRule.TargetID)
+			}
+		case models.MembershipRuleTypeAlliance:
+			if !alliances[membershipRule.TargetID] {
+				return fmt.Errorf("Membership rule #%d references unknown alliance #%d", membershipRule.ID, membershipRule.TargetID)
+			}
+		default:
+			return fmt.Errorf("Membership rule #%d has unknown type %q", membershipRule.ID, membershipRule.Type)
+		}
+	}
+
+	users := make(map[int64]bool)
+	for _, user := range archive.Users {
+		err := addID(users, "user", user.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	accounts := make(map[int64]bool)
+	for _, account := range archive.Accounts {
+		err := addID(accounts, "account", account.ID)
+		if err != nil {
+			return err
+		}
+
+		if !users[account.UserID] {
+			return fmt.Errorf("Account #%d references unknown user #%d", account.ID, account.UserID)
+		}
+	}
+
+	characters := make(map[int64]bool)
+	for _, character := range archive.Characters {
+		err := addID(characters, "character", character.ID)
+		if err != nil {
+			return err
+		}
+
+		if !accounts[character.AccountID] {
+			return fmt.Errorf("Character #%d references unknown account #%d", character.ID, character.AccountID)
+		}
+
+		if !corporations[character.CorporationID] {
+			return fmt.Errorf("Character #%d references unknown corporation #%d", character.ID, character.CorporationID)
+		}
+	}
+
+	groupRoles := make(map[int64]bool)
+	for _, groupRole := range archive.GroupRoles {
+		err := addID(groupRoles, "group role", groupRole.ID)
+		if err != nil {
+			return err
+		}
+
+		if !groups[groupRole.OwnerID] {
+			return fmt.Errorf("Group role #%d references unknown group #%d", groupRole.ID, groupRole.OwnerID)
+		}
+
+		if !roles[groupRole.RoleID] {
+			return fmt.Errorf("Group role #%d references unknown role #%d", groupRole.ID, groupRole.RoleID)
+		}
+	}
+
+	userRoles := make(map[int64]bool)
+	for _, userRole := range archive.UserRoles {
+		err := addID(userRoles, "user role", userRole.ID)
+		if err != nil {
+			return err
+		}
+
+		if !users[userRole.OwnerID] {
+			return fmt.Errorf("User role #%d references unknown user #%d", userRole.ID, userRole.OwnerID)
+		}
+
+		if !roles[userRole.RoleID] {
+			return fmt.Errorf("User role #%d references unknown role #%d", userRole.ID, userRole.RoleID)
+		}
+	}
+
+	for _, membership := range archive.Memberships {
+		if !users[membership.UserID] {
+			return fmt.Errorf("Membership references unknown user #%d", membership.UserID)
+		}
+
+		if !groups[membership.GroupID] {
+			return fmt.Errorf("Membership references unknown group #%d", membership.GroupID)
+		}
+	}
+
+	groupManagers := make(map[int64]bool)
+	for _, groupManager := range archive.GroupManagers {
+		err := addID(groupManagers, "group manager", groupManager.ID)
+		if err != nil {
+			return err
+		}
+
+		if !groups[groupManager.GroupID] {
+			return fmt.Errorf("Group manager #%d references unknown group #%d", groupManager.ID, groupManager.GroupID)
+		}
+
+		if !users[groupManager.UserID] {
+			return fmt.Errorf("Group manager #%d references unknown user #%d", groupManager.ID, groupManager.UserID)
+		}
+	}
+
+	for _, managerAction := range archive.ManagerActions {
+		if !groups[managerAction.GroupID] {
+			return fmt.Errorf("Manager action #%d references unknown group #%d", managerAction.ID, managerAction.GroupID)
+		}
+
+		if managerAction.ActorID > 0 && !users[managerAction.ActorID] {
+			return fmt.Errorf("Manager action #%d references unknown actor #%d", managerAction.ID, managerAction.ActorID)
+		}
+
+		if managerAction.UserID > 0 && !users[managerAction.UserID] {
+			return fmt.Errorf("Manager action #%d references unknown user #%d", managerAction.ID, managerAction.UserID)
+		}
+	}
+
+	groupApplications := make(map[int64]bool)
+	for _, groupApplication := range archive.GroupApplications {
+		err := addID(groupApplications, "group application", groupApplication.ID)
+		if err != nil {
+			return err
+		}
+
+		if !groups[groupApplication.GroupID] {
+			return fmt.Errorf("Group application #%d references unknown group #%d", groupApplication.ID, groupApplication.GroupID)
+		}
+
+		if !users[groupApplication.UserID] {
+			return fmt.Errorf("Group application #%d references unknown user #%d", groupApplication.ID, groupApplication.UserID)
+		}
+
+		if groupApplication.DeciderID > 0 && !users[groupApplication.DeciderID] {
+			return fmt.Errorf("Group application #%d references unknown decider #%d", groupApplication.ID, groupApplication.DeciderID)
+		}
+	}
+
+	applications := make(map[int64]bool)
+	for _, application := range archive.Applications {
+		err := addID(applications, "application", application.ID)
+		if err != nil {
+			return err
+		}
+
+		if !users[application.MaintainerID] {
+			return fmt.Errorf("Application #%d references unknown maintainer #%d", application.ID, application.MaintainerID)
+		}
+	}
+
+	for _, role := range archive.Roles {
+		if role.ApplicationID.Valid && !applications[role.ApplicationID.Int64] {
+			return fmt.Errorf("Role #%d references unknown application #%d", role.ID, role.ApplicationID.Int64)
+		}
+	}
+
+	for _, csrfFailure := range archive.CSRFFailures {
+		if csrfFailure.UserID > 0 && !users[csrfFailure.UserID] {
+			return fmt.Errorf("CSRF failure #%d references unknown user #%d", csrfFailure.ID, csrfFailure.UserID)
+		}
+	}
+
+	return nil
+}
+
+// addID adds the given ID to the set of known IDs, returning an error if it is invalid or already known
+func addID(ids map[int64]bool, entity string, id int64) error {
+	if id <= 0 {
+		return fmt.Errorf("Invalid %s ID #%d", entity, id)
+	}
+
+	if ids[id] {
+		return fmt.Errorf("Duplicate %s ID #%d", entity, id)
+	}
+
+	ids[id] = true
+
+	return nil
+}
@@ -0,0 +1,428 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database/memory"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+// createDatabase returns a connected, empty in-memory database
+func createDatabase() (*memory.DatabaseConnection, error) {
+	db := &memory.DatabaseConnection{
+		Config: &misc.Configuration{
+			DatabaseType: 0,
+			DebugLevel:   1,
+		},
+	}
+
+	err := db.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// populateDatabase fills the given database with a deleted and an active user, both having a group, roles, an account, a character and an application. The role of the active user's group is
+// scoped to their application and implies the other one as well as a role scoped to the deleted user's application,
+// the active user's group contains the other one and both groups add members of the alliance automatically. Both users manage their own group, the deleted one having added the active user to theirs
+// and approved their application to the other group. Both groups require an application
+func populateDatabase(db *memory.DatabaseConnection) error {
+	ctx := context.Background()
+
+	alliance, err := db.SaveAlliance(ctx, models.NewAlliance("Test Alliance Please Ignore", "TEST", 1, 1, true))
+	if err != nil {
+		return err
+	}
+
+	corporation := models.NewCorporation("Test Corp Please Ignore", "TEST", 1, 1, zero.IntFrom(0), zero.StringFrom(""), true)
+	corporation.AllianceID = zero.IntFrom(alliance.ID)
+
+	corporation, err = db.SaveCorporation(ctx, corporation)
+	if err != nil {
+		return err
+	}
+
+	for i, username := range []string{"deleted", "test1"} {
+		group := models.NewGroup("Group "+username, true)
+		group.Visibility = models.GroupVisibilityApplication
+		group.GroupRoles = append(group.GroupRoles, models.NewGroupRole(-1, models.NewRole("group."+username, true, false), false, true))
+
+		group, err = db.SaveGroup(ctx, group)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.SaveMembershipRule(ctx, models.NewMembershipRule(group.ID, models.MembershipRuleTypeAlliance, alliance.ID))
+		if err != nil {
+			return err
+		}
+
+		user := models.NewUser(username, "$2a$10$hash"+username, username+"@example.com", true, true)
+		user.UserRoles = append(user.UserRoles, models.NewUserRole(-1, group.GroupRoles[0].Role, false, false))
+		user.UserRoles[0].Validity = models.NewValidity(time.Time{}, 24*time.Hour)
+		user.Groups = append(user.Groups, group)
+		user.GroupValidities[group.ID] = models.NewValidity(time.Now().Add(-time.Hour), 0)
+		user.AutoAddedGroups[group.ID] = true
+
+		account := models.NewAccount(-1, int64(i+1), "vcode", 0, true)
+		account.Characters = append(account.Characters, models.NewCharacter(-1, corporation.ID, "Character "+username, int64(i+1), true, true))
+		user.Accounts = append(user.Accounts, account)
+
+		user, err = db.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.SaveGroupManager(ctx, models.NewGroupManager(group.ID, user.ID))
+		if err != nil {
+			return err
+		}
+
+		application := models.NewApplication("App "+username, user.ID, "secret"+username, "http://localhost", true)
+		application.RoleScopes = "group.*"
+
+		application, err = db.SaveApplication(ctx, application)
+		if err != nil {
+			return err
+		}
+
+		role := models.NewRole("app."+username, true, false)
+		if i > 0 {
+			role, err = db.LoadRole(ctx, group.GroupRoles[0].Role.ID)
+			if err != nil {
+				return err
+			}
+
+			role.Policy = "user.verifiedEmail"
+		}
+
+		role.ApplicationID = zero.IntFrom(application.ID)
+
+		_, err = db.SaveRole(ctx, role)
+		if err != nil {
+			return err
+		}
+
+		err = db.SaveCSRFFailure(ctx, &models.CSRFFailure{UserID: user.ID, Request: "{}"})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.SaveRoleImplications(ctx, 3, []int64{1, 2})
+	if err != nil {
+		return err
+	}
+
+	err = db.SaveSubGroups(ctx, 2, []int64{1})
+	if err != nil {
+		return err
+	}
+
+	err = db.SaveManagerAction(ctx, models.NewManagerAction(2, 1, 2, models.ManagerActionTypeAddMember))
+	if err != nil {
+		return err
+	}
+
+	groupApplication := models.NewGroupApplication(1, 2, "Let me in")
+	groupApplication.Decide(1, true, "Welcome")
+
+	_, err = db.SaveGroupApplication(ctx, groupApplication)
+	if err != nil {
+		return err
+	}
+
+	err = db.SaveLoginAttempt(ctx, models.NewLoginAttempt("test1", "127.0.0.1", "goconvey", true))
+	if err != nil {
+		return err
+	}
+
+	err = db.SaveLoginAttemptSummary(ctx, &models.LoginAttemptSummary{Day: "2016-01-01", Failed: 2, Successful: 1})
+	if err != nil {
+		return err
+	}
+
+	return db.DeleteUser(ctx, 1, 2)
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Exporting a populated database", t, func() {
+		source, err := createDatabase()
+		So(err, ShouldBeNil)
+		So(populateDatabase(source), ShouldBeNil)
+
+		archive, err := Export(ctx, source)
+		So(err, ShouldBeNil)
+		So(archive.Validate(), ShouldBeNil)
+
+		Convey("Should skip trashed entries and everything belonging to them", func() {
+			So(len(archive.Alliances), ShouldEqual, 1)
+			So(len(archive.Users), ShouldEqual, 1)
+			So(archive.Users[0].Password, ShouldEqual, "$2a$10$hashtest1")
+			So(len(archive.Groups), ShouldEqual, 2)
+			So(len(archive.GroupNestings), ShouldEqual, 1)
+			So(len(archive.Roles), ShouldEqual, 2)
+			So(len(archive.RoleImplications), ShouldEqual, 1)
+			So(len(archive.GroupRoles), ShouldEqual, 2)
+			So(len(archive.MembershipRules), ShouldEqual, 2)
+			So(len(archive.UserRoles), ShouldEqual, 1)
+			So(len(archive.Memberships), ShouldEqual, 1)
+			So(len(archive.GroupManagers), ShouldEqual, 1)
+			So(len(archive.ManagerActions), ShouldEqual, 1)
+			So(archive.ManagerActions[0].ActorID, ShouldEqual, -1)
+			So(len(archive.GroupApplications), ShouldEqual, 1)
+			So(archive.GroupApplications[0].DeciderID, ShouldEqual, -1)
+			So(archive.Memberships[0].AutoAdded, ShouldBeTrue)
+			So(len(archive.Accounts), ShouldEqual, 1)
+			So(len(archive.Characters), ShouldEqual, 1)
+			So(len(archive.Applications), ShouldEqual, 1)
+			So(archive.Applications[0].Secret, ShouldEqual, "secrettest1")
+			So(archive.Applications[0].RoleScopes, ShouldEqual, "group.*")
+			So(archive.Roles[0].IsGlobal(), ShouldBeTrue)
+			So(archive.Roles[1].ApplicationID.Int64, ShouldEqual, archive.Applications[0].ID)
+			So(archive.Roles[1].Policy, ShouldEqual, "user.verifiedEmail")
+			So(len(archive.LoginAttempts), ShouldEqual, 1)
+			So(len(archive.LoginAttemptSummaries), ShouldEqual, 1)
+			So(len(archive.CSRFFailures), ShouldEqual, 2)
+			So(archive.CSRFFailures[0].UserID, ShouldEqual, -1)
+		})
+
+		Convey("Should survive being written and read again", func() {
+			var buffer bytes.Buffer
+
+			So(archive.Write(&buffer), ShouldBeNil)
+
+			read, err := Read(&buffer)
+			So(err, ShouldBeNil)
+			So(read.Users[0].Password, ShouldEqual, archive.Users[0].Password)
+			So(read.LoginAttempts[0].Timestamp.Equal(archive.LoginAttempts[0].Timestamp), ShouldBeTrue)
+		})
+
+		Convey("Importing it into a non-empty database should remap all IDs consistently", func() {
+			target, err := createDatabase()
+			So(err, ShouldBeNil)
+
+			_, err = target.SaveRole(ctx, models.NewRole("existing.role", true, false))
+			So(err, ShouldBeNil)
+
+			_, err = target.SaveAlliance(ctx, models.NewAlliance("Existing Alliance", "EXIST", 2, 2, true))
+			So(err, ShouldBeNil)
+
+			_, err = target.SaveGroup(ctx, models.NewGroup("Existing Group", true))
+			So(err, ShouldBeNil)
+
+			err = Import(ctx, target, archive)
+			So(err, ShouldBeNil)
+
+			user, err := target.LoadUserFromUsername(ctx, "test1")
+			So(err, ShouldBeNil)
+			So(user.Password, ShouldEqual, "$2a$10$hashtest1")
+			So(len(user.Groups), ShouldEqual, 1)
+			So(user.Groups[0].Name, ShouldEqual, "Group test1")
+			So(user.Groups[0].GroupRoles[0].Role.Name, ShouldEqual, "group.test1")
+			So(len(user.Groups[0].GroupRoles[0].Role.ImpliedRoles), ShouldEqual, 1)
+			So(user.Groups[0].GroupRoles[0].Role.ImpliedRoles[0].Name, ShouldEqual, "group.deleted")
+			So(len(user.Groups[0].SubGroups), ShouldEqual, 1)
+			So(user.Groups[0].SubGroups[0].Name, ShouldEqual, "Group deleted")
+			So(len(user.UserRoles), ShouldEqual, 1)
+			So(user.UserRoles[0].Role.ID, ShouldEqual, user.Groups[0].GroupRoles[0].Role.ID)
+			So(user.UserRoles[0].Validity.ValidUntil, ShouldNotBeNil)
+			So(user.GroupValidity(user.Groups[0].ID).ValidFrom, ShouldNotBeNil)
+			So(user.IsGroupAutoAdded(user.Groups[0].ID), ShouldBeTrue)
+			So(user.Accounts[0].Characters[0].Name, ShouldEqual, "Character test1")
+
+			corporation, err := target.LoadCorporation(ctx, user.Accounts[0].Characters[0].CorporationID)
+			So(err, ShouldBeNil)
+
+			alliance, err := target.LoadAlliance(ctx, corporation.AllianceID.Int64)
+			So(err, ShouldBeNil)
+			So(alliance.Name, ShouldEqual, "Test Alliance Please Ignore")
+
+			membershipRules, err := target.LoadAllMembershipRulesForGroup(ctx, user.Groups[0].ID)
+			So(err, ShouldBeNil)
+			So(len(membershipRules), ShouldEqual, 1)
+			So(membershipRules[0].TargetID, ShouldEqual, alliance.ID)
+
+			managedGroups, err := target.LoadAllManagedGroupsForUser(ctx, user.ID)
+			So(err, ShouldBeNil)
+			So(len(managedGroups), ShouldEqual, 1)
+			So(managedGroups[0].ID, ShouldEqual, user.Groups[0].ID)
+
+			managerActions, err := target.LoadAllManagerActionsForGroup(ctx, user.Groups[0].ID)
+			So(err, ShouldBeNil)
+			So(len(managerActions), ShouldEqual, 1)
+			So(managerActions[0].UserID, ShouldEqual, user.ID)
+			So(managerActions[0].ActorID, ShouldEqual, -1)
+
+			So(user.Groups[0].Visibility, ShouldEqual, models.GroupVisibilityApplication)
+
+			groupApplications, err := target.LoadAllGroupApplicationsForUser(ctx, user.ID)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 1)
+			So(groupApplications[0].GroupID, ShouldEqual, user.Groups[0].SubGroups[0].ID)
+			So(groupApplications[0].Status, ShouldEqual, models.GroupApplicationStatusApproved)
+			So(groupApplications[0].DeciderID, ShouldEqual, -1)
+
+			applications, err := target.LoadAllApplicationsForUser(ctx, user.ID)
+			So(err, ShouldBeNil)
+			So(len(applications), ShouldEqual, 1)
+			So(applications[0].Secret, ShouldEqual, "secrettest1")
+			So(applications[0].RoleScopes, ShouldEqual, "group.*")
+			So(user.Groups[0].GroupRoles[0].Role.ApplicationID.Int64, ShouldEqual, applications[0].ID)
+			So(user.Groups[0].GroupRoles[0].Role.Policy, ShouldEqual, "user.verifiedEmail")
+			So(user.Groups[0].GroupRoles[0].Role.ImpliedRoles[0].IsGlobal(), ShouldBeTrue)
+
+			csrfFailures, err := target.LoadAllCSRFFailures(ctx)
+			So(err, ShouldBeNil)
+			So(csrfFailures[1].UserID, ShouldEqual, user.ID)
+
+			loginAttempts, err := target.LoadAllLoginAttempts(ctx)
+			So(err, ShouldBeNil)
+			So(loginAttempts[0].Timestamp.Equal(archive.LoginAttempts[0].Timestamp), ShouldBeTrue)
+
+			summaries, err := target.LoadAllLoginAttemptSummaries(ctx)
+			So(err, ShouldBeNil)
+			So(summaries, ShouldResemble, archive.LoginAttemptSummaries)
+		})
+
+		Convey("Importing roles sharing their name with a role of an application namespace should keep both", func() {
+			archive.Roles = append(archive.Roles, models.NewRole("group.test1", true, false))
+			archive.Roles[2].ID = 99
+
+			target, err := createDatabase()
+			So(err, ShouldBeNil)
+			So(Import(ctx, target, archive), ShouldBeNil)
+
+			roles, err := target.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 3)
+			So(roles[1].Name, ShouldEqual, "group.test1")
+			So(roles[1].IsGlobal(), ShouldBeTrue)
+			So(roles[2].Name, ShouldEqual, "group.test1")
+			So(roles[2].IsGlobal(), ShouldBeFalse)
+		})
+
+		Convey("Importing it twice should fail without writing anything the second time", func() {
+			target, err := createDatabase()
+			So(err, ShouldBeNil)
+
+			So(Import(ctx, target, archive), ShouldBeNil)
+			So(Import(ctx, target, archive), ShouldNotBeNil)
+
+			users, err := target.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(len(users), ShouldEqual, 1)
+
+			loginAttempts, err := target.LoadAllLoginAttempts(ctx)
+			So(err, ShouldBeNil)
+			So(len(loginAttempts), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestArchiveValidate(t *testing.T) {
+	Convey("Validating an archive", t, func() {
+		archive := &Archive{
+			FormatVersion: FormatVersion,
+			Users:         []*User{{ID: 1, Username: "test1"}},
+			Groups:        []*models.Group{{ID: 1, Name: "Test Group"}},
+			Roles:         []*models.Role{{ID: 1, Name: "ping.all"}},
+		}
+
+		Convey("Should accept consistent references", func() {
+			archive.Memberships = []*Membership{{UserID: 1, GroupID: 1}}
+			archive.UserRoles = []*RoleAssignment{{ID: 1, OwnerID: 1, RoleID: 1}}
+
+			So(archive.Validate(), ShouldBeNil)
+		})
+
+		Convey("Should reject duplicate IDs", func() {
+			archive.Users = append(archive.Users, &User{ID: 1, Username: "test2"})
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject references to unknown entries", func() {
+			archive.GroupRoles = []*RoleAssignment{{ID: 1, OwnerID: 1, RoleID: 2}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject characters without an account", func() {
+			archive.Corporations = []*models.Corporation{{ID: 1}}
+			archive.Characters = []*models.Character{{ID: 1, AccountID: 1, CorporationID: 1}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject corporations referencing an unknown alliance", func() {
+			archive.Corporations = []*models.Corporation{{ID: 1, AllianceID: zero.IntFrom(1)}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject membership rules referencing an unknown corporation", func() {
+			archive.MembershipRules = []*models.MembershipRule{{ID: 1, GroupID: 1, Type: models.MembershipRuleTypeCorporation, TargetID: 1}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject role implications referencing an unknown role", func() {
+			archive.RoleImplications = []*models.RoleImplication{{ID: 1, RoleID: 1, ImpliedRoleID: 2}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject group nestings referencing an unknown group", func() {
+			archive.GroupNestings = []*models.GroupNesting{{ID: 1, GroupID: 1, SubGroupID: 2}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject group managers referencing an unknown user", func() {
+			archive.GroupManagers = []*models.GroupManager{{ID: 1, GroupID: 1, UserID: 2}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject group applications referencing an unknown group", func() {
+			archive.GroupApplications = []*models.GroupApplication{{ID: 1, GroupID: 2, UserID: 1}}
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject groups with an unknown visibility", func() {
+			archive.Groups[0].Visibility = "secret"
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject roles referencing an unknown application", func() {
+			archive.Roles[0].ApplicationID = zero.IntFrom(1)
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject roles with an invalid policy", func() {
+			archive.Roles[0].Policy = "user.verifiedEmail and"
+
+			So(archive.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("Should reject unsupported format versions when reading", func() {
+			_, err := Read(bytes.NewBufferString(`{"formatVersion": 2}`))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
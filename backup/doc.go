@@ -0,0 +1,2 @@
+// Package backup provides exporting and importing all data stored by eveauth as a versioned JSON archive, independent of the database backend used.
+package backup
@@ -0,0 +1,327 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// Export loads all data from the given database into a new archive, returning an error if any query failed.
+// Entries kept in the trash are not exported, neither are accounts, characters and applications belonging to them
+func Export(ctx context.Context, db database.Connection) (*Archive, error) {
+	archive := &Archive{
+		FormatVersion:         FormatVersion,
+		CreatedAt:             time.Now(),
+		Alliances:             make([]*models.Alliance, 0),
+		Corporations:          make([]*models.Corporation, 0),
+		Roles:                 make([]*models.Role, 0),
+		RoleImplications:      make([]*models.RoleImplication, 0),
+		Groups:                make([]*models.Group, 0),
+		GroupNestings:         make([]*models.GroupNesting, 0),
+		GroupRoles:            make([]*RoleAssignment, 0),
+		MembershipRules:       make([]*models.MembershipRule, 0),
+		Users:                 make([]*User, 0),
+		UserRoles:             make([]*RoleAssignment, 0),
+		Memberships:           make([]*Membership, 0),
+		GroupManagers:         make([]*models.GroupManager, 0),
+		ManagerActions:        make([]*models.ManagerAction, 0),
+		GroupApplications:     make([]*models.GroupApplication, 0),
+		Accounts:              make([]*models.Account, 0),
+		Characters:            make([]*models.Character, 0),
+		Applications:          make([]*Application, 0),
+		LoginAttempts:         make([]*models.LoginAttempt, 0),
+		LoginAttemptSummaries: make([]*models.LoginAttemptSummary, 0),
+		CSRFFailures:          make([]*models.CSRFFailure, 0),
+	}
+
+	alliances, err := db.LoadAllAlliances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.Alliances = append(archive.Alliances, alliances...)
+
+	corporations, err := db.LoadAllCorporations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.Corporations = append(archive.Corporations, corporations...)
+
+	roles, err := db.LoadAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		role.ImpliedRoles = nil
+		archive.Roles = append(archive.Roles, role)
+	}
+
+	roleImplications, err := db.LoadAllRoleImplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.RoleImplications = append(archive.RoleImplications, roleImplications...)
+
+	groups, err := db.LoadAllGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := make(map[int64]bool)
+
+	for _, group := range groups {
+		groupIDs[group.ID] = true
+
+		group.GroupRoles = nil
+		group.SubGroups = nil
+		archive.Groups = append(archive.Groups, group)
+	}
+
+	groupNestings, err := db.LoadAllGroupNestings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.GroupNestings = append(archive.GroupNestings, groupNestings...)
+
+	groupRoles, err := db.LoadAllGroupRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, groupRole := range groupRoles {
+		archive.GroupRoles = append(archive.GroupRoles, &RoleAssignment{
+			ID:        groupRole.ID,
+			OwnerID:   groupRole.GroupID,
+			RoleID:    groupRole.Role.ID,
+			AutoAdded: groupRole.AutoAdded,
+			Granted:   groupRole.Granted,
+		})
+	}
+
+	membershipRules, err := db.LoadAllMembershipRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.MembershipRules = append(archive.MembershipRules, membershipRules...)
+
+	users, err := db.LoadAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make(map[int64]bool)
+
+	for _, user := range users {
+		userIDs[user.ID] = true
+
+		archive.Users = append(archive.Users, &User{
+			ID:            user.ID,
+			Username:      user.Username,
+			Password:      user.Password,
+			Email:         user.Email,
+			VerifiedEmail: user.VerifiedEmail,
+			Active:        user.Active,
+		})
+
+		for _, group := range user.Groups {
+			archive.Memberships = append(archive.Memberships, &Membership{
+				UserID:    user.ID,
+				GroupID:   group.ID,
+				Validity:  user.GroupValidity(group.ID),
+				AutoAdded: user.IsGroupAutoAdded(group.ID),
+			})
+		}
+	}
+
+	userRoles, err := db.LoadAllUserRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userRole := range userRoles {
+		archive.UserRoles = append(archive.UserRoles, &RoleAssignment{
+			ID:        userRole.ID,
+			OwnerID:   userRole.UserID,
+			RoleID:    userRole.Role.ID,
+			AutoAdded: userRole.AutoAdded,
+			Granted:   userRole.Granted,
+			Validity:  userRole.Validity,
+		})
+	}
+
+	groupManagers, err := db.LoadAllGroupManagers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.GroupManagers = append(archive.GroupManagers, groupManagers...)
+
+	managerActions, err := db.LoadAllManagerActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Actions performed on purged groups are skipped, purged users are replaced like the ones of CSRF failures
+	for _, managerAction := range managerActions {
+		if !groupIDs[managerAction.GroupID] {
+			continue
+		}
+
+		if managerAction.ActorID > 0 && !userIDs[managerAction.ActorID] {
+			managerAction.ActorID = -1
+		}
+
+		if managerAction.UserID > 0 && !userIDs[managerAction.UserID] {
+			managerAction.UserID = -1
+		}
+
+		archive.ManagerActions = append(archive.ManagerActions, managerAction)
+	}
+
+	groupApplications, err := db.LoadAllGroupApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decisions of purged users are kept, replacing the decider like the actors of manager actions
+	for _, groupApplication := range groupApplications {
+		if groupApplication.DeciderID > 0 && !userIDs[groupApplication.DeciderID] {
+			groupApplication.DeciderID = -1
+		}
+
+		archive.GroupApplications = append(archive.GroupApplications, groupApplication)
+	}
+
+	accounts, err := db.LoadAllAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIDs := make(map[int64]bool)
+
+	for _, account := range accounts {
+		if !userIDs[account.UserID] {
+			continue
+		}
+
+		accountIDs[account.ID] = true
+
+		account.Characters = nil
+		archive.Accounts = append(archive.Accounts, account)
+	}
+
+	characters, err := db.LoadAllCharacters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, character := range characters {
+		if !accountIDs[character.AccountID] {
+			continue
+		}
+
+		archive.Characters = append(archive.Characters, character)
+	}
+
+	applications, err := db.LoadAllApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, application := range applications {
+		if !userIDs[application.MaintainerID] {
+			continue
+		}
+
+		archive.Applications = append(archive.Applications, &Application{
+			ID:           application.ID,
+			Name:         application.Name,
+			MaintainerID: application.MaintainerID,
+			Secret:       application.Secret,
+			Callback:     application.Callback,
+			Active:       application.Active,
+			RoleScopes:   application.RoleScopes,
+		})
+	}
+
+	exportedApplications := make(map[int64]bool)
+	for _, application := range archive.Applications {
+		exportedApplications[application.ID] = true
+	}
+
+	// Roles of applications not being exported are skipped together with their implications and assignments, matching what happens once the application is purged
+	roleIDs := make(map[int64]bool)
+	exportedRoles := make([]*models.Role, 0)
+
+	for _, role := range archive.Roles {
+		if role.ApplicationID.Valid && !exportedApplications[role.ApplicationID.Int64] {
+			continue
+		}
+
+		roleIDs[role.ID] = true
+		exportedRoles = append(exportedRoles, role)
+	}
+
+	archive.Roles = exportedRoles
+
+	exportedRoleImplications := make([]*models.RoleImplication, 0)
+
+	for _, roleImplication := range archive.RoleImplications {
+		if roleIDs[roleImplication.RoleID] && roleIDs[roleImplication.ImpliedRoleID] {
+			exportedRoleImplications = append(exportedRoleImplications, roleImplication)
+		}
+	}
+
+	archive.RoleImplications = exportedRoleImplications
+	archive.GroupRoles = filterRoleAssignments(archive.GroupRoles, roleIDs)
+	archive.UserRoles = filterRoleAssignments(archive.UserRoles, roleIDs)
+
+	loginAttempts, err := db.LoadAllLoginAttempts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.LoginAttempts = append(archive.LoginAttempts, loginAttempts...)
+
+	loginAttemptSummaries, err := db.LoadAllLoginAttemptSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.LoginAttemptSummaries = append(archive.LoginAttemptSummaries, loginAttemptSummaries...)
+
+	csrfFailures, err := db.LoadAllCSRFFailures(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, csrfFailure := range csrfFailures {
+		if csrfFailure.UserID > 0 && !userIDs[csrfFailure.UserID] {
+			csrfFailure.UserID = -1
+		}
+
+		archive.CSRFFailures = append(archive.CSRFFailures, csrfFailure)
+	}
+
+	return archive, nil
+}
+
+// filterRoleAssignments returns the given role assignments referencing one of the given role IDs
+func filterRoleAssignments(assignments []*RoleAssignment, roleIDs map[int64]bool) []*RoleAssignment {
+	filtered := make([]*RoleAssignment, 0)
+
+	for _, assignment := range assignments {
+		if roleIDs[assignment.RoleID] {
+			filtered = append(filtered, assignment)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,335 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+// Import validates the given archive and writes all of its entries to the database within a single transaction, returning an error if the archive is invalid or any query failed.
+// New IDs are assigned by the database, references between entries are remapped accordingly
+func Import(ctx context.Context, db database.Connection, archive *Archive) error {
+	err := archive.Validate()
+	if err != nil {
+		return err
+	}
+
+	return db.WithTx(ctx, func(tx database.Connection) error {
+		return importArchive(ctx, tx, archive)
+	})
+}
+
+// importArchive performs the queries required by Import, expecting to be run within a transaction
+func importArchive(ctx context.Context, db database.Connection, archive *Archive) error {
+	allianceIDs := make(map[int64]int64)
+
+	for _, alliance := range archive.Alliances {
+		all, err := db.SaveAlliance(ctx, models.NewAlliance(alliance.Name, alliance.Ticker, alliance.EVEAllianceID, alliance.ExecutorCorpID, alliance.Active))
+		if err != nil {
+			return err
+		}
+
+		allianceIDs[alliance.ID] = all.ID
+	}
+
+	corporationIDs := make(map[int64]int64)
+
+	for _, corporation := range archive.Corporations {
+		corp := models.NewCorporation(corporation.Name, corporation.Ticker, corporation.EVECorporationID, corporation.CEOID, corporation.APIKeyID, corporation.APIvCode, corporation.Active)
+		if corporation.AllianceID.Valid {
+			corp.AllianceID = zero.IntFrom(allianceIDs[corporation.AllianceID.Int64])
+		}
+
+		corp, err := db.SaveCorporation(ctx, corp)
+		if err != nil {
+			return err
+		}
+
+		corporationIDs[corporation.ID] = corp.ID
+	}
+
+	roles := make(map[int64]*models.Role)
+	namespacedRoleIDs := make(map[int64]bool)
+
+	for _, role := range archive.Roles {
+		// Role names only have to be unique within their namespace, roles of applications are thus imported together with the applications
+		if role.ApplicationID.Valid {
+			namespacedRoleIDs[role.ID] = true
+			continue
+		}
+
+		r := models.NewRole(role.Name, role.Active, role.Locked)
+		r.Policy = role.Policy
+
+		r, err := db.SaveRole(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		// Group and user roles save their role as well, sharing the model keeps its version current
+		roles[role.ID] = r
+	}
+
+	groups := make(map[int64]*models.Group)
+
+	for _, group := range archive.Groups {
+		g := models.NewGroup(group.Name, group.Active)
+		if len(group.Visibility) > 0 {
+			g.Visibility = group.Visibility
+		}
+
+		for _, groupRole := range archive.GroupRoles {
+			if groupRole.OwnerID == group.ID && !namespacedRoleIDs[groupRole.RoleID] {
+				g.GroupRoles = append(g.GroupRoles, models.NewGroupRole(-1, roles[groupRole.RoleID], groupRole.AutoAdded, groupRole.Granted))
+			}
+		}
+
+		g, err := db.SaveGroup(ctx, g)
+		if err != nil {
+			return err
+		}
+
+		groups[group.ID] = g
+	}
+
+	for _, membershipRule := range archive.MembershipRules {
+		targetID := corporationIDs[membershipRule.TargetID]
+		if membershipRule.Type == models.MembershipRuleTypeAlliance {
+			targetID = allianceIDs[membershipRule.TargetID]
+		}
+
+		_, err := db.SaveMembershipRule(ctx, models.NewMembershipRule(groups[membershipRule.GroupID].ID, membershipRule.Type, targetID))
+		if err != nil {
+			return err
+		}
+	}
+
+	userIDs := make(map[int64]int64)
+
+	for _, user := range archive.Users {
+		u := models.NewUser(user.Username, user.Password, user.Email, user.VerifiedEmail, user.Active)
+
+		for _, membership := range archive.Memberships {
+			if membership.UserID != user.ID {
+				continue
+			}
+
+			group := groups[membership.GroupID]
+			u.Groups = append(u.Groups, group)
+
+			if membership.Validity.IsLimited() {
+				u.GroupValidities[group.ID] = membership.Validity
+			}
+
+			if membership.AutoAdded {
+				u.AutoAddedGroups[group.ID] = true
+			}
+		}
+
+		for _, userRole := range archive.UserRoles {
+			if userRole.OwnerID == user.ID && !namespacedRoleIDs[userRole.RoleID] {
+				ur := models.NewUserRole(-1, roles[userRole.RoleID], userRole.AutoAdded, userRole.Granted)
+				ur.Validity = userRole.Validity
+
+				u.UserRoles = append(u.UserRoles, ur)
+			}
+		}
+
+		for _, account := range archive.Accounts {
+			if account.UserID != user.ID {
+				continue
+			}
+
+			acc := models.NewAccount(-1, account.APIKeyID, account.APIvCode, account.APIAccessMask, account.Active)
+
+			for _, character := range archive.Characters {
+				if character.AccountID == account.ID {
+					acc.Characters = append(acc.Characters, models.NewCharacter(-1, corporationIDs[character.CorporationID], character.Name, character.EVECharacterID, character.DefaultCharacter, character.Active))
+				}
+			}
+
+			u.Accounts = append(u.Accounts, acc)
+		}
+
+		u, err := db.SaveUser(ctx, u)
+		if err != nil {
+			return err
+		}
+
+		userIDs[user.ID] = u.ID
+	}
+
+	// Saving nestings modifies the containing group, so they are imported after all users sharing the group models have been saved
+	for _, group := range archive.Groups {
+		var subGroupIDs []int64
+
+		for _, groupNesting := range archive.GroupNestings {
+			if groupNesting.GroupID == group.ID {
+				subGroupIDs = append(subGroupIDs, groups[groupNesting.SubGroupID].ID)
+			}
+		}
+
+		if len(subGroupIDs) == 0 {
+			continue
+		}
+
+		err := db.SaveSubGroups(ctx, groups[group.ID].ID, subGroupIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, groupManager := range archive.GroupManagers {
+		_, err := db.SaveGroupManager(ctx, models.NewGroupManager(groups[groupManager.GroupID].ID, userIDs[groupManager.UserID]))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, managerAction := range archive.ManagerActions {
+		action := *managerAction
+		action.ID = -1
+		action.GroupID = groups[action.GroupID].ID
+
+		if action.ActorID > 0 {
+			action.ActorID = userIDs[action.ActorID]
+		}
+
+		if action.UserID > 0 {
+			action.UserID = userIDs[action.UserID]
+		}
+
+		err := db.SaveManagerAction(ctx, &action)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, groupApplication := range archive.GroupApplications {
+		application := *groupApplication
+		application.ID = -1
+		application.GroupID = groups[application.GroupID].ID
+		application.UserID = userIDs[application.UserID]
+
+		if application.DeciderID > 0 {
+			application.DeciderID = userIDs[application.DeciderID]
+		}
+
+		_, err := db.SaveGroupApplication(ctx, &application)
+		if err != nil {
+			return err
+		}
+	}
+
+	applicationIDs := make(map[int64]int64)
+
+	for _, application := range archive.Applications {
+		app := models.NewApplication(application.Name, userIDs[application.MaintainerID], application.Secret, application.Callback, application.Active)
+		app.RoleScopes = application.RoleScopes
+
+		app, err := db.SaveApplication(ctx, app)
+		if err != nil {
+			return err
+		}
+
+		applicationIDs[application.ID] = app.ID
+	}
+
+	for _, role := range archive.Roles {
+		if !role.ApplicationID.Valid {
+			continue
+		}
+
+		r := models.NewRole(role.Name, role.Active, role.Locked)
+		r.Policy = role.Policy
+		r.ApplicationID = zero.IntFrom(applicationIDs[role.ApplicationID.Int64])
+
+		r, err := db.SaveRole(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		roles[role.ID] = r
+	}
+
+	for _, groupRole := range archive.GroupRoles {
+		if !namespacedRoleIDs[groupRole.RoleID] {
+			continue
+		}
+
+		_, err := db.SaveGroupRole(ctx, models.NewGroupRole(groups[groupRole.OwnerID].ID, roles[groupRole.RoleID], groupRole.AutoAdded, groupRole.Granted))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, userRole := range archive.UserRoles {
+		if !namespacedRoleIDs[userRole.RoleID] {
+			continue
+		}
+
+		ur := models.NewUserRole(userIDs[userRole.OwnerID], roles[userRole.RoleID], userRole.AutoAdded, userRole.Granted)
+		ur.Validity = userRole.Validity
+
+		_, err := db.SaveUserRole(ctx, ur)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Saving implications modifies the implying role, so they are imported after all group and user roles sharing the role models have been saved
+	for _, role := range archive.Roles {
+		var impliedRoleIDs []int64
+
+		for _, roleImplication := range archive.RoleImplications {
+			if roleImplication.RoleID == role.ID {
+				impliedRoleIDs = append(impliedRoleIDs, roles[roleImplication.ImpliedRoleID].ID)
+			}
+		}
+
+		if len(impliedRoleIDs) == 0 {
+			continue
+		}
+
+		err := db.SaveRoleImplications(ctx, roles[role.ID].ID, impliedRoleIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, loginAttempt := range archive.LoginAttempts {
+		attempt := *loginAttempt
+		attempt.ID = -1
+
+		err := db.SaveLoginAttempt(ctx, &attempt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, loginAttemptSummary := range archive.LoginAttemptSummaries {
+		err := db.SaveLoginAttemptSummary(ctx, loginAttemptSummary)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, csrfFailure := range archive.CSRFFailures {
+		failure := *csrfFailure
+		failure.ID = -1
+
+		if failure.UserID > 0 {
+			failure.UserID = userIDs[failure.UserID]
+		}
+
+		err := db.SaveCSRFFailure(ctx, &failure)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
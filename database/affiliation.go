@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// LoadDefaultAffiliation attaches the corporation of the user's default character and the corporation's alliance to the given user,
+// used by the backends while loading users. Corporations or alliances that could not be found are left empty, returning an error if a query failed
+func LoadDefaultAffiliation(ctx context.Context, db Connection, user *models.User) error {
+	user.DefaultCorporation = nil
+	user.DefaultAlliance = nil
+
+	character := user.GetDefaultCharacter()
+	if character == nil {
+		return nil
+	}
+
+	corporation, err := db.LoadCorporation(ctx, character.CorporationID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	user.DefaultCorporation = corporation
+
+	if !corporation.AllianceID.Valid {
+		return nil
+	}
+
+	alliance, err := db.LoadAlliance(ctx, corporation.AllianceID.Int64)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	user.DefaultAlliance = alliance
+
+	return nil
+}
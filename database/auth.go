@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// LoadAuthUser converts the given user to an AuthUser and adds the alliances of the user's characters, returning an error if a query failed.
+// Characters whose corporation or alliance could not be found are exported without an alliance
+func LoadAuthUser(ctx context.Context, db Connection, user *models.User) (*models.AuthUser, error) {
+	return loadAuthUserAlliances(ctx, db, user.ToAuthUser())
+}
+
+// LoadAuthUserForApplication converts the given user to an AuthUser exporting only the roles visible to the given app and adds the alliances of the user's characters,
+// returning an error if a query failed
+func LoadAuthUserForApplication(ctx context.Context, db Connection, user *models.User, application *models.Application) (*models.AuthUser, error) {
+	return loadAuthUserAlliances(ctx, db, user.ToAuthUserForApplication(application))
+}
+
+// loadAuthUserAlliances adds the alliances of the characters to the given AuthUser, returning an error if a query failed
+func loadAuthUserAlliances(ctx context.Context, db Connection, authUser *models.AuthUser) (*models.AuthUser, error) {
+	alliances := make(map[int64]*models.AuthAlliance)
+
+	for _, character := range authUser.Characters {
+		corporation, err := db.LoadCorporation(ctx, character.CorporationID)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !corporation.AllianceID.Valid {
+			continue
+		}
+
+		alliance, ok := alliances[corporation.AllianceID.Int64]
+		if !ok {
+			all, err := db.LoadAlliance(ctx, corporation.AllianceID.Int64)
+			if err == sql.ErrNoRows {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			alliance = all.ToAuthAlliance()
+			alliances[alliance.ID] = alliance
+			authUser.Alliances = append(authUser.Alliances, alliance)
+		}
+
+		character.Alliance = alliance
+	}
+
+	return authUser, nil
+}
@@ -0,0 +1,710 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/migration"
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// Connection wraps a database connection, caching users, groups, roles and corporations loaded by their ID.
+// All modifying calls are forwarded to the wrapped connection and invalidate every cached entry affected by them
+type Connection struct {
+	database.Connection
+
+	ttl  time.Duration
+	lock sync.RWMutex
+	// generation is increased on every invalidation, preventing values loaded before a modification from being cached afterwards
+	generation uint64
+
+	users        *store
+	groups       *store
+	roles        *store
+	corporations *store
+}
+
+// Statistics represents the hit and miss counters of all caches
+type Statistics struct {
+	// Users stores the counters of the user cache
+	Users Counter `json:"users"`
+	// Groups stores the counters of the group cache
+	Groups Counter `json:"groups"`
+	// Roles stores the counters of the role cache
+	Roles Counter `json:"roles"`
+	// Corporations stores the counters of the corporation cache
+	Corporations Counter `json:"corporations"`
+}
+
+// String represents a JSON encoded representation of the statistics
+func (statistics *Statistics) String() string {
+	jsonContent, err := json.Marshal(statistics)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
+
+// NewConnection wraps the given connection, caching entries for the provided duration
+func NewConnection(conn database.Connection, ttl time.Duration) *Connection {
+	c := &Connection{
+		Connection:   conn,
+		ttl:          ttl,
+		users:        newStore(),
+		groups:       newStore(),
+		roles:        newStore(),
+		corporations: newStore(),
+	}
+
+	return c
+}
+
+// Statistics returns the current hit and miss counters of all caches
+func (c *Connection) Statistics() *Statistics {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	statistics := &Statistics{
+		Users:        c.users.counter(),
+		Groups:       c.groups.counter(),
+		Roles:        c.roles.counter(),
+		Corporations: c.corporations.counter(),
+	}
+
+	return statistics
+}
+
+// Flush removes all cached entries
+func (c *Connection) Flush() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+
+	c.users.clear()
+	c.groups.clear()
+	c.roles.clear()
+	c.corporations.clear()
+}
+
+// MigrateUp applies all pending schema migrations and flushes the cache, returning the number of applied migrations or an error if the migration failed
+func (c *Connection) MigrateUp() (int, error) {
+	defer c.Flush()
+
+	return c.Connection.MigrateUp()
+}
+
+// MigrateDown reverts the most recently applied schema migration and flushes the cache, returning the reverted version or an error if the migration failed
+func (c *Connection) MigrateDown() (int, error) {
+	defer c.Flush()
+
+	return c.Connection.MigrateDown()
+}
+
+// MigrationStatus retrieves the current schema version and all pending migrations, returning an error if the query failed
+func (c *Connection) MigrationStatus() (*migration.Status, error) {
+	return c.Connection.MigrationStatus()
+}
+
+// WithTx runs the given function within a single transaction of the wrapped connection.
+// Queries performed within the transaction bypass the cache, which is flushed once the transaction has been committed
+func (c *Connection) WithTx(ctx context.Context, fn func(tx database.Connection) error) error {
+	err := c.Connection.WithTx(ctx, fn)
+	if err != nil {
+		return err
+	}
+
+	c.Flush()
+
+	return nil
+}
+
+// LoadCorporation retrieves the corporation with the given ID from the cache or the database, returning an error if the query failed
+func (c *Connection) LoadCorporation(ctx context.Context, corporationID int64) (*models.Corporation, error) {
+	value, generation, ok := c.lookup(c.corporations, corporationID)
+	if ok {
+		return copyCorporation(value.(*models.Corporation)), nil
+	}
+
+	corporation, err := c.Connection.LoadCorporation(ctx, corporationID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.remember(c.corporations, corporationID, copyCorporation(corporation), generation)
+
+	return corporation, nil
+}
+
+// LoadCorporationNameFromID retrieves the name of the corporation with the given ID from the cache or the database, returning an error if the query failed
+func (c *Connection) LoadCorporationNameFromID(ctx context.Context, corporationID int64) (string, error) {
+	corporation, err := c.LoadCorporation(ctx, corporationID)
+	if err != nil {
+		return "", err
+	}
+
+	return corporation.Name, nil
+}
+
+// LoadRole retrieves the role with the given ID from the cache or the database, returning an error if the query failed
+func (c *Connection) LoadRole(ctx context.Context, roleID int64) (*models.Role, error) {
+	value, generation, ok := c.lookup(c.roles, roleID)
+	if ok {
+		return copyRole(value.(*models.Role)), nil
+	}
+
+	role, err := c.Connection.LoadRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.remember(c.roles, roleID, copyRole(role), generation)
+
+	return role, nil
+}
+
+// LoadGroup retrieves the group (and its associated group roles) with the given ID from the cache or the database, returning an error if the query failed
+func (c *Connection) LoadGroup(ctx context.Context, groupID int64) (*models.Group, error) {
+	value, generation, ok := c.lookup(c.groups, groupID)
+	if ok {
+		return copyGroup(value.(*models.Group)), nil
+	}
+
+	group, err := c.Connection.LoadGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.remember(c.groups, groupID, copyGroup(group), generation)
+
+	return group, nil
+}
+
+// LoadUser retrieves the user (and its associated groups and user roles) with the given ID from the cache or the database, returning an error if the query failed
+func (c *Connection) LoadUser(ctx context.Context, userID int64) (*models.User, error) {
+	value, generation, ok := c.lookup(c.users, userID)
+	if ok {
+		return copyUser(value.(*models.User)), nil
+	}
+
+	user, err := c.Connection.LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.remember(c.users, userID, copyUser(user), generation)
+
+	return user, nil
+}
+
+// SaveAccount saves an account to the database and invalidates the user it belongs to, returning the updated model or an error if the query failed
+func (c *Connection) SaveAccount(ctx context.Context, account *models.Account) (*models.Account, error) {
+	defer c.invalidate(func() {
+		c.users.delete(account.UserID)
+		c.deleteUsersWithAccount(account.ID)
+	})
+
+	return c.Connection.SaveAccount(ctx, account)
+}
+
+// SaveCorporation saves a corporation to the database and invalidates its cached entry as well as all users affiliated with it, returning the updated model or an error if the query failed
+func (c *Connection) SaveCorporation(ctx context.Context, corporation *models.Corporation) (*models.Corporation, error) {
+	defer c.invalidate(func() {
+		c.corporations.delete(corporation.ID)
+		c.deleteUsersWithCorporation(corporation.ID)
+	})
+
+	return c.Connection.SaveCorporation(ctx, corporation)
+}
+
+// SaveAlliance saves an alliance to the database and invalidates all users affiliated with it, returning the updated model or an error if the query failed
+func (c *Connection) SaveAlliance(ctx context.Context, alliance *models.Alliance) (*models.Alliance, error) {
+	defer c.invalidate(func() {
+		c.deleteUsersWithAlliance(alliance.ID)
+	})
+
+	return c.Connection.SaveAlliance(ctx, alliance)
+}
+
+// SaveCharacter saves a character to the database and invalidates the user owning it, returning the updated model or an error if the query failed
+func (c *Connection) SaveCharacter(ctx context.Context, character *models.Character) (*models.Character, error) {
+	defer c.invalidate(func() {
+		c.deleteUsersWithAccount(character.AccountID)
+	})
+
+	return c.Connection.SaveCharacter(ctx, character)
+}
+
+// SaveRole saves a role to the database and invalidates all entries containing it, returning the updated model or an error if the query failed
+func (c *Connection) SaveRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	defer c.invalidate(func() {
+		c.deleteRole(role.ID)
+	})
+
+	return c.Connection.SaveRole(ctx, role)
+}
+
+// SaveRoleImplications replaces the roles implied by the given role and invalidates all entries containing it, returning an error if the query failed
+func (c *Connection) SaveRoleImplications(ctx context.Context, roleID int64, impliedRoleIDs []int64) error {
+	defer c.invalidate(func() {
+		c.deleteRole(roleID)
+	})
+
+	return c.Connection.SaveRoleImplications(ctx, roleID, impliedRoleIDs)
+}
+
+// SaveSubGroups replaces the groups nested in the given group and invalidates all entries containing it, returning an error if the query failed
+func (c *Connection) SaveSubGroups(ctx context.Context, groupID int64, subGroupIDs []int64) error {
+	defer c.invalidate(func() {
+		c.deleteGroup(groupID)
+	})
+
+	return c.Connection.SaveSubGroups(ctx, groupID, subGroupIDs)
+}
+
+// SaveGroupRole saves a group role to the database and invalidates all entries containing it, returning the updated model or an error if the query failed
+func (c *Connection) SaveGroupRole(ctx context.Context, groupRole *models.GroupRole) (*models.GroupRole, error) {
+	defer c.invalidate(func() {
+		c.deleteGroup(groupRole.GroupID)
+
+		if groupRole.Role != nil {
+			c.deleteRole(groupRole.Role.ID)
+		}
+	})
+
+	return c.Connection.SaveGroupRole(ctx, groupRole)
+}
+
+// SaveUserRole saves a user role to the database and invalidates all entries containing it, returning the updated model or an error if the query failed
+func (c *Connection) SaveUserRole(ctx context.Context, userRole *models.UserRole) (*models.UserRole, error) {
+	defer c.invalidate(func() {
+		c.users.delete(userRole.UserID)
+
+		if userRole.Role != nil {
+			c.deleteRole(userRole.Role.ID)
+		}
+	})
+
+	return c.Connection.SaveUserRole(ctx, userRole)
+}
+
+// SaveGroup saves a group to the database and invalidates all entries containing it or its roles, returning the updated model or an error if the query failed
+func (c *Connection) SaveGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	defer c.invalidate(func() {
+		c.deleteGroup(group.ID)
+
+		for _, groupRole := range group.GroupRoles {
+			if groupRole.Role != nil {
+				c.deleteRole(groupRole.Role.ID)
+			}
+		}
+	})
+
+	return c.Connection.SaveGroup(ctx, group)
+}
+
+// SaveUser saves a user to the database and invalidates all entries containing it or its roles, returning the updated model or an error if the query failed
+func (c *Connection) SaveUser(ctx context.Context, user *models.User) (*models.User, error) {
+	defer c.invalidate(func() {
+		c.users.delete(user.ID)
+
+		for _, userRole := range user.UserRoles {
+			if userRole.Role != nil {
+				c.deleteRole(userRole.Role.ID)
+			}
+		}
+	})
+
+	return c.Connection.SaveUser(ctx, user)
+}
+
+// DeleteAccount removes an account from the database and invalidates the user it belonged to, returning an error if the query failed
+func (c *Connection) DeleteAccount(ctx context.Context, accountID int64) error {
+	defer c.invalidate(func() {
+		c.deleteUsersWithAccount(accountID)
+	})
+
+	return c.Connection.DeleteAccount(ctx, accountID)
+}
+
+// DeleteCharacter removes a character from the database and invalidates the user it belonged to, returning an error if the query failed
+func (c *Connection) DeleteCharacter(ctx context.Context, characterID int64) error {
+	defer c.invalidate(func() {
+		c.users.deleteMatching(func(value interface{}) bool {
+			for _, account := range value.(*models.User).Accounts {
+				for _, character := range account.Characters {
+					if character.ID == characterID {
+						return true
+					}
+				}
+			}
+
+			return false
+		})
+	})
+
+	return c.Connection.DeleteCharacter(ctx, characterID)
+}
+
+// DeleteRole moves a role to the trash and invalidates all entries containing it, returning an error if the query failed
+func (c *Connection) DeleteRole(ctx context.Context, roleID int64, deletedBy int64) error {
+	defer c.invalidate(func() {
+		c.deleteRole(roleID)
+	})
+
+	return c.Connection.DeleteRole(ctx, roleID, deletedBy)
+}
+
+// DeleteGroupRole removes a group role from the database and invalidates all entries containing it, returning an error if the query failed
+func (c *Connection) DeleteGroupRole(ctx context.Context, groupRoleID int64) error {
+	defer c.invalidate(func() {
+		c.deleteGroupRole(groupRoleID)
+	})
+
+	return c.Connection.DeleteGroupRole(ctx, groupRoleID)
+}
+
+// DeleteUserRole removes a user role from the database and invalidates the user it belonged to, returning an error if the query failed
+func (c *Connection) DeleteUserRole(ctx context.Context, userRoleID int64) error {
+	defer c.invalidate(func() {
+		c.deleteUserRole(userRoleID)
+	})
+
+	return c.Connection.DeleteUserRole(ctx, userRoleID)
+}
+
+// DeleteGroup moves a group to the trash and invalidates all entries containing it, returning an error if the query failed
+func (c *Connection) DeleteGroup(ctx context.Context, groupID int64, deletedBy int64) error {
+	defer c.invalidate(func() {
+		c.deleteGroup(groupID)
+	})
+
+	return c.Connection.DeleteGroup(ctx, groupID, deletedBy)
+}
+
+// DeleteUser moves a user to the trash and invalidates its cached entry, returning an error if the query failed
+func (c *Connection) DeleteUser(ctx context.Context, userID int64, deletedBy int64) error {
+	defer c.invalidate(func() {
+		c.users.delete(userID)
+	})
+
+	return c.Connection.DeleteUser(ctx, userID, deletedBy)
+}
+
+// RestoreRole restores a deleted role and flushes the cache as all groups and users containing it have changed, returning an error if the query failed
+func (c *Connection) RestoreRole(ctx context.Context, roleID int64) error {
+	defer c.Flush()
+
+	return c.Connection.RestoreRole(ctx, roleID)
+}
+
+// RestoreGroup restores a deleted group and flushes the cache as all users belonging to it have changed, returning an error if the query failed
+func (c *Connection) RestoreGroup(ctx context.Context, groupID int64) error {
+	defer c.Flush()
+
+	return c.Connection.RestoreGroup(ctx, groupID)
+}
+
+// RestoreUser restores a deleted user and invalidates its cached entry, returning an error if the query failed
+func (c *Connection) RestoreUser(ctx context.Context, userID int64) error {
+	defer c.invalidate(func() {
+		c.users.delete(userID)
+	})
+
+	return c.Connection.RestoreUser(ctx, userID)
+}
+
+// PurgeTrash permanently removes all entities deleted before the given time and flushes the cache, returning the number of purged entities or an error if the query failed
+func (c *Connection) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer c.Flush()
+
+	return c.Connection.PurgeTrash(ctx, deletedBefore)
+}
+
+// RemoveExpiredGrants removes all user roles and group memberships expired at the given time and invalidates the affected users, returning the removed grants or an error if the query failed
+func (c *Connection) RemoveExpiredGrants(ctx context.Context, expiredAt time.Time) ([]*models.ExpiredGrant, error) {
+	var expiredGrants []*models.ExpiredGrant
+
+	defer c.invalidate(func() {
+		for _, expiredGrant := range expiredGrants {
+			c.users.delete(expiredGrant.UserID)
+		}
+	})
+
+	expiredGrants, err := c.Connection.RemoveExpiredGrants(ctx, expiredAt)
+
+	return expiredGrants, err
+}
+
+// RemoveUserFromGroup removes a user from the given group and invalidates the user, returning the updated model or an error if the query failed
+func (c *Connection) RemoveUserFromGroup(ctx context.Context, userID int64, groupID int64) (*models.User, error) {
+	defer c.invalidate(func() {
+		c.users.delete(userID)
+	})
+
+	return c.Connection.RemoveUserFromGroup(ctx, userID, groupID)
+}
+
+// RemoveUserRoleFromUser removes a user role from the given user and invalidates the user, returning the updated model or an error if the query failed
+func (c *Connection) RemoveUserRoleFromUser(ctx context.Context, userID int64, roleID int64) (*models.User, error) {
+	defer c.invalidate(func() {
+		c.users.delete(userID)
+	})
+
+	return c.Connection.RemoveUserRoleFromUser(ctx, userID, roleID)
+}
+
+// RemoveGroupRoleFromGroup removes a group role from the given group and invalidates all entries containing the group, returning the updated model or an error if the query failed
+func (c *Connection) RemoveGroupRoleFromGroup(ctx context.Context, groupID int64, roleID int64) (*models.Group, error) {
+	defer c.invalidate(func() {
+		c.deleteGroup(groupID)
+	})
+
+	return c.Connection.RemoveGroupRoleFromGroup(ctx, groupID, roleID)
+}
+
+// RemoveAPIKeyFromUser removes an API key from the given user and invalidates the user, returning the updated model or an error if the query failed
+func (c *Connection) RemoveAPIKeyFromUser(ctx context.Context, user *models.User, apiKeyID int64) (*models.User, error) {
+	defer c.invalidate(func() {
+		c.users.delete(user.ID)
+	})
+
+	return c.Connection.RemoveAPIKeyFromUser(ctx, user, apiKeyID)
+}
+
+// ToggleUserRoleGranted toggles the granted state of the given user role and invalidates the user it belongs to, returning the updated model or an error if the query failed
+func (c *Connection) ToggleUserRoleGranted(ctx context.Context, roleID int64) (*models.UserRole, error) {
+	defer c.invalidate(func() {
+		c.deleteUserRole(roleID)
+	})
+
+	return c.Connection.ToggleUserRoleGranted(ctx, roleID)
+}
+
+// ToggleGroupRoleGranted toggles the granted state of the given group role and invalidates all entries containing it, returning the updated model or an error if the query failed
+func (c *Connection) ToggleGroupRoleGranted(ctx context.Context, roleID int64) (*models.GroupRole, error) {
+	defer c.invalidate(func() {
+		c.deleteGroupRole(roleID)
+	})
+
+	return c.Connection.ToggleGroupRoleGranted(ctx, roleID)
+}
+
+// lookup retrieves a cached value, returning the current generation to be used when storing the value after a cache miss
+func (c *Connection) lookup(s *store, id int64) (interface{}, uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, ok := s.get(id, time.Now())
+
+	return value, c.generation, ok
+}
+
+// remember caches a value loaded from the database, unless an invalidation happened since the lookup
+func (c *Connection) remember(s *store, id int64, value interface{}, generation uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+
+	s.set(id, value, time.Now().Add(c.ttl))
+}
+
+// invalidate runs the given function while holding the write lock and increases the generation of the cache
+func (c *Connection) invalidate(fn func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+
+	fn()
+}
+
+// deleteRole removes the role with the given ID as well as all roles implying it and all users and groups containing it. The caller must hold the write lock
+func (c *Connection) deleteRole(roleID int64) {
+	c.roles.deleteMatching(func(value interface{}) bool {
+		return roleContainsRole(value.(*models.Role), roleID)
+	})
+
+	c.groups.deleteMatching(func(value interface{}) bool {
+		return groupContainsRole(value.(*models.Group), roleID)
+	})
+
+	c.users.deleteMatching(func(value interface{}) bool {
+		user := value.(*models.User)
+
+		for _, userRole := range user.UserRoles {
+			if userRole.Role != nil && roleContainsRole(userRole.Role, roleID) {
+				return true
+			}
+		}
+
+		for _, group := range user.Groups {
+			if groupContainsRole(group, roleID) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// deleteGroup removes the group with the given ID as well as all groups containing it and all users being a member of any of them. The caller must hold the write lock
+func (c *Connection) deleteGroup(groupID int64) {
+	c.groups.deleteMatching(func(value interface{}) bool {
+		return groupContainsGroup(value.(*models.Group), groupID)
+	})
+
+	c.users.deleteMatching(func(value interface{}) bool {
+		for _, group := range value.(*models.User).Groups {
+			if groupContainsGroup(group, groupID) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// deleteGroupRole removes all groups containing the group role with the given ID as well as all of their members. The caller must hold the write lock
+func (c *Connection) deleteGroupRole(groupRoleID int64) {
+	var groupIDs []int64
+
+	for _, e := range c.groups.entries {
+		ownerID := findGroupRoleOwner(e.value.(*models.Group), groupRoleID)
+		if ownerID > 0 {
+			groupIDs = append(groupIDs, ownerID)
+		}
+	}
+
+	c.users.deleteMatching(func(value interface{}) bool {
+		for _, group := range value.(*models.User).Groups {
+			ownerID := findGroupRoleOwner(group, groupRoleID)
+			if ownerID > 0 {
+				groupIDs = append(groupIDs, ownerID)
+				return true
+			}
+		}
+
+		return false
+	})
+
+	for _, groupID := range groupIDs {
+		c.deleteGroup(groupID)
+	}
+}
+
+// deleteUserRole removes all users containing the user role with the given ID. The caller must hold the write lock
+func (c *Connection) deleteUserRole(userRoleID int64) {
+	c.users.deleteMatching(func(value interface{}) bool {
+		for _, userRole := range value.(*models.User).UserRoles {
+			if userRole.ID == userRoleID {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// deleteUsersWithAccount removes all users owning the account with the given ID. The caller must hold the write lock
+func (c *Connection) deleteUsersWithAccount(accountID int64) {
+	c.users.deleteMatching(func(value interface{}) bool {
+		for _, account := range value.(*models.User).Accounts {
+			if account.ID == accountID {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// deleteUsersWithCorporation removes all users whose default character is in the corporation with the given ID. The caller must hold the write lock
+func (c *Connection) deleteUsersWithCorporation(corporationID int64) {
+	c.users.deleteMatching(func(value interface{}) bool {
+		character := value.(*models.User).GetDefaultCharacter()
+
+		return character != nil && character.CorporationID == corporationID
+	})
+}
+
+// deleteUsersWithAlliance removes all users whose default corporation is in the alliance with the given ID. The caller must hold the write lock
+func (c *Connection) deleteUsersWithAlliance(allianceID int64) {
+	c.users.deleteMatching(func(value interface{}) bool {
+		corporation := value.(*models.User).DefaultCorporation
+
+		return corporation != nil && corporation.AllianceID.Valid && corporation.AllianceID.Int64 == allianceID
+	})
+}
+
+// groupContainsRole checks whether the given group or one of the groups nested in it has a group role referencing or implying the role with the given ID
+func groupContainsRole(group *models.Group, roleID int64) bool {
+	for _, groupRole := range group.GroupRoles {
+		if groupRole.Role != nil && roleContainsRole(groupRole.Role, roleID) {
+			return true
+		}
+	}
+
+	for _, subGroup := range group.SubGroups {
+		if groupContainsRole(subGroup, roleID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// groupContainsGroup checks whether the given group is or contains the group with the given ID
+func groupContainsGroup(group *models.Group, groupID int64) bool {
+	if group.ID == groupID {
+		return true
+	}
+
+	for _, subGroup := range group.SubGroups {
+		if groupContainsGroup(subGroup, groupID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findGroupRoleOwner returns the ID of the given group or the group nested in it owning the group role with the given ID, 0 if none of them does
+func findGroupRoleOwner(group *models.Group, groupRoleID int64) int64 {
+	for _, groupRole := range group.GroupRoles {
+		if groupRole.ID == groupRoleID {
+			return group.ID
+		}
+	}
+
+	for _, subGroup := range group.SubGroups {
+		ownerID := findGroupRoleOwner(subGroup, groupRoleID)
+		if ownerID > 0 {
+			return ownerID
+		}
+	}
+
+	return 0
+}
+
+// roleContainsRole checks whether the given role is or implies the role with the given ID
+func roleContainsRole(role *models.Role, roleID int64) bool {
+	if role.ID == roleID {
+		return true
+	}
+
+	for _, impliedRole := range role.ImpliedRoles {
+		if roleContainsRole(impliedRole, roleID) {
+			return true
+		}
+	}
+
+	return false
+}
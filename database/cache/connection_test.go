@@ -0,0 +1,358 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/memory"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+// createCachedConnection returns a caching connection wrapping an in-memory database populated with a user, group, role and corporation
+func createCachedConnection(ttl time.Duration) (*Connection, error) {
+	ctx := context.Background()
+
+	db := &memory.DatabaseConnection{
+		Config: &misc.Configuration{
+			DatabaseType: 0,
+			DebugLevel:   1,
+		},
+	}
+
+	err := db.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.SaveCorporation(ctx, models.NewCorporation("Test Corp Please Ignore", "TEST", 1, 1, zero.IntFrom(0), zero.StringFrom(""), true))
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := db.SaveGroup(ctx, models.NewGroup("Test Group", true))
+	if err != nil {
+		return nil, err
+	}
+
+	group.GroupRoles = append(group.GroupRoles, models.NewGroupRole(group.ID, models.NewRole("ping.all", true, false), false, true))
+
+	group, err = db.SaveGroup(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.NewUser("test1", "password", "test1@example.com", true, true)
+	user.UserRoles = append(user.UserRoles, models.NewUserRole(-1, models.NewRole("logistics.read", true, false), false, true))
+	user.Groups = append(user.Groups, group)
+
+	account := models.NewAccount(-1, 1, "a", 0, true)
+	account.Characters = append(account.Characters, models.NewCharacter(-1, 1, "Test Character", 1, true, true))
+	user.Accounts = append(user.Accounts, account)
+
+	_, err = db.SaveUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnection(db, ttl), nil
+}
+
+func TestConnectionLoad(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Loading entries through a caching connection", t, func() {
+		db, err := createCachedConnection(time.Minute)
+		So(err, ShouldBeNil)
+
+		Convey("Loading a user twice should only query the database once", func() {
+			user, err := db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Username, ShouldEqual, "test1")
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 1)
+			So(len(user.UserRoles), ShouldEqual, 1)
+			So(len(user.Groups), ShouldEqual, 1)
+
+			statistics := db.Statistics()
+			So(statistics.Users.Hits, ShouldEqual, 1)
+			So(statistics.Users.Misses, ShouldEqual, 1)
+			So(statistics.Users.Entries, ShouldEqual, 1)
+			So(statistics.Users.HitRate(), ShouldEqual, 50)
+		})
+
+		Convey("Modifying a returned user should not modify the cached entry", func() {
+			user, err := db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+
+			user.Username = "modified"
+			user.Accounts[0].Characters[0].Name = "Modified Character"
+			user.Groups[0].GroupRoles[0].Role.Name = "modified.role"
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Username, ShouldEqual, "test1")
+			So(user.Accounts[0].Characters[0].Name, ShouldEqual, "Test Character")
+			So(user.Groups[0].GroupRoles[0].Role.Name, ShouldEqual, "ping.all")
+		})
+
+		Convey("Loading a corporation name should use the cached corporation", func() {
+			_, err := db.LoadCorporation(ctx, 1)
+			So(err, ShouldBeNil)
+
+			name, err := db.LoadCorporationNameFromID(ctx, 1)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Test Corp Please Ignore")
+			So(db.Statistics().Corporations.Hits, ShouldEqual, 1)
+		})
+
+		Convey("Loading a nonexistent entry should not be cached", func() {
+			_, err := db.LoadGroup(ctx, 1337)
+			So(err, ShouldNotBeNil)
+			So(db.Statistics().Groups.Entries, ShouldEqual, 0)
+		})
+
+		Convey("Expired entries should be loaded from the database again", func() {
+			db.ttl = time.Nanosecond
+
+			_, err := db.LoadRole(ctx, 1)
+			So(err, ShouldBeNil)
+
+			time.Sleep(time.Millisecond)
+
+			_, err = db.LoadRole(ctx, 1)
+			So(err, ShouldBeNil)
+			So(db.Statistics().Roles.Misses, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestConnectionInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Modifying entries through a caching connection", t, func() {
+		db, err := createCachedConnection(time.Minute)
+		So(err, ShouldBeNil)
+
+		user, err := db.LoadUser(ctx, 1)
+		So(err, ShouldBeNil)
+
+		_, err = db.LoadGroup(ctx, 1)
+		So(err, ShouldBeNil)
+
+		Convey("Saving a user should invalidate the cached user", func() {
+			user.Email = "changed@example.com"
+
+			_, err := db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Email, ShouldEqual, "changed@example.com")
+		})
+
+		Convey("Saving a character should invalidate the user owning it", func() {
+			character := user.Accounts[0].Characters[0]
+			character.Name = "Renamed Character"
+
+			_, err := db.SaveCharacter(ctx, character)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Accounts[0].Characters[0].Name, ShouldEqual, "Renamed Character")
+		})
+
+		Convey("Saving a corporation or alliance should invalidate the users affiliated with it", func() {
+			So(user.DefaultCorporation.Ticker, ShouldEqual, "TEST")
+			So(user.DefaultAlliance, ShouldBeNil)
+
+			alliance, err := db.SaveAlliance(ctx, models.NewAlliance("Test Alliance Please Ignore", "TEST", 1, 1, true))
+			So(err, ShouldBeNil)
+
+			corporation, err := db.LoadCorporation(ctx, 1)
+			So(err, ShouldBeNil)
+
+			corporation.AllianceID = zero.IntFrom(alliance.ID)
+
+			_, err = db.SaveCorporation(ctx, corporation)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.DefaultAlliance.Name, ShouldEqual, "Test Alliance Please Ignore")
+
+			alliance.Name = "Renamed Alliance"
+
+			_, err = db.SaveAlliance(ctx, alliance)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.DefaultAlliance.Name, ShouldEqual, "Renamed Alliance")
+		})
+
+		Convey("Saving a role should invalidate all groups and users containing it", func() {
+			role, err := db.LoadRole(ctx, 1)
+			So(err, ShouldBeNil)
+
+			role.Active = false
+
+			_, err = db.SaveRole(ctx, role)
+			So(err, ShouldBeNil)
+
+			group, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+			So(group.GroupRoles[0].Role.Active, ShouldBeFalse)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Groups[0].GroupRoles[0].Role.Active, ShouldBeFalse)
+		})
+
+		Convey("Saving role implications should invalidate all groups and users containing the role", func() {
+			err := db.SaveRoleImplications(ctx, 1, []int64{2})
+			So(err, ShouldBeNil)
+
+			group, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(group.GroupRoles[0].Role.ImpliedRoles), ShouldEqual, 1)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Groups[0].GroupRoles[0].Role.ImpliedRoles[0].Name, ShouldEqual, "logistics.read")
+		})
+
+		Convey("Saving sub groups should invalidate all groups and users containing the group", func() {
+			subGroup, err := db.SaveGroup(ctx, models.NewGroup("Sub Group", true))
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+
+			err = db.SaveSubGroups(ctx, 1, []int64{subGroup.ID})
+			So(err, ShouldBeNil)
+
+			group, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(group.SubGroups), ShouldEqual, 1)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Groups[0].SubGroups[0].Name, ShouldEqual, "Sub Group")
+
+			Convey("Saving a sub group should invalidate all groups and users containing it", func() {
+				subGroup.Name = "Renamed Sub Group"
+
+				_, err := db.SaveGroup(ctx, subGroup)
+				So(err, ShouldBeNil)
+
+				group, err := db.LoadGroup(ctx, 1)
+				So(err, ShouldBeNil)
+				So(group.SubGroups[0].Name, ShouldEqual, "Renamed Sub Group")
+
+				user, err = db.LoadUser(ctx, 1)
+				So(err, ShouldBeNil)
+				So(user.Groups[0].SubGroups[0].Name, ShouldEqual, "Renamed Sub Group")
+			})
+		})
+
+		Convey("Saving an implied role should invalidate all roles implying it", func() {
+			err := db.SaveRoleImplications(ctx, 1, []int64{2})
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadRole(ctx, 1)
+			So(err, ShouldBeNil)
+
+			role, err := db.LoadRole(ctx, 2)
+			So(err, ShouldBeNil)
+
+			role.Active = false
+
+			_, err = db.SaveRole(ctx, role)
+			So(err, ShouldBeNil)
+
+			implying, err := db.LoadRole(ctx, 1)
+			So(err, ShouldBeNil)
+			So(implying.ImpliedRoles[0].Active, ShouldBeFalse)
+		})
+
+		Convey("Toggling a user role should invalidate the user", func() {
+			_, err := db.ToggleUserRoleGranted(ctx, user.UserRoles[0].ID)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.UserRoles[0].Granted, ShouldBeFalse)
+		})
+
+		Convey("Toggling a group role should invalidate the group and its members", func() {
+			_, err := db.ToggleGroupRoleGranted(ctx, 1)
+			So(err, ShouldBeNil)
+
+			group, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+			So(group.GroupRoles[0].Granted, ShouldBeFalse)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Groups[0].GroupRoles[0].Granted, ShouldBeFalse)
+		})
+
+		Convey("Deleting a group should invalidate its members", func() {
+			err := db.DeleteGroup(ctx, 1, 1)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 0)
+		})
+
+		Convey("Removing expired grants should invalidate the affected users", func() {
+			user.UserRoles[0].Validity = models.NewValidity(time.Now().Add(-2*time.Hour), time.Hour)
+
+			_, err := db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+
+			expiredGrants, err := db.RemoveExpiredGrants(ctx, time.Now())
+			So(err, ShouldBeNil)
+			So(len(expiredGrants), ShouldEqual, 1)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+		})
+
+		Convey("Removing an API key should invalidate the user", func() {
+			_, err := db.RemoveAPIKeyFromUser(ctx, user, 1)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 0)
+		})
+
+		Convey("Committing a transaction should flush the cache", func() {
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				_, err := tx.RemoveUserFromGroup(ctx, 1, 1)
+				return err
+			})
+			So(err, ShouldBeNil)
+			So(db.Statistics().Users.Entries, ShouldEqual, 0)
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// copyCorporation returns a copy of the given corporation
+func copyCorporation(corporation *models.Corporation) *models.Corporation {
+	corp := *corporation
+
+	return &corp
+}
+
+// copyRole returns a deep copy of the given role and the roles implied by it
+func copyRole(role *models.Role) *models.Role {
+	r := *role
+
+	if role.ImpliedRoles != nil {
+		r.ImpliedRoles = make([]*models.Role, len(role.ImpliedRoles))
+
+		for index, impliedRole := range role.ImpliedRoles {
+			r.ImpliedRoles[index] = copyRole(impliedRole)
+		}
+	}
+
+	return &r
+}
+
+// copyGroup returns a deep copy of the given group, its group roles and the groups nested in it
+func copyGroup(group *models.Group) *models.Group {
+	grp := *group
+
+	if group.GroupRoles != nil {
+		grp.GroupRoles = make([]*models.GroupRole, len(group.GroupRoles))
+
+		for index, groupRole := range group.GroupRoles {
+			gr := *groupRole
+			if groupRole.Role != nil {
+				gr.Role = copyRole(groupRole.Role)
+			}
+
+			grp.GroupRoles[index] = &gr
+		}
+	}
+
+	if group.SubGroups != nil {
+		grp.SubGroups = make([]*models.Group, len(group.SubGroups))
+
+		for index, subGroup := range group.SubGroups {
+			grp.SubGroups[index] = copyGroup(subGroup)
+		}
+	}
+
+	return &grp
+}
+
+// copyUser returns a deep copy of the given user and its accounts, user roles, groups and default affiliation
+func copyUser(user *models.User) *models.User {
+	usr := *user
+
+	if user.Accounts != nil {
+		usr.Accounts = make([]*models.Account, len(user.Accounts))
+
+		for index, account := range user.Accounts {
+			acc := *account
+
+			if account.Characters != nil {
+				acc.Characters = make([]*models.Character, len(account.Characters))
+
+				for charIndex, character := range account.Characters {
+					char := *character
+					acc.Characters[charIndex] = &char
+				}
+			}
+
+			usr.Accounts[index] = &acc
+		}
+	}
+
+	if user.UserRoles != nil {
+		usr.UserRoles = make([]*models.UserRole, len(user.UserRoles))
+
+		for index, userRole := range user.UserRoles {
+			ur := *userRole
+			if userRole.Role != nil {
+				ur.Role = copyRole(userRole.Role)
+			}
+			ur.Validity = copyValidity(userRole.Validity)
+
+			usr.UserRoles[index] = &ur
+		}
+	}
+
+	if user.Groups != nil {
+		usr.Groups = make([]*models.Group, len(user.Groups))
+
+		for index, group := range user.Groups {
+			usr.Groups[index] = copyGroup(group)
+		}
+	}
+
+	if user.GroupValidities != nil {
+		usr.GroupValidities = make(map[int64]*models.Validity, len(user.GroupValidities))
+
+		for groupID, validity := range user.GroupValidities {
+			usr.GroupValidities[groupID] = copyValidity(validity)
+		}
+	}
+
+	if user.AutoAddedGroups != nil {
+		usr.AutoAddedGroups = make(map[int64]bool, len(user.AutoAddedGroups))
+
+		for groupID, autoAdded := range user.AutoAddedGroups {
+			usr.AutoAddedGroups[groupID] = autoAdded
+		}
+	}
+
+	if user.DefaultCorporation != nil {
+		usr.DefaultCorporation = copyCorporation(user.DefaultCorporation)
+	}
+
+	if user.DefaultAlliance != nil {
+		alliance := *user.DefaultAlliance
+		usr.DefaultAlliance = &alliance
+	}
+
+	return &usr
+}
+
+// copyValidity returns a deep copy of the given validity window, nil if no window was given
+func copyValidity(validity *models.Validity) *models.Validity {
+	if validity == nil {
+		return nil
+	}
+
+	v := *validity
+
+	if validity.ValidFrom != nil {
+		validFrom := *validity.ValidFrom
+		v.ValidFrom = &validFrom
+	}
+
+	if validity.ValidUntil != nil {
+		validUntil := *validity.ValidUntil
+		v.ValidUntil = &validUntil
+	}
+
+	return &v
+}
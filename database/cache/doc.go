@@ -0,0 +1,2 @@
+// Package cache provides a caching decorator for database connections, keeping users, groups, roles and corporations in memory until they are modified.
+package cache
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Counter stores the number of cache hits and misses of a single kind of cached entries
+type Counter struct {
+	// Hits represents the number of lookups answered from the cache
+	Hits uint64 `json:"hits"`
+	// Misses represents the number of lookups forwarded to the database
+	Misses uint64 `json:"misses"`
+	// Entries represents the number of entries currently cached
+	Entries int `json:"entries"`
+}
+
+// HitRate returns the percentage of lookups answered from the cache
+func (counter Counter) HitRate() float64 {
+	total := counter.Hits + counter.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(counter.Hits) / float64(total) * 100
+}
+
+// entry represents a single cached value alongside its expiry time
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// store caches entries of a single kind by their database ID. The caller must synchronise access to the entries
+type store struct {
+	entries map[int64]*entry
+	hits    uint64
+	misses  uint64
+}
+
+// newStore creates a new, empty store
+func newStore() *store {
+	s := &store{
+		entries: make(map[int64]*entry),
+	}
+
+	return s
+}
+
+// get returns the cached value with the given ID, counting the lookup as hit or miss
+func (s *store) get(id int64, now time.Time) (interface{}, bool) {
+	e, ok := s.entries[id]
+	if !ok || now.After(e.expires) {
+		atomic.AddUint64(&s.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddUint64(&s.hits, 1)
+
+	return e.value, true
+}
+
+// set caches the given value until the expiry time
+func (s *store) set(id int64, value interface{}, expires time.Time) {
+	s.entries[id] = &entry{
+		value:   value,
+		expires: expires,
+	}
+}
+
+// delete removes the cached value with the given ID
+func (s *store) delete(id int64) {
+	delete(s.entries, id)
+}
+
+// deleteMatching removes all cached values the given function returns true for
+func (s *store) deleteMatching(matches func(value interface{}) bool) {
+	for id, e := range s.entries {
+		if matches(e.value) {
+			delete(s.entries, id)
+		}
+	}
+}
+
+// clear removes all cached values
+func (s *store) clear() {
+	s.entries = make(map[int64]*entry)
+}
+
+// counter returns the current hit and miss counts of the store
+func (s *store) counter() Counter {
+	return Counter{
+		Hits:    atomic.LoadUint64(&s.hits),
+		Misses:  atomic.LoadUint64(&s.misses),
+		Entries: len(s.entries),
+	}
+}
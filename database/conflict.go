@@ -0,0 +1,35 @@
+package database
+
+import (
+	"fmt"
+)
+
+// ConflictError is returned when saving an entity which has been modified by someone else since it was loaded
+type ConflictError struct {
+	// Entity represents the type of the conflicting entity
+	Entity string
+	// ID represents the database ID of the conflicting entity
+	ID int64
+	// Version represents the outdated version of the entity the save was attempted with
+	Version int64
+}
+
+// NewConflictError creates a new conflict error for the entity with the given type, ID and outdated version
+func NewConflictError(entity string, id int64, version int64) *ConflictError {
+	return &ConflictError{
+		Entity:  entity,
+		ID:      id,
+		Version: version,
+	}
+}
+
+// Error returns a readable description of the conflict
+func (err *ConflictError) Error() string {
+	return fmt.Sprintf("The %s with ID %d has been modified since version %d was loaded", err.Entity, err.ID, err.Version)
+}
+
+// IsConflict checks whether the given error was caused by saving an outdated entity
+func IsConflict(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConflictError(t *testing.T) {
+	Convey("Creating a new conflict error", t, func() {
+		err := NewConflictError("group", 3, 7)
+
+		Convey("The error should describe the outdated entity", func() {
+			So(err.Error(), ShouldEqual, "The group with ID 3 has been modified since version 7 was loaded")
+		})
+
+		Convey("The error should be detected as conflict", func() {
+			So(IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Other errors should not be detected as conflict", func() {
+			So(IsConflict(errors.New("Something went wrong")), ShouldBeFalse)
+			So(IsConflict(nil), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+func testAlliances(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving and loading alliances", t, func() {
+		db, f := setup(factory)
+
+		alliance, err := db.SaveAlliance(ctx, models.NewAlliance("Test Alliance Please Ignore", "TEST", 99000001, f.corporation.EVECorporationID, true))
+		So(err, ShouldBeNil)
+		So(alliance.ID, ShouldBeGreaterThan, 0)
+
+		Convey("Should return the alliance by ID and EVE alliance ID", func() {
+			loaded, err := db.LoadAlliance(ctx, alliance.ID)
+			So(err, ShouldBeNil)
+			So(loaded.Name, ShouldEqual, "Test Alliance Please Ignore")
+			So(loaded.ExecutorCorpID, ShouldEqual, f.corporation.EVECorporationID)
+			So(loaded.Active, ShouldBeTrue)
+
+			loaded, err = db.LoadAllianceFromEVEAllianceID(ctx, 99000001)
+			So(err, ShouldBeNil)
+			So(loaded.ID, ShouldEqual, alliance.ID)
+
+			alliances, err := db.LoadAllAlliances(ctx)
+			So(err, ShouldBeNil)
+			So(len(alliances), ShouldEqual, 1)
+		})
+
+		Convey("Should update an existing alliance", func() {
+			alliance.Ticker = "TST"
+
+			_, err := db.SaveAlliance(ctx, alliance)
+			So(err, ShouldBeNil)
+
+			loaded, err := db.LoadAlliance(ctx, alliance.ID)
+			So(err, ShouldBeNil)
+			So(loaded.Ticker, ShouldEqual, "TST")
+		})
+
+		Convey("Should reject duplicate EVE alliance IDs", func() {
+			_, err := db.SaveAlliance(ctx, models.NewAlliance("Duplicate Alliance", "DUPE", 99000001, 0, true))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should return sql.ErrNoRows for unknown alliances", func() {
+			_, err := db.LoadAlliance(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.LoadAllianceFromEVEAllianceID(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+
+		Convey("Linking a corporation should expose the alliance to applications", func() {
+			f.corporation.AllianceID = zero.IntFrom(alliance.ID)
+
+			_, err := db.SaveCorporation(ctx, f.corporation)
+			So(err, ShouldBeNil)
+
+			corporation, err := db.LoadCorporation(ctx, f.corporation.ID)
+			So(err, ShouldBeNil)
+			So(corporation.AllianceID.Int64, ShouldEqual, alliance.ID)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			authUser, err := database.LoadAuthUser(ctx, db, user)
+			So(err, ShouldBeNil)
+			So(len(authUser.Alliances), ShouldEqual, 1)
+			So(authUser.Alliances[0].EVEAllianceID, ShouldEqual, 99000001)
+			So(authUser.Characters[0].Alliance, ShouldNotBeNil)
+			So(authUser.Characters[0].Alliance.ID, ShouldEqual, alliance.ID)
+		})
+
+		Convey("Corporations without an alliance should not expose one", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			authUser, err := database.LoadAuthUser(ctx, db, user)
+			So(err, ShouldBeNil)
+			So(len(authUser.Alliances), ShouldEqual, 0)
+			So(authUser.Characters[0].Alliance, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,214 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+// Factory creates a new connected database with an empty, up-to-date schema. The suite calls it once for every test case
+type Factory func() (database.Connection, error)
+
+// fixture contains the test data set seeded into every database created by the suite
+type fixture struct {
+	corporation      *models.Corporation
+	otherCorporation *models.Corporation
+	pingAll          *models.Role
+	destroyWorld     *models.Role
+	logisticsRead    *models.Role
+	logisticsWrite   *models.Role
+	testGroup        *models.Group
+	dankAccess       *models.Group
+	test1            *models.User
+	test2            *models.User
+	test3            *models.User
+	testApp          *models.Application
+	otherApp         *models.Application
+}
+
+// Run exercises every method of database.Connection against databases created by the given factory, seeding each one with the same test data set
+func Run(t *testing.T, factory Factory) {
+	suite := []struct {
+		name string
+		test func(*testing.T, Factory)
+	}{
+		{"Migrations", testMigrations},
+		{"Ping", testPing},
+		{"Transactions", testTransactions},
+		{"LoadAll", testLoadAll},
+		{"Query", testQuery},
+		{"LoadSingle", testLoadSingle},
+		{"LoadInvalid", testLoadInvalid},
+		{"LoadForOwner", testLoadForOwner},
+		{"LoadAvailable", testLoadAvailable},
+		{"Credentials", testCredentials},
+		{"Save", testSave},
+		{"Conflict", testConflict},
+		{"Delete", testDelete},
+		{"Trash", testTrash},
+		{"Prune", testPrune},
+		{"Remove", testRemove},
+		{"Toggle", testToggle},
+		{"Implications", testImplications},
+		{"GroupNestings", testGroupNestings},
+		{"Validity", testValidity},
+		{"MembershipRules", testMembershipRules},
+		{"GroupManagers", testGroupManagers},
+		{"GroupApplications", testGroupApplications},
+		{"RoleScopes", testRoleScopes},
+		{"RolePolicies", testRolePolicies},
+		{"Alliances", testAlliances},
+		{"Reports", testReports},
+	}
+
+	for _, test := range suite {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, factory)
+		})
+	}
+}
+
+// setup creates a new database using the given factory and seeds the test data set, failing the current assertion if either step failed
+func setup(factory Factory) (database.Connection, *fixture) {
+	db, err := factory()
+	So(err, ShouldBeNil)
+
+	f, err := seed(context.Background(), db)
+	So(err, ShouldBeNil)
+
+	return db, f
+}
+
+// seed populates the given database with two corporations, four roles, two groups, three users and two applications
+func seed(ctx context.Context, db database.Connection) (*fixture, error) {
+	var err error
+
+	f := &fixture{}
+
+	f.corporation, err = db.SaveCorporation(ctx, models.NewCorporation("Test Corp Please Ignore", "TEST", 1, 1, zero.IntFrom(1), zero.StringFrom("a"), true))
+	if err != nil {
+		return nil, err
+	}
+
+	f.otherCorporation, err = db.SaveCorporation(ctx, models.NewCorporation("Corp Test Ignore Please", "CORP", 2, 2, zero.IntFrom(0), zero.StringFrom(""), false))
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []struct {
+		role   **models.Role
+		name   string
+		active bool
+		locked bool
+	}{
+		{&f.pingAll, "ping.all", true, false},
+		{&f.destroyWorld, "destroy.world", false, true},
+		{&f.logisticsRead, "logistics.read", true, false},
+		{&f.logisticsWrite, "logistics.write", true, false},
+	}
+
+	for _, role := range roles {
+		*role.role, err = db.SaveRole(ctx, models.NewRole(role.name, role.active, role.locked))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	testGroup := models.NewGroup("Test Group", true)
+	testGroup.GroupRoles = append(testGroup.GroupRoles, models.NewGroupRole(-1, f.pingAll, true, true), models.NewGroupRole(-1, f.logisticsRead, false, true))
+
+	f.testGroup, err = db.SaveGroup(ctx, testGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	dankAccess := models.NewGroup("Dank Access", false)
+	dankAccess.GroupRoles = append(dankAccess.GroupRoles, models.NewGroupRole(-1, f.destroyWorld, false, false))
+
+	f.dankAccess, err = db.SaveGroup(ctx, dankAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	mainAccount := models.NewAccount(-1, 1, "a", 0, true)
+	mainAccount.Characters = append(mainAccount.Characters, models.NewCharacter(-1, f.corporation.ID, "Test Character", 1, true, true), models.NewCharacter(-1, f.corporation.ID, "Herp", 3, false, true))
+
+	spaiAccount := models.NewAccount(-1, 2, "b", 268435455, true)
+	spaiAccount.Characters = append(spaiAccount.Characters, models.NewCharacter(-1, f.otherCorporation.ID, "Spai", 5, true, false))
+
+	test1 := models.NewUser("test1", "$2a$10$veif8VUZt7lShFhJKD0wGeY1YjCwIuWjYL0vQzlTqu8wNaYQMqzbe", "test1@example.com", true, true)
+	test1.Groups = append(test1.Groups, f.testGroup)
+	test1.UserRoles = append(test1.UserRoles, models.NewUserRole(-1, f.logisticsWrite, false, false))
+	test1.Accounts = append(test1.Accounts, mainAccount, spaiAccount)
+
+	f.test1, err = db.SaveUser(ctx, test1)
+	if err != nil {
+		return nil, err
+	}
+
+	test2 := models.NewUser("test2", "$2a$10$95z.WXfIreLKJ9px.3KgpOq4aXTG3DF7/5ehGYzUWALhpN6MMq/aK", "test2@example.com", false, false)
+	test2.Groups = append(test2.Groups, f.testGroup, f.dankAccess)
+	test2.UserRoles = append(test2.UserRoles, models.NewUserRole(-1, f.pingAll, true, true))
+
+	f.test2, err = db.SaveUser(ctx, test2)
+	if err != nil {
+		return nil, err
+	}
+
+	f.test3, err = db.SaveUser(ctx, models.NewUser("test3", "$2a$10$7Yxm2scdTVpEJpvZAT7tbOFA.G9JfyxtiHbr989iocX6U37C3/j4q", "test3@example.com", false, true))
+	if err != nil {
+		return nil, err
+	}
+
+	f.testApp, err = db.SaveApplication(ctx, models.NewApplication("Testapp", f.test1.ID, "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "http://localhost/callback", true))
+	if err != nil {
+		return nil, err
+	}
+
+	f.otherApp, err = db.SaveApplication(ctx, models.NewApplication("Apptest", f.test2.ID, "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "http://example.com/callback", false))
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// roleNames returns the names of the given roles in order
+func roleNames(roles []*models.Role) []string {
+	names := make([]string, 0)
+
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
+
+// groupNames returns the names of the given groups in order
+func groupNames(groups []*models.Group) []string {
+	names := make([]string, 0)
+
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+
+	return names
+}
+
+// usernames returns the usernames of the given users in order
+func usernames(users []*models.User) []string {
+	names := make([]string, 0)
+
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+
+	return names
+}
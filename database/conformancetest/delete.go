@@ -0,0 +1,414 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testDelete(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Deleting entries", t, func() {
+		db, f := setup(factory)
+
+		Convey("Deleting an account should remove all of its characters", func() {
+			account := f.test1.Accounts[0]
+
+			err := db.DeleteAccount(ctx, account.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadAccount(ctx, account.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			for _, character := range account.Characters {
+				_, err = db.LoadCharacter(ctx, character.ID)
+				So(err, ShouldEqual, sql.ErrNoRows)
+			}
+
+			characters, err := db.LoadAllCharacters(ctx)
+			So(err, ShouldBeNil)
+			So(len(characters), ShouldEqual, 1)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 1)
+			So(user.Version, ShouldEqual, 2)
+		})
+
+		Convey("Deleting a character should only remove the character", func() {
+			character := f.test1.Accounts[0].Characters[1]
+
+			err := db.DeleteCharacter(ctx, character.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadCharacter(ctx, character.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			characters, err := db.LoadAllCharactersForAccount(ctx, character.AccountID)
+			So(err, ShouldBeNil)
+			So(len(characters), ShouldEqual, 1)
+		})
+
+		Convey("Deleting a group role should remove it from its group", func() {
+			groupRole := f.testGroup.GroupRoles[0]
+
+			err := db.DeleteGroupRole(ctx, groupRole.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadGroupRole(ctx, groupRole.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(group.GroupRoles), ShouldEqual, 1)
+		})
+
+		Convey("Deleting a user role should remove it from its user", func() {
+			userRole := f.test1.UserRoles[0]
+
+			err := db.DeleteUserRole(ctx, userRole.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadUserRole(ctx, userRole.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+		})
+
+		Convey("Deleting a role should hide all group and user roles associated", func() {
+			err := db.DeleteRole(ctx, f.pingAll.ID, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			groupRoles, err := db.LoadAllGroupRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupRoles), ShouldEqual, 2)
+
+			userRoles, err := db.LoadAllUserRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(userRoles), ShouldEqual, 1)
+
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(group.GroupRoles), ShouldEqual, 1)
+
+			user, err := db.LoadUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+		})
+
+		Convey("Deleting a group should hide all memberships", func() {
+			err := db.DeleteGroup(ctx, f.testGroup.ID, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			user, err := db.LoadUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Dank Access"})
+
+			groupRoles, err := db.LoadAllGroupRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupRoles), ShouldEqual, 1)
+		})
+
+		Convey("Deleting a user should hide it from all queries", func() {
+			err := db.DeleteUser(ctx, f.test2.ID, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadUser(ctx, f.test2.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.LoadUserFromUsername(ctx, "test2")
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.LoadPasswordForUser(ctx, "test2")
+			So(err, ShouldNotBeNil)
+
+			exists, err := db.QueryUserIDExists(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeFalse)
+
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(usernames(users), ShouldResemble, []string{"test1", "test3"})
+
+			userRoles, err := db.LoadAllUserRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(userRoles), ShouldEqual, 1)
+		})
+
+		Convey("Deleting an application should hide it", func() {
+			err := db.DeleteApplication(ctx, f.testApp.ID, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadApplication(ctx, f.testApp.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			applications, err := db.LoadAllApplicationsForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(applications), ShouldEqual, 0)
+		})
+	})
+}
+
+func testTrash(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Restoring and purging deleted entries", t, func() {
+		db, f := setup(factory)
+
+		So(db.DeleteUser(ctx, f.test2.ID, f.test1.ID), ShouldBeNil)
+		So(db.DeleteGroup(ctx, f.dankAccess.ID, f.test1.ID), ShouldBeNil)
+		So(db.DeleteRole(ctx, f.destroyWorld.ID, f.test1.ID), ShouldBeNil)
+		So(db.DeleteApplication(ctx, f.otherApp.ID, f.test1.ID), ShouldBeNil)
+
+		Convey("The trash should contain all deleted entries", func() {
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 4)
+
+			names := make(map[models.TrashEntryType]string)
+			for _, entry := range trash {
+				names[entry.Type] = entry.Name
+				So(entry.DeletedBy, ShouldEqual, f.test1.ID)
+				So(entry.DeletedAt.IsZero(), ShouldBeFalse)
+			}
+
+			So(names[models.TrashEntryTypeUser], ShouldEqual, "test2")
+			So(names[models.TrashEntryTypeGroup], ShouldEqual, "Dank Access")
+			So(names[models.TrashEntryTypeRole], ShouldEqual, "destroy.world")
+			So(names[models.TrashEntryTypeApplication], ShouldEqual, "Apptest")
+		})
+
+		Convey("Restoring entries should restore their associations", func() {
+			So(db.RestoreUser(ctx, f.test2.ID), ShouldBeNil)
+			So(db.RestoreGroup(ctx, f.dankAccess.ID), ShouldBeNil)
+			So(db.RestoreRole(ctx, f.destroyWorld.ID), ShouldBeNil)
+			So(db.RestoreApplication(ctx, f.otherApp.ID), ShouldBeNil)
+
+			user, err := db.LoadUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 2)
+			So(len(user.UserRoles), ShouldEqual, 1)
+
+			group, err := db.LoadGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(group.GroupRoles[0].Role.Name, ShouldEqual, "destroy.world")
+
+			_, err = db.LoadApplication(ctx, f.otherApp.ID)
+			So(err, ShouldBeNil)
+
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 0)
+		})
+
+		Convey("Restoring entries which are not in the trash should fail", func() {
+			So(db.RestoreUser(ctx, f.test1.ID), ShouldNotBeNil)
+			So(db.RestoreGroup(ctx, f.testGroup.ID), ShouldNotBeNil)
+			So(db.RestoreRole(ctx, f.pingAll.ID), ShouldNotBeNil)
+			So(db.RestoreApplication(ctx, f.testApp.ID), ShouldNotBeNil)
+		})
+
+		Convey("Purging the trash should only remove entries deleted before the given time", func() {
+			purged, err := db.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 0)
+
+			purged, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 4)
+
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 0)
+
+			So(db.RestoreUser(ctx, f.test2.ID), ShouldNotBeNil)
+
+			_, err = db.LoadGroupRole(ctx, f.dankAccess.GroupRoles[0].ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.LoadUserRole(ctx, f.test2.UserRoles[0].ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(usernames(users), ShouldResemble, []string{"test1", "test3"})
+		})
+
+		Convey("Purging users still maintaining applications should move the applications to the trash first", func() {
+			So(db.RestoreApplication(ctx, f.otherApp.ID), ShouldBeNil)
+
+			purged, err := db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 2)
+
+			_, err = db.LoadApplication(ctx, f.otherApp.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 2)
+
+			for _, entry := range trash {
+				So(entry.DeletedBy, ShouldEqual, f.test1.ID)
+			}
+
+			purged, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 2)
+
+			trash, err = db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 0)
+		})
+	})
+}
+
+func testRemove(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Removing associations", t, func() {
+		db, f := setup(factory)
+
+		Convey("Removing a user from a group should only remove the membership", func() {
+			user, err := db.RemoveUserFromGroup(ctx, f.test2.ID, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Test Group"})
+			So(user.Version, ShouldEqual, 2)
+
+			user, err = db.LoadUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Test Group"})
+			So(user.Version, ShouldEqual, 2)
+
+			_, err = db.LoadGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Removing a user role should delete it", func() {
+			userRole := f.test1.UserRoles[0]
+
+			user, err := db.RemoveUserRoleFromUser(ctx, f.test1.ID, userRole.ID)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+
+			_, err = db.LoadUserRole(ctx, userRole.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			user, err = db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+			So(user.Version, ShouldEqual, 2)
+		})
+
+		Convey("Removing a group role should delete it", func() {
+			groupRole := f.testGroup.GroupRoles[1]
+
+			group, err := db.RemoveGroupRoleFromGroup(ctx, f.testGroup.ID, groupRole.ID)
+			So(err, ShouldBeNil)
+			So(len(group.GroupRoles), ShouldEqual, 1)
+			So(group.Version, ShouldEqual, 2)
+
+			_, err = db.LoadGroupRole(ctx, groupRole.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			group, err = db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(group.GroupRoles), ShouldEqual, 1)
+			So(group.GroupRoles[0].Role.Name, ShouldEqual, "ping.all")
+		})
+
+		Convey("Removing an API key should delete the account and all of its characters", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			user, err = db.RemoveAPIKeyFromUser(ctx, user, 2)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 1)
+			So(user.Version, ShouldEqual, 2)
+
+			_, err = db.LoadAccount(ctx, f.test1.Accounts[1].ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.LoadCharacter(ctx, f.test1.Accounts[1].Characters[0].ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			user, err = db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 1)
+			So(user.GetCharacterCount(), ShouldEqual, 2)
+			So(user.Version, ShouldEqual, 2)
+		})
+
+		Convey("Removing an unknown API key should not modify the user", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			user, err = db.RemoveAPIKeyFromUser(ctx, user, 1337)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 2)
+
+			user, err = db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.Version, ShouldEqual, 1)
+		})
+	})
+}
+
+func testToggle(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Toggling the granted state of roles", t, func() {
+		db, f := setup(factory)
+
+		Convey("Toggling a user role should flip its state and modify its user", func() {
+			userRole, err := db.ToggleUserRoleGranted(ctx, f.test1.UserRoles[0].ID)
+			So(err, ShouldBeNil)
+			So(userRole.Granted, ShouldBeTrue)
+
+			userRole, err = db.LoadUserRole(ctx, userRole.ID)
+			So(err, ShouldBeNil)
+			So(userRole.Granted, ShouldBeTrue)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.Version, ShouldEqual, 2)
+
+			userRole, err = db.ToggleUserRoleGranted(ctx, userRole.ID)
+			So(err, ShouldBeNil)
+			So(userRole.Granted, ShouldBeFalse)
+		})
+
+		Convey("Toggling a group role should flip its state and modify its group", func() {
+			groupRole, err := db.ToggleGroupRoleGranted(ctx, f.dankAccess.GroupRoles[0].ID)
+			So(err, ShouldBeNil)
+			So(groupRole.Granted, ShouldBeTrue)
+
+			group, err := db.LoadGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(group.GroupRoles[0].Granted, ShouldBeTrue)
+			So(group.Version, ShouldEqual, 2)
+		})
+
+		Convey("Toggling nonexistent roles should fail", func() {
+			_, err := db.ToggleUserRoleGranted(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.ToggleGroupRoleGranted(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
@@ -0,0 +1,2 @@
+// Package conformancetest provides a test suite verifying that a database backend behaves like every other implementation of database.Connection.
+package conformancetest
@@ -0,0 +1,157 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testGroupApplications(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving group visibilities", t, func() {
+		db, f := setup(factory)
+
+		Convey("Should default to hidden groups", func() {
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(group.Visibility, ShouldEqual, models.GroupVisibilityHidden)
+		})
+
+		Convey("Should update the visibility of existing groups", func() {
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+
+			group.Visibility = models.GroupVisibilityApplication
+
+			_, err = db.SaveGroup(ctx, group)
+			So(err, ShouldBeNil)
+
+			group, err = db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(group.Visibility, ShouldEqual, models.GroupVisibilityApplication)
+
+			groups, err := db.LoadAllGroups(ctx)
+			So(err, ShouldBeNil)
+
+			for _, grp := range groups {
+				if grp.ID == f.testGroup.ID {
+					So(grp.Visibility, ShouldEqual, models.GroupVisibilityApplication)
+				}
+			}
+		})
+
+		Convey("Should store the visibility of new groups", func() {
+			group := models.NewGroup("Open Group", true)
+			group.Visibility = models.GroupVisibilityOpen
+
+			group, err := db.SaveGroup(ctx, group)
+			So(err, ShouldBeNil)
+
+			group, err = db.LoadGroup(ctx, group.ID)
+			So(err, ShouldBeNil)
+			So(group.Visibility, ShouldEqual, models.GroupVisibilityOpen)
+		})
+	})
+
+	Convey("Saving group applications", t, func() {
+		db, f := setup(factory)
+
+		testGroupApplication, err := db.SaveGroupApplication(ctx, models.NewGroupApplication(f.testGroup.ID, f.test3.ID, "Please let me in"))
+		So(err, ShouldBeNil)
+		So(testGroupApplication.ID, ShouldBeGreaterThan, 0)
+
+		dankAccessApplication, err := db.SaveGroupApplication(ctx, models.NewGroupApplication(f.dankAccess.ID, f.test3.ID, ""))
+		So(err, ShouldBeNil)
+
+		_, err = db.SaveGroupApplication(ctx, models.NewGroupApplication(f.dankAccess.ID, f.test1.ID, "Me too"))
+		So(err, ShouldBeNil)
+
+		Convey("Should load a group application", func() {
+			groupApplication, err := db.LoadGroupApplication(ctx, testGroupApplication.ID)
+			So(err, ShouldBeNil)
+			So(groupApplication.GroupID, ShouldEqual, f.testGroup.ID)
+			So(groupApplication.UserID, ShouldEqual, f.test3.ID)
+			So(groupApplication.Status, ShouldEqual, models.GroupApplicationStatusPending)
+			So(groupApplication.Message, ShouldEqual, "Please let me in")
+			So(groupApplication.DeciderID, ShouldEqual, -1)
+			So(groupApplication.DecidedAt, ShouldBeNil)
+			So(groupApplication.CreatedAt.Unix(), ShouldEqual, testGroupApplication.CreatedAt.Unix())
+
+			_, err = db.LoadGroupApplication(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+
+		Convey("Should load all group applications", func() {
+			groupApplications, err := db.LoadAllGroupApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 3)
+			So(groupApplications[0].ID, ShouldEqual, testGroupApplication.ID)
+		})
+
+		Convey("Should load the group applications of a user", func() {
+			groupApplications, err := db.LoadAllGroupApplicationsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 2)
+			So(groupApplications[1].ID, ShouldEqual, dankAccessApplication.ID)
+		})
+
+		Convey("Should store decisions and only load pending group applications", func() {
+			dankAccessApplication.Decide(f.test1.ID, true, "Welcome")
+
+			_, err := db.SaveGroupApplication(ctx, dankAccessApplication)
+			So(err, ShouldBeNil)
+
+			groupApplication, err := db.LoadGroupApplication(ctx, dankAccessApplication.ID)
+			So(err, ShouldBeNil)
+			So(groupApplication.Status, ShouldEqual, models.GroupApplicationStatusApproved)
+			So(groupApplication.Comment, ShouldEqual, "Welcome")
+			So(groupApplication.DeciderID, ShouldEqual, f.test1.ID)
+			So(groupApplication.DecidedAt, ShouldNotBeNil)
+
+			groupApplications, err := db.LoadPendingGroupApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 2)
+			So(groupApplications[0].ID, ShouldEqual, testGroupApplication.ID)
+		})
+
+		Convey("Should hide the group applications of deleted groups and remove them once purged", func() {
+			So(db.DeleteGroup(ctx, f.dankAccess.ID, f.test1.ID), ShouldBeNil)
+
+			groupApplications, err := db.LoadAllGroupApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 1)
+
+			groupApplications, err = db.LoadAllGroupApplicationsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 1)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			groupApplications, err = db.LoadAllGroupApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 1)
+		})
+
+		Convey("Should hide the group applications of deleted users and remove them once purged", func() {
+			So(db.DeleteUser(ctx, f.test3.ID, f.test1.ID), ShouldBeNil)
+
+			groupApplications, err := db.LoadPendingGroupApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 1)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			groupApplications, err = db.LoadAllGroupApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupApplications), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testImplications(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving role implications", t, func() {
+		db, f := setup(factory)
+
+		So(db.SaveRoleImplications(ctx, f.pingAll.ID, []int64{f.logisticsWrite.ID}), ShouldBeNil)
+		So(db.SaveRoleImplications(ctx, f.logisticsWrite.ID, []int64{f.logisticsRead.ID, f.logisticsRead.ID}), ShouldBeNil)
+
+		Convey("Should load implied roles recursively", func() {
+			role, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+			So(roleNames(role.ImpliedRoles), ShouldResemble, []string{"logistics.write"})
+			So(roleNames(role.ImpliedRoles[0].ImpliedRoles), ShouldResemble, []string{"logistics.read"})
+			So(len(role.ImpliedRoleClosure()), ShouldEqual, 2)
+
+			roleImplications, err := db.LoadAllRoleImplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(roleImplications), ShouldEqual, 2)
+		})
+
+		Convey("Should increase the version of the implying role", func() {
+			role, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+			So(role.Version, ShouldEqual, f.pingAll.Version+1)
+		})
+
+		Convey("Should reject implications causing a cycle without modifying existing ones", func() {
+			err := db.SaveRoleImplications(ctx, f.logisticsRead.ID, []int64{f.pingAll.ID})
+			So(database.IsImplicationCycle(err), ShouldBeTrue)
+
+			err = db.SaveRoleImplications(ctx, f.logisticsRead.ID, []int64{f.logisticsRead.ID})
+			So(database.IsImplicationCycle(err), ShouldBeTrue)
+
+			roleImplications, err := db.LoadAllRoleImplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(roleImplications), ShouldEqual, 2)
+		})
+
+		Convey("Should replace the existing implications of the role", func() {
+			So(db.SaveRoleImplications(ctx, f.pingAll.ID, []int64{}), ShouldBeNil)
+
+			role, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+			So(len(role.ImpliedRoles), ShouldEqual, 0)
+		})
+
+		Convey("Should grant implied roles to users unless they are denied", func() {
+			fleet, err := db.SaveRole(ctx, models.NewRole("fleet", true, false))
+			So(err, ShouldBeNil)
+
+			So(db.SaveRoleImplications(ctx, f.logisticsRead.ID, []int64{fleet.ID}), ShouldBeNil)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.HasRole("fleet"), ShouldEqual, models.RoleStatusGranted)
+			So(user.HasRole("logistics.write"), ShouldEqual, models.RoleStatusDenied)
+			So(user.ToAuthUser().Roles, ShouldContain, "fleet")
+			So(user.ToAuthUser().Roles, ShouldNotContain, "logistics.write")
+		})
+
+		Convey("Should ignore implications of deleted roles", func() {
+			So(db.DeleteRole(ctx, f.logisticsWrite.ID, f.test1.ID), ShouldBeNil)
+
+			role, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+			So(len(role.ImpliedRoles), ShouldEqual, 0)
+
+			roleImplications, err := db.LoadAllRoleImplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(roleImplications), ShouldEqual, 0)
+		})
+
+		Convey("Should return sql.ErrNoRows for unknown roles", func() {
+			So(db.SaveRoleImplications(ctx, 1337, []int64{f.pingAll.ID}), ShouldEqual, sql.ErrNoRows)
+			So(db.SaveRoleImplications(ctx, f.pingAll.ID, []int64{1337}), ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
@@ -0,0 +1,628 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testMigrations(t *testing.T, factory Factory) {
+	Convey("Retrieving the migration status of a new database", t, func() {
+		db, _ := setup(factory)
+
+		status, err := db.MigrationStatus()
+		So(err, ShouldBeNil)
+		So(status.Outdated(), ShouldBeFalse)
+		So(len(status.Pending), ShouldEqual, 0)
+	})
+}
+
+func testPing(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Pinging a connected database", t, func() {
+		db, _ := setup(factory)
+
+		So(db.Ping(ctx), ShouldBeNil)
+
+		Convey("Pinging within a transaction should succeed", func() {
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				return tx.Ping(ctx)
+			})
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func testTransactions(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Running functions within a transaction", t, func() {
+		db, _ := setup(factory)
+
+		Convey("Returning nil should commit all changes", func() {
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				_, err := tx.SaveRole(ctx, models.NewRole("transaction.commit", true, false))
+				return err
+			})
+			So(err, ShouldBeNil)
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(roleNames(roles), ShouldContain, "transaction.commit")
+		})
+
+		Convey("Returning an error should roll back all changes", func() {
+			rollback := errors.New("rollback")
+
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				_, err := tx.SaveRole(ctx, models.NewRole("transaction.rollback", true, false))
+				if err != nil {
+					return err
+				}
+
+				return rollback
+			})
+			So(err, ShouldEqual, rollback)
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(roleNames(roles), ShouldNotContain, "transaction.rollback")
+		})
+	})
+}
+
+func testLoadAll(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Loading all entries", t, func() {
+		db, f := setup(factory)
+
+		Convey("Should return all accounts including their characters", func() {
+			accounts, err := db.LoadAllAccounts(ctx)
+			So(err, ShouldBeNil)
+			So(len(accounts), ShouldEqual, 2)
+
+			characters := 0
+			for _, account := range accounts {
+				characters += len(account.Characters)
+			}
+			So(characters, ShouldEqual, 3)
+		})
+
+		Convey("Should return all corporations", func() {
+			corporations, err := db.LoadAllCorporations(ctx)
+			So(err, ShouldBeNil)
+			So(len(corporations), ShouldEqual, 2)
+		})
+
+		Convey("Should return all characters", func() {
+			characters, err := db.LoadAllCharacters(ctx)
+			So(err, ShouldBeNil)
+			So(len(characters), ShouldEqual, 3)
+		})
+
+		Convey("Should return all roles", func() {
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 4)
+		})
+
+		Convey("Should return all group roles including their roles", func() {
+			groupRoles, err := db.LoadAllGroupRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupRoles), ShouldEqual, 3)
+
+			for _, groupRole := range groupRoles {
+				So(groupRole.Role, ShouldNotBeNil)
+				So(groupRole.Role.Name, ShouldNotBeEmpty)
+			}
+		})
+
+		Convey("Should return all user roles including their roles", func() {
+			userRoles, err := db.LoadAllUserRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(userRoles), ShouldEqual, 2)
+
+			for _, userRole := range userRoles {
+				So(userRole.Role, ShouldNotBeNil)
+				So(userRole.Role.Name, ShouldNotBeEmpty)
+			}
+		})
+
+		Convey("Should return all groups including their group roles", func() {
+			groups, err := db.LoadAllGroups(ctx)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 2)
+
+			for _, group := range groups {
+				if group.ID == f.testGroup.ID {
+					So(len(group.GroupRoles), ShouldEqual, 2)
+				} else {
+					So(len(group.GroupRoles), ShouldEqual, 1)
+				}
+			}
+		})
+
+		Convey("Should return all users including their accounts, groups and user roles", func() {
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(usernames(users), ShouldResemble, []string{"test1", "test2", "test3"})
+
+			So(users[0].GetCharacterCount(), ShouldEqual, 3)
+			So(len(users[1].Groups), ShouldEqual, 2)
+			So(len(users[1].UserRoles), ShouldEqual, 1)
+			So(len(users[2].Accounts), ShouldEqual, 0)
+		})
+
+		Convey("Should return all applications", func() {
+			applications, err := db.LoadAllApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(applications), ShouldEqual, 2)
+		})
+
+		Convey("Should return all saved login attempts", func() {
+			err := db.SaveLoginAttempt(ctx, models.NewLoginAttempt("test1", "127.0.0.1", "conformancetest", false))
+			So(err, ShouldBeNil)
+
+			loginAttempts, err := db.LoadAllLoginAttempts(ctx)
+			So(err, ShouldBeNil)
+			So(len(loginAttempts), ShouldEqual, 1)
+			So(loginAttempts[0].Username, ShouldEqual, "test1")
+			So(loginAttempts[0].UserAgent, ShouldEqual, "conformancetest")
+			So(loginAttempts[0].Successful, ShouldBeFalse)
+		})
+
+		Convey("Should return all saved CSRF failures", func() {
+			err := db.SaveCSRFFailure(ctx, &models.CSRFFailure{UserID: f.test1.ID, Request: "{}"})
+			So(err, ShouldBeNil)
+
+			csrfFailures, err := db.LoadAllCSRFFailures(ctx)
+			So(err, ShouldBeNil)
+			So(len(csrfFailures), ShouldEqual, 1)
+			So(csrfFailures[0].UserID, ShouldEqual, f.test1.ID)
+			So(csrfFailures[0].Timestamp.IsZero(), ShouldBeFalse)
+		})
+	})
+}
+
+func testQuery(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Querying pages of entries", t, func() {
+		db, f := setup(factory)
+		criteria := database.NewListCriteria()
+
+		Convey("Should return all users sorted by ID by default", func() {
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(usernames(users), ShouldResemble, []string{"test1", "test2", "test3"})
+		})
+
+		Convey("Should filter users by username and email", func() {
+			criteria.Filter = "test2"
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(usernames(users), ShouldResemble, []string{"test2"})
+
+			criteria.Filter = "test3@example"
+
+			users, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(usernames(users), ShouldResemble, []string{"test3"})
+
+			criteria.Filter = "test_"
+
+			users, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 0)
+			So(users, ShouldBeEmpty)
+
+			criteria.Filter = "%"
+
+			_, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 0)
+		})
+
+		Convey("Should filter users by their active state", func() {
+			criteria.Active = database.ActiveFilterInactive
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(usernames(users), ShouldResemble, []string{"test2"})
+		})
+
+		Convey("Should filter users by group membership", func() {
+			criteria.GroupID = f.dankAccess.ID
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(usernames(users), ShouldResemble, []string{"test2"})
+		})
+
+		Convey("Should filter users by the corporation of their characters", func() {
+			criteria.CorporationID = f.otherCorporation.ID
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(usernames(users), ShouldResemble, []string{"test1"})
+		})
+
+		Convey("Should sort and page users", func() {
+			criteria.SortField = "username"
+			criteria.SortDirection = database.SortDescending
+			criteria.Limit = 1
+			criteria.Offset = 1
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(usernames(users), ShouldResemble, []string{"test2"})
+		})
+
+		Convey("Should sort groups by name", func() {
+			criteria.SortField = "name"
+
+			groups, total, err := db.QueryGroups(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(groupNames(groups), ShouldResemble, []string{"Dank Access", "Test Group"})
+			So(len(groups[1].GroupRoles), ShouldEqual, 2)
+		})
+
+		Convey("Should filter roles by name and sort them by their locked state", func() {
+			criteria.Filter = "logistics"
+
+			roles, total, err := db.QueryRoles(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(roleNames(roles), ShouldResemble, []string{"logistics.read", "logistics.write"})
+
+			criteria.Filter = ""
+			criteria.SortField = "locked"
+			criteria.SortDirection = database.SortDescending
+
+			roles, _, err = db.QueryRoles(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(roles[0].Name, ShouldEqual, "destroy.world")
+		})
+
+		Convey("Should filter applications by their active state", func() {
+			criteria.Active = database.ActiveFilterActive
+
+			applications, total, err := db.QueryApplications(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(applications[0].Name, ShouldEqual, "Testapp")
+		})
+	})
+}
+
+func testLoadSingle(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Loading single entries", t, func() {
+		db, f := setup(factory)
+		mainAccount := f.test1.Accounts[0]
+
+		Convey("Should return the account including its characters", func() {
+			account, err := db.LoadAccount(ctx, mainAccount.ID)
+			So(err, ShouldBeNil)
+			So(account.UserID, ShouldEqual, f.test1.ID)
+			So(account.APIKeyID, ShouldEqual, 1)
+			So(len(account.Characters), ShouldEqual, 2)
+		})
+
+		Convey("Should return the corporation by ID and EVE corporation ID", func() {
+			corporation, err := db.LoadCorporation(ctx, f.corporation.ID)
+			So(err, ShouldBeNil)
+			So(corporation.Ticker, ShouldEqual, "TEST")
+			So(corporation.APIKeyID.Int64, ShouldEqual, 1)
+
+			corporation, err = db.LoadCorporationFromEVECorporationID(ctx, 2)
+			So(err, ShouldBeNil)
+			So(corporation.ID, ShouldEqual, f.otherCorporation.ID)
+			So(corporation.Active, ShouldBeFalse)
+
+			name, err := db.LoadCorporationNameFromID(ctx, f.corporation.ID)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Test Corp Please Ignore")
+		})
+
+		Convey("Should return the character", func() {
+			character, err := db.LoadCharacter(ctx, mainAccount.Characters[1].ID)
+			So(err, ShouldBeNil)
+			So(character.Name, ShouldEqual, "Herp")
+			So(character.AccountID, ShouldEqual, mainAccount.ID)
+			So(character.CorporationID, ShouldEqual, f.corporation.ID)
+			So(character.DefaultCharacter, ShouldBeFalse)
+		})
+
+		Convey("Should return the role", func() {
+			role, err := db.LoadRole(ctx, f.destroyWorld.ID)
+			So(err, ShouldBeNil)
+			So(role.Name, ShouldEqual, "destroy.world")
+			So(role.Active, ShouldBeFalse)
+			So(role.Locked, ShouldBeTrue)
+		})
+
+		Convey("Should return the group role including its role", func() {
+			groupRole, err := db.LoadGroupRole(ctx, f.dankAccess.GroupRoles[0].ID)
+			So(err, ShouldBeNil)
+			So(groupRole.GroupID, ShouldEqual, f.dankAccess.ID)
+			So(groupRole.Role.Name, ShouldEqual, "destroy.world")
+			So(groupRole.Granted, ShouldBeFalse)
+		})
+
+		Convey("Should return the user role including its role", func() {
+			userRole, err := db.LoadUserRole(ctx, f.test2.UserRoles[0].ID)
+			So(err, ShouldBeNil)
+			So(userRole.UserID, ShouldEqual, f.test2.ID)
+			So(userRole.Role.Name, ShouldEqual, "ping.all")
+			So(userRole.AutoAdded, ShouldBeTrue)
+			So(userRole.Granted, ShouldBeTrue)
+		})
+
+		Convey("Should return the group including its group roles", func() {
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(group.Name, ShouldEqual, "Test Group")
+			So(group.Version, ShouldEqual, 1)
+			So(len(group.GroupRoles), ShouldEqual, 2)
+		})
+
+		Convey("Should return the user including its accounts, groups and user roles", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.Username, ShouldEqual, "test1")
+			So(user.VerifiedEmail, ShouldBeTrue)
+			So(user.Version, ShouldEqual, 1)
+			So(len(user.Accounts), ShouldEqual, 2)
+			So(user.GetCharacterCount(), ShouldEqual, 3)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Test Group"})
+			So(len(user.UserRoles), ShouldEqual, 1)
+			So(user.UserRoles[0].Role.Name, ShouldEqual, "logistics.write")
+		})
+
+		Convey("Should return the user by username", func() {
+			user, err := db.LoadUserFromUsername(ctx, "test2")
+			So(err, ShouldBeNil)
+			So(user.ID, ShouldEqual, f.test2.ID)
+			So(len(user.Groups), ShouldEqual, 2)
+		})
+
+		Convey("Should return the application", func() {
+			application, err := db.LoadApplication(ctx, f.otherApp.ID)
+			So(err, ShouldBeNil)
+			So(application.Name, ShouldEqual, "Apptest")
+			So(application.MaintainerID, ShouldEqual, f.test2.ID)
+			So(application.Secret, ShouldEqual, "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+			So(application.Active, ShouldBeFalse)
+		})
+	})
+}
+
+func testLoadInvalid(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Loading nonexistent entries", t, func() {
+		db, _ := setup(factory)
+
+		_, err := db.LoadAccount(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadCorporation(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadCorporationFromEVECorporationID(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadCorporationNameFromID(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadCharacter(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadRole(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadGroupRole(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadUserRole(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadGroup(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadUser(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadUserFromUsername(ctx, "nobody")
+		So(err, ShouldEqual, sql.ErrNoRows)
+
+		_, err = db.LoadApplication(ctx, 1337)
+		So(err, ShouldEqual, sql.ErrNoRows)
+	})
+}
+
+func testLoadForOwner(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Loading entries associated with an owner", t, func() {
+		db, f := setup(factory)
+
+		Convey("Should return the accounts of a user", func() {
+			accounts, err := db.LoadAllAccountsForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(accounts), ShouldEqual, 2)
+
+			accounts, err = db.LoadAllAccountsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(accounts), ShouldEqual, 0)
+		})
+
+		Convey("Should return the characters of an account", func() {
+			characters, err := db.LoadAllCharactersForAccount(ctx, f.test1.Accounts[1].ID)
+			So(err, ShouldBeNil)
+			So(len(characters), ShouldEqual, 1)
+			So(characters[0].Name, ShouldEqual, "Spai")
+		})
+
+		Convey("Should return the group roles of a group", func() {
+			groupRoles, err := db.LoadAllGroupRolesForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(groupRoles), ShouldEqual, 2)
+
+			var names []*models.Role
+			for _, groupRole := range groupRoles {
+				names = append(names, groupRole.Role)
+			}
+			So(roleNames(names), ShouldContain, "ping.all")
+			So(roleNames(names), ShouldContain, "logistics.read")
+		})
+
+		Convey("Should return the user roles of a user", func() {
+			userRoles, err := db.LoadAllUserRolesForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(userRoles), ShouldEqual, 1)
+			So(userRoles[0].Role.Name, ShouldEqual, "logistics.write")
+			So(userRoles[0].Granted, ShouldBeFalse)
+		})
+
+		Convey("Should return the groups of a user including their group roles", func() {
+			groups, err := db.LoadAllGroupsForUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 2)
+
+			for _, group := range groups {
+				So(len(group.GroupRoles), ShouldBeGreaterThan, 0)
+			}
+
+			groups, err = db.LoadAllGroupsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 0)
+		})
+
+		Convey("Should return the applications maintained by a user", func() {
+			applications, err := db.LoadAllApplicationsForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(len(applications), ShouldEqual, 1)
+			So(applications[0].Name, ShouldEqual, "Testapp")
+		})
+	})
+}
+
+func testLoadAvailable(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Loading entries available for assignment", t, func() {
+		db, f := setup(factory)
+
+		Convey("Should return all groups a user is not a member of, sorted by name", func() {
+			groups, err := db.LoadAvailableGroupsForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Dank Access"})
+
+			groups, err = db.LoadAvailableGroupsForUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{})
+
+			groups, err = db.LoadAvailableGroupsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Dank Access", "Test Group"})
+		})
+
+		Convey("Should return groups a user has been removed from again", func() {
+			_, err := db.RemoveUserFromGroup(ctx, f.test1.ID, f.testGroup.ID)
+			So(err, ShouldBeNil)
+
+			groups, err := db.LoadAvailableGroupsForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Dank Access", "Test Group"})
+		})
+
+		Convey("Should not return deleted groups", func() {
+			err := db.DeleteGroup(ctx, f.dankAccess.ID, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			groups, err := db.LoadAvailableGroupsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Test Group"})
+		})
+
+		Convey("Should return all roles not assigned to a user, sorted by name", func() {
+			roles, err := db.LoadAvailableUserRolesForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(roleNames(roles), ShouldResemble, []string{"destroy.world", "logistics.read", "ping.all"})
+
+			roles, err = db.LoadAvailableUserRolesForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 4)
+		})
+
+		Convey("Should return all roles not assigned to a group, sorted by name", func() {
+			roles, err := db.LoadAvailableGroupRolesForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(roleNames(roles), ShouldResemble, []string{"destroy.world", "logistics.write"})
+		})
+
+		Convey("Should not return deleted roles", func() {
+			err := db.DeleteRole(ctx, f.destroyWorld.ID, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			roles, err := db.LoadAvailableGroupRolesForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(roleNames(roles), ShouldResemble, []string{"logistics.write"})
+		})
+	})
+}
+
+func testCredentials(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Checking user credentials and existence", t, func() {
+		db, f := setup(factory)
+
+		password, err := db.LoadPasswordForUser(ctx, "test1")
+		So(err, ShouldBeNil)
+		So(password, ShouldEqual, "$2a$10$veif8VUZt7lShFhJKD0wGeY1YjCwIuWjYL0vQzlTqu8wNaYQMqzbe")
+
+		_, err = db.LoadPasswordForUser(ctx, "nobody")
+		So(err, ShouldNotBeNil)
+
+		exists, err := db.QueryUserIDExists(ctx, f.test2.ID)
+		So(err, ShouldBeNil)
+		So(exists, ShouldBeTrue)
+
+		exists, err = db.QueryUserIDExists(ctx, 1337)
+		So(err, ShouldBeNil)
+		So(exists, ShouldBeFalse)
+
+		exists, err = db.QueryUserNameEmailExists(ctx, "test3", "nobody@example.com")
+		So(err, ShouldBeNil)
+		So(exists, ShouldBeTrue)
+
+		exists, err = db.QueryUserNameEmailExists(ctx, "nobody", "test2@example.com")
+		So(err, ShouldBeNil)
+		So(exists, ShouldBeTrue)
+
+		exists, err = db.QueryUserNameEmailExists(ctx, "nobody", "nobody@example.com")
+		So(err, ShouldBeNil)
+		So(exists, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,137 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testGroupManagers(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving group managers", t, func() {
+		db, f := setup(factory)
+
+		testGroupManager, err := db.SaveGroupManager(ctx, models.NewGroupManager(f.testGroup.ID, f.test3.ID))
+		So(err, ShouldBeNil)
+		So(testGroupManager.ID, ShouldBeGreaterThan, 0)
+
+		dankAccessManager, err := db.SaveGroupManager(ctx, models.NewGroupManager(f.dankAccess.ID, f.test3.ID))
+		So(err, ShouldBeNil)
+
+		_, err = db.SaveGroupManager(ctx, models.NewGroupManager(f.dankAccess.ID, f.test2.ID))
+		So(err, ShouldBeNil)
+
+		Convey("Should load all group managers", func() {
+			groupManagers, err := db.LoadAllGroupManagers(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupManagers), ShouldEqual, 3)
+			So(groupManagers[0], ShouldResemble, testGroupManager)
+		})
+
+		Convey("Should load the managers of a group", func() {
+			groupManagers, err := db.LoadAllGroupManagersForGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(len(groupManagers), ShouldEqual, 2)
+			So(groupManagers[0], ShouldResemble, dankAccessManager)
+		})
+
+		Convey("Should load the groups managed by a user", func() {
+			groups, err := db.LoadAllManagedGroupsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Dank Access", "Test Group"})
+			So(len(groups[1].GroupRoles), ShouldEqual, 2)
+
+			groups, err = db.LoadAllManagedGroupsForUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(groups, ShouldBeEmpty)
+		})
+
+		Convey("Should reject duplicate group managers", func() {
+			_, err := db.SaveGroupManager(ctx, models.NewGroupManager(f.testGroup.ID, f.test3.ID))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should delete group managers", func() {
+			So(db.DeleteGroupManager(ctx, testGroupManager.ID), ShouldBeNil)
+
+			groups, err := db.LoadAllManagedGroupsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Dank Access"})
+		})
+
+		Convey("Should hide the managers of deleted groups and remove them once purged", func() {
+			So(db.DeleteGroup(ctx, f.dankAccess.ID, f.test1.ID), ShouldBeNil)
+
+			groups, err := db.LoadAllManagedGroupsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(groups), ShouldResemble, []string{"Test Group"})
+
+			groupManagers, err := db.LoadAllGroupManagers(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupManagers), ShouldEqual, 1)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			groupManagers, err = db.LoadAllGroupManagersForGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(groupManagers, ShouldBeEmpty)
+		})
+
+		Convey("Should hide deleted managers and remove them once purged", func() {
+			So(db.DeleteUser(ctx, f.test2.ID, f.test1.ID), ShouldBeNil)
+
+			groupManagers, err := db.LoadAllGroupManagersForGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(len(groupManagers), ShouldEqual, 1)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			groupManagers, err = db.LoadAllGroupManagers(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupManagers), ShouldEqual, 2)
+		})
+	})
+
+	Convey("Saving manager actions", t, func() {
+		db, f := setup(factory)
+
+		So(db.SaveManagerAction(ctx, models.NewManagerAction(f.testGroup.ID, f.test3.ID, f.test1.ID, models.ManagerActionTypeAddMember)), ShouldBeNil)
+		So(db.SaveManagerAction(ctx, models.NewManagerAction(f.testGroup.ID, f.test3.ID, f.test1.ID, models.ManagerActionTypeRemoveMember)), ShouldBeNil)
+		So(db.SaveManagerAction(ctx, &models.ManagerAction{GroupID: f.dankAccess.ID, ActorID: f.test3.ID, UserID: f.test2.ID, Type: models.ManagerActionTypeAddMember}), ShouldBeNil)
+
+		Convey("Should load all manager actions", func() {
+			managerActions, err := db.LoadAllManagerActions(ctx)
+			So(err, ShouldBeNil)
+			So(len(managerActions), ShouldEqual, 3)
+			So(managerActions[2].Timestamp.IsZero(), ShouldBeFalse)
+		})
+
+		Convey("Should load the manager actions of a group in order", func() {
+			managerActions, err := db.LoadAllManagerActionsForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(managerActions), ShouldEqual, 2)
+			So(managerActions[0].ActorID, ShouldEqual, f.test3.ID)
+			So(managerActions[0].UserID, ShouldEqual, f.test1.ID)
+			So(managerActions[0].Type, ShouldEqual, models.ManagerActionTypeAddMember)
+			So(managerActions[1].Type, ShouldEqual, models.ManagerActionTypeRemoveMember)
+		})
+
+		Convey("Should keep manager actions once the group has been purged", func() {
+			So(db.DeleteGroup(ctx, f.testGroup.ID, f.test1.ID), ShouldBeNil)
+
+			_, err := db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			managerActions, err := db.LoadAllManagerActionsForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(managerActions), ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,131 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testMembershipRules(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving membership rules", t, func() {
+		db, f := setup(factory)
+
+		corporationRule, err := db.SaveMembershipRule(ctx, models.NewMembershipRule(f.testGroup.ID, models.MembershipRuleTypeCorporation, f.corporation.ID))
+		So(err, ShouldBeNil)
+		So(corporationRule.ID, ShouldBeGreaterThan, 0)
+
+		allianceRule, err := db.SaveMembershipRule(ctx, models.NewMembershipRule(f.dankAccess.ID, models.MembershipRuleTypeAlliance, 1))
+		So(err, ShouldBeNil)
+
+		Convey("Should load all membership rules", func() {
+			membershipRules, err := db.LoadAllMembershipRules(ctx)
+			So(err, ShouldBeNil)
+			So(len(membershipRules), ShouldEqual, 2)
+			So(membershipRules[0], ShouldResemble, corporationRule)
+			So(membershipRules[1].Type, ShouldEqual, models.MembershipRuleTypeAlliance)
+		})
+
+		Convey("Should load the membership rules of a group", func() {
+			membershipRules, err := db.LoadAllMembershipRulesForGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(len(membershipRules), ShouldEqual, 1)
+			So(membershipRules[0].ID, ShouldEqual, allianceRule.ID)
+		})
+
+		Convey("Should reject duplicate membership rules", func() {
+			_, err := db.SaveMembershipRule(ctx, models.NewMembershipRule(f.testGroup.ID, models.MembershipRuleTypeCorporation, f.corporation.ID))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should update existing membership rules", func() {
+			allianceRule.Type = models.MembershipRuleTypeDefaultCorporation
+			allianceRule.TargetID = f.otherCorporation.ID
+
+			_, err := db.SaveMembershipRule(ctx, allianceRule)
+			So(err, ShouldBeNil)
+
+			membershipRules, err := db.LoadAllMembershipRulesForGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(membershipRules, ShouldResemble, []*models.MembershipRule{allianceRule})
+		})
+
+		Convey("Should delete membership rules", func() {
+			So(db.DeleteMembershipRule(ctx, corporationRule.ID), ShouldBeNil)
+
+			membershipRules, err := db.LoadAllMembershipRulesForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(membershipRules, ShouldBeEmpty)
+		})
+
+		Convey("Should hide the membership rules of deleted groups and remove them once purged", func() {
+			So(db.DeleteGroup(ctx, f.dankAccess.ID, f.test1.ID), ShouldBeNil)
+
+			membershipRules, err := db.LoadAllMembershipRules(ctx)
+			So(err, ShouldBeNil)
+			So(len(membershipRules), ShouldEqual, 1)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			membershipRules, err = db.LoadAllMembershipRulesForGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(membershipRules, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Saving automatically added group memberships", t, func() {
+		db, f := setup(factory)
+
+		test3, err := db.LoadUser(ctx, f.test3.ID)
+		So(err, ShouldBeNil)
+
+		test3.Groups = append(test3.Groups, f.testGroup, f.dankAccess)
+		test3.AutoAddedGroups = map[int64]bool{f.testGroup.ID: true}
+
+		_, err = db.SaveUser(ctx, test3)
+		So(err, ShouldBeNil)
+
+		Convey("Should load which memberships have been added automatically", func() {
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 2)
+			So(user.IsGroupAutoAdded(f.testGroup.ID), ShouldBeTrue)
+			So(user.IsGroupAutoAdded(f.dankAccess.ID), ShouldBeFalse)
+
+			user, err = db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.AutoAddedGroups, ShouldBeNil)
+		})
+
+		Convey("Should turn memberships into manual ones once they are no longer marked", func() {
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+
+			delete(user.AutoAddedGroups, f.testGroup.ID)
+
+			_, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 2)
+			So(user.AutoAddedGroups, ShouldBeNil)
+		})
+
+		Convey("Should forget the mark when removing the membership", func() {
+			user, err := db.RemoveUserFromGroup(ctx, f.test3.ID, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(user.IsGroupAutoAdded(f.testGroup.ID), ShouldBeFalse)
+
+			user, err = db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(user.AutoAddedGroups, ShouldBeNil)
+		})
+	})
+}
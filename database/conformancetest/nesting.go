@@ -0,0 +1,109 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testGroupNestings(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving group nestings", t, func() {
+		db, f := setup(factory)
+
+		fleet, err := db.SaveRole(ctx, models.NewRole("fleet", true, false))
+		So(err, ShouldBeNil)
+
+		fleetGroup := models.NewGroup("Fleet Group", true)
+		fleetGroup.GroupRoles = append(fleetGroup.GroupRoles, models.NewGroupRole(-1, fleet, false, true))
+
+		fleetGroup, err = db.SaveGroup(ctx, fleetGroup)
+		So(err, ShouldBeNil)
+
+		So(db.SaveSubGroups(ctx, f.testGroup.ID, []int64{fleetGroup.ID, fleetGroup.ID}), ShouldBeNil)
+		So(db.SaveSubGroups(ctx, fleetGroup.ID, []int64{f.dankAccess.ID}), ShouldBeNil)
+
+		Convey("Should load sub groups recursively", func() {
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(group.SubGroups), ShouldResemble, []string{"Fleet Group"})
+			So(groupNames(group.SubGroups[0].SubGroups), ShouldResemble, []string{"Dank Access"})
+			So(len(group.SubGroupClosure()), ShouldEqual, 1)
+
+			groupNestings, err := db.LoadAllGroupNestings(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupNestings), ShouldEqual, 2)
+		})
+
+		Convey("Should increase the version of the containing group", func() {
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(group.Version, ShouldEqual, f.testGroup.Version+1)
+		})
+
+		Convey("Should reject nestings causing a cycle without modifying existing ones", func() {
+			err := db.SaveSubGroups(ctx, f.dankAccess.ID, []int64{f.testGroup.ID})
+			So(database.IsNestingCycle(err), ShouldBeTrue)
+
+			err = db.SaveSubGroups(ctx, f.dankAccess.ID, []int64{f.dankAccess.ID})
+			So(database.IsNestingCycle(err), ShouldBeTrue)
+
+			groupNestings, err := db.LoadAllGroupNestings(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupNestings), ShouldEqual, 2)
+		})
+
+		Convey("Should replace the existing sub groups of the group", func() {
+			So(db.SaveSubGroups(ctx, f.testGroup.ID, []int64{}), ShouldBeNil)
+
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(group.SubGroups), ShouldEqual, 0)
+		})
+
+		Convey("Should grant the roles of sub groups to members of the containing group", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Test Group"})
+			So(user.HasRole("fleet"), ShouldEqual, models.RoleStatusGranted)
+			So(user.ToAuthUser().Roles, ShouldContain, "fleet")
+
+			user, err = db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(user.HasRole("fleet"), ShouldEqual, models.RoleStatusNonExistent)
+		})
+
+		Convey("Should hide the nestings of deleted groups and remove them once purged", func() {
+			So(db.DeleteGroup(ctx, fleetGroup.ID, f.test1.ID), ShouldBeNil)
+
+			group, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(group.SubGroups), ShouldEqual, 0)
+
+			groupNestings, err := db.LoadAllGroupNestings(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupNestings), ShouldEqual, 0)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			So(db.SaveSubGroups(ctx, f.testGroup.ID, []int64{f.dankAccess.ID}), ShouldBeNil)
+
+			groupNestings, err = db.LoadAllGroupNestings(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupNestings), ShouldEqual, 1)
+		})
+
+		Convey("Should return sql.ErrNoRows for unknown groups", func() {
+			So(db.SaveSubGroups(ctx, 1337, []int64{f.testGroup.ID}), ShouldEqual, sql.ErrNoRows)
+			So(db.SaveSubGroups(ctx, f.testGroup.ID, []int64{1337}), ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+func testRolePolicies(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Granting roles based on policies", t, func() {
+		db, f := setup(factory)
+
+		role, err := db.LoadRole(ctx, f.pingAll.ID)
+		So(err, ShouldBeNil)
+		So(role.Policy, ShouldBeEmpty)
+
+		role.Policy = `corporation.ticker == "TEST" and alliance.ticker == "TEST"`
+
+		_, err = db.SaveRole(ctx, role)
+		So(err, ShouldBeNil)
+
+		Convey("Should load the policy of roles", func() {
+			role, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+			So(role.Policy, ShouldEqual, `corporation.ticker == "TEST" and alliance.ticker == "TEST"`)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.Groups[0].GroupRoles[0].Role.Policy, ShouldNotBeEmpty)
+		})
+
+		Convey("Should load the corporation and alliance of the default character", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.DefaultCorporation.ID, ShouldEqual, f.corporation.ID)
+			So(user.DefaultAlliance, ShouldBeNil)
+			So(user.HasRole("ping.all"), ShouldEqual, models.RoleStatusDenied)
+			So(user.HasRole("logistics.read"), ShouldEqual, models.RoleStatusGranted)
+
+			alliance, err := db.SaveAlliance(ctx, models.NewAlliance("Test Alliance Please Ignore", "TEST", 99000001, f.corporation.EVECorporationID, true))
+			So(err, ShouldBeNil)
+
+			corporation, err := db.LoadCorporation(ctx, f.corporation.ID)
+			So(err, ShouldBeNil)
+
+			corporation.AllianceID = zero.IntFrom(alliance.ID)
+
+			_, err = db.SaveCorporation(ctx, corporation)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUserFromUsername(ctx, "test1")
+			So(err, ShouldBeNil)
+			So(user.DefaultAlliance.ID, ShouldEqual, alliance.ID)
+			So(user.HasRole("ping.all"), ShouldEqual, models.RoleStatusGranted)
+
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+
+			for _, u := range users {
+				if u.ID == f.test1.ID {
+					So(u.GetEffectiveRoles(), ShouldContainKey, f.pingAll.ID)
+				}
+			}
+		})
+
+		Convey("Should grant the role unconditionally after removing its policy", func() {
+			role, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			role.Policy = ""
+
+			_, err = db.SaveRole(ctx, role)
+			So(err, ShouldBeNil)
+
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+			So(user.HasRole("ping.all"), ShouldEqual, models.RoleStatusGranted)
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testPrune(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Pruning login attempts and CSRF failures", t, func() {
+		db, f := setup(factory)
+
+		now := time.Now().UTC()
+		old := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC).AddDate(0, 0, -10)
+
+		for _, attempt := range []struct {
+			successful bool
+			timestamp  time.Time
+		}{
+			{true, old},
+			{false, old},
+			{false, old.Add(time.Hour)},
+			{false, old.AddDate(0, 0, -1)},
+			{true, now},
+		} {
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", RemoteAddr: "127.0.0.1", UserAgent: "conformancetest", Successful: attempt.successful, Timestamp: attempt.timestamp}), ShouldBeNil)
+		}
+
+		Convey("Pruning login attempts should add them to the daily summaries", func() {
+			pruned, err := db.PruneLoginAttempts(ctx, now.AddDate(0, 0, -1))
+			So(err, ShouldBeNil)
+			So(pruned, ShouldEqual, 4)
+
+			loginAttempts, err := db.LoadAllLoginAttempts(ctx)
+			So(err, ShouldBeNil)
+			So(len(loginAttempts), ShouldEqual, 1)
+			So(loginAttempts[0].Successful, ShouldBeTrue)
+
+			summaries, err := db.LoadAllLoginAttemptSummaries(ctx)
+			So(err, ShouldBeNil)
+			So(summaries, ShouldResemble, []*models.LoginAttemptSummary{
+				{Day: old.AddDate(0, 0, -1).Format("2006-01-02"), Failed: 1, Successful: 0},
+				{Day: old.Format("2006-01-02"), Failed: 2, Successful: 1},
+			})
+
+			pruned, err = db.PruneLoginAttempts(ctx, now.AddDate(0, 0, -1))
+			So(err, ShouldBeNil)
+			So(pruned, ShouldEqual, 0)
+		})
+
+		Convey("Pruning login attempts of an already summarised day should add to its summary", func() {
+			_, err := db.PruneLoginAttempts(ctx, now.AddDate(0, 0, -1))
+			So(err, ShouldBeNil)
+
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test2", RemoteAddr: "127.0.0.1", UserAgent: "conformancetest", Successful: false, Timestamp: old.Add(2 * time.Hour)}), ShouldBeNil)
+
+			pruned, err := db.PruneLoginAttempts(ctx, now.AddDate(0, 0, -1))
+			So(err, ShouldBeNil)
+			So(pruned, ShouldEqual, 1)
+
+			summaries, err := db.LoadAllLoginAttemptSummaries(ctx)
+			So(err, ShouldBeNil)
+			So(len(summaries), ShouldEqual, 2)
+			So(summaries[1].Failed, ShouldEqual, 3)
+			So(summaries[1].Successful, ShouldEqual, 1)
+		})
+
+		Convey("The login attempts report should include pruned login attempts", func() {
+			_, err := db.PruneLoginAttempts(ctx, now.AddDate(0, 0, -1))
+			So(err, ShouldBeNil)
+
+			result, err := db.RunReport(ctx, database.ReportLoginAttempts, nil)
+			So(err, ShouldBeNil)
+			So(result.Rows, ShouldResemble, [][]interface{}{
+				[]interface{}{old.AddDate(0, 0, -1).Format("2006-01-02"), int64(1), int64(0)},
+				[]interface{}{old.Format("2006-01-02"), int64(2), int64(1)},
+				[]interface{}{now.Format("2006-01-02"), int64(0), int64(1)},
+			})
+
+			result, err = db.RunReport(ctx, database.ReportLoginAttempts, map[string]string{"days": "10"})
+			So(err, ShouldBeNil)
+			So(len(result.Rows), ShouldEqual, 1)
+		})
+
+		Convey("Pruning CSRF failures should remove all failures recorded before the given time", func() {
+			So(db.SaveCSRFFailure(ctx, &models.CSRFFailure{UserID: f.test1.ID, Request: "{}", Timestamp: old}), ShouldBeNil)
+			So(db.SaveCSRFFailure(ctx, &models.CSRFFailure{UserID: f.test1.ID, Request: "{}", Timestamp: now}), ShouldBeNil)
+
+			pruned, err := db.PruneCSRFFailures(ctx, now.AddDate(0, 0, -1))
+			So(err, ShouldBeNil)
+			So(pruned, ShouldEqual, 1)
+
+			csrfFailures, err := db.LoadAllCSRFFailures(ctx)
+			So(err, ShouldBeNil)
+			So(len(csrfFailures), ShouldEqual, 1)
+			So(csrfFailures[0].Timestamp.After(old), ShouldBeTrue)
+		})
+	})
+}
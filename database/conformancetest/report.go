@@ -0,0 +1,93 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testReports(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Running the registered reports", t, func() {
+		db, f := setup(factory)
+
+		Convey("The corporation members report should count users and characters per corporation", func() {
+			result, err := db.RunReport(ctx, database.ReportCorporationMembers, nil)
+			So(err, ShouldBeNil)
+			So(result.Rows, ShouldResemble, [][]interface{}{
+				[]interface{}{"Corp Test Ignore Please", "CORP", int64(1), int64(1)},
+				[]interface{}{"Test Corp Please Ignore", "TEST", int64(1), int64(2)},
+			})
+		})
+
+		Convey("The users without API keys report should list all users without accounts", func() {
+			result, err := db.RunReport(ctx, database.ReportUsersWithoutAPIKeys, nil)
+			So(err, ShouldBeNil)
+			So(result.Rows, ShouldResemble, [][]interface{}{
+				[]interface{}{f.test2.ID, "test2", "test2@example.com", false},
+				[]interface{}{f.test3.ID, "test3", "test3@example.com", true},
+			})
+		})
+
+		Convey("The reports should ignore deleted users", func() {
+			So(db.DeleteUser(ctx, f.test1.ID, f.test2.ID), ShouldBeNil)
+			So(db.DeleteUser(ctx, f.test3.ID, f.test2.ID), ShouldBeNil)
+
+			result, err := db.RunReport(ctx, database.ReportCorporationMembers, nil)
+			So(err, ShouldBeNil)
+			So(result.Rows[0][2], ShouldEqual, 0)
+			So(result.Rows[1][3], ShouldEqual, 0)
+
+			result, err = db.RunReport(ctx, database.ReportUsersWithoutAPIKeys, nil)
+			So(err, ShouldBeNil)
+			So(len(result.Rows), ShouldEqual, 1)
+			So(result.Rows[0][1], ShouldEqual, "test2")
+		})
+
+		Convey("The login attempts report should count attempts per day within the given range", func() {
+			now := time.Now().UTC()
+
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", RemoteAddr: "127.0.0.1", UserAgent: "test", Successful: true, Timestamp: now}), ShouldBeNil)
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", RemoteAddr: "127.0.0.1", UserAgent: "test", Successful: false, Timestamp: now}), ShouldBeNil)
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test2", RemoteAddr: "127.0.0.1", UserAgent: "test", Successful: false, Timestamp: now}), ShouldBeNil)
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", RemoteAddr: "127.0.0.1", UserAgent: "test", Successful: false, Timestamp: now.AddDate(0, 0, -5)}), ShouldBeNil)
+
+			result, err := db.RunReport(ctx, database.ReportLoginAttempts, map[string]string{"days": "2"})
+			So(err, ShouldBeNil)
+			So(result.Rows, ShouldResemble, [][]interface{}{
+				[]interface{}{now.Format("2006-01-02"), int64(2), int64(1)},
+			})
+
+			result, err = db.RunReport(ctx, database.ReportLoginAttempts, nil)
+			So(err, ShouldBeNil)
+			So(len(result.Rows), ShouldEqual, 2)
+			So(result.Rows[0][0], ShouldEqual, now.AddDate(0, 0, -5).Format("2006-01-02"))
+			So(result.Rows[0][1], ShouldEqual, 1)
+		})
+
+		Convey("Running reports within a transaction should be possible", func() {
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				result, err := tx.RunReport(ctx, database.ReportUsersWithoutAPIKeys, nil)
+				So(err, ShouldBeNil)
+				So(len(result.Rows), ShouldEqual, 2)
+
+				return err
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Running an unknown report or passing invalid parameters should return an error", func() {
+			_, err := db.RunReport(ctx, "nonexistent", nil)
+			So(err, ShouldNotBeNil)
+
+			_, err = db.RunReport(ctx, database.ReportLoginAttempts, map[string]string{"days": "-1"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
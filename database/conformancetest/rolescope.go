@@ -0,0 +1,130 @@
+package conformancetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+func testRoleScopes(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Scoping roles to applications", t, func() {
+		db, f := setup(factory)
+
+		role, err := db.LoadRole(ctx, f.logisticsRead.ID)
+		So(err, ShouldBeNil)
+		So(role.IsGlobal(), ShouldBeTrue)
+
+		role.ApplicationID = zero.IntFrom(f.testApp.ID)
+
+		_, err = db.SaveRole(ctx, role)
+		So(err, ShouldBeNil)
+
+		application, err := db.LoadApplication(ctx, f.testApp.ID)
+		So(err, ShouldBeNil)
+		So(application.RoleScopes, ShouldBeEmpty)
+
+		application.RoleScopes = "ping.*"
+
+		_, err = db.SaveApplication(ctx, application)
+		So(err, ShouldBeNil)
+
+		Convey("Should load the namespace of roles and the role scopes of applications", func() {
+			role, err := db.LoadRole(ctx, f.logisticsRead.ID)
+			So(err, ShouldBeNil)
+			So(role.ApplicationID, ShouldResemble, zero.IntFrom(f.testApp.ID))
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+
+			for _, r := range roles {
+				So(r.IsGlobal(), ShouldEqual, r.ID != f.logisticsRead.ID)
+			}
+
+			application, err := db.LoadApplication(ctx, f.testApp.ID)
+			So(err, ShouldBeNil)
+			So(application.RoleScopes, ShouldEqual, "ping.*")
+		})
+
+		Convey("Should only export the roles visible to an application", func() {
+			user, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			authUser, err := database.LoadAuthUserForApplication(ctx, db, user, application)
+			So(err, ShouldBeNil)
+			So(len(authUser.Roles), ShouldEqual, 2)
+			So(authUser.Roles, ShouldContain, "ping.all")
+			So(authUser.Roles, ShouldContain, "logistics.read")
+
+			otherApp, err := db.LoadApplication(ctx, f.otherApp.ID)
+			So(err, ShouldBeNil)
+
+			authUser, err = database.LoadAuthUserForApplication(ctx, db, user, otherApp)
+			So(err, ShouldBeNil)
+			So(authUser.Roles, ShouldBeEmpty)
+			So(authUser.Characters, ShouldNotBeEmpty)
+		})
+
+		Convey("Should only require role names to be unique within their application namespace", func() {
+			global, err := db.SaveRole(ctx, models.NewRole("logistics.read", true, false))
+			So(err, ShouldBeNil)
+			So(global.IsGlobal(), ShouldBeTrue)
+
+			_, err = db.SaveRole(ctx, models.NewRole("logistics.read", true, false))
+			So(err, ShouldNotBeNil)
+
+			other := models.NewRole("logistics.read", true, false)
+			other.ApplicationID = zero.IntFrom(f.otherApp.ID)
+
+			_, err = db.SaveRole(ctx, other)
+			So(err, ShouldBeNil)
+
+			duplicate := models.NewRole("logistics.read", true, false)
+			duplicate.ApplicationID = zero.IntFrom(f.testApp.ID)
+
+			_, err = db.SaveRole(ctx, duplicate)
+			So(err, ShouldNotBeNil)
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+
+			count := 0
+			for _, r := range roles {
+				if r.Name == "logistics.read" {
+					count++
+				}
+			}
+
+			So(count, ShouldEqual, 3)
+		})
+
+		Convey("Should purge the roles of purged applications instead of turning them into global roles", func() {
+			So(db.DeleteApplication(ctx, f.testApp.ID, f.test1.ID), ShouldBeNil)
+
+			role, err := db.LoadRole(ctx, f.logisticsRead.ID)
+			So(err, ShouldBeNil)
+			So(role.IsGlobal(), ShouldBeFalse)
+
+			_, err = db.PurgeTrash(ctx, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadRole(ctx, f.logisticsRead.ID)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+
+			for _, r := range roles {
+				So(r.ID, ShouldNotEqual, f.logisticsRead.ID)
+			}
+		})
+	})
+}
@@ -0,0 +1,297 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testSave(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving entries", t, func() {
+		db, f := setup(factory)
+
+		Convey("Should update an existing corporation", func() {
+			f.corporation.Name = "Renamed Corp"
+
+			_, err := db.SaveCorporation(ctx, f.corporation)
+			So(err, ShouldBeNil)
+
+			corporation, err := db.LoadCorporation(ctx, f.corporation.ID)
+			So(err, ShouldBeNil)
+			So(corporation.Name, ShouldEqual, "Renamed Corp")
+		})
+
+		Convey("Should update an existing character", func() {
+			character := f.test1.Accounts[0].Characters[0]
+			character.Name = "Renamed Character"
+			character.DefaultCharacter = false
+
+			_, err := db.SaveCharacter(ctx, character)
+			So(err, ShouldBeNil)
+
+			character, err = db.LoadCharacter(ctx, character.ID)
+			So(err, ShouldBeNil)
+			So(character.Name, ShouldEqual, "Renamed Character")
+			So(character.DefaultCharacter, ShouldBeFalse)
+		})
+
+		Convey("Should insert a new account including its characters", func() {
+			account := models.NewAccount(f.test3.ID, 3, "c", 0, true)
+			account.Characters = append(account.Characters, models.NewCharacter(-1, f.corporation.ID, "Derp", 4, true, true))
+
+			account, err := db.SaveAccount(ctx, account)
+			So(err, ShouldBeNil)
+			So(account.ID, ShouldBeGreaterThan, 0)
+			So(account.Characters[0].AccountID, ShouldEqual, account.ID)
+
+			accounts, err := db.LoadAllAccountsForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(accounts), ShouldEqual, 1)
+			So(len(accounts[0].Characters), ShouldEqual, 1)
+			So(accounts[0].Characters[0].Name, ShouldEqual, "Derp")
+		})
+
+		Convey("Should update an existing account", func() {
+			account, err := db.LoadAccount(ctx, f.test1.Accounts[1].ID)
+			So(err, ShouldBeNil)
+
+			account.Active = false
+			account.APIvCode = "z"
+
+			_, err = db.SaveAccount(ctx, account)
+			So(err, ShouldBeNil)
+
+			account, err = db.LoadAccount(ctx, account.ID)
+			So(err, ShouldBeNil)
+			So(account.Active, ShouldBeFalse)
+			So(account.APIvCode, ShouldEqual, "z")
+		})
+
+		Convey("Should update an existing role and increment its version", func() {
+			role, err := db.LoadRole(ctx, f.logisticsRead.ID)
+			So(err, ShouldBeNil)
+
+			version := role.Version
+			role.Locked = true
+
+			role, err = db.SaveRole(ctx, role)
+			So(err, ShouldBeNil)
+			So(role.Version, ShouldEqual, version+1)
+
+			role, err = db.LoadRole(ctx, f.logisticsRead.ID)
+			So(err, ShouldBeNil)
+			So(role.Locked, ShouldBeTrue)
+			So(role.Version, ShouldEqual, version+1)
+		})
+
+		Convey("Should insert new group and user roles", func() {
+			role, err := db.LoadRole(ctx, f.logisticsWrite.ID)
+			So(err, ShouldBeNil)
+
+			groupRole, err := db.SaveGroupRole(ctx, models.NewGroupRole(f.testGroup.ID, role, false, true))
+			So(err, ShouldBeNil)
+			So(groupRole.ID, ShouldBeGreaterThan, 0)
+
+			userRole, err := db.SaveUserRole(ctx, models.NewUserRole(f.test3.ID, role, false, true))
+			So(err, ShouldBeNil)
+			So(userRole.ID, ShouldBeGreaterThan, 0)
+
+			groupRoles, err := db.LoadAllGroupRolesForGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(groupRoles), ShouldEqual, 3)
+
+			userRoles, err := db.LoadAllUserRolesForUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(userRoles), ShouldEqual, 1)
+			So(userRoles[0].Role.Name, ShouldEqual, "logistics.write")
+		})
+
+		Convey("Should update an existing group including new group roles", func() {
+			group, err := db.LoadGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+
+			role, err := db.LoadRole(ctx, f.logisticsWrite.ID)
+			So(err, ShouldBeNil)
+
+			group.Active = true
+			group.GroupRoles = append(group.GroupRoles, models.NewGroupRole(group.ID, role, false, true))
+
+			group, err = db.SaveGroup(ctx, group)
+			So(err, ShouldBeNil)
+			So(group.Version, ShouldEqual, 2)
+
+			group, err = db.LoadGroup(ctx, f.dankAccess.ID)
+			So(err, ShouldBeNil)
+			So(group.Active, ShouldBeTrue)
+			So(group.Version, ShouldEqual, 2)
+			So(len(group.GroupRoles), ShouldEqual, 2)
+		})
+
+		Convey("Should update an existing user including new group memberships", func() {
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+
+			user.Email = "changed@example.com"
+			user.Groups = append(user.Groups, f.testGroup)
+
+			user, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+			So(user.Version, ShouldEqual, 2)
+
+			user, err = db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(user.Email, ShouldEqual, "changed@example.com")
+			So(user.Version, ShouldEqual, 2)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Test Group"})
+		})
+
+		Convey("Should update an existing application", func() {
+			application, err := db.LoadApplication(ctx, f.testApp.ID)
+			So(err, ShouldBeNil)
+
+			application.Callback = "http://example.org/callback"
+
+			application, err = db.SaveApplication(ctx, application)
+			So(err, ShouldBeNil)
+			So(application.Version, ShouldEqual, 2)
+
+			application, err = db.LoadApplication(ctx, f.testApp.ID)
+			So(err, ShouldBeNil)
+			So(application.Callback, ShouldEqual, "http://example.org/callback")
+		})
+
+		Convey("Should reject a duplicate username", func() {
+			_, err := db.SaveUser(ctx, models.NewUser("test1", "", "duplicate@example.com", false, true))
+			So(err, ShouldNotBeNil)
+
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(len(users), ShouldEqual, 3)
+		})
+	})
+}
+
+func testConflict(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving outdated entries", t, func() {
+		db, f := setup(factory)
+
+		Convey("Should reject an outdated user", func() {
+			first, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			second, err := db.LoadUser(ctx, f.test1.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveUser(ctx, first)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveUser(ctx, second)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Should reject an outdated group", func() {
+			first, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+
+			second, err := db.LoadGroup(ctx, f.testGroup.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveGroup(ctx, first)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveGroup(ctx, second)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Should reject an outdated role", func() {
+			first, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			second, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveRole(ctx, first)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveRole(ctx, second)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Should reject an outdated application", func() {
+			first, err := db.LoadApplication(ctx, f.testApp.ID)
+			So(err, ShouldBeNil)
+
+			second, err := db.LoadApplication(ctx, f.testApp.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveApplication(ctx, first)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveApplication(ctx, second)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Should leave the given user untouched if saving it failed", func() {
+			outdated, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			current, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveRole(ctx, current)
+			So(err, ShouldBeNil)
+
+			user, err := db.LoadUser(ctx, f.test2.ID)
+			So(err, ShouldBeNil)
+
+			version := user.Version
+			user.UserRoles = append(user.UserRoles, models.NewUserRole(user.ID, outdated, false, true))
+
+			_, err = db.SaveUser(ctx, user)
+			So(database.IsConflict(err), ShouldBeTrue)
+			So(user.Version, ShouldEqual, version)
+			So(outdated.Version, ShouldEqual, current.Version-1)
+
+			newUser := models.NewUser("test4", "", "test4@example.com", true, true)
+			newUser.UserRoles = append(newUser.UserRoles, models.NewUserRole(newUser.ID, outdated, false, true))
+
+			_, err = db.SaveUser(ctx, newUser)
+			So(database.IsConflict(err), ShouldBeTrue)
+			So(newUser.ID, ShouldEqual, -1)
+			So(newUser.UserRoles[0].UserID, ShouldEqual, -1)
+
+			user.UserRoles = user.UserRoles[:len(user.UserRoles)-1]
+
+			_, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+			So(user.Version, ShouldEqual, version+1)
+		})
+
+		Convey("Should leave the given group untouched if saving it failed", func() {
+			outdated, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			current, err := db.LoadRole(ctx, f.pingAll.ID)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveRole(ctx, current)
+			So(err, ShouldBeNil)
+
+			group := models.NewGroup("Outdated Group", true)
+			group.GroupRoles = append(group.GroupRoles, models.NewGroupRole(group.ID, outdated, false, true))
+
+			_, err = db.SaveGroup(ctx, group)
+			So(database.IsConflict(err), ShouldBeTrue)
+			So(group.ID, ShouldEqual, -1)
+			So(group.GroupRoles[0].GroupID, ShouldEqual, -1)
+		})
+	})
+}
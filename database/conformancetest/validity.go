@@ -0,0 +1,106 @@
+package conformancetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testValidity(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	Convey("Saving time-limited user roles and group memberships", t, func() {
+		db, f := setup(factory)
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		expiredRole := models.NewUserRole(f.test3.ID, f.logisticsWrite, false, true)
+		expiredRole.Validity = models.NewValidity(now.Add(-2*time.Hour), time.Hour)
+
+		pendingRole := models.NewUserRole(f.test3.ID, f.logisticsRead, false, true)
+		pendingRole.Validity = models.NewValidity(now.Add(time.Hour), 0)
+
+		test3, err := db.LoadUser(ctx, f.test3.ID)
+		So(err, ShouldBeNil)
+
+		test3.UserRoles = append(test3.UserRoles, expiredRole, pendingRole)
+		test3.Groups = append(test3.Groups, f.testGroup, f.dankAccess)
+		test3.GroupValidities = map[int64]*models.Validity{
+			f.testGroup.ID:  models.NewValidity(now.Add(-time.Hour), 2*time.Hour),
+			f.dankAccess.ID: {ValidUntil: &now},
+		}
+
+		_, err = db.SaveUser(ctx, test3)
+		So(err, ShouldBeNil)
+
+		Convey("Should load the validity windows", func() {
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 2)
+
+			for _, userRole := range user.UserRoles {
+				So(userRole.Validity, ShouldNotBeNil)
+
+				if userRole.Role.ID == f.logisticsWrite.ID {
+					So(*userRole.Validity.ValidUntil, ShouldHappenWithin, time.Second, now.Add(-time.Hour))
+				} else {
+					So(*userRole.Validity.ValidFrom, ShouldHappenWithin, time.Second, now.Add(time.Hour))
+					So(userRole.Validity.ValidUntil, ShouldBeNil)
+				}
+			}
+
+			So(len(user.GroupValidities), ShouldEqual, 2)
+			So(*user.GroupValidity(f.testGroup.ID).ValidUntil, ShouldHappenWithin, time.Second, now.Add(time.Hour))
+			So(user.GroupValidity(f.dankAccess.ID).ValidFrom, ShouldBeNil)
+		})
+
+		Convey("Should ignore expired and pending grants when evaluating roles", func() {
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(user.HasRole("ping.all"), ShouldEqual, models.RoleStatusGranted)
+			So(user.HasRole("logistics.write"), ShouldEqual, models.RoleStatusNonExistent)
+			So(user.HasRole("logistics.read"), ShouldEqual, models.RoleStatusGranted)
+			So(models.Explain(user, "logistics.read").Source, ShouldEqual, models.RoleSourceGroupRole)
+		})
+
+		Convey("Should remove expired grants only", func() {
+			expiredGrants, err := db.RemoveExpiredGrants(ctx, now)
+			So(err, ShouldBeNil)
+			So(len(expiredGrants), ShouldEqual, 2)
+			So(expiredGrants[0].Type, ShouldEqual, models.ExpiredGrantTypeUserRole)
+			So(expiredGrants[0].UserID, ShouldEqual, f.test3.ID)
+			So(expiredGrants[0].TargetID, ShouldEqual, f.logisticsWrite.ID)
+			So(expiredGrants[1].Type, ShouldEqual, models.ExpiredGrantTypeGroupMembership)
+			So(expiredGrants[1].TargetID, ShouldEqual, f.dankAccess.ID)
+
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 1)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Test Group"})
+			So(len(user.GroupValidities), ShouldEqual, 1)
+			So(user.Version, ShouldEqual, test3.Version+1)
+
+			expiredGrants, err = db.RemoveExpiredGrants(ctx, now)
+			So(err, ShouldBeNil)
+			So(len(expiredGrants), ShouldEqual, 0)
+		})
+
+		Convey("Should clear the validity window of memberships saved without one", func() {
+			user, err := db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+
+			delete(user.GroupValidities, f.testGroup.ID)
+
+			_, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			user, err = db.LoadUser(ctx, f.test3.ID)
+			So(err, ShouldBeNil)
+			So(user.GroupValidity(f.testGroup.ID), ShouldBeNil)
+		})
+	})
+}
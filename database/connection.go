@@ -1,160 +1,275 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
-	"github.com/morpheusxaut/eveauth/database/mysql"
+	"github.com/morpheusxaut/eveauth/database/migration"
 	"github.com/morpheusxaut/eveauth/misc"
 	"github.com/morpheusxaut/eveauth/models"
 )
 
-// Connection provides an interface for communicating with a database backend in order to retrieve and persist the needed information
+// Connection provides an interface for communicating with a database backend in order to retrieve and persist the needed information.
+// All methods accepting a context abort their queries as soon as the context is done
 type Connection interface {
 	// Connect tries to establish a connection to the database backend, returning an error if the attempt failed
 	Connect() error
+	// Ping verifies the database backend is still reachable, returning an error if it could not be reached before the context is done or the query timeout has been reached
+	Ping(ctx context.Context) error
 
-	// RawQuery performs a raw database query and returns a map of interfaces containing the retrieve data. An error is returned if the query failed
-	RawQuery(query string, v ...interface{}) ([]map[string]interface{}, error)
+	// MigrateUp applies all pending schema migrations, returning the resulting schema version or an error if a migration failed
+	MigrateUp() (int, error)
+	// MigrateDown reverts the most recently applied schema migration, returning the resulting schema version or an error if the migration failed
+	MigrateDown() (int, error)
+	// MigrationStatus retrieves the current schema version and all pending migrations, returning an error if the query failed
+	MigrationStatus() (*migration.Status, error)
+
+	// WithTx runs the given function within a single transaction, passing a Connection bound to it. The transaction is committed if the function returns nil and rolled back otherwise
+	WithTx(ctx context.Context, fn func(tx Connection) error) error
+
+	// RunReport runs the registered report with the given name and parameters without modifying any data, returning its typed rows or an error if the report is unknown, a parameter is invalid or the query failed
+	RunReport(ctx context.Context, name string, parameters map[string]string) (*ReportResult, error)
 
 	// LoadAllAccounts retrieves all accounts from the database, returning an error if the query failed
-	LoadAllAccounts() ([]*models.Account, error)
+	LoadAllAccounts(ctx context.Context) ([]*models.Account, error)
 	// LoadAllCorporations retrieves all corporations from the database, returning an error if the query failed
-	LoadAllCorporations() ([]*models.Corporation, error)
+	LoadAllCorporations(ctx context.Context) ([]*models.Corporation, error)
+	// LoadAllAlliances retrieves all alliances from the database, returning an error if the query failed
+	LoadAllAlliances(ctx context.Context) ([]*models.Alliance, error)
 	// LoadAllCharacters retrieves all characters from the database, returning an error if the query failed
-	LoadAllCharacters() ([]*models.Character, error)
+	LoadAllCharacters(ctx context.Context) ([]*models.Character, error)
 	// LoadAllRoles retrieves all roles from the database, returning an error if the query failed
-	LoadAllRoles() ([]*models.Role, error)
+	LoadAllRoles(ctx context.Context) ([]*models.Role, error)
 	// LoadAllGroupRoles retrieves all group roles (and their associated roles) from the database, returning an error if the query failed
-	LoadAllGroupRoles() ([]*models.GroupRole, error)
+	LoadAllGroupRoles(ctx context.Context) ([]*models.GroupRole, error)
 	// LoadAllUserRoles retrieves all user roles (and their associated roles) from the database, returning an error if the query failed
-	LoadAllUserRoles() ([]*models.UserRole, error)
-	// LoadAllGroups retrieves all groups (and their associated group roles) from the database, returning an error if the query failed
-	LoadAllGroups() ([]*models.Group, error)
+	LoadAllUserRoles(ctx context.Context) ([]*models.UserRole, error)
+	// LoadAllRoleImplications retrieves all implications between roles not in the trash from the database, returning an error if the query failed
+	LoadAllRoleImplications(ctx context.Context) ([]*models.RoleImplication, error)
+	// LoadAllGroupNestings retrieves all nestings between groups not in the trash from the database, returning an error if the query failed
+	LoadAllGroupNestings(ctx context.Context) ([]*models.GroupNesting, error)
+	// LoadAllMembershipRules retrieves all membership rules of groups not in the trash from the database, returning an error if the query failed
+	LoadAllMembershipRules(ctx context.Context) ([]*models.MembershipRule, error)
+	// LoadAllGroupManagers retrieves all group manager assignments of groups and users not in the trash from the database, returning an error if the query failed
+	LoadAllGroupManagers(ctx context.Context) ([]*models.GroupManager, error)
+	// LoadAllManagerActions retrieves all recorded manager actions from the database, returning an error if the query failed
+	LoadAllManagerActions(ctx context.Context) ([]*models.ManagerAction, error)
+	// LoadAllGroupApplications retrieves all group applications of groups and users not in the trash from the database, returning an error if the query failed
+	LoadAllGroupApplications(ctx context.Context) ([]*models.GroupApplication, error)
+	// LoadPendingGroupApplications retrieves all pending group applications of groups and users not in the trash from the database, oldest first, returning an error if the query failed
+	LoadPendingGroupApplications(ctx context.Context) ([]*models.GroupApplication, error)
+	// LoadAllGroups retrieves all groups (and their associated group roles and sub groups) from the database, returning an error if the query failed
+	LoadAllGroups(ctx context.Context) ([]*models.Group, error)
 	// LoadAllUsers retrieves all users (and their associates groups and user roles) from the database, returning an error if the query failed
-	LoadAllUsers() ([]*models.User, error)
+	LoadAllUsers(ctx context.Context) ([]*models.User, error)
 	// LoadAllApplications retrieves all applications from the database, returning an error if the query failed
-	LoadAllApplications() ([]*models.Application, error)
+	LoadAllApplications(ctx context.Context) ([]*models.Application, error)
+	// LoadAllLoginAttempts retrieves all login attempts from the database, returning an error if the query failed
+	LoadAllLoginAttempts(ctx context.Context) ([]*models.LoginAttempt, error)
+	// LoadAllLoginAttemptSummaries retrieves the daily summaries of all pruned login attempts from the database, sorted by day, returning an error if the query failed
+	LoadAllLoginAttemptSummaries(ctx context.Context) ([]*models.LoginAttemptSummary, error)
+	// LoadAllCSRFFailures retrieves all CSRF failures from the database, returning an error if the query failed
+	LoadAllCSRFFailures(ctx context.Context) ([]*models.CSRFFailure, error)
+
+	// QueryUsers retrieves a page of users (and their associated accounts, groups and user roles) matching the given criteria as well as the total number of matches, returning an error if the query failed
+	QueryUsers(ctx context.Context, criteria *ListCriteria) ([]*models.User, int64, error)
+	// QueryGroups retrieves a page of groups (and their associated group roles and sub groups) matching the given criteria as well as the total number of matches, returning an error if the query failed
+	QueryGroups(ctx context.Context, criteria *ListCriteria) ([]*models.Group, int64, error)
+	// QueryRoles retrieves a page of roles matching the given criteria as well as the total number of matches, returning an error if the query failed
+	QueryRoles(ctx context.Context, criteria *ListCriteria) ([]*models.Role, int64, error)
+	// QueryApplications retrieves a page of applications matching the given criteria as well as the total number of matches, returning an error if the query failed
+	QueryApplications(ctx context.Context, criteria *ListCriteria) ([]*models.Application, int64, error)
 
 	// LoadAccount retrieves the account with the given ID from the database, returning an error if the query failed
-	LoadAccount(accountID int64) (*models.Account, error)
+	LoadAccount(ctx context.Context, accountID int64) (*models.Account, error)
 	// LoadCorporation retrieves the corporation with the given ID from the database, returning an error if the query failed
-	LoadCorporation(corporationID int64) (*models.Corporation, error)
+	LoadCorporation(ctx context.Context, corporationID int64) (*models.Corporation, error)
 	// LoadCorporationFromEVECorporationID retrieves the corporation with the given EVE Online corporation ID from the database, returning an error if the query failed
-	LoadCorporationFromEVECorporationID(eveCorporationID int64) (*models.Corporation, error)
+	LoadCorporationFromEVECorporationID(ctx context.Context, eveCorporationID int64) (*models.Corporation, error)
 	// LoadCorporationNameFromID retrieves the name of the corporation with the given ID, returning an error if the query failed
-	LoadCorporationNameFromID(corporationID int64) (string, error)
+	LoadCorporationNameFromID(ctx context.Context, corporationID int64) (string, error)
+	// LoadAlliance retrieves the alliance with the given ID from the database, returning an error if the query failed
+	LoadAlliance(ctx context.Context, allianceID int64) (*models.Alliance, error)
+	// LoadAllianceFromEVEAllianceID retrieves the alliance with the given EVE Online alliance ID from the database, returning an error if the query failed
+	LoadAllianceFromEVEAllianceID(ctx context.Context, eveAllianceID int64) (*models.Alliance, error)
 	// LoadCharacter retrieves the character with the given ID from the database, returning an error if the query failed
-	LoadCharacter(characterID int64) (*models.Character, error)
-	// LoadRole retrieves the role with the given ID from the database, returning an error if the query failed
-	LoadRole(roleID int64) (*models.Role, error)
+	LoadCharacter(ctx context.Context, characterID int64) (*models.Character, error)
+	// LoadRole retrieves the role (and all roles implied by it) with the given ID from the database, returning an error if the query failed
+	LoadRole(ctx context.Context, roleID int64) (*models.Role, error)
 	// LoadGroupRole retrieves the group role (and its associated role) with the given ID from the database, returning an error if the query failed
-	LoadGroupRole(groupRoleID int64) (*models.GroupRole, error)
+	LoadGroupRole(ctx context.Context, groupRoleID int64) (*models.GroupRole, error)
 	// LoadUserRole retrieves the user role (and its associated role) with the given ID from the database, returning an error if the query failed
-	LoadUserRole(userRoleID int64) (*models.UserRole, error)
-	// LoadGroup retrieves the group (and its associated group roles) with the given ID from the database, returning an error if the query failed
-	LoadGroup(groupID int64) (*models.Group, error)
+	LoadUserRole(ctx context.Context, userRoleID int64) (*models.UserRole, error)
+	// LoadGroup retrieves the group (and its associated group roles and sub groups) with the given ID from the database, returning an error if the query failed
+	LoadGroup(ctx context.Context, groupID int64) (*models.Group, error)
 	// LoadUser retrieves the user (and its associated groups and user roles) with the given ID from the database, returning an error if the query failed
-	LoadUser(userID int64) (*models.User, error)
+	LoadUser(ctx context.Context, userID int64) (*models.User, error)
 	// LoadUserFromUsername retrieves the user (and its associated groups and user roles) with the given username from the database, returning an error if the query failed
-	LoadUserFromUsername(username string) (*models.User, error)
+	LoadUserFromUsername(ctx context.Context, username string) (*models.User, error)
 	// LoadApplication retrieves the application with the given application ID from the database, returning an error if the query failed
-	LoadApplication(applicationID int64) (*models.Application, error)
+	LoadApplication(ctx context.Context, applicationID int64) (*models.Application, error)
+	// LoadGroupApplication retrieves the group application with the given ID from the database, returning an error if the query failed
+	LoadGroupApplication(ctx context.Context, groupApplicationID int64) (*models.GroupApplication, error)
 
 	// LoadAllAccountsForUser retrieves all accounts associated with the given user from the database, returning an error if the query failed
-	LoadAllAccountsForUser(userID int64) ([]*models.Account, error)
+	LoadAllAccountsForUser(ctx context.Context, userID int64) ([]*models.Account, error)
 	// LoadAllCharactersForAccount retrieves all characters associated with the given account from the database, returning an error if the query failed
-	LoadAllCharactersForAccount(accountID int64) ([]*models.Character, error)
+	LoadAllCharactersForAccount(ctx context.Context, accountID int64) ([]*models.Character, error)
 	// LoadAllGroupRolesForGroup retrieves all group roles (and their associated roles) associated with the given group from the database, returning an error if the query failed
-	LoadAllGroupRolesForGroup(groupID int64) ([]*models.GroupRole, error)
+	LoadAllGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.GroupRole, error)
+	// LoadAllMembershipRulesForGroup retrieves all membership rules associated with the given group from the database, returning an error if the query failed
+	LoadAllMembershipRulesForGroup(ctx context.Context, groupID int64) ([]*models.MembershipRule, error)
+	// LoadAllGroupManagersForGroup retrieves all manager assignments of users not in the trash associated with the given group from the database, returning an error if the query failed
+	LoadAllGroupManagersForGroup(ctx context.Context, groupID int64) ([]*models.GroupManager, error)
+	// LoadAllManagerActionsForGroup retrieves all manager actions performed on the given group from the database, returning an error if the query failed
+	LoadAllManagerActionsForGroup(ctx context.Context, groupID int64) ([]*models.ManagerAction, error)
+	// LoadAllGroupApplicationsForUser retrieves all applications of the given user for groups not in the trash from the database, returning an error if the query failed
+	LoadAllGroupApplicationsForUser(ctx context.Context, userID int64) ([]*models.GroupApplication, error)
 	// LoadAllUserRolesForUser retrieves all user roles (and their associated roles) associated with the given user from the database, returning an error if the query failed
-	LoadAllUserRolesForUser(userID int64) ([]*models.UserRole, error)
-	// LoadAllGroupsForUser retrieves all groups (and their associated group roles) associated with the given user from the database, returning an error if the query failed
-	LoadAllGroupsForUser(userID int64) ([]*models.Group, error)
-	// LoadAvailableGroupsForUser retrieves all available groups (and their associated group roles) associated with the given user from the MySQL database, returning an error if the query failed
-	LoadAvailableGroupsForUser(userID int64) ([]*models.Group, error)
+	LoadAllUserRolesForUser(ctx context.Context, userID int64) ([]*models.UserRole, error)
+	// LoadAllGroupsForUser retrieves all groups (and their associated group roles and sub groups) the given user is a direct member of from the database, returning an error if the query failed
+	LoadAllGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error)
+	// LoadAvailableGroupsForUser retrieves all available groups (and their associated group roles and sub groups) associated with the given user from the database, returning an error if the query failed
+	LoadAvailableGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error)
+	// LoadAllManagedGroupsForUser retrieves all groups (and their associated group roles and sub groups) managed by the given user from the database, returning an error if the query failed
+	LoadAllManagedGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error)
 	// LoadAvailableUserRolesForUser retrieves all available user roles for the given user from the MySQL database, returning an error if the query failed
-	LoadAvailableUserRolesForUser(userID int64) ([]*models.Role, error)
+	LoadAvailableUserRolesForUser(ctx context.Context, userID int64) ([]*models.Role, error)
 	// LoadAvailableGroupRolesForGroup retrieves all available group roles for the given group from the MySQL database, returning an error if the query failed
-	LoadAvailableGroupRolesForGroup(groupID int64) ([]*models.Role, error)
+	LoadAvailableGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.Role, error)
 	// LoadAllApplicationsForUser retrieves all applications associated with the given user from the database, returning an error if the query failed
-	LoadAllApplicationsForUser(userID int64) ([]*models.Application, error)
+	LoadAllApplicationsForUser(ctx context.Context, userID int64) ([]*models.Application, error)
 
 	// LoadPasswordForUser retrieves the password associated with the given username from the database, returning an error if the query failed
-	LoadPasswordForUser(username string) (string, error)
+	LoadPasswordForUser(ctx context.Context, username string) (string, error)
 
 	// QueryUserIDExists checks whether a user with the given user ID exists in the database, returning an error if the query failed
-	QueryUserIDExists(userID int64) (bool, error)
+	QueryUserIDExists(ctx context.Context, userID int64) (bool, error)
 	// QueryUserNameEmailExists checks whether a user with the given username or email address exists in the database, returning an error if the query failed
-	QueryUserNameEmailExists(username string, email string) (bool, error)
+	QueryUserNameEmailExists(ctx context.Context, username string, email string) (bool, error)
 
 	// SaveAccount saves an account to the database, returning the updated model or an error if the query failed
-	SaveAccount(account *models.Account) (*models.Account, error)
+	SaveAccount(ctx context.Context, account *models.Account) (*models.Account, error)
 	// SaveCorporation saves a corporation to the database, returning the updated model or an error if the query failed
-	SaveCorporation(corporation *models.Corporation) (*models.Corporation, error)
+	SaveCorporation(ctx context.Context, corporation *models.Corporation) (*models.Corporation, error)
+	// SaveAlliance saves an alliance to the database, returning the updated model or an error if the query failed
+	SaveAlliance(ctx context.Context, alliance *models.Alliance) (*models.Alliance, error)
 	// SaveCharacter saves a character to the database, returning the updated model or an error if the query failed
-	SaveCharacter(character *models.Character) (*models.Character, error)
-	// SaveRole saves a role to the database, returning the updated model or an error if the query failed
-	SaveRole(role *models.Role) (*models.Role, error)
+	SaveCharacter(ctx context.Context, character *models.Character) (*models.Character, error)
+	// SaveRole saves a role to the database, returning the updated model or an error if the query failed. Saving an outdated version returns a *ConflictError
+	SaveRole(ctx context.Context, role *models.Role) (*models.Role, error)
 	// SaveGroupRole saves a group role to the database, returning the updated model or an error if the query failed
-	SaveGroupRole(groupRole *models.GroupRole) (*models.GroupRole, error)
+	SaveGroupRole(ctx context.Context, groupRole *models.GroupRole) (*models.GroupRole, error)
 	// SaveUserRole saves a user role to the database, returning the updated model or an error if the query failed
-	SaveUserRole(userRole *models.UserRole) (*models.UserRole, error)
-	// SaveGroup saves a group to the database, returning the updated model or an error if the query failed
-	SaveGroup(group *models.Group) (*models.Group, error)
-	// SaveUser saves a user to the database, returning the updated model or an error if the query failed
-	SaveUser(user *models.User) (*models.User, error)
-	// SaveApplication saves an application to the database, returning the updated model or an error if the query failed
-	SaveApplication(application *models.Application) (*models.Application, error)
+	SaveUserRole(ctx context.Context, userRole *models.UserRole) (*models.UserRole, error)
+	// SaveRoleImplications replaces the roles directly implied by the role with the given ID, returning an error if the query failed. Implications causing the role to imply itself return an *ImplicationCycleError
+	SaveRoleImplications(ctx context.Context, roleID int64, impliedRoleIDs []int64) error
+	// SaveSubGroups replaces the groups directly nested in the group with the given ID, returning an error if the query failed. Nestings causing the group to contain itself return a *NestingCycleError
+	SaveSubGroups(ctx context.Context, groupID int64, subGroupIDs []int64) error
+	// SaveMembershipRule saves a membership rule to the database, returning the updated model or an error if the query failed
+	SaveMembershipRule(ctx context.Context, membershipRule *models.MembershipRule) (*models.MembershipRule, error)
+	// SaveGroupManager saves a group manager assignment to the database, returning the updated model or an error if the query failed
+	SaveGroupManager(ctx context.Context, groupManager *models.GroupManager) (*models.GroupManager, error)
+	// SaveGroupApplication saves a group application to the database, returning the updated model or an error if the query failed
+	SaveGroupApplication(ctx context.Context, groupApplication *models.GroupApplication) (*models.GroupApplication, error)
+	// SaveGroup saves a group to the database, returning the updated model or an error if the query failed. Saving an outdated version returns a *ConflictError
+	SaveGroup(ctx context.Context, group *models.Group) (*models.Group, error)
+	// SaveUser saves a user to the database, returning the updated model or an error if the query failed. Saving an outdated version returns a *ConflictError
+	SaveUser(ctx context.Context, user *models.User) (*models.User, error)
+	// SaveApplication saves an application to the database, returning the updated model or an error if the query failed. Saving an outdated version returns a *ConflictError
+	SaveApplication(ctx context.Context, application *models.Application) (*models.Application, error)
 	// SaveLoginAttempt saves a login attempt to the database, returning an error if the query failed
-	SaveLoginAttempt(loginAttempt *models.LoginAttempt) error
+	SaveLoginAttempt(ctx context.Context, loginAttempt *models.LoginAttempt) error
+	// SaveLoginAttemptSummary adds the counts of the given summary to the stored summary of the same day, creating it if required. An error is returned if the query failed
+	SaveLoginAttemptSummary(ctx context.Context, summary *models.LoginAttemptSummary) error
 	// SaveCSRFFailure saves a CSRF failure to the database, returning an error if the query failed
-	SaveCSRFFailure(csrfFailure *models.CSRFFailure) error
+	SaveCSRFFailure(ctx context.Context, csrfFailure *models.CSRFFailure) error
+	// SaveManagerAction saves a manager action to the database, returning an error if the query failed
+	SaveManagerAction(ctx context.Context, managerAction *models.ManagerAction) error
 
 	// DeleteAccount removes an account and all associated characters from database
-	DeleteAccount(accountID int64) error
+	DeleteAccount(ctx context.Context, accountID int64) error
 	// DeleteCharacter removes a character from database
-	DeleteCharacter(characterID int64) error
-	// DeleteRole removes a role and all user and group roles associated from database
-	DeleteRole(roleID int64) error
+	DeleteCharacter(ctx context.Context, characterID int64) error
+	// DeleteRole moves a role to the trash, hiding it and all user and group roles associated until it is restored or purged
+	DeleteRole(ctx context.Context, roleID int64, deletedBy int64) error
 	// DeleteGroupRole removes a group role from database
-	DeleteGroupRole(groupRoleID int64) error
+	DeleteGroupRole(ctx context.Context, groupRoleID int64) error
 	// DeleteUserRole removes a user role from database
-	DeleteUserRole(userRoleID int64) error
-	// DeleteGroup removes a group and all associated group memberships and roles from database
-	DeleteGroup(groupID int64) error
-	// DeleteUser removes a user and all assoicated group memberships, roles and accounts from database
-	DeleteUser(userID int64) error
-	// DeleteApplication remove an application from the database
-	DeleteApplication(appID int64) error
+	DeleteUserRole(ctx context.Context, userRoleID int64) error
+	// DeleteMembershipRule removes a membership rule from database. Memberships added by the rule are kept until the next reconciliation
+	DeleteMembershipRule(ctx context.Context, membershipRuleID int64) error
+	// DeleteGroupManager removes a group manager assignment from database. Actions recorded for the manager are kept
+	DeleteGroupManager(ctx context.Context, groupManagerID int64) error
+	// DeleteGroup moves a group to the trash, hiding it and all associated group memberships and roles until it is restored or purged
+	DeleteGroup(ctx context.Context, groupID int64, deletedBy int64) error
+	// DeleteUser moves a user to the trash, hiding it and all associated group memberships, roles and accounts until it is restored or purged
+	DeleteUser(ctx context.Context, userID int64, deletedBy int64) error
+	// DeleteApplication moves an application to the trash, hiding it until it is restored or purged
+	DeleteApplication(ctx context.Context, appID int64, deletedBy int64) error
+
+	// LoadTrash retrieves all deleted users, groups, roles and applications from the database, most recently deleted first, returning an error if the query failed
+	LoadTrash(ctx context.Context) ([]*models.TrashEntry, error)
+	// RestoreRole restores a deleted role including all user and group roles associated, returning an error if the role is not in the trash
+	RestoreRole(ctx context.Context, roleID int64) error
+	// RestoreGroup restores a deleted group including all associated group memberships and roles, returning an error if the group is not in the trash
+	RestoreGroup(ctx context.Context, groupID int64) error
+	// RestoreUser restores a deleted user including all associated group memberships, roles and accounts, returning an error if the user is not in the trash
+	RestoreUser(ctx context.Context, userID int64) error
+	// RestoreApplication restores a deleted application, returning an error if the application is not in the trash
+	RestoreApplication(ctx context.Context, appID int64) error
+	// PurgeTrash permanently removes all entities deleted before the given time as well as their associations, returning the number of purged entities.
+	// Users still maintaining applications are kept and their applications are moved to the trash instead, so both are purged once the applications expire
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// PruneLoginAttempts adds all login attempts made before the given time to the daily summaries and removes them, returning the number of removed login attempts
+	PruneLoginAttempts(ctx context.Context, before time.Time) (int64, error)
+	// PruneCSRFFailures removes all CSRF failures recorded before the given time, returning the number of removed CSRF failures
+	PruneCSRFFailures(ctx context.Context, before time.Time) (int64, error)
+	// RemoveExpiredGrants removes all user roles and group memberships expired at the given time, returning the removed grants
+	RemoveExpiredGrants(ctx context.Context, expiredAt time.Time) ([]*models.ExpiredGrant, error)
 
 	// RemoveUserFromGroup removes a user from the given group, updates the database and returns the updated model
-	RemoveUserFromGroup(userID int64, groupID int64) (*models.User, error)
+	RemoveUserFromGroup(ctx context.Context, userID int64, groupID int64) (*models.User, error)
 	// RemoveUserRoleFromUser removes a user role from the given user, updates the database and returns the updated model
-	RemoveUserRoleFromUser(userID int64, roleID int64) (*models.User, error)
+	RemoveUserRoleFromUser(ctx context.Context, userID int64, roleID int64) (*models.User, error)
 	// RemoveGroupRoleFromGroup removes a group role from the given group, updates the database and returns the updated model
-	RemoveGroupRoleFromGroup(groupID int64, roleID int64) (*models.Group, error)
+	RemoveGroupRoleFromGroup(ctx context.Context, groupID int64, roleID int64) (*models.Group, error)
 	// RemoveAPIKeyFromUser removes an API key from the given user, updates the database and returns the updated model
-	RemoveAPIKeyFromUser(user *models.User, apiKeyID int64) (*models.User, error)
+	RemoveAPIKeyFromUser(ctx context.Context, user *models.User, apiKeyID int64) (*models.User, error)
 
 	// ToggleUserRoleGranted toggles the granted state of the given user role
-	ToggleUserRoleGranted(roleID int64) (*models.UserRole, error)
+	ToggleUserRoleGranted(ctx context.Context, roleID int64) (*models.UserRole, error)
 	// ToggleGroupRoleGranted toggles the granted state of the given group role
-	ToggleGroupRoleGranted(roleID int64) (*models.GroupRole, error)
+	ToggleGroupRoleGranted(ctx context.Context, roleID int64) (*models.GroupRole, error)
+}
+
+// Factory creates a new database implementation using the given configuration
+type Factory func(conf *misc.Configuration) Connection
+
+var (
+	factories = make(map[Type]Factory)
+)
+
+// Register makes a database implementation available for the given Type. Backends register themselves when their package is imported, registering the same Type twice panics
+func Register(databaseType Type, factory Factory) {
+	if factory == nil {
+		panic(fmt.Sprintf("Factory for database type %v is nil", databaseType))
+	}
+
+	if _, exists := factories[databaseType]; exists {
+		panic(fmt.Sprintf("Database type %v has already been registered", databaseType))
+	}
+
+	factories[databaseType] = factory
 }
 
 // SetupDatabase parses the database type set in the configuration and returns an appropriate database implementation or an error if the type is unknown
 func SetupDatabase(conf *misc.Configuration) (Connection, error) {
-	var database Connection
-
-	switch Type(conf.DatabaseType) {
-	case TypeMySQL:
-		database = &mysql.DatabaseConnection{
-			Config: conf,
-		}
-		break
-	default:
+	factory, ok := factories[Type(conf.DatabaseType)]
+	if !ok {
 		return nil, fmt.Errorf("Unknown type #%d", conf.DatabaseType)
 	}
 
-	return database, nil
+	return factory(conf), nil
 }
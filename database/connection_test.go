@@ -1,10 +1,16 @@
-package database
+package database_test
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/memory"
 	"github.com/morpheusxaut/eveauth/database/mysql"
+	"github.com/morpheusxaut/eveauth/database/postgres"
+	"github.com/morpheusxaut/eveauth/database/sqlite"
 	"github.com/morpheusxaut/eveauth/misc"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -48,7 +54,7 @@ func TestMySQLDatabaseSetup(t *testing.T) {
 	Convey("Running the database setup using a MySQL configuration", t, func() {
 		config := createConfig(1)
 
-		db, err := SetupDatabase(config)
+		db, err := database.SetupDatabase(config)
 
 		Convey("The returned error should be nil", func() {
 			So(err, ShouldBeNil)
@@ -73,11 +79,94 @@ func TestMySQLDatabaseSetup(t *testing.T) {
 	})
 }
 
+func TestPostgreSQLDatabaseSetup(t *testing.T) {
+	Convey("Running the database setup using a PostgreSQL configuration", t, func() {
+		config := createConfig(2)
+
+		db, err := database.SetupDatabase(config)
+
+		Convey("The returned error should be nil", func() {
+			So(err, ShouldBeNil)
+		})
+
+		Convey("The returned DatabaseConnection should not be nil", func() {
+			So(db, ShouldNotBeNil)
+		})
+
+		Convey("The returned DatabaseConnection's type should be PostgreSQL DatabaseConnection", func() {
+			postgresDbConn := &postgres.DatabaseConnection{}
+			So(db, ShouldHaveSameTypeAs, postgresDbConn)
+		})
+	})
+}
+
+func TestSQLiteDatabaseSetup(t *testing.T) {
+	Convey("Running the database setup using a SQLite configuration", t, func() {
+		databaseDir, err := ioutil.TempDir("", "eveauth")
+		So(err, ShouldBeNil)
+
+		config := createConfig(3)
+		config.DatabaseHost = filepath.Join(databaseDir, "eveauth.db")
+
+		db, err := database.SetupDatabase(config)
+
+		Convey("The returned error should be nil", func() {
+			So(err, ShouldBeNil)
+		})
+
+		Convey("The returned DatabaseConnection should not be nil", func() {
+			So(db, ShouldNotBeNil)
+		})
+
+		Convey("The returned DatabaseConnection's type should be SQLite DatabaseConnection", func() {
+			sqliteDbConn := &sqlite.DatabaseConnection{}
+			So(db, ShouldHaveSameTypeAs, sqliteDbConn)
+		})
+
+		Convey("Connecting to the database", func() {
+			err = db.Connect()
+
+			Convey("The returned error should be nil", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestNoneDatabaseSetup(t *testing.T) {
+	Convey("Running the database setup using an in-memory configuration", t, func() {
+		config := createConfig(0)
+
+		db, err := database.SetupDatabase(config)
+
+		Convey("The returned error should be nil", func() {
+			So(err, ShouldBeNil)
+		})
+
+		Convey("The returned DatabaseConnection should not be nil", func() {
+			So(db, ShouldNotBeNil)
+		})
+
+		Convey("The returned DatabaseConnection's type should be memory DatabaseConnection", func() {
+			memoryDbConn := &memory.DatabaseConnection{}
+			So(db, ShouldHaveSameTypeAs, memoryDbConn)
+		})
+
+		Convey("Connecting to the database", func() {
+			err = db.Connect()
+
+			Convey("The returned error should be nil", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
+
 func TestInvalidDatabaseSetup(t *testing.T) {
 	Convey("Running the database setup using an invalid configuration", t, func() {
 		config := createConfig(1337)
 
-		db, err := SetupDatabase(config)
+		db, err := database.SetupDatabase(config)
 
 		Convey("The returned error should not be nil", func() {
 			So(err, ShouldNotBeNil)
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"strings"
+)
+
+// SortDirection specifies the order results of a list query are returned in
+type SortDirection int
+
+const (
+	// SortAscending returns the results in ascending order
+	SortAscending SortDirection = iota
+	// SortDescending returns the results in descending order
+	SortDescending
+)
+
+// String returns the SQL keyword for the SortDirection
+func (direction SortDirection) String() string {
+	if direction == SortDescending {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
+// ActiveFilter specifies whether a list query should only return active or inactive entries
+type ActiveFilter int
+
+const (
+	// ActiveFilterAll returns entries regardless of their active state
+	ActiveFilterAll ActiveFilter = iota
+	// ActiveFilterActive only returns active entries
+	ActiveFilterActive
+	// ActiveFilterInactive only returns inactive entries
+	ActiveFilterInactive
+)
+
+// LikeEscape represents the escape character used by the SQL backends when matching text filters with LIKE, passed in their ESCAPE clause
+const LikeEscape = "!"
+
+// DefaultListLimit represents the number of entries returned per page if no limit has been specified
+const DefaultListLimit int64 = 25
+
+// MaxListLimit represents the maximum number of entries returned per page
+const MaxListLimit int64 = 500
+
+var (
+	// UserSortFields contains all fields users can be sorted by
+	UserSortFields = []string{"id", "username", "email", "active"}
+	// GroupSortFields contains all fields groups can be sorted by
+	GroupSortFields = []string{"id", "name", "active"}
+	// RoleSortFields contains all fields roles can be sorted by
+	RoleSortFields = []string{"id", "name", "active", "locked"}
+	// ApplicationSortFields contains all fields applications can be sorted by
+	ApplicationSortFields = []string{"id", "name", "active"}
+)
+
+// ListCriteria specifies the filtering, sorting and paging options used by list queries
+type ListCriteria struct {
+	// Filter contains a text filter, matched case-insensitively against the names (or username and email) of entries
+	Filter string
+	// Active specifies whether only active or inactive entries should be returned
+	Active ActiveFilter
+	// GroupID limits the returned users to active members of the given group, ignored if not positive or for other entries
+	GroupID int64
+	// CorporationID limits the returned users to ones owning a character in the given corporation, ignored if not positive or for other entries
+	CorporationID int64
+	// SortField specifies the field to sort the results by, defaults to the ID if the field is not supported
+	SortField string
+	// SortDirection specifies the order the results are sorted in
+	SortDirection SortDirection
+	// Limit specifies the maximum number of entries returned
+	Limit int64
+	// Offset specifies the number of entries skipped before returning results
+	Offset int64
+}
+
+// NewListCriteria creates new list criteria returning the first page of all entries, sorted by their ID
+func NewListCriteria() *ListCriteria {
+	criteria := &ListCriteria{
+		Active:        ActiveFilterAll,
+		SortField:     "id",
+		SortDirection: SortAscending,
+		Limit:         DefaultListLimit,
+		Offset:        0,
+	}
+
+	return criteria
+}
+
+// SortColumn returns the sort field if it is contained in the given list of supported fields, falling back to the ID otherwise
+func (criteria *ListCriteria) SortColumn(fields []string) string {
+	sortField := strings.ToLower(criteria.SortField)
+
+	for _, field := range fields {
+		if field == sortField {
+			return field
+		}
+	}
+
+	return "id"
+}
+
+// PageLimit returns the limit used for the query, applying the default and maximum limits
+func (criteria *ListCriteria) PageLimit() int64 {
+	if criteria.Limit <= 0 {
+		return DefaultListLimit
+	} else if criteria.Limit > MaxListLimit {
+		return MaxListLimit
+	}
+
+	return criteria.Limit
+}
+
+// PageOffset returns the offset used for the query, ignoring negative values
+func (criteria *ListCriteria) PageOffset() int64 {
+	if criteria.Offset < 0 {
+		return 0
+	}
+
+	return criteria.Offset
+}
+
+// FilterPattern returns a LIKE pattern matching the filter anywhere within a value. Wildcards contained in the filter itself are escaped
+// using LikeEscape, so they are matched literally
+func (criteria *ListCriteria) FilterPattern() string {
+	replacer := strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_")
+
+	return "%" + replacer.Replace(criteria.Filter) + "%"
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestListCriteria(t *testing.T) {
+	Convey("Creating new list criteria", t, func() {
+		criteria := NewListCriteria()
+
+		Convey("The default criteria should return the first page sorted by ID", func() {
+			So(criteria.SortColumn(UserSortFields), ShouldEqual, "id")
+			So(criteria.SortDirection.String(), ShouldEqual, "ASC")
+			So(criteria.PageLimit(), ShouldEqual, DefaultListLimit)
+			So(criteria.PageOffset(), ShouldEqual, 0)
+		})
+
+		Convey("Sorting by a supported field should use the field as sort column", func() {
+			criteria.SortField = "Username"
+			criteria.SortDirection = SortDescending
+
+			So(criteria.SortColumn(UserSortFields), ShouldEqual, "username")
+			So(criteria.SortDirection.String(), ShouldEqual, "DESC")
+		})
+
+		Convey("Sorting by an unsupported field should fall back to the ID", func() {
+			criteria.SortField = "password"
+
+			So(criteria.SortColumn(UserSortFields), ShouldEqual, "id")
+			So(criteria.SortColumn(RoleSortFields), ShouldEqual, "id")
+		})
+
+		Convey("Filter patterns should match wildcards literally", func() {
+			criteria.Filter = "test"
+			So(criteria.FilterPattern(), ShouldEqual, "%test%")
+
+			criteria.Filter = "100%_done!"
+			So(criteria.FilterPattern(), ShouldEqual, "%100!%!_done!!%")
+		})
+
+		Convey("Invalid limits and offsets should be corrected", func() {
+			criteria.Limit = 0
+			criteria.Offset = -5
+
+			So(criteria.PageLimit(), ShouldEqual, DefaultListLimit)
+			So(criteria.PageOffset(), ShouldEqual, 0)
+
+			criteria.Limit = MaxListLimit + 1
+
+			So(criteria.PageLimit(), ShouldEqual, MaxListLimit)
+		})
+	})
+}
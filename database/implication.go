@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// ImplicationCycleError is returned when saving role implications which would cause a role to imply itself
+type ImplicationCycleError struct {
+	// RoleID represents the database ID of the role the implications were saved for
+	RoleID int64
+	// ImpliedRoleID represents the database ID of the implied role leading back to the role
+	ImpliedRoleID int64
+}
+
+// NewImplicationCycleError creates a new cycle error for the role with the given ID and the implied role causing the cycle
+func NewImplicationCycleError(roleID int64, impliedRoleID int64) *ImplicationCycleError {
+	return &ImplicationCycleError{
+		RoleID:        roleID,
+		ImpliedRoleID: impliedRoleID,
+	}
+}
+
+// Error returns a readable description of the cycle
+func (err *ImplicationCycleError) Error() string {
+	return fmt.Sprintf("The role with ID %d cannot imply the role with ID %d as it would imply itself", err.RoleID, err.ImpliedRoleID)
+}
+
+// IsImplicationCycle checks whether the given error was caused by saving cyclic role implications
+func IsImplicationCycle(err error) bool {
+	_, ok := err.(*ImplicationCycleError)
+	return ok
+}
+
+// CheckRoleImplications verifies the role with the given ID can imply the given roles (and all roles implied by them) without implying itself,
+// returning an *ImplicationCycleError otherwise
+func CheckRoleImplications(roleID int64, impliedRoles []*models.Role) error {
+	for _, impliedRole := range impliedRoles {
+		if reachesRole(impliedRole, roleID, make(map[int64]bool)) {
+			return NewImplicationCycleError(roleID, impliedRole.ID)
+		}
+	}
+
+	return nil
+}
+
+// reachesRole checks whether the given role is or implies the role with the given ID, regardless of whether the roles are active
+func reachesRole(role *models.Role, roleID int64, visited map[int64]bool) bool {
+	if role.ID == roleID {
+		return true
+	}
+
+	if visited[role.ID] {
+		return false
+	}
+
+	visited[role.ID] = true
+
+	for _, impliedRole := range role.ImpliedRoles {
+		if reachesRole(impliedRole, roleID, visited) {
+			return true
+		}
+	}
+
+	return false
+}
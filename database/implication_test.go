@@ -0,0 +1,60 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestImplicationCycleError(t *testing.T) {
+	Convey("Creating a new implication cycle error", t, func() {
+		err := NewImplicationCycleError(2, 5)
+
+		Convey("The error should describe the cycle", func() {
+			So(err.Error(), ShouldEqual, "The role with ID 2 cannot imply the role with ID 5 as it would imply itself")
+		})
+
+		Convey("The error should be detected as implication cycle", func() {
+			So(IsImplicationCycle(err), ShouldBeTrue)
+		})
+
+		Convey("Other errors should not be detected as implication cycle", func() {
+			So(IsImplicationCycle(errors.New("Something went wrong")), ShouldBeFalse)
+			So(IsImplicationCycle(NewConflictError("role", 2, 1)), ShouldBeFalse)
+			So(IsImplicationCycle(nil), ShouldBeFalse)
+		})
+	})
+}
+
+func TestCheckRoleImplications(t *testing.T) {
+	Convey("Checking role implications", t, func() {
+		fc := &models.Role{ID: 1, Name: "fc", Active: true}
+		senior := &models.Role{ID: 2, Name: "fc.senior", Active: true, ImpliedRoles: []*models.Role{fc}}
+		inactive := &models.Role{ID: 3, Name: "fc.lead", Active: false, ImpliedRoles: []*models.Role{senior}}
+		other := &models.Role{ID: 4, Name: "admin", Active: true}
+
+		Convey("Implying unrelated roles should be allowed", func() {
+			So(CheckRoleImplications(fc.ID, []*models.Role{other}), ShouldBeNil)
+			So(CheckRoleImplications(inactive.ID, []*models.Role{senior, other}), ShouldBeNil)
+		})
+
+		Convey("Implying the role itself should be detected as cycle", func() {
+			err := CheckRoleImplications(fc.ID, []*models.Role{other, fc})
+			So(IsImplicationCycle(err), ShouldBeTrue)
+			So(err.(*ImplicationCycleError).ImpliedRoleID, ShouldEqual, fc.ID)
+		})
+
+		Convey("Implying a role implying the role should be detected as cycle", func() {
+			err := CheckRoleImplications(fc.ID, []*models.Role{senior})
+			So(IsImplicationCycle(err), ShouldBeTrue)
+			So(err.(*ImplicationCycleError).ImpliedRoleID, ShouldEqual, senior.ID)
+		})
+
+		Convey("Cycles through inactive roles should be detected as well", func() {
+			err := CheckRoleImplications(fc.ID, []*models.Role{inactive})
+			So(IsImplicationCycle(err), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,3665 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/migration"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+func init() {
+	database.Register(database.TypeNone, func(conf *misc.Configuration) database.Connection {
+		return &DatabaseConnection{
+			Config: conf,
+		}
+	})
+}
+
+// DatabaseConnection provides an implementation of the Connection interface storing all data in memory.
+// As no query can block, the contexts passed to its methods are not used
+type DatabaseConnection struct {
+	// Config stores the current configuration values being used
+	Config *misc.Configuration
+
+	lock sync.RWMutex
+
+	tables
+}
+
+// tables stores the rows of all in-memory tables as well as their auto-increment counters
+type tables struct {
+	autoIncrement map[string]int64
+
+	accounts              []*models.Account
+	alliances             []*models.Alliance
+	applications          []*models.Application
+	characters            []*models.Character
+	corporations          []*models.Corporation
+	csrfFailures          []*models.CSRFFailure
+	groupApplications     []*models.GroupApplication
+	groupManagers         []*models.GroupManager
+	groupRoles            []*groupRoleEntry
+	groupNestings         []*models.GroupNesting
+	groups                []*models.Group
+	loginAttempts         []*models.LoginAttempt
+	loginAttemptSummaries []*models.LoginAttemptSummary
+	managerActions        []*models.ManagerAction
+	membershipRules       []*models.MembershipRule
+	roleImplications      []*models.RoleImplication
+	roles                 []*models.Role
+	trash                 []*models.TrashEntry
+	userGroups            []*userGroupEntry
+	userRoles             []*userRoleEntry
+	users                 []*models.User
+}
+
+// groupRoleEntry represents a row of the grouproles table, referencing the role by its ID
+type groupRoleEntry struct {
+	ID        int64
+	GroupID   int64
+	RoleID    int64
+	AutoAdded bool
+	Granted   bool
+}
+
+// userRoleEntry represents a row of the userroles table, referencing the role by its ID
+type userRoleEntry struct {
+	ID        int64
+	UserID    int64
+	RoleID    int64
+	AutoAdded bool
+	Granted   bool
+	Validity  *models.Validity
+}
+
+// userGroupEntry represents a row of the usergroups table, storing a user's group membership
+type userGroupEntry struct {
+	ID        int64
+	UserID    int64
+	GroupID   int64
+	Active    bool
+	AutoAdded bool
+	Validity  *models.Validity
+}
+
+// Connect prepares the in-memory tables, returning an error if the attempt failed
+func (c *DatabaseConnection) Connect() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.autoIncrement == nil {
+		c.autoIncrement = make(map[string]int64)
+	}
+
+	return nil
+}
+
+// Ping verifies the in-memory database is available, returning an error if it has not been connected yet
+func (c *DatabaseConnection) Ping(ctx context.Context) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.autoIncrement == nil {
+		return fmt.Errorf("Not connected to the in-memory database")
+	}
+
+	return nil
+}
+
+// WithTx runs the given function against a copy of the in-memory tables, replacing the stored data only if the function returns nil
+func (c *DatabaseConnection) WithTx(ctx context.Context, fn func(tx database.Connection) error) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	tx := &DatabaseConnection{
+		Config: c.Config,
+		tables: c.tables.clone(),
+	}
+
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	c.tables = tx.tables
+
+	return nil
+}
+
+// MigrateUp does nothing as the in-memory database does not use a versioned schema
+func (c *DatabaseConnection) MigrateUp() (int, error) {
+	return 0, nil
+}
+
+// MigrateDown does nothing as the in-memory database does not use a versioned schema
+func (c *DatabaseConnection) MigrateDown() (int, error) {
+	return 0, nil
+}
+
+// MigrationStatus returns an empty status as the in-memory database does not use a versioned schema
+func (c *DatabaseConnection) MigrationStatus() (*migration.Status, error) {
+	status := &migration.Status{
+		Pending: make([]*migration.Migration, 0),
+	}
+
+	return status, nil
+}
+
+// RunReport runs the registered report with the given name and parameters at the in-memory database, returning its typed rows or an error if the report is unknown or a parameter is invalid
+func (c *DatabaseConnection) RunReport(ctx context.Context, name string, parameters map[string]string) (*database.ReportResult, error) {
+	result, err := database.NewReportResult(name, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	switch result.Report.Name {
+	case database.ReportCorporationMembers:
+		result.Rows = c.reportCorporationMembers()
+	case database.ReportUsersWithoutAPIKeys:
+		result.Rows = c.reportUsersWithoutAPIKeys()
+	case database.ReportLoginAttempts:
+		args := result.Arguments()
+		result.Rows = c.reportLoginAttempts(args[0].(time.Time), args[1].(string))
+	default:
+		return nil, fmt.Errorf("Report %q is not supported by the in-memory database", result.Report.Name)
+	}
+
+	return result, nil
+}
+
+// reportCorporationMembers counts the users and characters per corporation, ignoring deleted users. The caller must hold the read lock
+func (c *DatabaseConnection) reportCorporationMembers() [][]interface{} {
+	rows := make([][]interface{}, 0, len(c.corporations))
+
+	for _, corporation := range c.corporations {
+		users := make(map[int64]bool)
+		var characters int64
+
+		for _, character := range c.characters {
+			if character.CorporationID != corporation.ID {
+				continue
+			}
+
+			for _, account := range c.accounts {
+				if account.ID != character.AccountID || c.isDeleted(models.TrashEntryTypeUser, account.UserID) {
+					continue
+				}
+
+				users[account.UserID] = true
+				characters++
+			}
+		}
+
+		rows = append(rows, []interface{}{corporation.Name, corporation.Ticker, int64(len(users)), characters})
+	}
+
+	sort.Sort(reportRowsByColumn{rows: rows, column: 0})
+
+	return rows
+}
+
+// reportUsersWithoutAPIKeys lists all users which have not added any accounts, ignoring deleted users. The caller must hold the read lock
+func (c *DatabaseConnection) reportUsersWithoutAPIKeys() [][]interface{} {
+	rows := make([][]interface{}, 0)
+
+	for _, user := range c.users {
+		if c.isDeleted(models.TrashEntryTypeUser, user.ID) {
+			continue
+		}
+
+		hasAccount := false
+		for _, account := range c.accounts {
+			if account.UserID == user.ID {
+				hasAccount = true
+				break
+			}
+		}
+
+		if !hasAccount {
+			rows = append(rows, []interface{}{user.ID, user.Username, user.Email, user.Active})
+		}
+	}
+
+	sort.Sort(reportRowsByColumn{rows: rows, column: 1})
+
+	return rows
+}
+
+// reportLoginAttempts counts the failed and successful login attempts per UTC day since the given time, including the summaries of pruned login attempts since the given day. The caller must hold the read lock
+func (c *DatabaseConnection) reportLoginAttempts(since time.Time, sinceDay string) [][]interface{} {
+	days := make(map[string][]interface{})
+	rows := make([][]interface{}, 0)
+
+	dayRow := func(day string) []interface{} {
+		row, ok := days[day]
+		if !ok {
+			row = []interface{}{day, int64(0), int64(0)}
+			days[day] = row
+			rows = append(rows, row)
+		}
+
+		return row
+	}
+
+	for _, summary := range c.loginAttemptSummaries {
+		if summary.Day < sinceDay {
+			continue
+		}
+
+		row := dayRow(summary.Day)
+		row[1] = row[1].(int64) + summary.Failed
+		row[2] = row[2].(int64) + summary.Successful
+	}
+
+	for _, loginAttempt := range c.loginAttempts {
+		if loginAttempt.Timestamp.Before(since) {
+			continue
+		}
+
+		row := dayRow(loginAttempt.Timestamp.UTC().Format("2006-01-02"))
+
+		if loginAttempt.Successful {
+			row[2] = row[2].(int64) + 1
+		} else {
+			row[1] = row[1].(int64) + 1
+		}
+	}
+
+	sort.Sort(reportRowsByColumn{rows: rows, column: 0})
+
+	return rows
+}
+
+// LoadAllAccounts retrieves all accounts from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllAccounts(ctx context.Context) ([]*models.Account, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var accounts []*models.Account
+
+	for _, entry := range c.accounts {
+		account := copyAccount(entry)
+		account.Characters = c.loadAllCharactersForAccount(account.ID)
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// LoadAllCorporations retrieves all corporations from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCorporations(ctx context.Context) ([]*models.Corporation, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var corporations []*models.Corporation
+
+	for _, entry := range c.corporations {
+		corporation := *entry
+		corporations = append(corporations, &corporation)
+	}
+
+	return corporations, nil
+}
+
+// LoadAllAlliances retrieves all alliances from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllAlliances(ctx context.Context) ([]*models.Alliance, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var alliances []*models.Alliance
+
+	for _, entry := range c.alliances {
+		alliance := *entry
+		alliances = append(alliances, &alliance)
+	}
+
+	return alliances, nil
+}
+
+// LoadAllCharacters retrieves all characters from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCharacters(ctx context.Context) ([]*models.Character, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var characters []*models.Character
+
+	for _, entry := range c.characters {
+		character := *entry
+		characters = append(characters, &character)
+	}
+
+	return characters, nil
+}
+
+// LoadAllRoles retrieves all roles (and the roles implied by them) from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllRoles(ctx context.Context) ([]*models.Role, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var roles []*models.Role
+
+	for _, entry := range c.roles {
+		if c.isDeleted(models.TrashEntryTypeRole, entry.ID) {
+			continue
+		}
+
+		role := *entry
+
+		err := c.loadImpliedRoles(&role, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, &role)
+	}
+
+	return roles, nil
+}
+
+// LoadAllGroupRoles retrieves all group roles (and their associated roles) from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupRoles(ctx context.Context) ([]*models.GroupRole, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groupRoles []*models.GroupRole
+
+	for _, entry := range c.groupRoles {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.GroupID) || c.isDeleted(models.TrashEntryTypeRole, entry.RoleID) {
+			continue
+		}
+
+		groupRole, err := c.groupRoleFromEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		groupRoles = append(groupRoles, groupRole)
+	}
+
+	return groupRoles, nil
+}
+
+// LoadAllUserRoles retrieves all user roles (and their associated roles) from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllUserRoles(ctx context.Context) ([]*models.UserRole, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var userRoles []*models.UserRole
+
+	for _, entry := range c.userRoles {
+		if c.isDeleted(models.TrashEntryTypeUser, entry.UserID) || c.isDeleted(models.TrashEntryTypeRole, entry.RoleID) {
+			continue
+		}
+
+		userRole, err := c.userRoleFromEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		userRoles = append(userRoles, userRole)
+	}
+
+	return userRoles, nil
+}
+
+// LoadAllRoleImplications retrieves all implications between roles not in the trash from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllRoleImplications(ctx context.Context) ([]*models.RoleImplication, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var roleImplications []*models.RoleImplication
+
+	for _, entry := range c.roleImplications {
+		if c.isDeleted(models.TrashEntryTypeRole, entry.RoleID) || c.isDeleted(models.TrashEntryTypeRole, entry.ImpliedRoleID) {
+			continue
+		}
+
+		roleImplication := *entry
+		roleImplications = append(roleImplications, &roleImplication)
+	}
+
+	return roleImplications, nil
+}
+
+// LoadAllGroupNestings retrieves all nestings between groups not in the trash from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupNestings(ctx context.Context) ([]*models.GroupNesting, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groupNestings []*models.GroupNesting
+
+	for _, entry := range c.groupNestings {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.GroupID) || c.isDeleted(models.TrashEntryTypeGroup, entry.SubGroupID) {
+			continue
+		}
+
+		groupNesting := *entry
+		groupNestings = append(groupNestings, &groupNesting)
+	}
+
+	return groupNestings, nil
+}
+
+// LoadAllMembershipRules retrieves all membership rules of groups not in the trash from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllMembershipRules(ctx context.Context) ([]*models.MembershipRule, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var membershipRules []*models.MembershipRule
+
+	for _, entry := range c.membershipRules {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.GroupID) {
+			continue
+		}
+
+		membershipRule := *entry
+		membershipRules = append(membershipRules, &membershipRule)
+	}
+
+	return membershipRules, nil
+}
+
+// LoadAllGroupApplications retrieves all group applications of groups and users not in the trash from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupApplications(ctx context.Context) ([]*models.GroupApplication, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadGroupApplications(func(groupApplication *models.GroupApplication) bool { return true }), nil
+}
+
+// LoadPendingGroupApplications retrieves all pending group applications of groups and users not in the trash from the in-memory database, oldest first, returning an error if the query failed
+func (c *DatabaseConnection) LoadPendingGroupApplications(ctx context.Context) ([]*models.GroupApplication, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadGroupApplications(func(groupApplication *models.GroupApplication) bool { return groupApplication.IsPending() }), nil
+}
+
+// LoadAllGroupManagers retrieves all group manager assignments of groups and users not in the trash from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupManagers(ctx context.Context) ([]*models.GroupManager, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groupManagers []*models.GroupManager
+
+	for _, entry := range c.groupManagers {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.GroupID) || c.isDeleted(models.TrashEntryTypeUser, entry.UserID) {
+			continue
+		}
+
+		groupManager := *entry
+		groupManagers = append(groupManagers, &groupManager)
+	}
+
+	return groupManagers, nil
+}
+
+// LoadAllManagerActions retrieves all recorded manager actions from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllManagerActions(ctx context.Context) ([]*models.ManagerAction, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var managerActions []*models.ManagerAction
+
+	for _, entry := range c.managerActions {
+		managerAction := *entry
+		managerActions = append(managerActions, &managerAction)
+	}
+
+	return managerActions, nil
+}
+
+// LoadAllGroups retrieves all groups (and their associated group roles and sub groups) from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroups(ctx context.Context) ([]*models.Group, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groups []*models.Group
+
+	for _, entry := range c.groups {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.ID) {
+			continue
+		}
+
+		group := copyGroup(entry)
+
+		groupRoles, err := c.loadAllGroupRolesForGroup(group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// LoadAllUsers retrieves all users (and their associates groups and user roles) from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllUsers(ctx context.Context) ([]*models.User, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var users []*models.User
+
+	for _, entry := range c.users {
+		if c.isDeleted(models.TrashEntryTypeUser, entry.ID) {
+			continue
+		}
+
+		user, err := c.populateUser(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// LoadAllApplications retrieves all applications from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllApplications(ctx context.Context) ([]*models.Application, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var applications []*models.Application
+
+	for _, entry := range c.applications {
+		if c.isDeleted(models.TrashEntryTypeApplication, entry.ID) {
+			continue
+		}
+
+		application := *entry
+		applications = append(applications, &application)
+	}
+
+	return applications, nil
+}
+
+// LoadAllLoginAttempts retrieves all login attempts from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllLoginAttempts(ctx context.Context) ([]*models.LoginAttempt, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var loginAttempts []*models.LoginAttempt
+
+	for _, entry := range c.loginAttempts {
+		loginAttempt := *entry
+		loginAttempts = append(loginAttempts, &loginAttempt)
+	}
+
+	return loginAttempts, nil
+}
+
+// LoadAllLoginAttemptSummaries retrieves the daily summaries of all pruned login attempts from the in-memory database, sorted by day, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllLoginAttemptSummaries(ctx context.Context) ([]*models.LoginAttemptSummary, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var summaries []*models.LoginAttemptSummary
+
+	for _, entry := range c.loginAttemptSummaries {
+		summary := *entry
+		summaries = append(summaries, &summary)
+	}
+
+	sort.Sort(summariesByDay(summaries))
+
+	return summaries, nil
+}
+
+// LoadAllCSRFFailures retrieves all CSRF failures from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCSRFFailures(ctx context.Context) ([]*models.CSRFFailure, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var csrfFailures []*models.CSRFFailure
+
+	for _, entry := range c.csrfFailures {
+		csrfFailure := *entry
+		csrfFailures = append(csrfFailures, &csrfFailure)
+	}
+
+	return csrfFailures, nil
+}
+
+// QueryUsers retrieves a page of users (and their associated accounts, groups and user roles) matching the given criteria as well as the total number of matches from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) QueryUsers(ctx context.Context, criteria *database.ListCriteria) ([]*models.User, int64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	sortColumn := criteria.SortColumn(database.UserSortFields)
+
+	var entries []*listEntry
+
+	for _, entry := range c.users {
+		if c.isDeleted(models.TrashEntryTypeUser, entry.ID) {
+			continue
+		} else if !matchesListCriteria(criteria, entry.Active, entry.Username, entry.Email) {
+			continue
+		} else if criteria.GroupID > 0 && !c.isActiveGroupMember(entry.ID, criteria.GroupID) {
+			continue
+		} else if criteria.CorporationID > 0 && !c.hasCharacterInCorporation(entry.ID, criteria.CorporationID) {
+			continue
+		}
+
+		switch sortColumn {
+		case "username":
+			entries = append(entries, textListEntry(entry.ID, entry.Username, entry))
+		case "email":
+			entries = append(entries, textListEntry(entry.ID, entry.Email, entry))
+		case "active":
+			entries = append(entries, boolListEntry(entry.ID, entry.Active, entry))
+		default:
+			entries = append(entries, numericListEntry(entry.ID, entry.ID, entry))
+		}
+	}
+
+	page, total := pageListEntries(entries, criteria)
+
+	var users []*models.User
+
+	for _, entry := range page {
+		user, err := c.populateUser(entry.value.(*models.User))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, total, nil
+}
+
+// QueryGroups retrieves a page of groups (and their associated group roles and sub groups) matching the given criteria as well as the total number of matches from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) QueryGroups(ctx context.Context, criteria *database.ListCriteria) ([]*models.Group, int64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	sortColumn := criteria.SortColumn(database.GroupSortFields)
+
+	var entries []*listEntry
+
+	for _, entry := range c.groups {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.ID) || !matchesListCriteria(criteria, entry.Active, entry.Name) {
+			continue
+		}
+
+		switch sortColumn {
+		case "name":
+			entries = append(entries, textListEntry(entry.ID, entry.Name, entry))
+		case "active":
+			entries = append(entries, boolListEntry(entry.ID, entry.Active, entry))
+		default:
+			entries = append(entries, numericListEntry(entry.ID, entry.ID, entry))
+		}
+	}
+
+	page, total := pageListEntries(entries, criteria)
+
+	var groups []*models.Group
+
+	for _, entry := range page {
+		group, err := c.loadGroup(entry.id)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, total, nil
+}
+
+// QueryRoles retrieves a page of roles (and the roles implied by them) matching the given criteria as well as the total number of matches from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) QueryRoles(ctx context.Context, criteria *database.ListCriteria) ([]*models.Role, int64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	sortColumn := criteria.SortColumn(database.RoleSortFields)
+
+	var entries []*listEntry
+
+	for _, entry := range c.roles {
+		if c.isDeleted(models.TrashEntryTypeRole, entry.ID) || !matchesListCriteria(criteria, entry.Active, entry.Name) {
+			continue
+		}
+
+		switch sortColumn {
+		case "name":
+			entries = append(entries, textListEntry(entry.ID, entry.Name, entry))
+		case "active":
+			entries = append(entries, boolListEntry(entry.ID, entry.Active, entry))
+		case "locked":
+			entries = append(entries, boolListEntry(entry.ID, entry.Locked, entry))
+		default:
+			entries = append(entries, numericListEntry(entry.ID, entry.ID, entry))
+		}
+	}
+
+	page, total := pageListEntries(entries, criteria)
+
+	var roles []*models.Role
+
+	for _, entry := range page {
+		role := *entry.value.(*models.Role)
+
+		err := c.loadImpliedRoles(&role, make(map[int64]bool))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		roles = append(roles, &role)
+	}
+
+	return roles, total, nil
+}
+
+// QueryApplications retrieves a page of applications matching the given criteria as well as the total number of matches from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) QueryApplications(ctx context.Context, criteria *database.ListCriteria) ([]*models.Application, int64, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	sortColumn := criteria.SortColumn(database.ApplicationSortFields)
+
+	var entries []*listEntry
+
+	for _, entry := range c.applications {
+		if c.isDeleted(models.TrashEntryTypeApplication, entry.ID) || !matchesListCriteria(criteria, entry.Active, entry.Name) {
+			continue
+		}
+
+		switch sortColumn {
+		case "name":
+			entries = append(entries, textListEntry(entry.ID, entry.Name, entry))
+		case "active":
+			entries = append(entries, boolListEntry(entry.ID, entry.Active, entry))
+		default:
+			entries = append(entries, numericListEntry(entry.ID, entry.ID, entry))
+		}
+	}
+
+	page, total := pageListEntries(entries, criteria)
+
+	var applications []*models.Application
+
+	for _, entry := range page {
+		application := *entry.value.(*models.Application)
+		applications = append(applications, &application)
+	}
+
+	return applications, total, nil
+}
+
+// LoadAccount retrieves the account with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry := c.findAccount(accountID)
+	if entry == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	account := copyAccount(entry)
+	account.Characters = c.loadAllCharactersForAccount(account.ID)
+
+	return account, nil
+}
+
+// LoadCorporation retrieves the corporation with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadCorporation(ctx context.Context, corporationID int64) (*models.Corporation, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry := c.findCorporation(corporationID)
+	if entry == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	corporation := *entry
+
+	return &corporation, nil
+}
+
+// LoadCorporationFromEVECorporationID retrieves the corporation with the given EVE Online corporation ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadCorporationFromEVECorporationID(ctx context.Context, eveCorporationID int64) (*models.Corporation, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, entry := range c.corporations {
+		if entry.EVECorporationID == eveCorporationID {
+			corporation := *entry
+			return &corporation, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// LoadCorporationNameFromID retrieves the name of the corporation with the given ID, returning an error if the query failed
+func (c *DatabaseConnection) LoadCorporationNameFromID(ctx context.Context, corporationID int64) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry := c.findCorporation(corporationID)
+	if entry == nil {
+		return "", sql.ErrNoRows
+	}
+
+	return entry.Name, nil
+}
+
+// LoadAlliance retrieves the alliance with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAlliance(ctx context.Context, allianceID int64) (*models.Alliance, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry := c.findAlliance(allianceID)
+	if entry == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	alliance := *entry
+
+	return &alliance, nil
+}
+
+// LoadAllianceFromEVEAllianceID retrieves the alliance with the given EVE Online alliance ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllianceFromEVEAllianceID(ctx context.Context, eveAllianceID int64) (*models.Alliance, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, entry := range c.alliances {
+		if entry.EVEAllianceID == eveAllianceID {
+			alliance := *entry
+			return &alliance, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// LoadCharacter retrieves the character with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadCharacter(ctx context.Context, characterID int64) (*models.Character, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry := c.findCharacter(characterID)
+	if entry == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	character := *entry
+
+	return &character, nil
+}
+
+// LoadRole retrieves the role (and all roles implied by it) with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadRole(ctx context.Context, roleID int64) (*models.Role, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadRole(roleID)
+}
+
+// LoadGroupRole retrieves the group role (and its associated role) with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadGroupRole(ctx context.Context, groupRoleID int64) (*models.GroupRole, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadGroupRole(groupRoleID)
+}
+
+// LoadUserRole retrieves the user role (and its associated role) with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUserRole(ctx context.Context, userRoleID int64) (*models.UserRole, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadUserRole(userRoleID)
+}
+
+// LoadGroup retrieves the group (and its associated group roles and sub groups) with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadGroup(ctx context.Context, groupID int64) (*models.Group, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadGroup(groupID)
+}
+
+// LoadUser retrieves the user (and its associated groups and user roles) with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUser(ctx context.Context, userID int64) (*models.User, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadUser(userID)
+}
+
+// LoadUserFromUsername retrieves the user (and its associated groups and user roles) with the given username from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUserFromUsername(ctx context.Context, username string) (*models.User, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, entry := range c.users {
+		if strings.EqualFold(entry.Username, username) && !c.isDeleted(models.TrashEntryTypeUser, entry.ID) {
+			return c.populateUser(entry)
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// LoadApplication retrieves the application with the given application ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadApplication(ctx context.Context, applicationID int64) (*models.Application, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry := c.findApplication(applicationID)
+	if entry == nil || c.isDeleted(models.TrashEntryTypeApplication, entry.ID) {
+		return nil, sql.ErrNoRows
+	}
+
+	application := *entry
+
+	return &application, nil
+}
+
+// LoadGroupApplication retrieves the group application with the given ID from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadGroupApplication(ctx context.Context, groupApplicationID int64) (*models.GroupApplication, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	groupApplications := c.loadGroupApplications(func(groupApplication *models.GroupApplication) bool { return groupApplication.ID == groupApplicationID })
+	if len(groupApplications) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return groupApplications[0], nil
+}
+
+// LoadAllAccountsForUser retrieves all accounts associated with the given user from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllAccountsForUser(ctx context.Context, userID int64) ([]*models.Account, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadAllAccountsForUser(userID), nil
+}
+
+// LoadAllCharactersForAccount retrieves all characters associated with the given account from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCharactersForAccount(ctx context.Context, accountID int64) ([]*models.Character, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadAllCharactersForAccount(accountID), nil
+}
+
+// LoadAllGroupRolesForGroup retrieves all group roles (and their associated roles) associated with the given group from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.GroupRole, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadAllGroupRolesForGroup(groupID)
+}
+
+// LoadAllMembershipRulesForGroup retrieves all membership rules associated with the given group from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllMembershipRulesForGroup(ctx context.Context, groupID int64) ([]*models.MembershipRule, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var membershipRules []*models.MembershipRule
+
+	for _, entry := range c.membershipRules {
+		if entry.GroupID != groupID {
+			continue
+		}
+
+		membershipRule := *entry
+		membershipRules = append(membershipRules, &membershipRule)
+	}
+
+	return membershipRules, nil
+}
+
+// LoadAllGroupApplicationsForUser retrieves all applications of the given user for groups not in the trash from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupApplicationsForUser(ctx context.Context, userID int64) ([]*models.GroupApplication, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groupApplications []*models.GroupApplication
+
+	for _, entry := range c.groupApplications {
+		if entry.UserID != userID || c.isDeleted(models.TrashEntryTypeGroup, entry.GroupID) {
+			continue
+		}
+
+		groupApplication := *entry
+		groupApplications = append(groupApplications, &groupApplication)
+	}
+
+	return groupApplications, nil
+}
+
+// LoadAllGroupManagersForGroup retrieves all manager assignments of users not in the trash associated with the given group from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupManagersForGroup(ctx context.Context, groupID int64) ([]*models.GroupManager, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groupManagers []*models.GroupManager
+
+	for _, entry := range c.groupManagers {
+		if entry.GroupID != groupID || c.isDeleted(models.TrashEntryTypeUser, entry.UserID) {
+			continue
+		}
+
+		groupManager := *entry
+		groupManagers = append(groupManagers, &groupManager)
+	}
+
+	return groupManagers, nil
+}
+
+// LoadAllManagerActionsForGroup retrieves all manager actions performed on the given group from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllManagerActionsForGroup(ctx context.Context, groupID int64) ([]*models.ManagerAction, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var managerActions []*models.ManagerAction
+
+	for _, entry := range c.managerActions {
+		if entry.GroupID != groupID {
+			continue
+		}
+
+		managerAction := *entry
+		managerActions = append(managerActions, &managerAction)
+	}
+
+	return managerActions, nil
+}
+
+// LoadAllUserRolesForUser retrieves all user roles (and their associated roles) associated with the given user from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllUserRolesForUser(ctx context.Context, userID int64) ([]*models.UserRole, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadAllUserRolesForUser(userID)
+}
+
+// LoadAllGroupsForUser retrieves all groups (and their associated group roles and sub groups) the given user is a direct member of from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.loadAllGroupsForUser(userID)
+}
+
+// LoadAvailableGroupsForUser retrieves all available groups (and their associated group roles and sub groups) associated with the given user from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAvailableGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	groups := make([]*models.Group, 0)
+
+	for _, entry := range c.groups {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.ID) || c.isActiveGroupMember(userID, entry.ID) {
+			continue
+		}
+
+		group := copyGroup(entry)
+
+		groupRoles, err := c.loadAllGroupRolesForGroup(group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	sort.Sort(groupsByName(groups))
+
+	return groups, nil
+}
+
+// LoadAllManagedGroupsForUser retrieves all groups (and their associated group roles and sub groups) managed by the given user from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllManagedGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	groups := make([]*models.Group, 0)
+
+	for _, groupManager := range c.groupManagers {
+		if groupManager.UserID != userID || c.isDeleted(models.TrashEntryTypeGroup, groupManager.GroupID) {
+			continue
+		}
+
+		entry := c.findGroup(groupManager.GroupID)
+		if entry == nil {
+			continue
+		}
+
+		group := copyGroup(entry)
+
+		groupRoles, err := c.loadAllGroupRolesForGroup(group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	sort.Sort(groupsByName(groups))
+
+	return groups, nil
+}
+
+// LoadAvailableUserRolesForUser retrieves all available user roles for the given user from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAvailableUserRolesForUser(ctx context.Context, userID int64) ([]*models.Role, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	roles := make([]*models.Role, 0)
+
+	for _, entry := range c.roles {
+		if c.isDeleted(models.TrashEntryTypeRole, entry.ID) {
+			continue
+		}
+
+		assigned := false
+
+		for _, userRole := range c.userRoles {
+			if userRole.UserID == userID && userRole.RoleID == entry.ID {
+				assigned = true
+				break
+			}
+		}
+
+		if !assigned {
+			role := *entry
+			roles = append(roles, &role)
+		}
+	}
+
+	sort.Sort(rolesByName(roles))
+
+	return roles, nil
+}
+
+// LoadAvailableGroupRolesForGroup retrieves all available group roles for the given group from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAvailableGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.Role, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	roles := make([]*models.Role, 0)
+
+	for _, entry := range c.roles {
+		if c.isDeleted(models.TrashEntryTypeRole, entry.ID) {
+			continue
+		}
+
+		assigned := false
+
+		for _, groupRole := range c.groupRoles {
+			if groupRole.GroupID == groupID && groupRole.RoleID == entry.ID {
+				assigned = true
+				break
+			}
+		}
+
+		if !assigned {
+			role := *entry
+			roles = append(roles, &role)
+		}
+	}
+
+	sort.Sort(rolesByName(roles))
+
+	return roles, nil
+}
+
+// LoadAllApplicationsForUser retrieves all applications associated with the given user from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllApplicationsForUser(ctx context.Context, userID int64) ([]*models.Application, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var applications []*models.Application
+
+	for _, entry := range c.applications {
+		if entry.MaintainerID == userID && !c.isDeleted(models.TrashEntryTypeApplication, entry.ID) {
+			application := *entry
+			applications = append(applications, &application)
+		}
+	}
+
+	return applications, nil
+}
+
+// LoadPasswordForUser retrieves the password associated with the given username from the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) LoadPasswordForUser(ctx context.Context, username string) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, entry := range c.users {
+		if strings.EqualFold(entry.Username, username) && !c.isDeleted(models.TrashEntryTypeUser, entry.ID) {
+			return entry.Password, nil
+		}
+	}
+
+	return "", sql.ErrNoRows
+}
+
+// QueryUserIDExists checks whether a user with the given user ID exists in the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) QueryUserIDExists(ctx context.Context, userID int64) (bool, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return (c.findUser(userID) != nil && !c.isDeleted(models.TrashEntryTypeUser, userID)), nil
+}
+
+// QueryUserNameEmailExists checks whether a user with the given username or email address exists in the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) QueryUserNameEmailExists(ctx context.Context, username string, email string) (bool, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, entry := range c.users {
+		if strings.EqualFold(entry.Username, username) || strings.EqualFold(entry.Email, email) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// SaveAccount saves an account to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveAccount(ctx context.Context, account *models.Account) (*models.Account, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.saveAccount(account)
+}
+
+// SaveCorporation saves a corporation to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveCorporation(ctx context.Context, corporation *models.Corporation) (*models.Corporation, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, entry := range c.corporations {
+		if entry.ID == corporation.ID {
+			continue
+		}
+
+		if strings.EqualFold(entry.Name, corporation.Name) {
+			return nil, duplicateEntryError(corporation.Name, "name")
+		} else if strings.EqualFold(entry.Ticker, corporation.Ticker) {
+			return nil, duplicateEntryError(corporation.Ticker, "ticker")
+		} else if entry.EVECorporationID == corporation.EVECorporationID {
+			return nil, duplicateEntryError(corporation.EVECorporationID, "evecorporationid")
+		} else if entry.APIKeyID.Valid && corporation.APIKeyID.Valid && entry.APIKeyID.Int64 == corporation.APIKeyID.Int64 {
+			return nil, duplicateEntryError(corporation.APIKeyID.Int64, "apikeyid")
+		}
+	}
+
+	if corporation.ID > 0 {
+		entry := c.findCorporation(corporation.ID)
+		if entry != nil {
+			*entry = *corporation
+		}
+	} else {
+		corporation.ID = c.nextID("corporations")
+
+		entry := *corporation
+		c.corporations = append(c.corporations, &entry)
+	}
+
+	return corporation, nil
+}
+
+// SaveAlliance saves an alliance to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveAlliance(ctx context.Context, alliance *models.Alliance) (*models.Alliance, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, entry := range c.alliances {
+		if entry.ID != alliance.ID && entry.EVEAllianceID == alliance.EVEAllianceID {
+			return nil, duplicateEntryError(alliance.EVEAllianceID, "eveallianceid")
+		}
+	}
+
+	if alliance.ID > 0 {
+		entry := c.findAlliance(alliance.ID)
+		if entry != nil {
+			*entry = *alliance
+		}
+	} else {
+		alliance.ID = c.nextID("alliances")
+
+		entry := *alliance
+		c.alliances = append(c.alliances, &entry)
+	}
+
+	return alliance, nil
+}
+
+// SaveCharacter saves a character to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveCharacter(ctx context.Context, character *models.Character) (*models.Character, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.saveCharacter(character)
+}
+
+// SaveRole saves a role to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.saveRole(role)
+}
+
+// SaveGroupRole saves a group role to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveGroupRole(ctx context.Context, groupRole *models.GroupRole) (*models.GroupRole, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.saveGroupRole(groupRole)
+}
+
+// SaveUserRole saves a user role to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveUserRole(ctx context.Context, userRole *models.UserRole) (*models.UserRole, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.saveUserRole(userRole)
+}
+
+// SaveRoleImplications replaces the roles directly implied by the role with the given ID in the in-memory database, returning an error if the query failed.
+// Implications causing the role to imply itself return an *ImplicationCycleError
+func (c *DatabaseConnection) SaveRoleImplications(ctx context.Context, roleID int64, impliedRoleIDs []int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := c.findRole(roleID)
+	if entry == nil || c.isDeleted(models.TrashEntryTypeRole, roleID) {
+		return sql.ErrNoRows
+	}
+
+	impliedRoles := make([]*models.Role, 0, len(impliedRoleIDs))
+	seen := make(map[int64]bool)
+
+	for _, impliedRoleID := range impliedRoleIDs {
+		if seen[impliedRoleID] {
+			continue
+		}
+
+		seen[impliedRoleID] = true
+
+		impliedRole, err := c.loadRole(impliedRoleID)
+		if err != nil {
+			return err
+		}
+
+		impliedRoles = append(impliedRoles, impliedRole)
+	}
+
+	err := database.CheckRoleImplications(roleID, impliedRoles)
+	if err != nil {
+		return err
+	}
+
+	c.deleteRoleImplications(func(implication *models.RoleImplication) bool { return implication.RoleID == roleID })
+
+	for _, impliedRole := range impliedRoles {
+		c.roleImplications = append(c.roleImplications, &models.RoleImplication{
+			ID:            c.nextID("roleimplications"),
+			RoleID:        roleID,
+			ImpliedRoleID: impliedRole.ID,
+		})
+	}
+
+	entry.Version++
+
+	return nil
+}
+
+// SaveSubGroups replaces the groups directly nested in the group with the given ID in the in-memory database, returning an error if the query failed.
+// Nestings causing the group to contain itself return a *NestingCycleError
+func (c *DatabaseConnection) SaveSubGroups(ctx context.Context, groupID int64, subGroupIDs []int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := c.findGroup(groupID)
+	if entry == nil || c.isDeleted(models.TrashEntryTypeGroup, groupID) {
+		return sql.ErrNoRows
+	}
+
+	subGroups := make([]*models.Group, 0, len(subGroupIDs))
+	seen := make(map[int64]bool)
+
+	for _, subGroupID := range subGroupIDs {
+		if seen[subGroupID] {
+			continue
+		}
+
+		seen[subGroupID] = true
+
+		subGroup, err := c.loadGroup(subGroupID)
+		if err != nil {
+			return err
+		}
+
+		subGroups = append(subGroups, subGroup)
+	}
+
+	err := database.CheckSubGroups(groupID, subGroups)
+	if err != nil {
+		return err
+	}
+
+	c.deleteGroupNestings(func(nesting *models.GroupNesting) bool { return nesting.GroupID == groupID })
+
+	for _, subGroup := range subGroups {
+		c.groupNestings = append(c.groupNestings, &models.GroupNesting{
+			ID:         c.nextID("groupnestings"),
+			GroupID:    groupID,
+			SubGroupID: subGroup.ID,
+		})
+	}
+
+	entry.Version++
+
+	return nil
+}
+
+// SaveMembershipRule saves a membership rule to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveMembershipRule(ctx context.Context, membershipRule *models.MembershipRule) (*models.MembershipRule, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, entry := range c.membershipRules {
+		if entry.ID != membershipRule.ID && entry.GroupID == membershipRule.GroupID && entry.Type == membershipRule.Type && entry.TargetID == membershipRule.TargetID {
+			return nil, duplicateEntryError(fmt.Sprintf("%d-%s-%d", membershipRule.GroupID, membershipRule.Type, membershipRule.TargetID), "groupid_type_targetid")
+		}
+	}
+
+	if membershipRule.ID > 0 {
+		for _, entry := range c.membershipRules {
+			if entry.ID == membershipRule.ID {
+				*entry = *membershipRule
+				break
+			}
+		}
+	} else {
+		membershipRule.ID = c.nextID("membershiprules")
+
+		entry := *membershipRule
+		c.membershipRules = append(c.membershipRules, &entry)
+	}
+
+	return membershipRule, nil
+}
+
+// SaveGroupManager saves a group manager assignment to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveGroupManager(ctx context.Context, groupManager *models.GroupManager) (*models.GroupManager, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, entry := range c.groupManagers {
+		if entry.ID != groupManager.ID && entry.GroupID == groupManager.GroupID && entry.UserID == groupManager.UserID {
+			return nil, duplicateEntryError(fmt.Sprintf("%d-%d", groupManager.GroupID, groupManager.UserID), "groupid_userid")
+		}
+	}
+
+	if groupManager.ID > 0 {
+		for _, entry := range c.groupManagers {
+			if entry.ID == groupManager.ID {
+				*entry = *groupManager
+				break
+			}
+		}
+	} else {
+		groupManager.ID = c.nextID("groupmanagers")
+
+		entry := *groupManager
+		c.groupManagers = append(c.groupManagers, &entry)
+	}
+
+	return groupManager, nil
+}
+
+// SaveGroupApplication saves a group application to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveGroupApplication(ctx context.Context, groupApplication *models.GroupApplication) (*models.GroupApplication, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if groupApplication.CreatedAt.IsZero() {
+		groupApplication.CreatedAt = time.Now()
+	}
+
+	if groupApplication.ID > 0 {
+		for _, entry := range c.groupApplications {
+			if entry.ID == groupApplication.ID {
+				*entry = *groupApplication
+				break
+			}
+		}
+	} else {
+		groupApplication.ID = c.nextID("groupapplications")
+
+		entry := *groupApplication
+		c.groupApplications = append(c.groupApplications, &entry)
+	}
+
+	return groupApplication, nil
+}
+
+// SaveGroup saves a group to the in-memory database, returning the updated model or an error if the query failed. All changes are reverted if any of them fails,
+// including the IDs and versions assigned to the given model
+func (c *DatabaseConnection) SaveGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	snapshot := c.tables.clone()
+	modelSnapshot := database.SnapshotGroup(group)
+
+	result, err := c.saveGroup(group)
+	if err != nil {
+		c.tables = snapshot
+		modelSnapshot.Restore()
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *DatabaseConnection) saveGroup(group *models.Group) (*models.Group, error) {
+	for _, entry := range c.groups {
+		if entry.ID != group.ID && strings.EqualFold(entry.Name, group.Name) {
+			return nil, duplicateEntryError(group.Name, "name")
+		}
+	}
+
+	if group.ID > 0 {
+		entry := c.findGroup(group.ID)
+		if entry == nil || entry.Version != group.Version {
+			return nil, database.NewConflictError("group", group.ID, group.Version)
+		}
+
+		group.Version++
+
+		entry.Name = group.Name
+		entry.Active = group.Active
+		entry.Visibility = group.Visibility
+		entry.Version = group.Version
+
+		for _, groupRole := range group.GroupRoles {
+			_, err := c.saveGroupRole(groupRole)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		group.ID = c.nextID("groups")
+		group.Version = 1
+
+		c.groups = append(c.groups, copyGroup(group))
+
+		for _, groupRole := range group.GroupRoles {
+			groupRole.GroupID = group.ID
+
+			_, err := c.saveGroupRole(groupRole)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return group, nil
+}
+
+// SaveUser saves a user to the in-memory database, returning the updated model or an error if the query failed. All changes are reverted if any of them fails,
+// including the IDs and versions assigned to the given model
+func (c *DatabaseConnection) SaveUser(ctx context.Context, user *models.User) (*models.User, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	snapshot := c.tables.clone()
+	modelSnapshot := database.SnapshotUser(user)
+
+	result, err := c.saveUser(user)
+	if err != nil {
+		c.tables = snapshot
+		modelSnapshot.Restore()
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *DatabaseConnection) saveUser(user *models.User) (*models.User, error) {
+	for _, entry := range c.users {
+		if entry.ID != user.ID && strings.EqualFold(entry.Username, user.Username) {
+			return nil, duplicateEntryError(user.Username, "username")
+		}
+	}
+
+	if user.ID > 0 {
+		entry := c.findUser(user.ID)
+		if entry == nil || entry.Version != user.Version {
+			return nil, database.NewConflictError("user", user.ID, user.Version)
+		}
+
+		user.Version++
+
+		entry.Username = user.Username
+		entry.Password = user.Password
+		entry.Email = user.Email
+		entry.VerifiedEmail = user.VerifiedEmail
+		entry.Active = user.Active
+		entry.Version = user.Version
+
+		for _, account := range user.Accounts {
+			_, err := c.saveAccount(account)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, userRole := range user.UserRoles {
+			_, err := c.saveUserRole(userRole)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		c.saveAllGroupsForUser(user.ID, user.Groups, user.GroupValidities, user.AutoAddedGroups)
+	} else {
+		user.ID = c.nextID("users")
+		user.Version = 1
+
+		c.users = append(c.users, copyUser(user))
+
+		for _, account := range user.Accounts {
+			account.UserID = user.ID
+
+			_, err := c.saveAccount(account)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, userRole := range user.UserRoles {
+			userRole.UserID = user.ID
+
+			_, err := c.saveUserRole(userRole)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		c.saveAllGroupsForUser(user.ID, user.Groups, user.GroupValidities, user.AutoAddedGroups)
+	}
+
+	return user, nil
+}
+
+// SaveApplication saves an application to the in-memory database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveApplication(ctx context.Context, application *models.Application) (*models.Application, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, entry := range c.applications {
+		if entry.ID == application.ID {
+			continue
+		}
+
+		if strings.EqualFold(entry.Name, application.Name) {
+			return nil, duplicateEntryError(application.Name, "name")
+		} else if entry.Secret == application.Secret {
+			return nil, duplicateEntryError(application.Secret, "secret")
+		}
+	}
+
+	if application.ID > 0 {
+		entry := c.findApplication(application.ID)
+		if entry == nil || entry.Version != application.Version {
+			return nil, database.NewConflictError("application", application.ID, application.Version)
+		}
+
+		application.Version++
+
+		*entry = *application
+	} else {
+		application.ID = c.nextID("applications")
+		application.Version = 1
+
+		entry := *application
+		c.applications = append(c.applications, &entry)
+	}
+
+	return application, nil
+}
+
+// SaveLoginAttempt saves a login attempt to the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) SaveLoginAttempt(ctx context.Context, loginAttempt *models.LoginAttempt) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := *loginAttempt
+	entry.ID = c.nextID("loginattempts")
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	c.loginAttempts = append(c.loginAttempts, &entry)
+
+	return nil
+}
+
+// SaveLoginAttemptSummary adds the counts of the given summary to the stored summary of the same day in the in-memory database, creating it if required. An error is returned if the query failed
+func (c *DatabaseConnection) SaveLoginAttemptSummary(ctx context.Context, summary *models.LoginAttemptSummary) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.saveLoginAttemptSummary(summary)
+
+	return nil
+}
+
+// SaveCSRFFailure saves a CSRF failure to the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) SaveCSRFFailure(ctx context.Context, csrfFailure *models.CSRFFailure) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := *csrfFailure
+	entry.ID = c.nextID("csrffailures")
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	c.csrfFailures = append(c.csrfFailures, &entry)
+
+	return nil
+}
+
+// SaveManagerAction saves a manager action to the in-memory database, returning an error if the query failed
+func (c *DatabaseConnection) SaveManagerAction(ctx context.Context, managerAction *models.ManagerAction) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := *managerAction
+	entry.ID = c.nextID("manageractions")
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	c.managerActions = append(c.managerActions, &entry)
+
+	return nil
+}
+
+// SaveAllGroupsForUser saves all group memberships for the user, limiting them to the validity windows given for their group IDs and marking the ones added by membership rules
+func (c *DatabaseConnection) SaveAllGroupsForUser(ctx context.Context, userID int64, groups []*models.Group, validities map[int64]*models.Validity, autoAdded map[int64]bool) ([]*models.Group, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.saveAllGroupsForUser(userID, groups, validities, autoAdded)
+
+	return groups, nil
+}
+
+// DeleteAccount removes an account and all associated characters from the in-memory database
+func (c *DatabaseConnection) DeleteAccount(ctx context.Context, accountID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, account := range c.accounts {
+		if account.ID == accountID {
+			c.touchUser(account.UserID)
+		}
+	}
+
+	c.deleteCharacters(func(character *models.Character) bool { return character.AccountID == accountID })
+	c.deleteAccounts(func(account *models.Account) bool { return account.ID == accountID })
+
+	return nil
+}
+
+// DeleteCharacter removes a character from the in-memory database
+func (c *DatabaseConnection) DeleteCharacter(ctx context.Context, characterID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deleteCharacters(func(character *models.Character) bool { return character.ID == characterID })
+
+	return nil
+}
+
+// DeleteRole moves a role to the trash of the in-memory database, hiding it and all user and group roles associated until it is restored or purged
+func (c *DatabaseConnection) DeleteRole(ctx context.Context, roleID int64, deletedBy int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.findRole(roleID) != nil {
+		c.moveToTrash(models.TrashEntryTypeRole, roleID, deletedBy)
+	}
+
+	return nil
+}
+
+// DeleteGroupRole removes a group role from the in-memory database
+func (c *DatabaseConnection) DeleteGroupRole(ctx context.Context, groupRoleID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deleteGroupRoles(func(groupRole *groupRoleEntry) bool { return groupRole.ID == groupRoleID })
+
+	return nil
+}
+
+// DeleteUserRole removes a user role from the in-memory database
+func (c *DatabaseConnection) DeleteUserRole(ctx context.Context, userRoleID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deleteUserRoles(func(userRole *userRoleEntry) bool { return userRole.ID == userRoleID })
+
+	return nil
+}
+
+// DeleteMembershipRule removes a membership rule from the in-memory database. Memberships added by the rule are kept until the next reconciliation
+func (c *DatabaseConnection) DeleteMembershipRule(ctx context.Context, membershipRuleID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deleteMembershipRules(func(membershipRule *models.MembershipRule) bool { return membershipRule.ID == membershipRuleID })
+
+	return nil
+}
+
+// DeleteGroupManager removes a group manager assignment from the in-memory database. Actions recorded for the manager are kept
+func (c *DatabaseConnection) DeleteGroupManager(ctx context.Context, groupManagerID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deleteGroupManagers(func(groupManager *models.GroupManager) bool { return groupManager.ID == groupManagerID })
+
+	return nil
+}
+
+// DeleteGroup moves a group to the trash of the in-memory database, hiding it and all associated group memberships and roles until it is restored or purged
+func (c *DatabaseConnection) DeleteGroup(ctx context.Context, groupID int64, deletedBy int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.findGroup(groupID) != nil {
+		c.moveToTrash(models.TrashEntryTypeGroup, groupID, deletedBy)
+	}
+
+	return nil
+}
+
+// DeleteUser moves a user to the trash of the in-memory database, hiding it and all associated group memberships, roles and accounts until it is restored or purged
+func (c *DatabaseConnection) DeleteUser(ctx context.Context, userID int64, deletedBy int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.findUser(userID) != nil {
+		c.moveToTrash(models.TrashEntryTypeUser, userID, deletedBy)
+	}
+
+	return nil
+}
+
+// DeleteApplication moves an application to the trash of the in-memory database, hiding it until it is restored or purged
+func (c *DatabaseConnection) DeleteApplication(ctx context.Context, appID int64, deletedBy int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.findApplication(appID) != nil {
+		c.moveToTrash(models.TrashEntryTypeApplication, appID, deletedBy)
+	}
+
+	return nil
+}
+
+// LoadTrash retrieves all deleted users, groups, roles and applications from the in-memory database, most recently deleted first, returning an error if the query failed
+func (c *DatabaseConnection) LoadTrash(ctx context.Context) ([]*models.TrashEntry, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var trash []*models.TrashEntry
+
+	for _, entry := range c.trash {
+		trashEntry := *entry
+
+		switch entry.Type {
+		case models.TrashEntryTypeUser:
+			trashEntry.Name = c.findUser(entry.ID).Username
+		case models.TrashEntryTypeGroup:
+			trashEntry.Name = c.findGroup(entry.ID).Name
+		case models.TrashEntryTypeRole:
+			trashEntry.Name = c.findRole(entry.ID).Name
+		case models.TrashEntryTypeApplication:
+			trashEntry.Name = c.findApplication(entry.ID).Name
+		}
+
+		trash = append(trash, &trashEntry)
+	}
+
+	sort.Sort(trashByDeletion(trash))
+
+	return trash, nil
+}
+
+// RestoreRole restores a deleted role including all user and group roles associated in the in-memory database, returning an error if the role is not in the trash
+func (c *DatabaseConnection) RestoreRole(ctx context.Context, roleID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.restoreFromTrash(models.TrashEntryTypeRole, roleID)
+}
+
+// RestoreGroup restores a deleted group including all associated group memberships and roles in the in-memory database, returning an error if the group is not in the trash
+func (c *DatabaseConnection) RestoreGroup(ctx context.Context, groupID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.restoreFromTrash(models.TrashEntryTypeGroup, groupID)
+}
+
+// RestoreUser restores a deleted user including all associated group memberships, roles and accounts in the in-memory database, returning an error if the user is not in the trash
+func (c *DatabaseConnection) RestoreUser(ctx context.Context, userID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.restoreFromTrash(models.TrashEntryTypeUser, userID)
+}
+
+// RestoreApplication restores a deleted application in the in-memory database, returning an error if the application is not in the trash
+func (c *DatabaseConnection) RestoreApplication(ctx context.Context, appID int64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.restoreFromTrash(models.TrashEntryTypeApplication, appID)
+}
+
+// PurgeTrash permanently removes all entities deleted before the given time as well as their associations from the in-memory database, returning the number of purged entities.
+// Users still maintaining applications are kept and their applications are moved to the trash instead, so both are purged once the applications expire
+func (c *DatabaseConnection) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var purged int64
+
+	for _, entryType := range []models.TrashEntryType{models.TrashEntryTypeApplication, models.TrashEntryTypeUser, models.TrashEntryTypeGroup, models.TrashEntryTypeRole} {
+		for _, entry := range c.trash {
+			if entry.Type != entryType || !entry.DeletedAt.Before(deletedBefore) {
+				continue
+			}
+
+			if entry.Type == models.TrashEntryTypeUser && c.maintainsApplications(entry.ID) {
+				continue
+			}
+
+			if entry.Type == models.TrashEntryTypeRole && c.findRole(entry.ID) == nil {
+				continue
+			}
+
+			switch entry.Type {
+			case models.TrashEntryTypeUser:
+				c.purgeUser(entry.ID)
+			case models.TrashEntryTypeGroup:
+				c.purgeGroup(entry.ID)
+			case models.TrashEntryTypeRole:
+				c.purgeRole(entry.ID)
+			case models.TrashEntryTypeApplication:
+				c.purgeApplication(entry.ID)
+			}
+
+			purged++
+		}
+	}
+
+	var trash []*models.TrashEntry
+
+	for _, entry := range c.trash {
+		switch {
+		case entry.Type == models.TrashEntryTypeUser && c.findUser(entry.ID) == nil:
+		case entry.Type == models.TrashEntryTypeGroup && c.findGroup(entry.ID) == nil:
+		case entry.Type == models.TrashEntryTypeRole && c.findRole(entry.ID) == nil:
+		case entry.Type == models.TrashEntryTypeApplication && c.findApplication(entry.ID) == nil:
+		default:
+			trash = append(trash, entry)
+		}
+	}
+
+	c.trash = trash
+
+	for _, entry := range trash {
+		if entry.Type != models.TrashEntryTypeUser || !entry.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+
+		for _, application := range c.applications {
+			if application.MaintainerID == entry.ID {
+				c.moveToTrash(models.TrashEntryTypeApplication, application.ID, entry.DeletedBy)
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// PruneLoginAttempts adds all login attempts made before the given time to the daily summaries and removes them from the in-memory database, returning the number of removed login attempts
+func (c *DatabaseConnection) PruneLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	days := make(map[string]*models.LoginAttemptSummary)
+	kept := make([]*models.LoginAttempt, 0, len(c.loginAttempts))
+
+	for _, loginAttempt := range c.loginAttempts {
+		if !loginAttempt.Timestamp.Before(before) {
+			kept = append(kept, loginAttempt)
+			continue
+		}
+
+		day := loginAttempt.Timestamp.UTC().Format("2006-01-02")
+
+		summary, ok := days[day]
+		if !ok {
+			summary = &models.LoginAttemptSummary{Day: day}
+			days[day] = summary
+		}
+
+		if loginAttempt.Successful {
+			summary.Successful++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	for _, summary := range days {
+		c.saveLoginAttemptSummary(summary)
+	}
+
+	pruned := int64(len(c.loginAttempts) - len(kept))
+	c.loginAttempts = kept
+
+	return pruned, nil
+}
+
+// PruneCSRFFailures removes all CSRF failures recorded before the given time from the in-memory database, returning the number of removed CSRF failures
+func (c *DatabaseConnection) PruneCSRFFailures(ctx context.Context, before time.Time) (int64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	kept := make([]*models.CSRFFailure, 0, len(c.csrfFailures))
+
+	for _, csrfFailure := range c.csrfFailures {
+		if !csrfFailure.Timestamp.Before(before) {
+			kept = append(kept, csrfFailure)
+		}
+	}
+
+	pruned := int64(len(c.csrfFailures) - len(kept))
+	c.csrfFailures = kept
+
+	return pruned, nil
+}
+
+// RemoveExpiredGrants removes all user roles and group memberships expired at the given time from the in-memory database, returning the removed grants
+func (c *DatabaseConnection) RemoveExpiredGrants(ctx context.Context, expiredAt time.Time) ([]*models.ExpiredGrant, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	expiredGrants := make([]*models.ExpiredGrant, 0)
+
+	c.deleteUserRoles(func(userRole *userRoleEntry) bool {
+		if !userRole.Validity.IsExpiredAt(expiredAt) {
+			return false
+		}
+
+		expiredGrants = append(expiredGrants, &models.ExpiredGrant{
+			Type:       models.ExpiredGrantTypeUserRole,
+			UserID:     userRole.UserID,
+			TargetID:   userRole.RoleID,
+			ValidUntil: *userRole.Validity.ValidUntil,
+		})
+
+		return true
+	})
+
+	c.deleteUserGroups(func(userGroup *userGroupEntry) bool {
+		if !userGroup.Validity.IsExpiredAt(expiredAt) {
+			return false
+		}
+
+		expiredGrants = append(expiredGrants, &models.ExpiredGrant{
+			Type:       models.ExpiredGrantTypeGroupMembership,
+			UserID:     userGroup.UserID,
+			TargetID:   userGroup.GroupID,
+			ValidUntil: *userGroup.Validity.ValidUntil,
+		})
+
+		return true
+	})
+
+	sort.Stable(expiredGrantsByUser(expiredGrants))
+
+	touched := make(map[int64]bool)
+
+	for _, expiredGrant := range expiredGrants {
+		if !touched[expiredGrant.UserID] {
+			c.touchUser(expiredGrant.UserID)
+			touched[expiredGrant.UserID] = true
+		}
+	}
+
+	return expiredGrants, nil
+}
+
+// RemoveUserFromGroup removes a user from the given group, updates the in-memory database and returns the updated model
+func (c *DatabaseConnection) RemoveUserFromGroup(ctx context.Context, userID int64, groupID int64) (*models.User, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	user, err := c.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.deleteUserGroups(func(userGroup *userGroupEntry) bool {
+		return userGroup.UserID == user.ID && userGroup.GroupID == groupID
+	})
+
+	c.touchUser(user.ID)
+	user.Version++
+
+	var groups []*models.Group
+
+	for _, group := range user.Groups {
+		if group.ID != groupID {
+			groups = append(groups, group)
+		}
+	}
+
+	user.Groups = groups
+	delete(user.GroupValidities, groupID)
+	delete(user.AutoAddedGroups, groupID)
+
+	return user, nil
+}
+
+// RemoveUserRoleFromUser removes a user role from the given user, updates the in-memory database and returns the updated model
+func (c *DatabaseConnection) RemoveUserRoleFromUser(ctx context.Context, userID int64, roleID int64) (*models.User, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	user, err := c.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.deleteUserRoles(func(userRole *userRoleEntry) bool {
+		return userRole.UserID == user.ID && userRole.ID == roleID
+	})
+
+	c.touchUser(user.ID)
+	user.Version++
+
+	var userRoles []*models.UserRole
+
+	for _, userRole := range user.UserRoles {
+		if userRole.ID != roleID {
+			userRoles = append(userRoles, userRole)
+		}
+	}
+
+	user.UserRoles = userRoles
+
+	return user, nil
+}
+
+// RemoveGroupRoleFromGroup removes a group role from the given group, updates the in-memory database and returns the updated model
+func (c *DatabaseConnection) RemoveGroupRoleFromGroup(ctx context.Context, groupID int64, roleID int64) (*models.Group, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	group, err := c.loadGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.deleteGroupRoles(func(groupRole *groupRoleEntry) bool {
+		return groupRole.GroupID == group.ID && groupRole.ID == roleID
+	})
+
+	c.touchGroup(group.ID)
+	group.Version++
+
+	var groupRoles []*models.GroupRole
+
+	for _, groupRole := range group.GroupRoles {
+		if groupRole.ID != roleID {
+			groupRoles = append(groupRoles, groupRole)
+		}
+	}
+
+	group.GroupRoles = groupRoles
+
+	return group, nil
+}
+
+// RemoveAPIKeyFromUser removes an API key from the given user, updates the in-memory database and returns the updated model
+func (c *DatabaseConnection) RemoveAPIKeyFromUser(ctx context.Context, user *models.User, apiKeyID int64) (*models.User, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for index, account := range user.Accounts {
+		if account.APIKeyID == apiKeyID {
+			accountID := account.ID
+
+			for _, character := range account.Characters {
+				characterID := character.ID
+				c.deleteCharacters(func(character *models.Character) bool {
+					return character.ID == characterID && character.AccountID == accountID
+				})
+			}
+
+			c.deleteAccounts(func(account *models.Account) bool {
+				return account.ID == accountID && account.APIKeyID == apiKeyID
+			})
+
+			c.touchUser(user.ID)
+			user.Version++
+
+			user.Accounts[index], user.Accounts[len(user.Accounts)-1], user.Accounts = user.Accounts[len(user.Accounts)-1], nil, user.Accounts[:len(user.Accounts)-1]
+
+			break
+		}
+	}
+
+	return user, nil
+}
+
+// ToggleUserRoleGranted toggles the granted state of the given user role
+func (c *DatabaseConnection) ToggleUserRoleGranted(ctx context.Context, roleID int64) (*models.UserRole, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	userRole, err := c.loadUserRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole.Granted = !userRole.Granted
+
+	userRole, err = c.saveUserRole(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	c.touchUser(userRole.UserID)
+
+	return userRole, nil
+}
+
+// ToggleGroupRoleGranted toggles the granted state of the given group role
+func (c *DatabaseConnection) ToggleGroupRoleGranted(ctx context.Context, roleID int64) (*models.GroupRole, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	groupRole, err := c.loadGroupRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRole.Granted = !groupRole.Granted
+
+	groupRole, err = c.saveGroupRole(groupRole)
+	if err != nil {
+		return nil, err
+	}
+
+	c.touchGroup(groupRole.GroupID)
+
+	return groupRole, nil
+}
+
+// touchUser increments the version of the user with the given ID, marking it as modified
+func (c *DatabaseConnection) touchUser(userID int64) {
+	entry := c.findUser(userID)
+	if entry != nil {
+		entry.Version++
+	}
+}
+
+// touchGroup increments the version of the group with the given ID, marking it as modified
+func (c *DatabaseConnection) touchGroup(groupID int64) {
+	entry := c.findGroup(groupID)
+	if entry != nil {
+		entry.Version++
+	}
+}
+
+// clone returns a copy of all tables, allowing modifications without affecting the original rows
+func (t tables) clone() tables {
+	clone := tables{
+		autoIncrement: make(map[string]int64, len(t.autoIncrement)),
+	}
+
+	for table, value := range t.autoIncrement {
+		clone.autoIncrement[table] = value
+	}
+
+	for _, account := range t.accounts {
+		acc := *account
+		clone.accounts = append(clone.accounts, &acc)
+	}
+	for _, alliance := range t.alliances {
+		all := *alliance
+		clone.alliances = append(clone.alliances, &all)
+	}
+	for _, application := range t.applications {
+		app := *application
+		clone.applications = append(clone.applications, &app)
+	}
+	for _, character := range t.characters {
+		char := *character
+		clone.characters = append(clone.characters, &char)
+	}
+	for _, corporation := range t.corporations {
+		corp := *corporation
+		clone.corporations = append(clone.corporations, &corp)
+	}
+	for _, csrfFailure := range t.csrfFailures {
+		failure := *csrfFailure
+		clone.csrfFailures = append(clone.csrfFailures, &failure)
+	}
+	for _, groupApplication := range t.groupApplications {
+		application := *groupApplication
+		clone.groupApplications = append(clone.groupApplications, &application)
+	}
+	for _, groupManager := range t.groupManagers {
+		manager := *groupManager
+		clone.groupManagers = append(clone.groupManagers, &manager)
+	}
+	for _, groupRole := range t.groupRoles {
+		entry := *groupRole
+		clone.groupRoles = append(clone.groupRoles, &entry)
+	}
+	for _, group := range t.groups {
+		grp := *group
+		clone.groups = append(clone.groups, &grp)
+	}
+	for _, loginAttemptSummary := range t.loginAttemptSummaries {
+		summary := *loginAttemptSummary
+		clone.loginAttemptSummaries = append(clone.loginAttemptSummaries, &summary)
+	}
+	for _, loginAttempt := range t.loginAttempts {
+		attempt := *loginAttempt
+		clone.loginAttempts = append(clone.loginAttempts, &attempt)
+	}
+	for _, managerAction := range t.managerActions {
+		action := *managerAction
+		clone.managerActions = append(clone.managerActions, &action)
+	}
+	for _, membershipRule := range t.membershipRules {
+		rule := *membershipRule
+		clone.membershipRules = append(clone.membershipRules, &rule)
+	}
+	for _, groupNesting := range t.groupNestings {
+		nesting := *groupNesting
+		clone.groupNestings = append(clone.groupNestings, &nesting)
+	}
+	for _, roleImplication := range t.roleImplications {
+		implication := *roleImplication
+		clone.roleImplications = append(clone.roleImplications, &implication)
+	}
+	for _, role := range t.roles {
+		r := *role
+		clone.roles = append(clone.roles, &r)
+	}
+	for _, trashEntry := range t.trash {
+		entry := *trashEntry
+		clone.trash = append(clone.trash, &entry)
+	}
+	for _, userGroup := range t.userGroups {
+		entry := *userGroup
+		clone.userGroups = append(clone.userGroups, &entry)
+	}
+	for _, userRole := range t.userRoles {
+		entry := *userRole
+		clone.userRoles = append(clone.userRoles, &entry)
+	}
+	for _, user := range t.users {
+		usr := *user
+		clone.users = append(clone.users, &usr)
+	}
+
+	return clone
+}
+
+// nextID returns the next auto-increment value for the given table. The caller must hold the write lock
+func (c *DatabaseConnection) nextID(table string) int64 {
+	if c.autoIncrement == nil {
+		c.autoIncrement = make(map[string]int64)
+	}
+
+	c.autoIncrement[table]++
+
+	return c.autoIncrement[table]
+}
+
+// isDeleted checks whether the entity of the given type and ID has been moved to the trash
+func (c *DatabaseConnection) isDeleted(entryType models.TrashEntryType, id int64) bool {
+	for _, entry := range c.trash {
+		if entry.Type == entryType && entry.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// moveToTrash marks the entity of the given type and ID as deleted by the given user. The caller must hold the write lock
+func (c *DatabaseConnection) moveToTrash(entryType models.TrashEntryType, id int64, deletedBy int64) {
+	if c.isDeleted(entryType, id) {
+		return
+	}
+
+	c.trash = append(c.trash, &models.TrashEntry{
+		Type:      entryType,
+		ID:        id,
+		DeletedAt: time.Now().UTC(),
+		DeletedBy: deletedBy,
+	})
+}
+
+// restoreFromTrash removes the deletion mark of the entity with the given type and ID, returning sql.ErrNoRows if the entity was not deleted. The caller must hold the write lock
+func (c *DatabaseConnection) restoreFromTrash(entryType models.TrashEntryType, id int64) error {
+	for i, entry := range c.trash {
+		if entry.Type == entryType && entry.ID == id {
+			c.trash = append(c.trash[:i], c.trash[i+1:]...)
+			return nil
+		}
+	}
+
+	return sql.ErrNoRows
+}
+
+// purgeRole permanently removes a role and all user roles, group roles and implications associated. The caller must hold the write lock
+func (c *DatabaseConnection) purgeRole(roleID int64) {
+	c.deleteRoleImplications(func(implication *models.RoleImplication) bool {
+		return implication.RoleID == roleID || implication.ImpliedRoleID == roleID
+	})
+	c.deleteUserRoles(func(userRole *userRoleEntry) bool { return userRole.RoleID == roleID })
+	c.deleteGroupRoles(func(groupRole *groupRoleEntry) bool { return groupRole.RoleID == roleID })
+
+	var roles []*models.Role
+
+	for _, role := range c.roles {
+		if role.ID != roleID {
+			roles = append(roles, role)
+		}
+	}
+
+	c.roles = roles
+}
+
+// purgeGroup permanently removes a group and all associated group memberships, roles, membership rules, nestings, manager assignments and group applications. The caller must hold the write lock
+func (c *DatabaseConnection) purgeGroup(groupID int64) {
+	c.deleteGroupNestings(func(nesting *models.GroupNesting) bool {
+		return nesting.GroupID == groupID || nesting.SubGroupID == groupID
+	})
+	c.deleteGroupRoles(func(groupRole *groupRoleEntry) bool { return groupRole.GroupID == groupID })
+	c.deleteMembershipRules(func(membershipRule *models.MembershipRule) bool { return membershipRule.GroupID == groupID })
+	c.deleteGroupManagers(func(groupManager *models.GroupManager) bool { return groupManager.GroupID == groupID })
+	c.deleteGroupApplications(func(groupApplication *models.GroupApplication) bool { return groupApplication.GroupID == groupID })
+	c.deleteUserGroups(func(userGroup *userGroupEntry) bool { return userGroup.GroupID == groupID })
+
+	var groups []*models.Group
+
+	for _, group := range c.groups {
+		if group.ID != groupID {
+			groups = append(groups, group)
+		}
+	}
+
+	c.groups = groups
+}
+
+// purgeUser permanently removes a user and all associated group memberships, manager assignments, group applications, roles and accounts.
+// The user must not maintain any applications anymore, see PurgeTrash. The caller must hold the write lock
+func (c *DatabaseConnection) purgeUser(userID int64) {
+	c.deleteUserGroups(func(userGroup *userGroupEntry) bool { return userGroup.UserID == userID })
+	c.deleteGroupManagers(func(groupManager *models.GroupManager) bool { return groupManager.UserID == userID })
+	c.deleteGroupApplications(func(groupApplication *models.GroupApplication) bool { return groupApplication.UserID == userID })
+	c.deleteUserRoles(func(userRole *userRoleEntry) bool { return userRole.UserID == userID })
+
+	for _, account := range c.accounts {
+		if account.UserID == userID {
+			accountID := account.ID
+			c.deleteCharacters(func(character *models.Character) bool { return character.AccountID == accountID })
+		}
+	}
+
+	c.deleteAccounts(func(account *models.Account) bool { return account.UserID == userID })
+
+	var users []*models.User
+
+	for _, user := range c.users {
+		if user.ID != userID {
+			users = append(users, user)
+		}
+	}
+
+	c.users = users
+}
+
+// purgeApplication permanently removes an application and all roles of its namespace, including deleted ones. The caller must hold the write lock
+func (c *DatabaseConnection) purgeApplication(appID int64) {
+	var roleIDs []int64
+
+	for _, role := range c.roles {
+		if role.ApplicationID.Valid && role.ApplicationID.Int64 == appID {
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+
+	for _, roleID := range roleIDs {
+		c.purgeRole(roleID)
+	}
+
+	var applications []*models.Application
+
+	for _, application := range c.applications {
+		if application.ID != appID {
+			applications = append(applications, application)
+		}
+	}
+
+	c.applications = applications
+}
+
+// maintainsApplications checks whether the user with the given ID maintains any applications, including the ones in the trash. The caller must hold the read lock
+func (c *DatabaseConnection) maintainsApplications(userID int64) bool {
+	for _, application := range c.applications {
+		if application.MaintainerID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *DatabaseConnection) findAccount(accountID int64) *models.Account {
+	for _, account := range c.accounts {
+		if account.ID == accountID {
+			return account
+		}
+	}
+
+	return nil
+}
+
+func (c *DatabaseConnection) findApplication(applicationID int64) *models.Application {
+	for _, application := range c.applications {
+		if application.ID == applicationID {
+			return application
+		}
+	}
+
+	return nil
+}
+
+func (c *DatabaseConnection) findCharacter(characterID int64) *models.Character {
+	for _, character := range c.characters {
+		if character.ID == characterID {
+			return character
+		}
+	}
+
+	return nil
+}
+
+// saveLoginAttemptSummary adds the counts of the given summary to the stored summary of the same day, creating it if required. The caller must hold the write lock
+func (c *DatabaseConnection) saveLoginAttemptSummary(summary *models.LoginAttemptSummary) {
+	for _, entry := range c.loginAttemptSummaries {
+		if entry.Day == summary.Day {
+			entry.Failed += summary.Failed
+			entry.Successful += summary.Successful
+			return
+		}
+	}
+
+	entry := *summary
+	c.loginAttemptSummaries = append(c.loginAttemptSummaries, &entry)
+}
+
+func (c *DatabaseConnection) findAlliance(allianceID int64) *models.Alliance {
+	for _, alliance := range c.alliances {
+		if alliance.ID == allianceID {
+			return alliance
+		}
+	}
+
+	return nil
+}
+
+func (c *DatabaseConnection) findCorporation(corporationID int64) *models.Corporation {
+	for _, corporation := range c.corporations {
+		if corporation.ID == corporationID {
+			return corporation
+		}
+	}
+
+	return nil
+}
+
+func (c *DatabaseConnection) findGroup(groupID int64) *models.Group {
+	for _, group := range c.groups {
+		if group.ID == groupID {
+			return group
+		}
+	}
+
+	return nil
+}
+
+func (c *DatabaseConnection) findRole(roleID int64) *models.Role {
+	for _, role := range c.roles {
+		if role.ID == roleID {
+			return role
+		}
+	}
+
+	return nil
+}
+
+func (c *DatabaseConnection) findUser(userID int64) *models.User {
+	for _, user := range c.users {
+		if user.ID == userID {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// loadGroupMemberships returns the validity windows of all time-limited group memberships as well as the group memberships added by membership rules of the given user,
+// both indexed by the group ID. No map is returned if the user has no memberships of the respective kind
+func (c *DatabaseConnection) loadGroupMemberships(userID int64) (map[int64]*models.Validity, map[int64]bool) {
+	var groupValidities map[int64]*models.Validity
+	var autoAddedGroups map[int64]bool
+
+	for _, userGroup := range c.userGroups {
+		if userGroup.UserID != userID || !userGroup.Active {
+			continue
+		}
+
+		if userGroup.Validity.IsLimited() {
+			if groupValidities == nil {
+				groupValidities = make(map[int64]*models.Validity)
+			}
+
+			groupValidities[userGroup.GroupID] = copyValidity(userGroup.Validity)
+		}
+
+		if userGroup.AutoAdded {
+			if autoAddedGroups == nil {
+				autoAddedGroups = make(map[int64]bool)
+			}
+
+			autoAddedGroups[userGroup.GroupID] = true
+		}
+	}
+
+	return groupValidities, autoAddedGroups
+}
+
+// isActiveGroupMember checks whether the given user has an active membership in the given group
+func (c *DatabaseConnection) isActiveGroupMember(userID int64, groupID int64) bool {
+	for _, userGroup := range c.userGroups {
+		if userGroup.UserID == userID && userGroup.GroupID == groupID && userGroup.Active {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *DatabaseConnection) hasCharacterInCorporation(userID int64, corporationID int64) bool {
+	for _, account := range c.accounts {
+		if account.UserID != userID {
+			continue
+		}
+
+		for _, character := range c.characters {
+			if character.AccountID == account.ID && character.CorporationID == corporationID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (c *DatabaseConnection) loadRole(roleID int64) (*models.Role, error) {
+	return c.loadRoleOnPath(roleID, make(map[int64]bool))
+}
+
+// loadRoleOnPath retrieves the role (and all roles implied by it) with the given ID, ignoring implications leading back to a role on the given path
+func (c *DatabaseConnection) loadRoleOnPath(roleID int64, path map[int64]bool) (*models.Role, error) {
+	entry := c.findRole(roleID)
+	if entry == nil || c.isDeleted(models.TrashEntryTypeRole, roleID) {
+		return nil, sql.ErrNoRows
+	}
+
+	role := *entry
+
+	err := c.loadImpliedRoles(&role, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// loadImpliedRoles retrieves all roles directly implied by the given role (and the roles implied by them), ignoring implications leading back to a role on the given path
+func (c *DatabaseConnection) loadImpliedRoles(role *models.Role, path map[int64]bool) error {
+	path[role.ID] = true
+	defer delete(path, role.ID)
+
+	role.ImpliedRoles = nil
+
+	for _, implication := range c.sortedRoleImplications() {
+		if implication.RoleID != role.ID || path[implication.ImpliedRoleID] || c.isDeleted(models.TrashEntryTypeRole, implication.ImpliedRoleID) {
+			continue
+		}
+
+		impliedRole, err := c.loadRoleOnPath(implication.ImpliedRoleID, path)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		role.ImpliedRoles = append(role.ImpliedRoles, impliedRole)
+	}
+
+	return nil
+}
+
+// sortedRoleImplications returns all role implications ordered by the ID of the implied role
+func (c *DatabaseConnection) sortedRoleImplications() []*models.RoleImplication {
+	implications := append([]*models.RoleImplication{}, c.roleImplications...)
+
+	sort.Sort(roleImplicationsByImpliedRole(implications))
+
+	return implications
+}
+
+func (c *DatabaseConnection) loadGroupRole(groupRoleID int64) (*models.GroupRole, error) {
+	for _, entry := range c.groupRoles {
+		if entry.ID == groupRoleID {
+			return c.groupRoleFromEntry(entry)
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (c *DatabaseConnection) loadUserRole(userRoleID int64) (*models.UserRole, error) {
+	for _, entry := range c.userRoles {
+		if entry.ID == userRoleID {
+			return c.userRoleFromEntry(entry)
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (c *DatabaseConnection) loadGroup(groupID int64) (*models.Group, error) {
+	return c.loadGroupOnPath(groupID, make(map[int64]bool))
+}
+
+// loadGroupOnPath retrieves the group (and its associated group roles and sub groups) with the given ID, ignoring nestings leading back to a group on the given path
+func (c *DatabaseConnection) loadGroupOnPath(groupID int64, path map[int64]bool) (*models.Group, error) {
+	entry := c.findGroup(groupID)
+	if entry == nil || c.isDeleted(models.TrashEntryTypeGroup, groupID) {
+		return nil, sql.ErrNoRows
+	}
+
+	group := copyGroup(entry)
+
+	groupRoles, err := c.loadAllGroupRolesForGroup(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.GroupRoles = groupRoles
+
+	err = c.loadSubGroups(group, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// loadSubGroups retrieves all groups directly nested in the given group (and the groups nested in them), ignoring nestings leading back to a group on the given path
+func (c *DatabaseConnection) loadSubGroups(group *models.Group, path map[int64]bool) error {
+	path[group.ID] = true
+	defer delete(path, group.ID)
+
+	group.SubGroups = nil
+
+	for _, nesting := range c.sortedGroupNestings() {
+		if nesting.GroupID != group.ID || path[nesting.SubGroupID] || c.isDeleted(models.TrashEntryTypeGroup, nesting.SubGroupID) {
+			continue
+		}
+
+		subGroup, err := c.loadGroupOnPath(nesting.SubGroupID, path)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		group.SubGroups = append(group.SubGroups, subGroup)
+	}
+
+	return nil
+}
+
+// sortedGroupNestings returns all group nestings ordered by the ID of the sub group
+func (c *DatabaseConnection) sortedGroupNestings() []*models.GroupNesting {
+	nestings := append([]*models.GroupNesting{}, c.groupNestings...)
+
+	sort.Sort(groupNestingsBySubGroup(nestings))
+
+	return nestings
+}
+
+func (c *DatabaseConnection) loadUser(userID int64) (*models.User, error) {
+	entry := c.findUser(userID)
+	if entry == nil || c.isDeleted(models.TrashEntryTypeUser, userID) {
+		return nil, sql.ErrNoRows
+	}
+
+	return c.populateUser(entry)
+}
+
+// populateUser copies the stored user and attaches its accounts, user roles, groups, the validities and origins of its group memberships as well as its default corporation and alliance
+func (c *DatabaseConnection) populateUser(entry *models.User) (*models.User, error) {
+	user := copyUser(entry)
+
+	userRoles, err := c.loadAllUserRolesForUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.loadAllGroupsForUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Accounts = c.loadAllAccountsForUser(user.ID)
+	user.UserRoles = userRoles
+	user.Groups = groups
+	user.GroupValidities, user.AutoAddedGroups = c.loadGroupMemberships(user.ID)
+	user.DefaultCorporation, user.DefaultAlliance = c.loadDefaultAffiliation(user)
+
+	return user, nil
+}
+
+// loadDefaultAffiliation returns copies of the corporation of the user's default character and the corporation's alliance, nil if they could not be found.
+// The caller must hold the read lock
+func (c *DatabaseConnection) loadDefaultAffiliation(user *models.User) (*models.Corporation, *models.Alliance) {
+	character := user.GetDefaultCharacter()
+	if character == nil {
+		return nil, nil
+	}
+
+	entry := c.findCorporation(character.CorporationID)
+	if entry == nil {
+		return nil, nil
+	}
+
+	corporation := *entry
+
+	if !corporation.AllianceID.Valid {
+		return &corporation, nil
+	}
+
+	allianceEntry := c.findAlliance(corporation.AllianceID.Int64)
+	if allianceEntry == nil {
+		return &corporation, nil
+	}
+
+	alliance := *allianceEntry
+
+	return &corporation, &alliance
+}
+
+func (c *DatabaseConnection) loadAllAccountsForUser(userID int64) []*models.Account {
+	var accounts []*models.Account
+
+	for _, entry := range c.accounts {
+		if entry.UserID == userID {
+			account := copyAccount(entry)
+			account.Characters = c.loadAllCharactersForAccount(account.ID)
+
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts
+}
+
+func (c *DatabaseConnection) loadAllCharactersForAccount(accountID int64) []*models.Character {
+	var characters []*models.Character
+
+	for _, entry := range c.characters {
+		if entry.AccountID == accountID {
+			character := *entry
+			characters = append(characters, &character)
+		}
+	}
+
+	return characters
+}
+
+func (c *DatabaseConnection) loadAllGroupRolesForGroup(groupID int64) ([]*models.GroupRole, error) {
+	var groupRoles []*models.GroupRole
+
+	for _, entry := range c.groupRoles {
+		if entry.GroupID == groupID && !c.isDeleted(models.TrashEntryTypeRole, entry.RoleID) {
+			groupRole, err := c.groupRoleFromEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			groupRoles = append(groupRoles, groupRole)
+		}
+	}
+
+	return groupRoles, nil
+}
+
+func (c *DatabaseConnection) loadAllUserRolesForUser(userID int64) ([]*models.UserRole, error) {
+	userRoles := make([]*models.UserRole, 0)
+
+	for _, entry := range c.userRoles {
+		if entry.UserID == userID && !c.isDeleted(models.TrashEntryTypeRole, entry.RoleID) {
+			userRole, err := c.userRoleFromEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			userRoles = append(userRoles, userRole)
+		}
+	}
+
+	return userRoles, nil
+}
+
+func (c *DatabaseConnection) loadAllGroupsForUser(userID int64) ([]*models.Group, error) {
+	groups := make([]*models.Group, 0)
+
+	for _, entry := range c.groups {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.ID) || !c.isActiveGroupMember(userID, entry.ID) {
+			continue
+		}
+
+		group := copyGroup(entry)
+
+		groupRoles, err := c.loadAllGroupRolesForGroup(group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (c *DatabaseConnection) groupRoleFromEntry(entry *groupRoleEntry) (*models.GroupRole, error) {
+	role, err := c.loadRole(entry.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRole := &models.GroupRole{
+		ID:        entry.ID,
+		GroupID:   entry.GroupID,
+		Role:      role,
+		AutoAdded: entry.AutoAdded,
+		Granted:   entry.Granted,
+	}
+
+	return groupRole, nil
+}
+
+func (c *DatabaseConnection) userRoleFromEntry(entry *userRoleEntry) (*models.UserRole, error) {
+	role, err := c.loadRole(entry.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole := &models.UserRole{
+		ID:        entry.ID,
+		UserID:    entry.UserID,
+		Role:      role,
+		AutoAdded: entry.AutoAdded,
+		Granted:   entry.Granted,
+		Validity:  copyValidity(entry.Validity),
+	}
+
+	return userRole, nil
+}
+
+func (c *DatabaseConnection) saveAccount(account *models.Account) (*models.Account, error) {
+	for _, entry := range c.accounts {
+		if entry.ID != account.ID && entry.APIKeyID == account.APIKeyID {
+			return nil, duplicateEntryError(account.APIKeyID, "keyid")
+		}
+	}
+
+	if account.ID > 0 {
+		for _, character := range account.Characters {
+			_, err := c.saveCharacter(character)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		entry := c.findAccount(account.ID)
+		if entry != nil {
+			*entry = *copyAccount(account)
+		}
+	} else {
+		account.ID = c.nextID("accounts")
+
+		c.accounts = append(c.accounts, copyAccount(account))
+
+		for _, character := range account.Characters {
+			character.AccountID = account.ID
+
+			_, err := c.saveCharacter(character)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return account, nil
+}
+
+func (c *DatabaseConnection) saveCharacter(character *models.Character) (*models.Character, error) {
+	for _, entry := range c.characters {
+		if entry.ID == character.ID {
+			continue
+		}
+
+		if strings.EqualFold(entry.Name, character.Name) {
+			return nil, duplicateEntryError(character.Name, "name")
+		} else if entry.EVECharacterID == character.EVECharacterID {
+			return nil, duplicateEntryError(character.EVECharacterID, "evecharacterid")
+		}
+	}
+
+	if character.ID > 0 {
+		entry := c.findCharacter(character.ID)
+		if entry != nil {
+			*entry = *character
+		}
+	} else {
+		character.ID = c.nextID("characters")
+
+		entry := *character
+		c.characters = append(c.characters, &entry)
+	}
+
+	return character, nil
+}
+
+func (c *DatabaseConnection) saveRole(role *models.Role) (*models.Role, error) {
+	for _, entry := range c.roles {
+		if entry.ID != role.ID && entry.ApplicationID.Int64 == role.ApplicationID.Int64 && strings.EqualFold(entry.Name, role.Name) {
+			return nil, duplicateEntryError(fmt.Sprintf("%d-%s", role.ApplicationID.Int64, role.Name), "applicationid_name")
+		}
+	}
+
+	if role.ID > 0 {
+		entry := c.findRole(role.ID)
+		if entry == nil || entry.Version != role.Version {
+			return nil, database.NewConflictError("role", role.ID, role.Version)
+		}
+
+		role.Version++
+
+		*entry = *role
+		entry.ImpliedRoles = nil
+	} else {
+		role.ID = c.nextID("roles")
+		role.Version = 1
+
+		entry := *role
+		entry.ImpliedRoles = nil
+		c.roles = append(c.roles, &entry)
+	}
+
+	return role, nil
+}
+
+func (c *DatabaseConnection) saveGroupRole(groupRole *models.GroupRole) (*models.GroupRole, error) {
+	role, err := c.saveRole(groupRole.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRole.Role = role
+
+	for _, entry := range c.groupRoles {
+		if entry.ID != groupRole.ID && entry.GroupID == groupRole.GroupID && entry.RoleID == groupRole.Role.ID {
+			return nil, duplicateEntryError(fmt.Sprintf("%d-%d", groupRole.GroupID, groupRole.Role.ID), "groupid_roleid")
+		}
+	}
+
+	if groupRole.ID > 0 {
+		for _, entry := range c.groupRoles {
+			if entry.ID == groupRole.ID {
+				entry.GroupID = groupRole.GroupID
+				entry.RoleID = groupRole.Role.ID
+				entry.AutoAdded = groupRole.AutoAdded
+				entry.Granted = groupRole.Granted
+				break
+			}
+		}
+	} else {
+		groupRole.ID = c.nextID("grouproles")
+
+		c.groupRoles = append(c.groupRoles, &groupRoleEntry{
+			ID:        groupRole.ID,
+			GroupID:   groupRole.GroupID,
+			RoleID:    groupRole.Role.ID,
+			AutoAdded: groupRole.AutoAdded,
+			Granted:   groupRole.Granted,
+		})
+	}
+
+	return groupRole, nil
+}
+
+func (c *DatabaseConnection) saveUserRole(userRole *models.UserRole) (*models.UserRole, error) {
+	role, err := c.saveRole(userRole.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole.Role = role
+
+	for _, entry := range c.userRoles {
+		if entry.ID != userRole.ID && entry.UserID == userRole.UserID && entry.RoleID == userRole.Role.ID {
+			return nil, duplicateEntryError(fmt.Sprintf("%d-%d", userRole.UserID, userRole.Role.ID), "userid_roleid")
+		}
+	}
+
+	if userRole.ID > 0 {
+		for _, entry := range c.userRoles {
+			if entry.ID == userRole.ID {
+				entry.UserID = userRole.UserID
+				entry.RoleID = userRole.Role.ID
+				entry.AutoAdded = userRole.AutoAdded
+				entry.Granted = userRole.Granted
+				entry.Validity = copyValidity(userRole.Validity)
+				break
+			}
+		}
+	} else {
+		userRole.ID = c.nextID("userroles")
+
+		c.userRoles = append(c.userRoles, &userRoleEntry{
+			ID:        userRole.ID,
+			UserID:    userRole.UserID,
+			RoleID:    userRole.Role.ID,
+			AutoAdded: userRole.AutoAdded,
+			Granted:   userRole.Granted,
+			Validity:  copyValidity(userRole.Validity),
+		})
+	}
+
+	return userRole, nil
+}
+
+func (c *DatabaseConnection) saveAllGroupsForUser(userID int64, groups []*models.Group, validities map[int64]*models.Validity, autoAdded map[int64]bool) {
+	for _, group := range groups {
+		found := false
+
+		for _, entry := range c.userGroups {
+			if entry.UserID == userID && entry.GroupID == group.ID {
+				entry.Active = true
+				entry.AutoAdded = autoAdded[group.ID]
+				entry.Validity = copyValidity(validities[group.ID])
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			c.userGroups = append(c.userGroups, &userGroupEntry{
+				ID:        c.nextID("usergroups"),
+				UserID:    userID,
+				GroupID:   group.ID,
+				Active:    true,
+				AutoAdded: autoAdded[group.ID],
+				Validity:  copyValidity(validities[group.ID]),
+			})
+		}
+	}
+}
+
+func (c *DatabaseConnection) deleteAccounts(matches func(*models.Account) bool) {
+	var accounts []*models.Account
+
+	for _, account := range c.accounts {
+		if !matches(account) {
+			accounts = append(accounts, account)
+		}
+	}
+
+	c.accounts = accounts
+}
+
+func (c *DatabaseConnection) deleteCharacters(matches func(*models.Character) bool) {
+	var characters []*models.Character
+
+	for _, character := range c.characters {
+		if !matches(character) {
+			characters = append(characters, character)
+		}
+	}
+
+	c.characters = characters
+}
+
+func (c *DatabaseConnection) deleteGroupRoles(matches func(*groupRoleEntry) bool) {
+	var groupRoles []*groupRoleEntry
+
+	for _, groupRole := range c.groupRoles {
+		if !matches(groupRole) {
+			groupRoles = append(groupRoles, groupRole)
+		}
+	}
+
+	c.groupRoles = groupRoles
+}
+
+func (c *DatabaseConnection) deleteUserRoles(matches func(*userRoleEntry) bool) {
+	var userRoles []*userRoleEntry
+
+	for _, userRole := range c.userRoles {
+		if !matches(userRole) {
+			userRoles = append(userRoles, userRole)
+		}
+	}
+
+	c.userRoles = userRoles
+}
+
+func (c *DatabaseConnection) deleteRoleImplications(matches func(*models.RoleImplication) bool) {
+	var roleImplications []*models.RoleImplication
+
+	for _, roleImplication := range c.roleImplications {
+		if !matches(roleImplication) {
+			roleImplications = append(roleImplications, roleImplication)
+		}
+	}
+
+	c.roleImplications = roleImplications
+}
+
+func (c *DatabaseConnection) deleteGroupNestings(matches func(*models.GroupNesting) bool) {
+	var groupNestings []*models.GroupNesting
+
+	for _, groupNesting := range c.groupNestings {
+		if !matches(groupNesting) {
+			groupNestings = append(groupNestings, groupNesting)
+		}
+	}
+
+	c.groupNestings = groupNestings
+}
+
+func (c *DatabaseConnection) deleteMembershipRules(matches func(*models.MembershipRule) bool) {
+	var membershipRules []*models.MembershipRule
+
+	for _, membershipRule := range c.membershipRules {
+		if !matches(membershipRule) {
+			membershipRules = append(membershipRules, membershipRule)
+		}
+	}
+
+	c.membershipRules = membershipRules
+}
+
+func (c *DatabaseConnection) deleteGroupManagers(matches func(*models.GroupManager) bool) {
+	var groupManagers []*models.GroupManager
+
+	for _, groupManager := range c.groupManagers {
+		if !matches(groupManager) {
+			groupManagers = append(groupManagers, groupManager)
+		}
+	}
+
+	c.groupManagers = groupManagers
+}
+
+func (c *DatabaseConnection) deleteGroupApplications(matches func(*models.GroupApplication) bool) {
+	var groupApplications []*models.GroupApplication
+
+	for _, groupApplication := range c.groupApplications {
+		if !matches(groupApplication) {
+			groupApplications = append(groupApplications, groupApplication)
+		}
+	}
+
+	c.groupApplications = groupApplications
+}
+
+// loadGroupApplications returns copies of all group applications of groups and users not in the trash matching the given filter. The caller must hold the read lock
+func (c *DatabaseConnection) loadGroupApplications(matches func(*models.GroupApplication) bool) []*models.GroupApplication {
+	var groupApplications []*models.GroupApplication
+
+	for _, entry := range c.groupApplications {
+		if c.isDeleted(models.TrashEntryTypeGroup, entry.GroupID) || c.isDeleted(models.TrashEntryTypeUser, entry.UserID) || !matches(entry) {
+			continue
+		}
+
+		groupApplication := *entry
+		groupApplications = append(groupApplications, &groupApplication)
+	}
+
+	return groupApplications
+}
+
+func (c *DatabaseConnection) deleteUserGroups(matches func(*userGroupEntry) bool) {
+	var userGroups []*userGroupEntry
+
+	for _, userGroup := range c.userGroups {
+		if !matches(userGroup) {
+			userGroups = append(userGroups, userGroup)
+		}
+	}
+
+	c.userGroups = userGroups
+}
+
+// copyAccount returns a copy of the given account without its characters
+func copyAccount(account *models.Account) *models.Account {
+	acc := *account
+	acc.Characters = nil
+
+	return &acc
+}
+
+// copyGroup returns a copy of the given group without its group roles and sub groups
+func copyGroup(group *models.Group) *models.Group {
+	grp := *group
+	grp.GroupRoles = nil
+	grp.SubGroups = nil
+
+	return &grp
+}
+
+// copyUser returns a copy of the given user without its accounts, user roles, groups and the validities and origins of its group memberships
+func copyUser(user *models.User) *models.User {
+	usr := *user
+	usr.Accounts = nil
+	usr.UserRoles = nil
+	usr.Groups = nil
+	usr.GroupValidities = nil
+	usr.AutoAddedGroups = nil
+
+	return &usr
+}
+
+// copyValidity returns a copy of the given validity window, nil if no window or an unlimited one was given
+func copyValidity(validity *models.Validity) *models.Validity {
+	if !validity.IsLimited() {
+		return nil
+	}
+
+	return database.ValidityFromTimes(copyTime(validity.ValidFrom), copyTime(validity.ValidUntil))
+}
+
+// copyTime returns a copy of the given time, nil if no time was given
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	tm := *t
+
+	return &tm
+}
+
+// duplicateEntryError returns an error resembling the one raised by a violated unique key
+func duplicateEntryError(value interface{}, key string) error {
+	return fmt.Errorf("Duplicate entry '%v' for key '%s'", value, key)
+}
+
+// groupsByName allows sorting of groups by their name
+type groupsByName []*models.Group
+
+func (g groupsByName) Len() int           { return len(g) }
+func (g groupsByName) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g groupsByName) Less(i, j int) bool { return g[i].Name < g[j].Name }
+
+// rolesByName allows sorting of roles by their name
+type rolesByName []*models.Role
+
+func (r rolesByName) Len() int           { return len(r) }
+func (r rolesByName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r rolesByName) Less(i, j int) bool { return r[i].Name < r[j].Name }
+
+// reportRowsByColumn allows sorting of report rows by the string value of a single column
+type reportRowsByColumn struct {
+	rows   [][]interface{}
+	column int
+}
+
+func (r reportRowsByColumn) Len() int      { return len(r.rows) }
+func (r reportRowsByColumn) Swap(i, j int) { r.rows[i], r.rows[j] = r.rows[j], r.rows[i] }
+func (r reportRowsByColumn) Less(i, j int) bool {
+	return r.rows[i][r.column].(string) < r.rows[j][r.column].(string)
+}
+
+// roleImplicationsByImpliedRole allows sorting of role implications by the ID of the implied role
+type roleImplicationsByImpliedRole []*models.RoleImplication
+
+func (r roleImplicationsByImpliedRole) Len() int      { return len(r) }
+func (r roleImplicationsByImpliedRole) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r roleImplicationsByImpliedRole) Less(i, j int) bool {
+	return r[i].ImpliedRoleID < r[j].ImpliedRoleID
+}
+
+// groupNestingsBySubGroup allows sorting of group nestings by the ID of the sub group
+type groupNestingsBySubGroup []*models.GroupNesting
+
+func (g groupNestingsBySubGroup) Len() int      { return len(g) }
+func (g groupNestingsBySubGroup) Swap(i, j int) { g[i], g[j] = g[j], g[i] }
+func (g groupNestingsBySubGroup) Less(i, j int) bool {
+	return g[i].SubGroupID < g[j].SubGroupID
+}
+
+// summariesByDay allows sorting of login attempt summaries by their day
+type summariesByDay []*models.LoginAttemptSummary
+
+func (s summariesByDay) Len() int           { return len(s) }
+func (s summariesByDay) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s summariesByDay) Less(i, j int) bool { return s[i].Day < s[j].Day }
+
+// trashByDeletion allows sorting of trash entries by their deletion time, most recently deleted first
+type trashByDeletion []*models.TrashEntry
+
+func (t trashByDeletion) Len() int      { return len(t) }
+func (t trashByDeletion) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t trashByDeletion) Less(i, j int) bool {
+	if t[i].DeletedAt.Equal(t[j].DeletedAt) {
+		return t[i].ID > t[j].ID
+	}
+
+	return t[i].DeletedAt.After(t[j].DeletedAt)
+}
+
+// listEntry represents an entry of a list query, storing the value used for sorting alongside the entry itself
+type listEntry struct {
+	id      int64
+	text    string
+	numeric int64
+	value   interface{}
+}
+
+// textListEntry returns a list entry sorted case-insensitively by the given text
+func textListEntry(id int64, text string, value interface{}) *listEntry {
+	return &listEntry{id: id, text: strings.ToLower(text), value: value}
+}
+
+// numericListEntry returns a list entry sorted by the given number
+func numericListEntry(id int64, numeric int64, value interface{}) *listEntry {
+	return &listEntry{id: id, numeric: numeric, value: value}
+}
+
+// boolListEntry returns a list entry sorted by the given flag, placing false before true
+func boolListEntry(id int64, flag bool, value interface{}) *listEntry {
+	if flag {
+		return numericListEntry(id, 1, value)
+	}
+
+	return numericListEntry(id, 0, value)
+}
+
+// listEntries allows sorting of list entries in the given direction, ordering equal entries by their ID
+type listEntries struct {
+	entries   []*listEntry
+	direction database.SortDirection
+}
+
+func (l listEntries) Len() int      { return len(l.entries) }
+func (l listEntries) Swap(i, j int) { l.entries[i], l.entries[j] = l.entries[j], l.entries[i] }
+func (l listEntries) Less(i, j int) bool {
+	a, b := l.entries[i], l.entries[j]
+
+	if a.text == b.text && a.numeric == b.numeric {
+		return a.id < b.id
+	}
+
+	less := a.numeric < b.numeric || (a.numeric == b.numeric && a.text < b.text)
+	if l.direction == database.SortDescending {
+		return !less
+	}
+
+	return less
+}
+
+// pageListEntries sorts the given entries according to the criteria, returning the requested page as well as the total number of entries
+func pageListEntries(entries []*listEntry, criteria *database.ListCriteria) ([]*listEntry, int64) {
+	sort.Sort(listEntries{entries: entries, direction: criteria.SortDirection})
+
+	total := int64(len(entries))
+
+	start := criteria.PageOffset()
+	if start > total {
+		start = total
+	}
+
+	end := start + criteria.PageLimit()
+	if end > total {
+		end = total
+	}
+
+	return entries[start:end], total
+}
+
+// matchesListCriteria checks whether an entry with the given active state and texts matches the text and active filters of the criteria
+func matchesListCriteria(criteria *database.ListCriteria, active bool, texts ...string) bool {
+	if criteria.Active == database.ActiveFilterActive && !active {
+		return false
+	} else if criteria.Active == database.ActiveFilterInactive && active {
+		return false
+	}
+
+	if len(criteria.Filter) == 0 {
+		return true
+	}
+
+	filter := strings.ToLower(criteria.Filter)
+
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), filter) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expiredGrantsByUser allows sorting of expired grants by the ID of their user and their expiry time
+type expiredGrantsByUser []*models.ExpiredGrant
+
+func (e expiredGrantsByUser) Len() int      { return len(e) }
+func (e expiredGrantsByUser) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e expiredGrantsByUser) Less(i, j int) bool {
+	if e[i].UserID != e[j].UserID {
+		return e[i].UserID < e[j].UserID
+	}
+
+	return e[i].ValidUntil.Before(e[j].ValidUntil)
+}
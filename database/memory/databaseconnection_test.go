@@ -0,0 +1,988 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/conformancetest"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+// createMemoryConnection returns a fresh in-memory database populated with the same test data set used by the MySQL tests
+func createMemoryConnection() *DatabaseConnection {
+	db := &DatabaseConnection{
+		Config: &misc.Configuration{
+			DatabaseType: 0,
+			DebugLevel:   1,
+			HTTPHost:     "localhost:5000",
+		},
+	}
+
+	db.Connect()
+
+	db.accounts = []*models.Account{
+		&models.Account{ID: 1, UserID: 1, APIKeyID: 1, APIvCode: "a", APIAccessMask: 0, Active: true},
+		&models.Account{ID: 2, UserID: 2, APIKeyID: 2, APIvCode: "b", APIAccessMask: 0, Active: false},
+		&models.Account{ID: 3, UserID: 3, APIKeyID: 3, APIvCode: "c", APIAccessMask: 0, Active: true},
+		&models.Account{ID: 4, UserID: 3, APIKeyID: 4, APIvCode: "d", APIAccessMask: 268435455, Active: true},
+		&models.Account{ID: 5, UserID: 4, APIKeyID: 5, APIvCode: "e", APIAccessMask: 268435455, Active: false},
+		&models.Account{ID: 6, UserID: 4, APIKeyID: 6, APIvCode: "f", APIAccessMask: 268435455, Active: false},
+	}
+	db.applications = []*models.Application{
+		&models.Application{ID: 1, Name: "Testapp", MaintainerID: 1, Secret: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Callback: "http://localhost/callback", Active: true},
+		&models.Application{ID: 2, Name: "Apptest", MaintainerID: 2, Secret: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", Callback: "http://example.com/callback", Active: false},
+	}
+	db.characters = []*models.Character{
+		&models.Character{ID: 1, AccountID: 1, CorporationID: 1, Name: "Test Character", EVECharacterID: 1, DefaultCharacter: true, Active: true},
+		&models.Character{ID: 2, AccountID: 2, CorporationID: 2, Name: "Please Ignore", EVECharacterID: 2, DefaultCharacter: true, Active: true},
+		&models.Character{ID: 3, AccountID: 3, CorporationID: 1, Name: "Herp", EVECharacterID: 3, DefaultCharacter: true, Active: true},
+		&models.Character{ID: 4, AccountID: 3, CorporationID: 1, Name: "Derp", EVECharacterID: 4, DefaultCharacter: false, Active: true},
+		&models.Character{ID: 5, AccountID: 4, CorporationID: 2, Name: "Spai", EVECharacterID: 5, DefaultCharacter: false, Active: false},
+		&models.Character{ID: 6, AccountID: 4, CorporationID: 2, Name: "NoSpai", EVECharacterID: 6, DefaultCharacter: true, Active: false},
+	}
+	db.corporations = []*models.Corporation{
+		&models.Corporation{ID: 1, Name: "Test Corp Please Ignore", Ticker: "TEST", EVECorporationID: 1, CEOID: 1, APIKeyID: zero.IntFrom(1), APIvCode: zero.StringFrom("a"), Active: true},
+		&models.Corporation{ID: 2, Name: "Corp Test Ignore Please", Ticker: "CORP", EVECorporationID: 2, CEOID: 2, Active: false},
+	}
+	db.groupRoles = []*groupRoleEntry{
+		&groupRoleEntry{ID: 1, GroupID: 1, RoleID: 1, AutoAdded: true, Granted: true},
+		&groupRoleEntry{ID: 2, GroupID: 1, RoleID: 3, AutoAdded: false, Granted: true},
+		&groupRoleEntry{ID: 3, GroupID: 2, RoleID: 2, AutoAdded: false, Granted: false},
+		&groupRoleEntry{ID: 4, GroupID: 2, RoleID: 4, AutoAdded: true, Granted: false},
+	}
+	db.groups = []*models.Group{
+		&models.Group{ID: 1, Name: "Test Group", Active: true},
+		&models.Group{ID: 2, Name: "Dank Access", Active: false},
+	}
+	db.roles = []*models.Role{
+		&models.Role{ID: 1, Name: "ping.all", Active: true, Locked: false},
+		&models.Role{ID: 2, Name: "destroy.world", Active: false, Locked: true},
+		&models.Role{ID: 3, Name: "logistics.read", Active: true, Locked: false},
+		&models.Role{ID: 4, Name: "logistics.write", Active: true, Locked: false},
+	}
+	db.userGroups = []*userGroupEntry{
+		&userGroupEntry{ID: 1, UserID: 1, GroupID: 1, Active: true},
+		&userGroupEntry{ID: 2, UserID: 2, GroupID: 1, Active: false},
+		&userGroupEntry{ID: 3, UserID: 3, GroupID: 1, Active: true},
+		&userGroupEntry{ID: 4, UserID: 3, GroupID: 2, Active: true},
+		&userGroupEntry{ID: 5, UserID: 4, GroupID: 1, Active: false},
+		&userGroupEntry{ID: 6, UserID: 4, GroupID: 2, Active: false},
+	}
+	db.userRoles = []*userRoleEntry{
+		&userRoleEntry{ID: 1, UserID: 1, RoleID: 1, AutoAdded: false, Granted: false},
+		&userRoleEntry{ID: 2, UserID: 3, RoleID: 2, AutoAdded: true, Granted: true},
+	}
+	db.users = []*models.User{
+		&models.User{ID: 1, Username: "test1", Password: "$2a$10$veif8VUZt7lShFhJKD0wGeY1YjCwIuWjYL0vQzlTqu8wNaYQMqzbe", Email: "test1@example.com", VerifiedEmail: true, Active: true},
+		&models.User{ID: 2, Username: "test2", Password: "$2a$10$95z.WXfIreLKJ9px.3KgpOq4aXTG3DF7/5ehGYzUWALhpN6MMq/aK", Email: "test2@example.com", VerifiedEmail: false, Active: false},
+		&models.User{ID: 3, Username: "test3", Password: "$2a$10$7Yxm2scdTVpEJpvZAT7tbOFA.G9JfyxtiHbr989iocX6U37C3/j4q", Email: "test3@example.com", VerifiedEmail: false, Active: true},
+		&models.User{ID: 4, Username: "test4", Password: "$2a$10$WOWTgqaqLKbkb1uhYbtLnOuuYX4kXBC61GVAke7RkjiODoBpgGGzy", Email: "test4@example.com", VerifiedEmail: true, Active: false},
+	}
+
+	db.autoIncrement = map[string]int64{
+		"accounts":     6,
+		"applications": 2,
+		"characters":   6,
+		"corporations": 2,
+		"grouproles":   4,
+		"groups":       2,
+		"roles":        4,
+		"usergroups":   6,
+		"userroles":    2,
+		"users":        4,
+	}
+
+	return db
+}
+
+func TestDatabaseConnectionConnect(t *testing.T) {
+	Convey("Connecting to an in-memory database", t, func() {
+		db := &DatabaseConnection{}
+
+		err := db.Connect()
+
+		Convey("The returned error should be nil", func() {
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func TestDatabaseConnectionRunReport(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Running reports at an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("The corporation members report should count users and characters per corporation", func() {
+			result, err := db.RunReport(ctx, database.ReportCorporationMembers, nil)
+			So(err, ShouldBeNil)
+			So(result.Rows, ShouldResemble, [][]interface{}{
+				[]interface{}{"Corp Test Ignore Please", "CORP", int64(2), int64(3)},
+				[]interface{}{"Test Corp Please Ignore", "TEST", int64(2), int64(3)},
+			})
+		})
+
+		Convey("The users without API keys report should only list users without accounts", func() {
+			_, err := db.SaveUser(ctx, models.NewUser("test5", "password", "test5@example.com", false, true))
+			So(err, ShouldBeNil)
+
+			result, err := db.RunReport(ctx, database.ReportUsersWithoutAPIKeys, nil)
+			So(err, ShouldBeNil)
+			So(len(result.Rows), ShouldEqual, 1)
+			So(result.Rows[0][1], ShouldEqual, "test5")
+			So(result.Rows[0][3], ShouldEqual, true)
+		})
+
+		Convey("The login attempts report should count attempts per day within the given range", func() {
+			now := time.Now().UTC()
+
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", Successful: true, Timestamp: now}), ShouldBeNil)
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", Successful: false, Timestamp: now}), ShouldBeNil)
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test2", Successful: false, Timestamp: now}), ShouldBeNil)
+			So(db.SaveLoginAttempt(ctx, &models.LoginAttempt{Username: "test1", Successful: false, Timestamp: now.AddDate(0, 0, -3)}), ShouldBeNil)
+
+			result, err := db.RunReport(ctx, database.ReportLoginAttempts, map[string]string{"days": "2"})
+			So(err, ShouldBeNil)
+			So(result.Rows, ShouldResemble, [][]interface{}{
+				[]interface{}{now.Format("2006-01-02"), int64(2), int64(1)},
+			})
+
+			result, err = db.RunReport(ctx, database.ReportLoginAttempts, nil)
+			So(err, ShouldBeNil)
+			So(len(result.Rows), ShouldEqual, 2)
+		})
+
+		Convey("Running a nonexistent report should return an error", func() {
+			result, err := db.RunReport(ctx, "nonexistent", nil)
+			So(err, ShouldNotBeNil)
+			So(result, ShouldBeNil)
+		})
+	})
+}
+
+func TestDatabaseConnectionLoadAll(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Loading all entities from an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Loading all accounts should return 6 accounts including their characters", func() {
+			accounts, err := db.LoadAllAccounts(ctx)
+			So(err, ShouldBeNil)
+			So(len(accounts), ShouldEqual, 6)
+			So(len(accounts[2].Characters), ShouldEqual, 2)
+			So(accounts[2].Characters[1].Name, ShouldEqual, "Derp")
+		})
+
+		Convey("Loading all corporations should return 2 corporations", func() {
+			corporations, err := db.LoadAllCorporations(ctx)
+			So(err, ShouldBeNil)
+			So(len(corporations), ShouldEqual, 2)
+			So(corporations[0].APIKeyID.Int64, ShouldEqual, 1)
+			So(corporations[1].APIKeyID.Valid, ShouldBeFalse)
+		})
+
+		Convey("Loading all characters should return 6 characters", func() {
+			characters, err := db.LoadAllCharacters(ctx)
+			So(err, ShouldBeNil)
+			So(len(characters), ShouldEqual, 6)
+		})
+
+		Convey("Loading all roles should return 4 roles", func() {
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 4)
+		})
+
+		Convey("Loading all group roles should return 4 group roles with their roles attached", func() {
+			groupRoles, err := db.LoadAllGroupRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(groupRoles), ShouldEqual, 4)
+			So(groupRoles[1].Role.Name, ShouldEqual, "logistics.read")
+		})
+
+		Convey("Loading all user roles should return 2 user roles with their roles attached", func() {
+			userRoles, err := db.LoadAllUserRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(userRoles), ShouldEqual, 2)
+			So(userRoles[1].Role.Name, ShouldEqual, "destroy.world")
+		})
+
+		Convey("Loading all groups should return 2 groups with their group roles attached", func() {
+			groups, err := db.LoadAllGroups(ctx)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 2)
+			So(len(groups[0].GroupRoles), ShouldEqual, 2)
+		})
+
+		Convey("Loading all users should return 4 users", func() {
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(len(users), ShouldEqual, 4)
+			So(len(users[2].Accounts), ShouldEqual, 2)
+			So(len(users[2].Groups), ShouldEqual, 2)
+			So(len(users[2].UserRoles), ShouldEqual, 1)
+		})
+
+		Convey("Loading all applications should return 2 applications", func() {
+			applications, err := db.LoadAllApplications(ctx)
+			So(err, ShouldBeNil)
+			So(len(applications), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestDatabaseConnectionLoadSingle(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Loading single entities from an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Loading an existing user should return the user with its associations", func() {
+			user, err := db.LoadUser(ctx, 3)
+			So(err, ShouldBeNil)
+			So(user.Username, ShouldEqual, "test3")
+			So(len(user.Accounts), ShouldEqual, 2)
+			So(len(user.Groups), ShouldEqual, 2)
+			So(len(user.UserRoles), ShouldEqual, 1)
+		})
+
+		Convey("Loading a user by username should ignore the case", func() {
+			user, err := db.LoadUserFromUsername(ctx, "TEST1")
+			So(err, ShouldBeNil)
+			So(user.ID, ShouldEqual, 1)
+		})
+
+		Convey("Loading a corporation by EVE corporation ID should return the corporation", func() {
+			corporation, err := db.LoadCorporationFromEVECorporationID(ctx, 2)
+			So(err, ShouldBeNil)
+			So(corporation.Ticker, ShouldEqual, "CORP")
+		})
+
+		Convey("Loading the name of a corporation should return its name", func() {
+			name, err := db.LoadCorporationNameFromID(ctx, 1)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Test Corp Please Ignore")
+		})
+
+		Convey("Loading the password of a user should return the hash", func() {
+			password, err := db.LoadPasswordForUser(ctx, "test2")
+			So(err, ShouldBeNil)
+			So(password, ShouldEqual, "$2a$10$95z.WXfIreLKJ9px.3KgpOq4aXTG3DF7/5ehGYzUWALhpN6MMq/aK")
+		})
+
+		Convey("Loading nonexistent entities should return sql.ErrNoRows", func() {
+			_, err := db.LoadAccount(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadCorporation(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadCorporationFromEVECorporationID(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadCharacter(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadRole(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadGroupRole(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadUserRole(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadGroup(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadUser(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadUserFromUsername(ctx, "nonexistent")
+			So(err, ShouldEqual, sql.ErrNoRows)
+			_, err = db.LoadApplication(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+
+		Convey("Modifying a loaded model should not modify the stored data", func() {
+			user, err := db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+
+			user.Username = "modified"
+			user.UserRoles[0].Role.Name = "modified"
+
+			user, err = db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(user.Username, ShouldEqual, "test1")
+			So(user.UserRoles[0].Role.Name, ShouldEqual, "ping.all")
+		})
+	})
+}
+
+func TestDatabaseConnectionAvailable(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Loading associated and available entities from an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Loading all groups for a user should only return active memberships", func() {
+			groups, err := db.LoadAllGroupsForUser(ctx, 4)
+			So(err, ShouldBeNil)
+			So(groups, ShouldNotBeNil)
+			So(len(groups), ShouldEqual, 0)
+		})
+
+		Convey("Loading the available groups for a user should return all groups without an active membership sorted by name", func() {
+			groups, err := db.LoadAvailableGroupsForUser(ctx, 4)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 2)
+			So(groups[0].Name, ShouldEqual, "Dank Access")
+			So(groups[1].Name, ShouldEqual, "Test Group")
+
+			groups, err = db.LoadAvailableGroupsForUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 1)
+			So(groups[0].ID, ShouldEqual, 2)
+		})
+
+		Convey("Loading the available user roles should return all roles not assigned to the user sorted by name", func() {
+			roles, err := db.LoadAvailableUserRolesForUser(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 3)
+			So(roles[0].Name, ShouldEqual, "destroy.world")
+			So(roles[1].Name, ShouldEqual, "logistics.read")
+			So(roles[2].Name, ShouldEqual, "logistics.write")
+		})
+
+		Convey("Loading the available group roles should return all roles not assigned to the group sorted by name", func() {
+			roles, err := db.LoadAvailableGroupRolesForGroup(ctx, 1)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 2)
+			So(roles[0].Name, ShouldEqual, "destroy.world")
+			So(roles[1].Name, ShouldEqual, "logistics.write")
+		})
+
+		Convey("Loading all applications for a user should return the maintained applications", func() {
+			applications, err := db.LoadAllApplicationsForUser(ctx, 2)
+			So(err, ShouldBeNil)
+			So(len(applications), ShouldEqual, 1)
+			So(applications[0].Name, ShouldEqual, "Apptest")
+		})
+
+		Convey("Querying the existence of users should check the username and email", func() {
+			exists, err := db.QueryUserIDExists(ctx, 2)
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeTrue)
+
+			exists, err = db.QueryUserIDExists(ctx, 1337)
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeFalse)
+
+			exists, err = db.QueryUserNameEmailExists(ctx, "nonexistent", "TEST3@example.com")
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeTrue)
+
+			exists, err = db.QueryUserNameEmailExists(ctx, "nonexistent", "nonexistent@example.com")
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeFalse)
+		})
+	})
+}
+
+func TestDatabaseConnectionSave(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Saving entities to an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Saving a new user should assign IDs to the user and its associations", func() {
+			user := models.NewUser("test5", "password", "test5@example.com", false, true)
+			account := models.NewAccount(-1, 7, "g", 0, true)
+			account.Characters = append(account.Characters, models.NewCharacter(-1, 1, "Test Five", 7, true, true))
+			user.Accounts = append(user.Accounts, account)
+			user.UserRoles = append(user.UserRoles, models.NewUserRole(-1, models.NewRole("new.role", true, false), false, true))
+
+			user, err := db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+			So(user.ID, ShouldEqual, 5)
+			So(user.Accounts[0].ID, ShouldEqual, 7)
+			So(user.Accounts[0].UserID, ShouldEqual, 5)
+			So(user.Accounts[0].Characters[0].ID, ShouldEqual, 7)
+			So(user.UserRoles[0].ID, ShouldEqual, 3)
+			So(user.UserRoles[0].Role.ID, ShouldEqual, 5)
+
+			loaded, err := db.LoadUser(ctx, 5)
+			So(err, ShouldBeNil)
+			So(loaded.Accounts[0].Characters[0].Name, ShouldEqual, "Test Five")
+			So(loaded.UserRoles[0].Role.Name, ShouldEqual, "new.role")
+		})
+
+		Convey("Saving an existing user should update the stored values", func() {
+			user, err := db.LoadUser(ctx, 2)
+			So(err, ShouldBeNil)
+
+			user.Email = "changed@example.com"
+
+			_, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			loaded, err := db.LoadUser(ctx, 2)
+			So(err, ShouldBeNil)
+			So(loaded.Email, ShouldEqual, "changed@example.com")
+		})
+
+		Convey("Saving entities violating unique keys should return an error", func() {
+			_, err := db.SaveUser(ctx, models.NewUser("TEST1", "password", "other@example.com", false, true))
+			So(err, ShouldNotBeNil)
+
+			_, err = db.SaveRole(ctx, models.NewRole("ping.all", true, false))
+			So(err, ShouldNotBeNil)
+
+			_, err = db.SaveGroup(ctx, models.NewGroup("Test Group", true))
+			So(err, ShouldNotBeNil)
+
+			_, err = db.SaveAccount(ctx, models.NewAccount(1, 1, "x", 0, true))
+			So(err, ShouldNotBeNil)
+
+			_, err = db.SaveCharacter(ctx, models.NewCharacter(1, 1, "Herp", 1337, false, true))
+			So(err, ShouldNotBeNil)
+
+			_, err = db.SaveCorporation(ctx, models.NewCorporation("New Corp", "TEST", 1337, 1, zero.IntFrom(1337), zero.StringFrom("x"), true))
+			So(err, ShouldNotBeNil)
+
+			_, err = db.SaveApplication(ctx, models.NewApplication("Testapp", 1, "cccccccccccccccccccccccccccccccc", "http://localhost/callback", true))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Saving the groups for a user should reactivate inactive memberships", func() {
+			group, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveAllGroupsForUser(ctx, 2, []*models.Group{group}, nil, nil)
+			So(err, ShouldBeNil)
+
+			groups, err := db.LoadAllGroupsForUser(ctx, 2)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 1)
+			So(len(db.userGroups), ShouldEqual, 6)
+		})
+
+		Convey("Saving a login attempt and CSRF failure should store them", func() {
+			err := db.SaveLoginAttempt(ctx, models.NewLoginAttempt("test1", "127.0.0.1", "test", true))
+			So(err, ShouldBeNil)
+			So(len(db.loginAttempts), ShouldEqual, 1)
+
+			err = db.SaveCSRFFailure(ctx, &models.CSRFFailure{UserID: 1, Request: "request"})
+			So(err, ShouldBeNil)
+			So(len(db.csrfFailures), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestDatabaseConnectionDelete(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Deleting entities from an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Deleting a user should move it to the trash while keeping its memberships, roles, accounts and characters", func() {
+			err := db.DeleteUser(ctx, 3, 1)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadUser(ctx, 3)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			So(len(db.userGroups), ShouldEqual, 6)
+			So(len(db.userRoles), ShouldEqual, 2)
+			So(len(db.accounts), ShouldEqual, 6)
+			So(len(db.characters), ShouldEqual, 6)
+		})
+
+		Convey("Deleting a group should move it to the trash and hide it from its members", func() {
+			err := db.DeleteGroup(ctx, 2, 1)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadGroup(ctx, 2)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			So(len(db.groupRoles), ShouldEqual, 4)
+			So(len(db.userGroups), ShouldEqual, 6)
+
+			groups, err := db.LoadAllGroupsForUser(ctx, 3)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 1)
+		})
+
+		Convey("Deleting a role should move it to the trash and hide it from users and groups", func() {
+			err := db.DeleteRole(ctx, 2, 1)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadRole(ctx, 2)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			So(len(db.userRoles), ShouldEqual, 2)
+			So(len(db.groupRoles), ShouldEqual, 4)
+
+			user, err := db.LoadUser(ctx, 3)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+		})
+
+		Convey("Deleting an account should remove its characters", func() {
+			err := db.DeleteAccount(ctx, 3)
+			So(err, ShouldBeNil)
+
+			_, err = db.LoadAccount(ctx, 3)
+			So(err, ShouldEqual, sql.ErrNoRows)
+			So(len(db.characters), ShouldEqual, 4)
+		})
+
+		Convey("Removing an API key from a user should remove the account and its characters", func() {
+			user, err := db.LoadUser(ctx, 3)
+			So(err, ShouldBeNil)
+
+			user, err = db.RemoveAPIKeyFromUser(ctx, user, 3)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 1)
+			So(len(db.accounts), ShouldEqual, 5)
+			So(len(db.characters), ShouldEqual, 4)
+		})
+
+		Convey("Removing a user from a group should remove the membership", func() {
+			user, err := db.RemoveUserFromGroup(ctx, 3, 2)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 1)
+
+			groups, err := db.LoadAllGroupsForUser(ctx, 3)
+			So(err, ShouldBeNil)
+			So(len(groups), ShouldEqual, 1)
+		})
+
+		Convey("Removing a user role from a user should remove the user role", func() {
+			user, err := db.RemoveUserRoleFromUser(ctx, 3, 2)
+			So(err, ShouldBeNil)
+			So(len(user.UserRoles), ShouldEqual, 0)
+			So(len(db.userRoles), ShouldEqual, 1)
+		})
+
+		Convey("Removing a group role from a group should remove the group role", func() {
+			group, err := db.RemoveGroupRoleFromGroup(ctx, 1, 2)
+			So(err, ShouldBeNil)
+			So(len(group.GroupRoles), ShouldEqual, 1)
+			So(len(db.groupRoles), ShouldEqual, 3)
+		})
+	})
+}
+
+func TestDatabaseConnectionTrash(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Restoring and purging deleted entities in an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		So(db.DeleteUser(ctx, 3, 1), ShouldBeNil)
+		So(db.DeleteGroup(ctx, 2, 1), ShouldBeNil)
+		So(db.DeleteRole(ctx, 2, 1), ShouldBeNil)
+		So(db.DeleteApplication(ctx, 2, 1), ShouldBeNil)
+
+		Convey("Loading the trash should return all deleted entities", func() {
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 4)
+
+			names := make(map[models.TrashEntryType]string)
+			for _, entry := range trash {
+				So(entry.DeletedBy, ShouldEqual, 1)
+				So(entry.DeletedAt.IsZero(), ShouldBeFalse)
+				names[entry.Type] = entry.Name
+			}
+
+			So(names[models.TrashEntryTypeUser], ShouldEqual, "test3")
+			So(names[models.TrashEntryTypeGroup], ShouldEqual, "Dank Access")
+			So(names[models.TrashEntryTypeRole], ShouldEqual, "destroy.world")
+			So(names[models.TrashEntryTypeApplication], ShouldEqual, "Apptest")
+		})
+
+		Convey("Restoring a user should make it and its relations available again", func() {
+			err := db.RestoreUser(ctx, 3)
+			So(err, ShouldBeNil)
+
+			user, err := db.LoadUser(ctx, 3)
+			So(err, ShouldBeNil)
+			So(len(user.Accounts), ShouldEqual, 2)
+			So(len(user.Groups), ShouldEqual, 1)
+
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 3)
+		})
+
+		Convey("Restoring a group and role should make them available to their members again", func() {
+			So(db.RestoreGroup(ctx, 2), ShouldBeNil)
+			So(db.RestoreRole(ctx, 2), ShouldBeNil)
+			So(db.RestoreUser(ctx, 3), ShouldBeNil)
+
+			user, err := db.LoadUser(ctx, 3)
+			So(err, ShouldBeNil)
+			So(len(user.Groups), ShouldEqual, 2)
+			So(len(user.UserRoles), ShouldEqual, 1)
+		})
+
+		Convey("Restoring an application should make it available again", func() {
+			err := db.RestoreApplication(ctx, 2)
+			So(err, ShouldBeNil)
+
+			application, err := db.LoadApplication(ctx, 2)
+			So(err, ShouldBeNil)
+			So(application.Name, ShouldEqual, "Apptest")
+		})
+
+		Convey("Restoring an entity that is not in the trash should return an error", func() {
+			err := db.RestoreGroup(ctx, 1)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+
+		Convey("Purging the trash should keep entities deleted after the cutoff", func() {
+			purged, err := db.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 0)
+
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 4)
+		})
+
+		Convey("Purging the trash should permanently remove the entities and their relations", func() {
+			purged, err := db.PurgeTrash(ctx, time.Now().Add(time.Minute))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 4)
+
+			So(len(db.users), ShouldEqual, 3)
+			So(len(db.groups), ShouldEqual, 1)
+			So(len(db.roles), ShouldEqual, 3)
+			So(len(db.applications), ShouldEqual, 1)
+			So(len(db.userGroups), ShouldEqual, 3)
+			So(len(db.userRoles), ShouldEqual, 1)
+			So(len(db.groupRoles), ShouldEqual, 2)
+			So(len(db.accounts), ShouldEqual, 4)
+			So(len(db.characters), ShouldEqual, 2)
+
+			trash, err := db.LoadTrash(ctx)
+			So(err, ShouldBeNil)
+			So(len(trash), ShouldEqual, 0)
+
+			err = db.RestoreUser(ctx, 3)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
+
+func TestDatabaseConnectionConflict(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Saving outdated entities to an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Saving an outdated group should return a conflict error and keep the newer changes", func() {
+			first, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+
+			second, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+
+			first.Name = "First Group"
+
+			first, err = db.SaveGroup(ctx, first)
+			So(err, ShouldBeNil)
+			So(first.Version, ShouldEqual, 1)
+
+			second.Name = "Second Group"
+
+			_, err = db.SaveGroup(ctx, second)
+			So(err, ShouldNotBeNil)
+			So(database.IsConflict(err), ShouldBeTrue)
+
+			group, err := db.LoadGroup(ctx, 1)
+			So(err, ShouldBeNil)
+			So(group.Name, ShouldEqual, "First Group")
+		})
+
+		Convey("Saving the same user repeatedly should increment its version", func() {
+			user, err := db.LoadUser(ctx, 1)
+			So(err, ShouldBeNil)
+
+			user, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			user, err = db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+			So(user.Version, ShouldEqual, 2)
+		})
+
+		Convey("Modifying the roles of a user should make previously loaded copies outdated", func() {
+			user, err := db.LoadUser(ctx, 3)
+			So(err, ShouldBeNil)
+
+			_, err = db.ToggleUserRoleGranted(ctx, 2)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveUser(ctx, user)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Saving an outdated role should return a conflict error", func() {
+			role, err := db.LoadRole(ctx, 1)
+			So(err, ShouldBeNil)
+
+			_, err = db.SaveRole(ctx, role)
+			So(err, ShouldBeNil)
+
+			role.Version = 0
+
+			_, err = db.SaveRole(ctx, role)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+
+		Convey("Saving an outdated application should return a conflict error", func() {
+			application, err := db.LoadApplication(ctx, 1)
+			So(err, ShouldBeNil)
+
+			application.Version = 5
+
+			_, err = db.SaveApplication(ctx, application)
+			So(database.IsConflict(err), ShouldBeTrue)
+		})
+	})
+}
+
+func TestDatabaseConnectionToggle(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Toggling the granted state of roles in an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Toggling a user role should flip its granted state", func() {
+			userRole, err := db.ToggleUserRoleGranted(ctx, 1)
+			So(err, ShouldBeNil)
+			So(userRole.Granted, ShouldBeTrue)
+
+			userRole, err = db.LoadUserRole(ctx, 1)
+			So(err, ShouldBeNil)
+			So(userRole.Granted, ShouldBeTrue)
+		})
+
+		Convey("Toggling a group role should flip its granted state", func() {
+			groupRole, err := db.ToggleGroupRoleGranted(ctx, 1)
+			So(err, ShouldBeNil)
+			So(groupRole.Granted, ShouldBeFalse)
+
+			groupRole, err = db.LoadGroupRole(ctx, 1)
+			So(err, ShouldBeNil)
+			So(groupRole.Granted, ShouldBeFalse)
+		})
+
+		Convey("Toggling a nonexistent role should return an error", func() {
+			_, err := db.ToggleUserRoleGranted(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+
+			_, err = db.ToggleGroupRoleGranted(ctx, 1337)
+			So(err, ShouldEqual, sql.ErrNoRows)
+		})
+	})
+}
+
+func TestDatabaseConnectionTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Running transactions against the in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Saving a role within a successful transaction should persist it", func() {
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				_, err := tx.SaveRole(ctx, models.NewRole("transaction.commit", true, false))
+				return err
+			})
+			So(err, ShouldBeNil)
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 5)
+		})
+
+		Convey("Saving a role within a failed transaction should discard it", func() {
+			err := db.WithTx(ctx, func(tx database.Connection) error {
+				_, err := tx.SaveRole(ctx, models.NewRole("transaction.rollback", true, false))
+				if err != nil {
+					return err
+				}
+
+				return errors.New("Rollback")
+			})
+			So(err, ShouldNotBeNil)
+
+			roles, err := db.LoadAllRoles(ctx)
+			So(err, ShouldBeNil)
+			So(len(roles), ShouldEqual, 4)
+		})
+
+		Convey("Saving a user with a duplicate API key should not leave a partially saved user", func() {
+			user := models.NewUser("transaction", "password", "transaction@example.com", false, true)
+			user.Accounts = append(user.Accounts, models.NewAccount(-1, 1337, "x", 0, true), models.NewAccount(-1, 1, "y", 0, true))
+
+			_, err := db.SaveUser(ctx, user)
+			So(err, ShouldNotBeNil)
+
+			users, err := db.LoadAllUsers(ctx)
+			So(err, ShouldBeNil)
+			So(len(users), ShouldEqual, 4)
+
+			accounts, err := db.LoadAllAccounts(ctx)
+			So(err, ShouldBeNil)
+			So(len(accounts), ShouldEqual, 6)
+		})
+	})
+}
+
+func TestDatabaseConnectionQuery(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Querying pages of entries from an in-memory database", t, func() {
+		db := createMemoryConnection()
+
+		Convey("Querying users with the default criteria should return all users", func() {
+			users, total, err := db.QueryUsers(ctx, database.NewListCriteria())
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(len(users), ShouldEqual, 4)
+			So(users[0].ID, ShouldEqual, 1)
+			So(users[0].Accounts, ShouldNotBeEmpty)
+		})
+
+		Convey("Querying users with a text filter should only return matching users", func() {
+			criteria := database.NewListCriteria()
+			criteria.Filter = "TEST1@"
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(users[0].Username, ShouldEqual, "test1")
+		})
+
+		Convey("Querying users by active state, group and corporation should apply all filters", func() {
+			criteria := database.NewListCriteria()
+			criteria.Active = database.ActiveFilterActive
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(users[1].ID, ShouldEqual, 3)
+
+			criteria = database.NewListCriteria()
+			criteria.GroupID = 2
+
+			users, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(users[0].ID, ShouldEqual, 3)
+
+			criteria = database.NewListCriteria()
+			criteria.CorporationID = 2
+			criteria.Active = database.ActiveFilterInactive
+
+			users, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(users[0].ID, ShouldEqual, 2)
+		})
+
+		Convey("Querying users sorted by username in descending order should return the requested page", func() {
+			criteria := database.NewListCriteria()
+			criteria.SortField = "username"
+			criteria.SortDirection = database.SortDescending
+			criteria.Limit = 2
+			criteria.Offset = 1
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(len(users), ShouldEqual, 2)
+			So(users[0].Username, ShouldEqual, "test3")
+			So(users[1].Username, ShouldEqual, "test2")
+		})
+
+		Convey("Querying users past the last page should return no users but the total count", func() {
+			criteria := database.NewListCriteria()
+			criteria.Offset = 10
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(users, ShouldBeNil)
+		})
+
+		Convey("Querying groups sorted by an unsupported field should fall back to the ID", func() {
+			criteria := database.NewListCriteria()
+			criteria.SortField = "password"
+
+			groups, total, err := db.QueryGroups(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(groups[0].ID, ShouldEqual, 1)
+			So(len(groups[0].GroupRoles), ShouldEqual, 2)
+
+			criteria.SortField = "name"
+
+			groups, total, err = db.QueryGroups(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(groups[0].Name, ShouldEqual, "Dank Access")
+		})
+
+		Convey("Querying roles should apply the filters and sorting", func() {
+			criteria := database.NewListCriteria()
+			criteria.Filter = "logistics"
+			criteria.SortField = "name"
+			criteria.SortDirection = database.SortDescending
+
+			roles, total, err := db.QueryRoles(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(roles[0].Name, ShouldEqual, "logistics.write")
+
+			criteria = database.NewListCriteria()
+			criteria.SortField = "locked"
+			criteria.SortDirection = database.SortDescending
+
+			roles, total, err = db.QueryRoles(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(roles[0].Name, ShouldEqual, "destroy.world")
+			So(roles[1].ID, ShouldEqual, 1)
+		})
+
+		Convey("Querying applications should apply the filters", func() {
+			criteria := database.NewListCriteria()
+			criteria.Filter = "app"
+			criteria.Active = database.ActiveFilterInactive
+
+			applications, total, err := db.QueryApplications(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(applications[0].Name, ShouldEqual, "Apptest")
+		})
+	})
+}
+
+func TestDatabaseConnectionConformance(t *testing.T) {
+	conformancetest.Run(t, func() (database.Connection, error) {
+		db := &DatabaseConnection{
+			Config: &misc.Configuration{
+				DatabaseType: 0,
+				DebugLevel:   1,
+			},
+		}
+
+		err := db.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return db, nil
+	})
+}
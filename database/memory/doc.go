@@ -0,0 +1,2 @@
+// Package memory provides a non-persistent implementation of the Database interface, storing all values in memory.
+package memory
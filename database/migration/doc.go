@@ -0,0 +1,6 @@
+// Package migration provides versioned schema migrations shared by the SQL database backends, tracking the applied versions in a schema_version table.
+//
+// Every migration is applied within a transaction, which only guarantees atomic migrations for backends supporting transactional DDL such as
+// PostgreSQL and SQLite. MySQL implicitly commits data definition statements, leaving a partially applied schema behind if a later statement
+// of the same migration fails. Migrations should therefore keep the number of DDL statements small and be checked manually after a failure.
+package migration
@@ -0,0 +1,216 @@
+package migration
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migration represents a single versioned change to the database schema
+type Migration struct {
+	// Version represents the schema version reached after applying the migration
+	Version int `json:"version"`
+	// Description briefly describes the changes performed by the migration
+	Description string `json:"description"`
+	// Up stores the statements executed when applying the migration
+	Up []string `json:"-"`
+	// Down stores the statements executed when reverting the migration
+	Down []string `json:"-"`
+}
+
+// Status represents the current migration state of a database schema
+type Status struct {
+	// Version represents the currently applied schema version
+	Version int `json:"version"`
+	// LatestVersion represents the schema version available after applying all migrations
+	LatestVersion int `json:"latestVersion"`
+	// Pending stores all migrations not applied yet
+	Pending []*Migration `json:"pending"`
+}
+
+// Outdated checks whether the schema is missing any of the available migrations
+func (status *Status) Outdated() bool {
+	return status.Version < status.LatestVersion
+}
+
+// Newer checks whether the schema has been migrated past the latest available migration, e.g. by a more recent release of the application
+func (status *Status) Newer() bool {
+	return status.Version > status.LatestVersion
+}
+
+// String represents a JSON encoded representation of the status
+func (status *Status) String() string {
+	jsonContent, err := json.Marshal(status)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
+
+// EnsureVersionTable creates the schema_version table if it does not exist yet, returning an error if the query failed
+func EnsureVersionTable(conn *sqlx.DB) error {
+	_, err := conn.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL PRIMARY KEY, description VARCHAR(128) NOT NULL, applied TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CurrentVersion retrieves the most recently applied schema version, returning 0 if no migration has been applied yet
+func CurrentVersion(conn *sqlx.DB) (int, error) {
+	err := EnsureVersionTable(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+
+	err = conn.Get(&version, "SELECT MAX(version) FROM schema_version")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(version.Int64), nil
+}
+
+// LatestVersion returns the highest version provided by the given migrations
+func LatestVersion(migrations []*Migration) int {
+	latest := 0
+
+	for _, migration := range migrations {
+		if migration.Version > latest {
+			latest = migration.Version
+		}
+	}
+
+	return latest
+}
+
+// LoadStatus compares the applied schema version with the given migrations, returning an error if the query failed
+func LoadStatus(conn *sqlx.DB, migrations []*Migration) (*Status, error) {
+	version, err := CurrentVersion(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Version:       version,
+		LatestVersion: LatestVersion(migrations),
+		Pending:       make([]*Migration, 0),
+	}
+
+	for _, migration := range migrations {
+		if migration.Version > version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// Up applies all pending migrations in order, returning the resulting schema version or an error if a migration failed
+func Up(conn *sqlx.DB, migrations []*Migration) (int, error) {
+	status, err := LoadStatus(conn, migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	version := status.Version
+
+	for _, migration := range status.Pending {
+		err = apply(conn, migration.Up, conn.Rebind("INSERT INTO schema_version(version, description) VALUES(?, ?)"), migration.Version, migration.Description)
+		if err != nil {
+			return version, fmt.Errorf("Failed to apply migration #%d (%s): %v", migration.Version, migration.Description, err)
+		}
+
+		version = migration.Version
+	}
+
+	return version, nil
+}
+
+// Down reverts the most recently applied migration, returning the resulting schema version or an error if the migration failed
+func Down(conn *sqlx.DB, migrations []*Migration) (int, error) {
+	version, err := CurrentVersion(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if version == 0 {
+		return 0, nil
+	}
+
+	var current *Migration
+
+	for _, migration := range migrations {
+		if migration.Version == version {
+			current = migration
+			break
+		}
+	}
+
+	if current == nil {
+		return version, fmt.Errorf("Unknown schema version #%d", version)
+	}
+
+	err = apply(conn, current.Down, conn.Rebind("DELETE FROM schema_version WHERE version=?"), current.Version)
+	if err != nil {
+		return version, fmt.Errorf("Failed to revert migration #%d (%s): %v", current.Version, current.Description, err)
+	}
+
+	return CurrentVersion(conn)
+}
+
+// Prepare checks whether the schema is up to date, applying all pending migrations if autoMigrate is set and returning an error if the schema is outdated otherwise.
+// Schemas newer than the latest available migration are always rejected since the application cannot know how to work with them
+func Prepare(conn *sqlx.DB, migrations []*Migration, autoMigrate bool) error {
+	status, err := LoadStatus(conn, migrations)
+	if err != nil {
+		return err
+	}
+
+	if status.Newer() {
+		return fmt.Errorf("Database schema is newer than supported (version #%d, latest #%d), please upgrade eveauth or revert the schema using a more recent release", status.Version, status.LatestVersion)
+	}
+
+	if !status.Outdated() {
+		return nil
+	}
+
+	if !autoMigrate {
+		return fmt.Errorf("Database schema is outdated (version #%d, latest #%d), please run \"eveauth migrate up\"", status.Version, status.LatestVersion)
+	}
+
+	_, err = Up(conn, migrations)
+
+	return err
+}
+
+// apply executes the given statements followed by the schema_version update within a single transaction.
+// The transaction does not cover DDL statements on MySQL, see the package documentation
+func apply(conn *sqlx.DB, statements []string, versionQuery string, versionArgs ...interface{}) error {
+	tx, err := conn.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		_, err = tx.Exec(statement)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(versionQuery, versionArgs...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,169 @@
+package migration
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	// Blank import of the SQLite driver to run the migrations against a temporary database
+	_ "github.com/mattn/go-sqlite3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testMigrations = []*Migration{
+	&Migration{
+		Version:     1,
+		Description: "Create test table",
+		Up:          []string{"CREATE TABLE test (id INTEGER PRIMARY KEY)"},
+		Down:        []string{"DROP TABLE test"},
+	},
+	&Migration{
+		Version:     2,
+		Description: "Add name to test table",
+		Up:          []string{"ALTER TABLE test ADD COLUMN name VARCHAR(32)"},
+		Down:        []string{"CREATE TABLE test_old (id INTEGER PRIMARY KEY)", "DROP TABLE test", "ALTER TABLE test_old RENAME TO test"},
+	},
+}
+
+func createTestDatabase() (*sqlx.DB, error) {
+	databaseDir, err := ioutil.TempDir("", "eveauth")
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlx.Connect("sqlite3", filepath.Join(databaseDir, "migration.db"))
+}
+
+func TestMigrationStatus(t *testing.T) {
+	Convey("Loading the migration status of an empty database", t, func() {
+		conn, err := createTestDatabase()
+		So(err, ShouldBeNil)
+
+		status, err := LoadStatus(conn, testMigrations)
+
+		Convey("The returned error should be nil", func() {
+			So(err, ShouldBeNil)
+		})
+
+		Convey("The schema should be at version 0 with all migrations pending", func() {
+			So(status.Version, ShouldEqual, 0)
+			So(status.LatestVersion, ShouldEqual, 2)
+			So(len(status.Pending), ShouldEqual, 2)
+			So(status.Outdated(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestMigrationUpDown(t *testing.T) {
+	Convey("Applying and reverting migrations", t, func() {
+		conn, err := createTestDatabase()
+		So(err, ShouldBeNil)
+
+		version, err := Up(conn, testMigrations)
+
+		Convey("Applying all migrations should reach the latest version", func() {
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, 2)
+
+			_, err = conn.Exec("INSERT INTO test(id, name) VALUES(1, 'test')")
+			So(err, ShouldBeNil)
+
+			status, err := LoadStatus(conn, testMigrations)
+			So(err, ShouldBeNil)
+			So(status.Outdated(), ShouldBeFalse)
+			So(len(status.Pending), ShouldEqual, 0)
+		})
+
+		Convey("Applying the migrations again should not change anything", func() {
+			version, err = Up(conn, testMigrations)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, 2)
+		})
+
+		Convey("Reverting a migration should go back one version", func() {
+			version, err = Down(conn, testMigrations)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, 1)
+
+			_, err = conn.Exec("INSERT INTO test(id, name) VALUES(1, 'test')")
+			So(err, ShouldNotBeNil)
+
+			Convey("Reverting all migrations should reach version 0", func() {
+				version, err = Down(conn, testMigrations)
+				So(err, ShouldBeNil)
+				So(version, ShouldEqual, 0)
+
+				version, err = Down(conn, testMigrations)
+				So(err, ShouldBeNil)
+				So(version, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestMigrationFailure(t *testing.T) {
+	Convey("Applying a failing migration", t, func() {
+		conn, err := createTestDatabase()
+		So(err, ShouldBeNil)
+
+		migrations := append(testMigrations, &Migration{
+			Version:     3,
+			Description: "Broken migration",
+			Up:          []string{"ALTER TABLE nonexistent ADD COLUMN name VARCHAR(32)"},
+		})
+
+		version, err := Up(conn, migrations)
+
+		Convey("The returned error should not be nil", func() {
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("The schema should remain at the last successful version", func() {
+			So(version, ShouldEqual, 2)
+
+			current, err := CurrentVersion(conn)
+			So(err, ShouldBeNil)
+			So(current, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestMigrationPrepare(t *testing.T) {
+	Convey("Preparing an outdated database", t, func() {
+		conn, err := createTestDatabase()
+		So(err, ShouldBeNil)
+
+		Convey("Without automatic migrations the returned error should not be nil", func() {
+			err = Prepare(conn, testMigrations, false)
+			So(err, ShouldNotBeNil)
+
+			version, err := CurrentVersion(conn)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, 0)
+		})
+
+		Convey("With automatic migrations all pending migrations should be applied", func() {
+			err = Prepare(conn, testMigrations, true)
+			So(err, ShouldBeNil)
+
+			version, err := CurrentVersion(conn)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, 2)
+		})
+
+		Convey("A schema newer than the latest migration should be rejected", func() {
+			_, err = Up(conn, testMigrations)
+			So(err, ShouldBeNil)
+
+			err = Prepare(conn, testMigrations[:1], true)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "newer")
+
+			version, err := CurrentVersion(conn)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, 2)
+		})
+	})
+}
@@ -1,10 +1,13 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
 
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/conformancetest"
 	"github.com/morpheusxaut/eveauth/misc"
 	"github.com/morpheusxaut/eveauth/models"
 
@@ -13,40 +16,68 @@ import (
 )
 
 var (
-	database *DatabaseConnection
+	testDatabase        *DatabaseConnection
+	conformanceDatabase *DatabaseConnection
 )
 
+// testConfiguration returns the configuration used to connect to the MySQL test database, overridable using environment variables
+func testConfiguration() *misc.Configuration {
+	databaseHost := "localhost:3306"
+	if len(os.Getenv("DATABASE_HOST")) > 0 {
+		databaseHost = os.Getenv("DATABASE_HOST")
+	}
+
+	databaseSchema := "eveauth"
+	if len(os.Getenv("DATABASE_SCHEMA")) > 0 {
+		databaseSchema = os.Getenv("DATABASE_SCHEMA")
+	}
+
+	databaseUser := "eveauth"
+	if len(os.Getenv("DATABASE_USER")) > 0 {
+		databaseUser = os.Getenv("DATABASE_USER")
+	}
+
+	databasePassword := "eveauth"
+	if len(os.Getenv("DATABASE_PASSWORD")) > 0 {
+		databasePassword = os.Getenv("DATABASE_PASSWORD")
+	}
+
+	config := &misc.Configuration{
+		DatabaseType:     1,
+		DatabaseHost:     databaseHost,
+		DatabaseSchema:   databaseSchema,
+		DatabaseUser:     databaseUser,
+		DatabasePassword: databasePassword,
+		DebugLevel:       1,
+		HTTPHost:         "localhost:5000",
+	}
+
+	return config
+}
+
 func createMySQLConnection() (*DatabaseConnection, error) {
-	if database == nil {
-		databaseHost := "localhost:3306"
-		if len(os.Getenv("DATABASE_HOST")) > 0 {
-			databaseHost = os.Getenv("DATABASE_HOST")
+	if testDatabase == nil {
+		db := &DatabaseConnection{
+			Config: testConfiguration(),
 		}
 
-		databaseSchema := "eveauth"
-		if len(os.Getenv("DATABASE_SCHEMA")) > 0 {
-			databaseSchema = os.Getenv("DATABASE_SCHEMA")
+		err := db.Connect()
+		if err != nil {
+			return nil, err
 		}
 
-		databaseUser := "eveauth"
-		if len(os.Getenv("DATABASE_USER")) > 0 {
-			databaseUser = os.Getenv("DATABASE_USER")
-		}
+		testDatabase = db
+	}
 
-		databasePassword := "eveauth"
-		if len(os.Getenv("DATABASE_PASSWORD")) > 0 {
-			databasePassword = os.Getenv("DATABASE_PASSWORD")
-		}
+	return testDatabase, nil
+}
 
-		config := &misc.Configuration{
-			DatabaseType:     1,
-			DatabaseHost:     databaseHost,
-			DatabaseSchema:   databaseSchema,
-			DatabaseUser:     databaseUser,
-			DatabasePassword: databasePassword,
-			DebugLevel:       1,
-			HTTPHost:         "localhost:5000",
-		}
+// createConformanceConnection returns the MySQL database used by the conformance suite, reverting and reapplying all migrations to start with an empty schema
+func createConformanceConnection() (database.Connection, error) {
+	if conformanceDatabase == nil {
+		config := testConfiguration()
+		config.DatabaseSchema = os.Getenv("CONFORMANCE_DATABASE_SCHEMA")
+		config.DatabaseAutoMigrate = true
 
 		db := &DatabaseConnection{
 			Config: config,
@@ -57,10 +88,31 @@ func createMySQLConnection() (*DatabaseConnection, error) {
 			return nil, err
 		}
 
-		database = db
+		conformanceDatabase = db
+	}
+
+	for {
+		status, err := conformanceDatabase.MigrationStatus()
+		if err != nil {
+			return nil, err
+		}
+
+		if status.Version == 0 {
+			break
+		}
+
+		_, err = conformanceDatabase.MigrateDown()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return database, nil
+	_, err := conformanceDatabase.MigrateUp()
+	if err != nil {
+		return nil, err
+	}
+
+	return conformanceDatabase, nil
 }
 
 func TestDatabaseConnectionConnect(t *testing.T) {
@@ -103,8 +155,10 @@ func TestDatabaseConnectionInvalidConnect(t *testing.T) {
 	})
 }
 
-func TestDatabaseConnectionRawQuery(t *testing.T) {
-	Convey("Performing a raw query at a MySQL database", t, func() {
+func TestDatabaseConnectionRunReport(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Running a report at a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
 		Convey("The returned error should be nil", func() {
@@ -115,36 +169,33 @@ func TestDatabaseConnectionRawQuery(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		Convey("Performing a raw query of the users table", func() {
-			result, err := db.RawQuery("SELECT * FROM users;")
+		Convey("Running the corporation members report", func() {
+			result, err := db.RunReport(ctx, database.ReportCorporationMembers, nil)
 
 			Convey("The returned error should be nil", func() {
 				So(err, ShouldBeNil)
 			})
 
-			Convey("The returned map should not be nil", func() {
+			Convey("The returned result should not be nil", func() {
 				So(result, ShouldNotBeNil)
 			})
 
-			Convey("The returned map should have 4 entries", func() {
-				So(len(result), ShouldBeGreaterThan, 0)
-				So(len(result), ShouldEqual, 4)
+			Convey("The returned result should have 2 rows", func() {
+				So(len(result.Rows), ShouldEqual, 2)
 			})
 
-			Convey("Iterating over the result map", func() {
-				for key, value := range result {
-					Convey(fmt.Sprintf("The raw data table for key %v should have 6 entries", key), func() {
-						So(len(value), ShouldBeGreaterThan, 0)
-						So(len(value), ShouldEqual, 6)
-					})
-				}
+			Convey("The returned rows should contain typed values", func() {
+				So(result.Rows[0], ShouldResemble, []interface{}{"Corp Test Ignore Please", "CORP", int64(2), int64(3)})
+				So(result.Rows[1], ShouldResemble, []interface{}{"Test Corp Please Ignore", "TEST", int64(2), int64(3)})
 			})
 		})
 	})
 }
 
-func TestDatabaseConnectionRawInvalidQuery(t *testing.T) {
-	Convey("Performing a raw invalid query at a MySQL database", t, func() {
+func TestDatabaseConnectionRunInvalidReport(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Running an invalid report at a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
 		Convey("The returned error should be nil", func() {
@@ -155,14 +206,26 @@ func TestDatabaseConnectionRawInvalidQuery(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		Convey("Performing a raw invalid query of the users table", func() {
-			result, err := db.RawQuery("SELECT nonexistent FROM users;")
+		Convey("Running a nonexistent report", func() {
+			result, err := db.RunReport(ctx, "nonexistent", nil)
+
+			Convey("The returned error should not be nil", func() {
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("The returned result should be nil", func() {
+				So(result, ShouldBeNil)
+			})
+		})
+
+		Convey("Running a report with an invalid parameter", func() {
+			result, err := db.RunReport(ctx, database.ReportLoginAttempts, map[string]string{"days": "never"})
 
 			Convey("The returned error should not be nil", func() {
 				So(err, ShouldNotBeNil)
 			})
 
-			Convey("The returned map should be nil", func() {
+			Convey("The returned result should be nil", func() {
 				So(result, ShouldBeNil)
 			})
 		})
@@ -170,6 +233,8 @@ func TestDatabaseConnectionRawInvalidQuery(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllAccounts(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all accounts from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -181,7 +246,7 @@ func TestDatabaseConnectionLoadAllAccounts(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		accounts, err := db.LoadAllAccounts()
+		accounts, err := db.LoadAllAccounts(ctx)
 
 		Convey("Loading all accounts should return no error", func() {
 			So(err, ShouldBeNil)
@@ -207,6 +272,8 @@ func TestDatabaseConnectionLoadAllAccounts(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllCorporations(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all corporations from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -218,7 +285,7 @@ func TestDatabaseConnectionLoadAllCorporations(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		corporations, err := db.LoadAllCorporations()
+		corporations, err := db.LoadAllCorporations(ctx)
 
 		Convey("Loading all corporations should return no error", func() {
 			So(err, ShouldBeNil)
@@ -244,6 +311,8 @@ func TestDatabaseConnectionLoadAllCorporations(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllCharacters(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all characters from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -255,7 +324,7 @@ func TestDatabaseConnectionLoadAllCharacters(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		characters, err := db.LoadAllCharacters()
+		characters, err := db.LoadAllCharacters(ctx)
 
 		Convey("Loading all characters should return no error", func() {
 			So(err, ShouldBeNil)
@@ -281,6 +350,8 @@ func TestDatabaseConnectionLoadAllCharacters(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllRoles(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all roles from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -292,7 +363,7 @@ func TestDatabaseConnectionLoadAllRoles(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		roles, err := db.LoadAllRoles()
+		roles, err := db.LoadAllRoles(ctx)
 
 		Convey("Loading all roles should return no error", func() {
 			So(err, ShouldBeNil)
@@ -318,6 +389,8 @@ func TestDatabaseConnectionLoadAllRoles(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllGroupRoles(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all group roles from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -329,7 +402,7 @@ func TestDatabaseConnectionLoadAllGroupRoles(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		groupRoles, err := db.LoadAllGroupRoles()
+		groupRoles, err := db.LoadAllGroupRoles(ctx)
 
 		Convey("Loading all group roles should return no error", func() {
 			So(err, ShouldBeNil)
@@ -355,6 +428,8 @@ func TestDatabaseConnectionLoadAllGroupRoles(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllUserRoles(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all user roles from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -366,7 +441,7 @@ func TestDatabaseConnectionLoadAllUserRoles(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		userRoles, err := db.LoadAllUserRoles()
+		userRoles, err := db.LoadAllUserRoles(ctx)
 
 		Convey("Loading all user roles should return no error", func() {
 			So(err, ShouldBeNil)
@@ -392,6 +467,8 @@ func TestDatabaseConnectionLoadAllUserRoles(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllGroups(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all groups from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -403,7 +480,7 @@ func TestDatabaseConnectionLoadAllGroups(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		groups, err := db.LoadAllGroups()
+		groups, err := db.LoadAllGroups(ctx)
 
 		Convey("Loading all groups should return no error", func() {
 			So(err, ShouldBeNil)
@@ -429,6 +506,8 @@ func TestDatabaseConnectionLoadAllGroups(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllUsers(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all users from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -440,7 +519,7 @@ func TestDatabaseConnectionLoadAllUsers(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		users, err := db.LoadAllUsers()
+		users, err := db.LoadAllUsers(ctx)
 
 		Convey("Loading all users should return no error", func() {
 			So(err, ShouldBeNil)
@@ -466,6 +545,8 @@ func TestDatabaseConnectionLoadAllUsers(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllApplications(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all applications from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -477,7 +558,7 @@ func TestDatabaseConnectionLoadAllApplications(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		applications, err := db.LoadAllApplications()
+		applications, err := db.LoadAllApplications(ctx)
 
 		Convey("Loading all applications should return no error", func() {
 			So(err, ShouldBeNil)
@@ -503,6 +584,8 @@ func TestDatabaseConnectionLoadAllApplications(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAccount(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading account #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -514,7 +597,7 @@ func TestDatabaseConnectionLoadAccount(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		account, err := db.LoadAccount(1)
+		account, err := db.LoadAccount(ctx, 1)
 
 		Convey("Loading account #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -533,6 +616,8 @@ func TestDatabaseConnectionLoadAccount(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadCorporation(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading corporation #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -544,7 +629,7 @@ func TestDatabaseConnectionLoadCorporation(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		corporation, err := db.LoadCorporation(1)
+		corporation, err := db.LoadCorporation(ctx, 1)
 
 		Convey("Loading corporation #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -563,6 +648,8 @@ func TestDatabaseConnectionLoadCorporation(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadCorporationFromEVECorporationID(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading corporation with EVE corporation ID #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -574,7 +661,7 @@ func TestDatabaseConnectionLoadCorporationFromEVECorporationID(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		corporation, err := db.LoadCorporationFromEVECorporationID(1)
+		corporation, err := db.LoadCorporationFromEVECorporationID(ctx, 1)
 
 		Convey("Loading corporation with EVE corporation ID #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -593,6 +680,8 @@ func TestDatabaseConnectionLoadCorporationFromEVECorporationID(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadCorporationNameFromID(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading corporation name for corporation ID #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -604,7 +693,7 @@ func TestDatabaseConnectionLoadCorporationNameFromID(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		name, err := db.LoadCorporationNameFromID(1)
+		name, err := db.LoadCorporationNameFromID(ctx, 1)
 
 		Convey("Loading corporation name for corporation ID #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -625,6 +714,8 @@ func TestDatabaseConnectionLoadCorporationNameFromID(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadCharacter(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading character #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -636,7 +727,7 @@ func TestDatabaseConnectionLoadCharacter(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		character, err := db.LoadCharacter(1)
+		character, err := db.LoadCharacter(ctx, 1)
 
 		Convey("Loading character #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -655,6 +746,8 @@ func TestDatabaseConnectionLoadCharacter(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadRole(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading role #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -666,7 +759,7 @@ func TestDatabaseConnectionLoadRole(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		role, err := db.LoadRole(1)
+		role, err := db.LoadRole(ctx, 1)
 
 		Convey("Loading role #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -685,6 +778,8 @@ func TestDatabaseConnectionLoadRole(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadGroupRole(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading group role #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -696,7 +791,7 @@ func TestDatabaseConnectionLoadGroupRole(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		groupRole, err := db.LoadGroupRole(1)
+		groupRole, err := db.LoadGroupRole(ctx, 1)
 
 		Convey("Loading group role #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -715,6 +810,8 @@ func TestDatabaseConnectionLoadGroupRole(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadUserRole(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading user role #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -726,7 +823,7 @@ func TestDatabaseConnectionLoadUserRole(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		userRole, err := db.LoadUserRole(1)
+		userRole, err := db.LoadUserRole(ctx, 1)
 
 		Convey("Loading user role #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -745,6 +842,8 @@ func TestDatabaseConnectionLoadUserRole(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadGroup(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading group #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -756,7 +855,7 @@ func TestDatabaseConnectionLoadGroup(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		group, err := db.LoadGroup(1)
+		group, err := db.LoadGroup(ctx, 1)
 
 		Convey("Loading group #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -775,6 +874,8 @@ func TestDatabaseConnectionLoadGroup(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadUser(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading user #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -786,7 +887,7 @@ func TestDatabaseConnectionLoadUser(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		user, err := db.LoadUser(1)
+		user, err := db.LoadUser(ctx, 1)
 
 		Convey("Loading user #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -805,6 +906,8 @@ func TestDatabaseConnectionLoadUser(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadUserFromUsername(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading user with name test1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -816,7 +919,7 @@ func TestDatabaseConnectionLoadUserFromUsername(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		user, err := db.LoadUserFromUsername("test1")
+		user, err := db.LoadUserFromUsername(ctx, "test1")
 
 		Convey("Loading user with name test1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -835,6 +938,8 @@ func TestDatabaseConnectionLoadUserFromUsername(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadApplication(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading application #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -846,7 +951,7 @@ func TestDatabaseConnectionLoadApplication(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		application, err := db.LoadApplication(1)
+		application, err := db.LoadApplication(ctx, 1)
 
 		Convey("Loading application #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -865,6 +970,8 @@ func TestDatabaseConnectionLoadApplication(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAvailableGroupsForUser(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading available groups for user #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -876,7 +983,7 @@ func TestDatabaseConnectionLoadAvailableGroupsForUser(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		groups, err := db.LoadAvailableGroupsForUser(1)
+		groups, err := db.LoadAvailableGroupsForUser(ctx, 1)
 
 		Convey("Loading available groups for user #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -895,6 +1002,8 @@ func TestDatabaseConnectionLoadAvailableGroupsForUser(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAvailableUserRolesForUser(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading available user roles for user #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -906,7 +1015,7 @@ func TestDatabaseConnectionLoadAvailableUserRolesForUser(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		roles, err := db.LoadAvailableUserRolesForUser(1)
+		roles, err := db.LoadAvailableUserRolesForUser(ctx, 1)
 
 		Convey("Loading available user roles for user #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -929,6 +1038,8 @@ func TestDatabaseConnectionLoadAvailableUserRolesForUser(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAvailableGroupRolesForGroup(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading available group roles for group #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -940,7 +1051,7 @@ func TestDatabaseConnectionLoadAvailableGroupRolesForGroup(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		roles, err := db.LoadAvailableGroupRolesForGroup(1)
+		roles, err := db.LoadAvailableGroupRolesForGroup(ctx, 1)
 
 		Convey("Loading available group roles for group #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -962,6 +1073,8 @@ func TestDatabaseConnectionLoadAvailableGroupRolesForGroup(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadAllApplicationsForUser(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading all applications for user #1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -973,7 +1086,7 @@ func TestDatabaseConnectionLoadAllApplicationsForUser(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		applications, err := db.LoadAllApplicationsForUser(1)
+		applications, err := db.LoadAllApplicationsForUser(ctx, 1)
 
 		Convey("Loading all applications for user #1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -992,6 +1105,8 @@ func TestDatabaseConnectionLoadAllApplicationsForUser(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadPasswordForUser(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading password for user test1 from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1003,7 +1118,7 @@ func TestDatabaseConnectionLoadPasswordForUser(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		password, err := db.LoadPasswordForUser("test1")
+		password, err := db.LoadPasswordForUser(ctx, "test1")
 
 		Convey("Loading password for user test1 should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1023,6 +1138,8 @@ func TestDatabaseConnectionLoadPasswordForUser(t *testing.T) {
 }
 
 func TestDatabaseConnectionQueryUserIDExists(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Querying whether a user ID exists in a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1034,7 +1151,7 @@ func TestDatabaseConnectionQueryUserIDExists(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		exists, err := db.QueryUserIDExists(1)
+		exists, err := db.QueryUserIDExists(ctx, 1)
 
 		Convey("Querying whether user ID #1 exists should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1044,7 +1161,7 @@ func TestDatabaseConnectionQueryUserIDExists(t *testing.T) {
 			})
 		})
 
-		exists, err = db.QueryUserIDExists(-1)
+		exists, err = db.QueryUserIDExists(ctx, -1)
 
 		Convey("Querying whether user ID #-1 exists should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1057,6 +1174,8 @@ func TestDatabaseConnectionQueryUserIDExists(t *testing.T) {
 }
 
 func TestDatabaseConnectionQueryUserNameEmailExists(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Querying whether a username or email exists in a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1068,7 +1187,7 @@ func TestDatabaseConnectionQueryUserNameEmailExists(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		exists, err := db.QueryUserNameEmailExists("test1", "test1@example.com")
+		exists, err := db.QueryUserNameEmailExists(ctx, "test1", "test1@example.com")
 
 		Convey("Querying whether username test1 or email test1@example.com exists should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1078,7 +1197,7 @@ func TestDatabaseConnectionQueryUserNameEmailExists(t *testing.T) {
 			})
 		})
 
-		exists, err = db.QueryUserNameEmailExists("test1", "does.not@exist.com")
+		exists, err = db.QueryUserNameEmailExists(ctx, "test1", "does.not@exist.com")
 
 		Convey("Querying whether username test1 or email does.not@exist.com exists should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1088,7 +1207,7 @@ func TestDatabaseConnectionQueryUserNameEmailExists(t *testing.T) {
 			})
 		})
 
-		exists, err = db.QueryUserNameEmailExists("does.not.exist", "test1@example.com")
+		exists, err = db.QueryUserNameEmailExists(ctx, "does.not.exist", "test1@example.com")
 
 		Convey("Querying whether username does.not.exist or email test1@example.com exists should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1098,7 +1217,7 @@ func TestDatabaseConnectionQueryUserNameEmailExists(t *testing.T) {
 			})
 		})
 
-		exists, err = db.QueryUserNameEmailExists("does.not.exist", "does.not@exist.com")
+		exists, err = db.QueryUserNameEmailExists(ctx, "does.not.exist", "does.not@exist.com")
 
 		Convey("Querying whether username does.not.exist or email does.not@exist.com exists should return no error", func() {
 			So(err, ShouldBeNil)
@@ -1111,6 +1230,8 @@ func TestDatabaseConnectionQueryUserNameEmailExists(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidAccount(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid account from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1122,7 +1243,7 @@ func TestDatabaseConnectionLoadInvalidAccount(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		account, err := db.LoadAccount(-1)
+		account, err := db.LoadAccount(ctx, -1)
 
 		Convey("Loading an invalid account should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1135,6 +1256,8 @@ func TestDatabaseConnectionLoadInvalidAccount(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidCorporation(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid corporation from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1146,7 +1269,7 @@ func TestDatabaseConnectionLoadInvalidCorporation(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		corporation, err := db.LoadCorporation(-1)
+		corporation, err := db.LoadCorporation(ctx, -1)
 
 		Convey("Loading an invalid corporation should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1159,6 +1282,8 @@ func TestDatabaseConnectionLoadInvalidCorporation(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidCharacter(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid character from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1170,7 +1295,7 @@ func TestDatabaseConnectionLoadInvalidCharacter(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		character, err := db.LoadCharacter(-1)
+		character, err := db.LoadCharacter(ctx, -1)
 
 		Convey("Loading an invalid character should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1183,6 +1308,8 @@ func TestDatabaseConnectionLoadInvalidCharacter(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidRole(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid role from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1194,7 +1321,7 @@ func TestDatabaseConnectionLoadInvalidRole(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		role, err := db.LoadRole(-1)
+		role, err := db.LoadRole(ctx, -1)
 
 		Convey("Loading an role should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1207,6 +1334,8 @@ func TestDatabaseConnectionLoadInvalidRole(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidGroupRole(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid group role from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1218,7 +1347,7 @@ func TestDatabaseConnectionLoadInvalidGroupRole(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		groupRole, err := db.LoadGroupRole(-1)
+		groupRole, err := db.LoadGroupRole(ctx, -1)
 
 		Convey("Loading an invalid group role should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1231,6 +1360,8 @@ func TestDatabaseConnectionLoadInvalidGroupRole(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidUserRole(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid user role from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1242,7 +1373,7 @@ func TestDatabaseConnectionLoadInvalidUserRole(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		userRole, err := db.LoadUserRole(-1)
+		userRole, err := db.LoadUserRole(ctx, -1)
 
 		Convey("Loading an invalid user role should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1255,6 +1386,8 @@ func TestDatabaseConnectionLoadInvalidUserRole(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidGroup(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid group from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1266,7 +1399,7 @@ func TestDatabaseConnectionLoadInvalidGroup(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		group, err := db.LoadGroup(-1)
+		group, err := db.LoadGroup(ctx, -1)
 
 		Convey("Loading an invalid group should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1279,6 +1412,8 @@ func TestDatabaseConnectionLoadInvalidGroup(t *testing.T) {
 }
 
 func TestDatabaseConnectionLoadInvalidUser(t *testing.T) {
+	ctx := context.Background()
+
 	Convey("Loading invalid user from a MySQL database", t, func() {
 		db, err := createMySQLConnection()
 
@@ -1290,7 +1425,7 @@ func TestDatabaseConnectionLoadInvalidUser(t *testing.T) {
 			So(db, ShouldNotBeNil)
 		})
 
-		user, err := db.LoadUser(-1)
+		user, err := db.LoadUser(ctx, -1)
 
 		Convey("Loading an invalid user should return an error", func() {
 			So(err, ShouldNotBeNil)
@@ -1376,6 +1511,7 @@ var (
 			Secret:       "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
 			Callback:     "http://localhost/callback",
 			Active:       true,
+			Version:      1,
 		},
 		2: &models.Application{
 			ID:           2,
@@ -1384,6 +1520,7 @@ var (
 			Secret:       "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
 			Callback:     "http://example.com/callback",
 			Active:       false,
+			Version:      1,
 		},
 	}
 
@@ -1469,28 +1606,32 @@ var (
 
 	testRoles = map[int]*models.Role{
 		1: &models.Role{
-			ID:     1,
-			Name:   "ping.all",
-			Active: true,
-			Locked: false,
+			ID:      1,
+			Name:    "ping.all",
+			Active:  true,
+			Locked:  false,
+			Version: 1,
 		},
 		2: &models.Role{
-			ID:     2,
-			Name:   "destroy.world",
-			Active: false,
-			Locked: true,
+			ID:      2,
+			Name:    "destroy.world",
+			Active:  false,
+			Locked:  true,
+			Version: 1,
 		},
 		3: &models.Role{
-			ID:     3,
-			Name:   "logistics.read",
-			Active: true,
-			Locked: false,
+			ID:      3,
+			Name:    "logistics.read",
+			Active:  true,
+			Locked:  false,
+			Version: 1,
 		},
 		4: &models.Role{
-			ID:     4,
-			Name:   "logistics.write",
-			Active: true,
-			Locked: false,
+			ID:      4,
+			Name:    "logistics.write",
+			Active:  true,
+			Locked:  false,
+			Version: 1,
 		},
 	}
 
@@ -1544,18 +1685,22 @@ var (
 
 	testGroups = map[int]*models.Group{
 		1: &models.Group{
-			ID:     1,
-			Name:   "Test Group",
-			Active: true,
+			ID:         1,
+			Name:       "Test Group",
+			Active:     true,
+			Version:    1,
+			Visibility: models.GroupVisibilityHidden,
 			GroupRoles: []*models.GroupRole{
 				testGroupRoles[1],
 				testGroupRoles[2],
 			},
 		},
 		2: &models.Group{
-			ID:     2,
-			Name:   "Dank Access",
-			Active: false,
+			ID:         2,
+			Name:       "Dank Access",
+			Active:     false,
+			Version:    1,
+			Visibility: models.GroupVisibilityHidden,
 			GroupRoles: []*models.GroupRole{
 				testGroupRoles[3],
 				testGroupRoles[4],
@@ -1571,6 +1716,7 @@ var (
 			Email:         "test1@example.com",
 			VerifiedEmail: true,
 			Active:        true,
+			Version:       1,
 			Accounts: []*models.Account{
 				testAccounts[1],
 			},
@@ -1580,6 +1726,7 @@ var (
 			Groups: []*models.Group{
 				testGroups[1],
 			},
+			DefaultCorporation: testCorporations[1],
 		},
 		2: &models.User{
 			ID:            2,
@@ -1588,11 +1735,13 @@ var (
 			Email:         "test2@example.com",
 			VerifiedEmail: false,
 			Active:        false,
+			Version:       1,
 			Accounts: []*models.Account{
 				testAccounts[2],
 			},
-			UserRoles: []*models.UserRole{},
-			Groups:    []*models.Group{},
+			UserRoles:          []*models.UserRole{},
+			Groups:             []*models.Group{},
+			DefaultCorporation: testCorporations[2],
 		},
 		3: &models.User{
 			ID:            3,
@@ -1601,6 +1750,7 @@ var (
 			Email:         "test3@example.com",
 			VerifiedEmail: false,
 			Active:        true,
+			Version:       1,
 			Accounts: []*models.Account{
 				testAccounts[3],
 				testAccounts[4],
@@ -1612,6 +1762,7 @@ var (
 				testGroups[1],
 				testGroups[2],
 			},
+			DefaultCorporation: testCorporations[1],
 		},
 		4: &models.User{
 			ID:            4,
@@ -1620,6 +1771,7 @@ var (
 			Email:         "test4@example.com",
 			VerifiedEmail: true,
 			Active:        false,
+			Version:       1,
 			Accounts: []*models.Account{
 				testAccounts[5],
 				testAccounts[6],
@@ -1629,3 +1781,141 @@ var (
 		},
 	}
 )
+
+func TestDatabaseConnectionQuery(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Querying pages of entries from a MySQL database", t, func() {
+		db, err := createMySQLConnection()
+		So(err, ShouldBeNil)
+
+		Convey("Querying users with the default criteria should return all users", func() {
+			users, total, err := db.QueryUsers(ctx, database.NewListCriteria())
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(len(users), ShouldEqual, 4)
+			So(users[0].ID, ShouldEqual, 1)
+			So(users[0].Accounts, ShouldNotBeEmpty)
+		})
+
+		Convey("Querying users with a text filter should only return matching users", func() {
+			criteria := database.NewListCriteria()
+			criteria.Filter = "TEST1@"
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(users[0].Username, ShouldEqual, "test1")
+		})
+
+		Convey("Querying users by active state, group and corporation should apply all filters", func() {
+			criteria := database.NewListCriteria()
+			criteria.Active = database.ActiveFilterActive
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(users[1].ID, ShouldEqual, 3)
+
+			criteria = database.NewListCriteria()
+			criteria.GroupID = 2
+
+			users, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(users[0].ID, ShouldEqual, 3)
+
+			criteria = database.NewListCriteria()
+			criteria.CorporationID = 2
+			criteria.Active = database.ActiveFilterInactive
+
+			users, total, err = db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(users[0].ID, ShouldEqual, 2)
+		})
+
+		Convey("Querying users sorted by username in descending order should return the requested page", func() {
+			criteria := database.NewListCriteria()
+			criteria.SortField = "username"
+			criteria.SortDirection = database.SortDescending
+			criteria.Limit = 2
+			criteria.Offset = 1
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(len(users), ShouldEqual, 2)
+			So(users[0].Username, ShouldEqual, "test3")
+			So(users[1].Username, ShouldEqual, "test2")
+		})
+
+		Convey("Querying users past the last page should return no users but the total count", func() {
+			criteria := database.NewListCriteria()
+			criteria.Offset = 10
+
+			users, total, err := db.QueryUsers(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(users, ShouldBeNil)
+		})
+
+		Convey("Querying groups sorted by an unsupported field should fall back to the ID", func() {
+			criteria := database.NewListCriteria()
+			criteria.SortField = "password"
+
+			groups, total, err := db.QueryGroups(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(groups[0].ID, ShouldEqual, 1)
+			So(len(groups[0].GroupRoles), ShouldEqual, 2)
+
+			criteria.SortField = "name"
+
+			groups, total, err = db.QueryGroups(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(groups[0].Name, ShouldEqual, "Dank Access")
+		})
+
+		Convey("Querying roles should apply the filters and sorting", func() {
+			criteria := database.NewListCriteria()
+			criteria.Filter = "logistics"
+			criteria.SortField = "name"
+			criteria.SortDirection = database.SortDescending
+
+			roles, total, err := db.QueryRoles(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(roles[0].Name, ShouldEqual, "logistics.write")
+
+			criteria = database.NewListCriteria()
+			criteria.SortField = "locked"
+			criteria.SortDirection = database.SortDescending
+
+			roles, total, err = db.QueryRoles(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(roles[0].Name, ShouldEqual, "destroy.world")
+			So(roles[1].ID, ShouldEqual, 1)
+		})
+
+		Convey("Querying applications should apply the filters", func() {
+			criteria := database.NewListCriteria()
+			criteria.Filter = "app"
+			criteria.Active = database.ActiveFilterInactive
+
+			applications, total, err := db.QueryApplications(ctx, criteria)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(applications[0].Name, ShouldEqual, "Apptest")
+		})
+	})
+}
+
+func TestDatabaseConnectionConformance(t *testing.T) {
+	if len(os.Getenv("CONFORMANCE_DATABASE_SCHEMA")) == 0 {
+		t.Skip("CONFORMANCE_DATABASE_SCHEMA is not set, the conformance suite drops all tables of the schema it runs against")
+	}
+
+	conformancetest.Run(t, createConformanceConnection)
+}
@@ -0,0 +1,396 @@
+package mysql
+
+import (
+	"github.com/morpheusxaut/eveauth/database/migration"
+)
+
+// migrations stores all schema migrations for the MySQL database in the order they have to be applied
+var migrations = []*migration.Migration{
+	&migration.Migration{
+		Version:     1,
+		Description: "Create initial schema",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS users (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  username varchar(32) NOT NULL,
+  password varchar(60) NOT NULL,
+  email varchar(128) NOT NULL,
+  verifiedemail tinyint(1) NOT NULL DEFAULT '0',
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY username (username)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS corporations (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  ticker varchar(5) NOT NULL,
+  evecorporationid int(16) NOT NULL,
+  ceoid int(16) NOT NULL,
+  apikeyid int(11) DEFAULT NULL,
+  apivcode varchar(64) DEFAULT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY name (name),
+  UNIQUE KEY ticker (ticker),
+  UNIQUE KEY evecorporationid (evecorporationid),
+  UNIQUE KEY apikeyid (apikeyid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS groups (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS roles (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  locked tinyint(1) NOT NULL DEFAULT '0',
+  PRIMARY KEY (id),
+  UNIQUE KEY name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS accounts (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  userid int(11) NOT NULL,
+  apikeyid int(11) NOT NULL,
+  apivcode varchar(64) NOT NULL,
+  apiaccessmask int(9) NOT NULL DEFAULT '0',
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY keyid (apikeyid),
+  KEY fk_userapikeys_user (userid),
+  CONSTRAINT fk_userapikeys_user FOREIGN KEY (userid) REFERENCES users (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS applications (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  maintainerid int(11) NOT NULL,
+  secret varchar(32) NOT NULL,
+  callback varchar(128) NOT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY name (name),
+  UNIQUE KEY secret (secret),
+  KEY fk_applications_maintainer (maintainerid),
+  CONSTRAINT fk_applications_maintainer FOREIGN KEY (maintainerid) REFERENCES users (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS characters (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  accountid int(11) NOT NULL,
+  corporationid int(11) NOT NULL,
+  name varchar(64) NOT NULL,
+  evecharacterid int(11) NOT NULL,
+  defaultcharacter tinyint(1) NOT NULL DEFAULT '0',
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY name (name),
+  UNIQUE KEY evecharacterid (evecharacterid),
+  KEY corporationid (corporationid),
+  KEY fk_characters_account (accountid),
+  CONSTRAINT fk_characters_account FOREIGN KEY (accountid) REFERENCES accounts (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_characters_corporation FOREIGN KEY (corporationid) REFERENCES corporations (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS csrffailures (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  userid int(11) NOT NULL,
+  request text NOT NULL,
+  timestamp timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS grouproles (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  groupid int(11) NOT NULL,
+  roleid int(11) NOT NULL,
+  autoadded tinyint(1) NOT NULL DEFAULT '1',
+  granted tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY groupid_roleid (groupid,roleid),
+  KEY fk_grouproles_group (groupid),
+  KEY fk_grouproles_role (roleid),
+  CONSTRAINT fk_grouproles_group FOREIGN KEY (groupid) REFERENCES groups (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_grouproles_role FOREIGN KEY (roleid) REFERENCES roles (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS loginattempts (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  username varchar(64) NOT NULL,
+  remoteaddr varchar(64) NOT NULL,
+  useragent varchar(256) NOT NULL,
+  successful tinyint(1) NOT NULL,
+  timestamp timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS usergroups (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  userid int(11) NOT NULL,
+  groupid int(11) NOT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY userid_groupid (userid,groupid),
+  KEY fk_usergroups_user (userid),
+  KEY fk_usergroups_group (groupid),
+  CONSTRAINT fk_usergroups_group FOREIGN KEY (groupid) REFERENCES groups (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_usergroups_user FOREIGN KEY (userid) REFERENCES users (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS userroles (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  userid int(11) NOT NULL,
+  roleid int(11) NOT NULL,
+  autoadded tinyint(1) NOT NULL DEFAULT '1',
+  granted tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY userid_roleid (userid,roleid),
+  KEY fk_userroles_user (userid),
+  KEY fk_userroles_role (roleid),
+  CONSTRAINT fk_userroles_role FOREIGN KEY (roleid) REFERENCES roles (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_userroles_user FOREIGN KEY (userid) REFERENCES users (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS userroles",
+			"DROP TABLE IF EXISTS usergroups",
+			"DROP TABLE IF EXISTS loginattempts",
+			"DROP TABLE IF EXISTS grouproles",
+			"DROP TABLE IF EXISTS csrffailures",
+			"DROP TABLE IF EXISTS characters",
+			"DROP TABLE IF EXISTS applications",
+			"DROP TABLE IF EXISTS accounts",
+			"DROP TABLE IF EXISTS roles",
+			"DROP TABLE IF EXISTS groups",
+			"DROP TABLE IF EXISTS corporations",
+			"DROP TABLE IF EXISTS users",
+		},
+	},
+	&migration.Migration{
+		Version:     2,
+		Description: "Add soft deletion of users, groups, roles and applications",
+		Up: []string{
+			"ALTER TABLE users ADD COLUMN deletedat timestamp NULL DEFAULT NULL, ADD COLUMN deletedby int(11) DEFAULT NULL, ADD KEY deletedat (deletedat)",
+			"ALTER TABLE groups ADD COLUMN deletedat timestamp NULL DEFAULT NULL, ADD COLUMN deletedby int(11) DEFAULT NULL, ADD KEY deletedat (deletedat)",
+			"ALTER TABLE roles ADD COLUMN deletedat timestamp NULL DEFAULT NULL, ADD COLUMN deletedby int(11) DEFAULT NULL, ADD KEY deletedat (deletedat)",
+			"ALTER TABLE applications ADD COLUMN deletedat timestamp NULL DEFAULT NULL, ADD COLUMN deletedby int(11) DEFAULT NULL, ADD KEY deletedat (deletedat)",
+		},
+		Down: []string{
+			"ALTER TABLE applications DROP KEY deletedat, DROP COLUMN deletedby, DROP COLUMN deletedat",
+			"ALTER TABLE roles DROP KEY deletedat, DROP COLUMN deletedby, DROP COLUMN deletedat",
+			"ALTER TABLE groups DROP KEY deletedat, DROP COLUMN deletedby, DROP COLUMN deletedat",
+			"ALTER TABLE users DROP KEY deletedat, DROP COLUMN deletedby, DROP COLUMN deletedat",
+		},
+	},
+	&migration.Migration{
+		Version:     3,
+		Description: "Add versions of users, groups, roles and applications for optimistic concurrency control",
+		Up: []string{
+			"ALTER TABLE users ADD COLUMN version int(11) NOT NULL DEFAULT 1",
+			"ALTER TABLE groups ADD COLUMN version int(11) NOT NULL DEFAULT 1",
+			"ALTER TABLE roles ADD COLUMN version int(11) NOT NULL DEFAULT 1",
+			"ALTER TABLE applications ADD COLUMN version int(11) NOT NULL DEFAULT 1",
+		},
+		Down: []string{
+			"ALTER TABLE applications DROP COLUMN version",
+			"ALTER TABLE roles DROP COLUMN version",
+			"ALTER TABLE groups DROP COLUMN version",
+			"ALTER TABLE users DROP COLUMN version",
+		},
+	},
+	&migration.Migration{
+		Version:     4,
+		Description: "Add alliances and link corporations to them",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS alliances (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  ticker varchar(5) NOT NULL,
+  eveallianceid int(16) NOT NULL,
+  executorcorpid int(16) NOT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  PRIMARY KEY (id),
+  UNIQUE KEY eveallianceid (eveallianceid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			"ALTER TABLE corporations ADD COLUMN allianceid int(11) DEFAULT NULL, ADD KEY fk_corporations_alliance (allianceid), ADD CONSTRAINT fk_corporations_alliance FOREIGN KEY (allianceid) REFERENCES alliances (id) ON UPDATE CASCADE",
+		},
+		Down: []string{
+			"ALTER TABLE corporations DROP FOREIGN KEY fk_corporations_alliance, DROP KEY fk_corporations_alliance, DROP COLUMN allianceid",
+			"DROP TABLE IF EXISTS alliances",
+		},
+	},
+	&migration.Migration{
+		Version:     5,
+		Description: "Add daily summaries of pruned login attempts",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS loginattemptsummaries (
+  day char(10) NOT NULL,
+  failed int(11) NOT NULL DEFAULT '0',
+  successful int(11) NOT NULL DEFAULT '0',
+  PRIMARY KEY (day)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS loginattemptsummaries",
+		},
+	},
+	&migration.Migration{
+		Version:     6,
+		Description: "Add role implications",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS roleimplications (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  roleid int(11) NOT NULL,
+  impliedroleid int(11) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY roleid_impliedroleid (roleid,impliedroleid),
+  KEY fk_roleimplications_role (roleid),
+  KEY fk_roleimplications_impliedrole (impliedroleid),
+  CONSTRAINT fk_roleimplications_impliedrole FOREIGN KEY (impliedroleid) REFERENCES roles (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_roleimplications_role FOREIGN KEY (roleid) REFERENCES roles (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS roleimplications",
+		},
+	},
+	&migration.Migration{
+		Version:     7,
+		Description: "Add validity windows to user roles and group memberships",
+		Up: []string{
+			"ALTER TABLE userroles ADD COLUMN validfrom timestamp NULL DEFAULT NULL, ADD COLUMN validuntil timestamp NULL DEFAULT NULL, ADD KEY validuntil (validuntil)",
+			"ALTER TABLE usergroups ADD COLUMN validfrom timestamp NULL DEFAULT NULL, ADD COLUMN validuntil timestamp NULL DEFAULT NULL, ADD KEY validuntil (validuntil)",
+		},
+		Down: []string{
+			"ALTER TABLE usergroups DROP KEY validuntil, DROP COLUMN validuntil, DROP COLUMN validfrom",
+			"ALTER TABLE userroles DROP KEY validuntil, DROP COLUMN validuntil, DROP COLUMN validfrom",
+		},
+	},
+	&migration.Migration{
+		Version:     8,
+		Description: "Add membership rules and mark automatically added group memberships",
+		Up: []string{
+			"ALTER TABLE usergroups ADD COLUMN autoadded tinyint(1) NOT NULL DEFAULT '0'",
+			`CREATE TABLE IF NOT EXISTS membershiprules (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  groupid int(11) NOT NULL,
+  type varchar(32) NOT NULL,
+  targetid int(11) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY groupid_type_targetid (groupid,type,targetid),
+  KEY fk_membershiprules_group (groupid),
+  CONSTRAINT fk_membershiprules_group FOREIGN KEY (groupid) REFERENCES groups (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS membershiprules",
+			"ALTER TABLE usergroups DROP COLUMN autoadded",
+		},
+	},
+	&migration.Migration{
+		Version:     9,
+		Description: "Add group nestings",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS groupnestings (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  groupid int(11) NOT NULL,
+  subgroupid int(11) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY groupid_subgroupid (groupid,subgroupid),
+  KEY fk_groupnestings_group (groupid),
+  KEY fk_groupnestings_subgroup (subgroupid),
+  CONSTRAINT fk_groupnestings_subgroup FOREIGN KEY (subgroupid) REFERENCES groups (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_groupnestings_group FOREIGN KEY (groupid) REFERENCES groups (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS groupnestings",
+		},
+	},
+	&migration.Migration{
+		Version:     10,
+		Description: "Add group managers and record their actions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS groupmanagers (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  groupid int(11) NOT NULL,
+  userid int(11) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY groupid_userid (groupid,userid),
+  KEY fk_groupmanagers_group (groupid),
+  KEY fk_groupmanagers_user (userid),
+  CONSTRAINT fk_groupmanagers_user FOREIGN KEY (userid) REFERENCES users (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_groupmanagers_group FOREIGN KEY (groupid) REFERENCES groups (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+			`CREATE TABLE IF NOT EXISTS manageractions (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  groupid int(11) NOT NULL,
+  actorid int(11) NOT NULL,
+  userid int(11) NOT NULL,
+  type varchar(32) NOT NULL,
+  timestamp timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  KEY manageractions_group (groupid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS manageractions",
+			"DROP TABLE IF EXISTS groupmanagers",
+		},
+	},
+	&migration.Migration{
+		Version:     11,
+		Description: "Add group visibilities and applications for group memberships",
+		Up: []string{
+			"ALTER TABLE groups ADD COLUMN visibility varchar(32) NOT NULL DEFAULT 'hidden'",
+			`CREATE TABLE IF NOT EXISTS groupapplications (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  groupid int(11) NOT NULL,
+  userid int(11) NOT NULL,
+  status varchar(32) NOT NULL DEFAULT 'pending',
+  message text NOT NULL,
+  comment text NOT NULL,
+  deciderid int(11) NOT NULL DEFAULT -1,
+  createdat timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  decidedat timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY fk_groupapplications_group (groupid),
+  KEY fk_groupapplications_user (userid),
+  KEY groupapplications_status (status),
+  CONSTRAINT fk_groupapplications_user FOREIGN KEY (userid) REFERENCES users (id) ON UPDATE CASCADE,
+  CONSTRAINT fk_groupapplications_group FOREIGN KEY (groupid) REFERENCES groups (id) ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS groupapplications",
+			"ALTER TABLE groups DROP COLUMN visibility",
+		},
+	},
+	&migration.Migration{
+		Version:     12,
+		Description: "Add application namespaces to roles and role scopes to applications",
+		Up: []string{
+			"ALTER TABLE roles ADD COLUMN applicationid int(11) DEFAULT NULL, ADD KEY fk_roles_application (applicationid), ADD CONSTRAINT fk_roles_application FOREIGN KEY (applicationid) REFERENCES applications (id) ON UPDATE CASCADE",
+			"ALTER TABLE applications ADD COLUMN rolescopes varchar(1024) NOT NULL DEFAULT ''",
+		},
+		Down: []string{
+			"ALTER TABLE applications DROP COLUMN rolescopes",
+			"ALTER TABLE roles DROP FOREIGN KEY fk_roles_application, DROP KEY fk_roles_application, DROP COLUMN applicationid",
+		},
+	},
+	&migration.Migration{
+		Version:     13,
+		Description: "Add policies to roles",
+		Up: []string{
+			"ALTER TABLE roles ADD COLUMN policy varchar(1024) NOT NULL DEFAULT ''",
+		},
+		Down: []string{
+			"ALTER TABLE roles DROP COLUMN policy",
+		},
+	},
+	&migration.Migration{
+		Version:     14,
+		Description: "Make role names unique within their application namespace instead of globally",
+		Up: []string{
+			"ALTER TABLE roles ADD COLUMN applicationkey int(11) AS (IFNULL(applicationid, 0)) VIRTUAL, DROP KEY name, ADD UNIQUE KEY applicationkey_name (applicationkey, name)",
+		},
+		Down: []string{
+			"ALTER TABLE roles DROP KEY applicationkey_name, DROP COLUMN applicationkey, ADD UNIQUE KEY name (name)",
+		},
+	},
+}
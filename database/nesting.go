@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// NestingCycleError is returned when saving sub groups which would cause a group to contain itself
+type NestingCycleError struct {
+	// GroupID represents the database ID of the group the sub groups were saved for
+	GroupID int64
+	// SubGroupID represents the database ID of the sub group leading back to the group
+	SubGroupID int64
+}
+
+// NewNestingCycleError creates a new cycle error for the group with the given ID and the sub group causing the cycle
+func NewNestingCycleError(groupID int64, subGroupID int64) *NestingCycleError {
+	return &NestingCycleError{
+		GroupID:    groupID,
+		SubGroupID: subGroupID,
+	}
+}
+
+// Error returns a readable description of the cycle
+func (err *NestingCycleError) Error() string {
+	return fmt.Sprintf("The group with ID %d cannot contain the group with ID %d as it would contain itself", err.GroupID, err.SubGroupID)
+}
+
+// IsNestingCycle checks whether the given error was caused by saving cyclic group nestings
+func IsNestingCycle(err error) bool {
+	_, ok := err.(*NestingCycleError)
+	return ok
+}
+
+// CheckSubGroups verifies the group with the given ID can contain the given groups (and all groups nested in them) without containing itself,
+// returning a *NestingCycleError otherwise
+func CheckSubGroups(groupID int64, subGroups []*models.Group) error {
+	for _, subGroup := range subGroups {
+		if reachesGroup(subGroup, groupID, make(map[int64]bool)) {
+			return NewNestingCycleError(groupID, subGroup.ID)
+		}
+	}
+
+	return nil
+}
+
+// reachesGroup checks whether the given group is or contains the group with the given ID, regardless of whether the groups are active
+func reachesGroup(group *models.Group, groupID int64, visited map[int64]bool) bool {
+	if group.ID == groupID {
+		return true
+	}
+
+	if visited[group.ID] {
+		return false
+	}
+
+	visited[group.ID] = true
+
+	for _, subGroup := range group.SubGroups {
+		if reachesGroup(subGroup, groupID, visited) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNestingCycleError(t *testing.T) {
+	Convey("Creating a new nesting cycle error", t, func() {
+		err := NewNestingCycleError(2, 5)
+
+		Convey("The error should describe the cycle", func() {
+			So(err.Error(), ShouldEqual, "The group with ID 2 cannot contain the group with ID 5 as it would contain itself")
+		})
+
+		Convey("The error should be detected as nesting cycle", func() {
+			So(IsNestingCycle(err), ShouldBeTrue)
+		})
+
+		Convey("Other errors should not be detected as nesting cycle", func() {
+			So(IsNestingCycle(errors.New("Something went wrong")), ShouldBeFalse)
+			So(IsNestingCycle(NewImplicationCycleError(2, 5)), ShouldBeFalse)
+			So(IsNestingCycle(nil), ShouldBeFalse)
+		})
+	})
+}
+
+func TestCheckSubGroups(t *testing.T) {
+	Convey("Checking sub groups", t, func() {
+		capitals := &models.Group{ID: 1, Name: "Capitals", Active: true}
+		supers := &models.Group{ID: 2, Name: "Supers", Active: true, SubGroups: []*models.Group{capitals}}
+		inactive := &models.Group{ID: 3, Name: "Titans", Active: false, SubGroups: []*models.Group{supers}}
+		other := &models.Group{ID: 4, Name: "Logistics", Active: true}
+
+		Convey("Containing unrelated groups should be allowed", func() {
+			So(CheckSubGroups(capitals.ID, []*models.Group{other}), ShouldBeNil)
+			So(CheckSubGroups(inactive.ID, []*models.Group{supers, other}), ShouldBeNil)
+		})
+
+		Convey("Containing the group itself should be detected as cycle", func() {
+			err := CheckSubGroups(capitals.ID, []*models.Group{other, capitals})
+			So(IsNestingCycle(err), ShouldBeTrue)
+			So(err.(*NestingCycleError).SubGroupID, ShouldEqual, capitals.ID)
+		})
+
+		Convey("Containing a group containing the group should be detected as cycle", func() {
+			err := CheckSubGroups(capitals.ID, []*models.Group{supers})
+			So(IsNestingCycle(err), ShouldBeTrue)
+			So(err.(*NestingCycleError).SubGroupID, ShouldEqual, supers.ID)
+		})
+
+		Convey("Cycles through inactive groups should be detected as well", func() {
+			err := CheckSubGroups(capitals.ID, []*models.Group{inactive})
+			So(IsNestingCycle(err), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/misc"
+)
+
+// ConfigurePool applies the pool size and connection lifetime set in the configuration to the given database handle, keeping the driver's defaults for unset values
+func ConfigurePool(db *sql.DB, conf *misc.Configuration) {
+	if conf == nil {
+		return
+	}
+
+	if conf.DatabaseMaxOpenConnections > 0 {
+		db.SetMaxOpenConns(conf.DatabaseMaxOpenConnections)
+	}
+	if conf.DatabaseMaxIdleConnections > 0 {
+		db.SetMaxIdleConns(conf.DatabaseMaxIdleConnections)
+	}
+	if conf.DatabaseConnectionLifetime > 0 {
+		db.SetConnMaxLifetime(time.Duration(conf.DatabaseConnectionLifetime) * time.Second)
+	}
+}
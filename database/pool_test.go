@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/misc"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigurePool(t *testing.T) {
+	Convey("Applying the configured pool settings to a database handle", t, func() {
+		db, err := sql.Open("mysql", "user:password@tcp(localhost:3306)/eveauth")
+		So(err, ShouldBeNil)
+		defer db.Close()
+
+		Convey("The maximum number of open connections should be applied", func() {
+			ConfigurePool(db, &misc.Configuration{DatabaseMaxOpenConnections: 7, DatabaseMaxIdleConnections: 3, DatabaseConnectionLifetime: 60})
+			So(db.Stats().MaxOpenConnections, ShouldEqual, 7)
+		})
+
+		Convey("Unset values and a missing configuration should keep the defaults", func() {
+			ConfigurePool(db, &misc.Configuration{})
+			ConfigurePool(db, nil)
+			So(db.Stats().MaxOpenConnections, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,2 @@
+// Package postgres provides the underlying connection used by the Database interface, using a PostgreSQL connection.
+package postgres
@@ -0,0 +1,71 @@
+-- Test data for the eveauth PostgreSQL database
+
+INSERT INTO users (id, username, password, email, verifiedemail, active) VALUES
+	(1, 'test1', '$2a$10$veif8VUZt7lShFhJKD0wGeY1YjCwIuWjYL0vQzlTqu8wNaYQMqzbe', 'test1@example.com', TRUE, TRUE),
+	(2, 'test2', '$2a$10$95z.WXfIreLKJ9px.3KgpOq4aXTG3DF7/5ehGYzUWALhpN6MMq/aK', 'test2@example.com', FALSE, FALSE),
+	(3, 'test3', '$2a$10$7Yxm2scdTVpEJpvZAT7tbOFA.G9JfyxtiHbr989iocX6U37C3/j4q', 'test3@example.com', FALSE, TRUE),
+	(4, 'test4', '$2a$10$WOWTgqaqLKbkb1uhYbtLnOuuYX4kXBC61GVAke7RkjiODoBpgGGzy', 'test4@example.com', TRUE, FALSE);
+
+INSERT INTO accounts (id, userid, apikeyid, apivcode, apiaccessmask, active) VALUES
+	(1, 1, 1, 'a', 0, TRUE),
+	(2, 2, 2, 'b', 0, FALSE),
+	(3, 3, 3, 'c', 0, TRUE),
+	(4, 3, 4, 'd', 268435455, TRUE),
+	(5, 4, 5, 'e', 268435455, FALSE),
+	(6, 4, 6, 'f', 268435455, FALSE);
+
+INSERT INTO applications (id, name, maintainerid, secret, callback, active) VALUES
+	(1, 'Testapp', 1, 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa', 'http://localhost/callback', TRUE),
+	(2, 'Apptest', 2, 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb', 'http://example.com/callback', FALSE);
+
+INSERT INTO corporations (id, name, ticker, evecorporationid, ceoid, apikeyid, apivcode, active) VALUES
+	(1, 'Test Corp Please Ignore', 'TEST', 1, 1, 1, 'a', TRUE),
+	(2, 'Corp Test Ignore Please', 'CORP', 2, 2, NULL, NULL, FALSE);
+
+INSERT INTO characters (id, accountid, corporationid, name, evecharacterid, defaultcharacter, active) VALUES
+	(1, 1, 1, 'Test Character', 1, TRUE, TRUE),
+	(2, 2, 2, 'Please Ignore', 2, TRUE, TRUE),
+	(3, 3, 1, 'Herp', 3, TRUE, TRUE),
+	(4, 3, 1, 'Derp', 4, FALSE, TRUE),
+	(5, 4, 2, 'Spai', 5, FALSE, FALSE),
+	(6, 4, 2, 'NoSpai', 6, TRUE, FALSE);
+
+INSERT INTO groups (id, name, active) VALUES
+	(1, 'Test Group', TRUE),
+	(2, 'Dank Access', FALSE);
+
+INSERT INTO roles (id, name, active, locked) VALUES
+	(1, 'ping.all', TRUE, FALSE),
+	(2, 'destroy.world', FALSE, TRUE),
+	(3, 'logistics.read', TRUE, FALSE),
+	(4, 'logistics.write', TRUE, FALSE);
+
+INSERT INTO grouproles (id, groupid, roleid, autoadded, granted) VALUES
+	(1, 1, 1, TRUE, TRUE),
+	(2, 1, 3, FALSE, TRUE),
+	(3, 2, 2, FALSE, FALSE),
+	(4, 2, 4, TRUE, FALSE);
+
+INSERT INTO usergroups (id, userid, groupid, active) VALUES
+	(1, 1, 1, TRUE),
+	(2, 2, 1, FALSE),
+	(3, 3, 1, TRUE),
+	(4, 3, 2, TRUE),
+	(5, 4, 1, FALSE),
+	(6, 4, 2, FALSE);
+
+INSERT INTO userroles (id, userid, roleid, autoadded, granted) VALUES
+	(1, 1, 1, FALSE, FALSE),
+	(2, 3, 2, TRUE, TRUE);
+
+-- Explicit IDs were inserted above, so the sequences have to be moved past them
+SELECT setval('users_id_seq', (SELECT MAX(id) FROM users));
+SELECT setval('accounts_id_seq', (SELECT MAX(id) FROM accounts));
+SELECT setval('applications_id_seq', (SELECT MAX(id) FROM applications));
+SELECT setval('corporations_id_seq', (SELECT MAX(id) FROM corporations));
+SELECT setval('characters_id_seq', (SELECT MAX(id) FROM characters));
+SELECT setval('groups_id_seq', (SELECT MAX(id) FROM groups));
+SELECT setval('roles_id_seq', (SELECT MAX(id) FROM roles));
+SELECT setval('grouproles_id_seq', (SELECT MAX(id) FROM grouproles));
+SELECT setval('usergroups_id_seq', (SELECT MAX(id) FROM usergroups));
+SELECT setval('userroles_id_seq', (SELECT MAX(id) FROM userroles));
@@ -0,0 +1,339 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportValueType specifies the type of the values contained in a report column or accepted by a report parameter
+type ReportValueType string
+
+const (
+	// ReportValueTypeInteger marks integer values
+	ReportValueTypeInteger ReportValueType = "integer"
+	// ReportValueTypeString marks string values
+	ReportValueTypeString ReportValueType = "string"
+	// ReportValueTypeBoolean marks boolean values
+	ReportValueTypeBoolean ReportValueType = "boolean"
+)
+
+const (
+	// ReportCorporationMembers counts the users and characters per corporation
+	ReportCorporationMembers = "corporation-members"
+	// ReportUsersWithoutAPIKeys lists all users which have not added any API keys
+	ReportUsersWithoutAPIKeys = "users-without-api-keys"
+	// ReportLoginAttempts counts the failed and successful login attempts per day, including the daily summaries of pruned login attempts
+	ReportLoginAttempts = "login-attempts"
+)
+
+// ReportParameter describes a parameter accepted by a report
+type ReportParameter struct {
+	// Name represents the name the parameter is passed as
+	Name string `json:"name"`
+	// Description represents a short explanation of the parameter
+	Description string `json:"description"`
+	// Type represents the type of the parameter's value
+	Type ReportValueType `json:"type"`
+	// Default represents the value used if the parameter has not been passed
+	Default string `json:"default"`
+	// Min represents the smallest value allowed for integer parameters
+	Min int64 `json:"min"`
+	// Max represents the largest value allowed for integer parameters, ignored if not positive
+	Max int64 `json:"max"`
+}
+
+// Parse converts the given value to the parameter's type, using the default value if the value is empty
+func (parameter *ReportParameter) Parse(value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		value = parameter.Default
+	}
+
+	switch parameter.Type {
+	case ReportValueTypeInteger:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Parameter %q must be an integer", parameter.Name)
+		}
+
+		if parameter.Max > 0 && (parsed < parameter.Min || parsed > parameter.Max) {
+			return nil, fmt.Errorf("Parameter %q must be between %d and %d", parameter.Name, parameter.Min, parameter.Max)
+		} else if parsed < parameter.Min {
+			return nil, fmt.Errorf("Parameter %q must be at least %d", parameter.Name, parameter.Min)
+		}
+
+		return parsed, nil
+	case ReportValueTypeBoolean:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("Parameter %q must be a boolean", parameter.Name)
+		}
+
+		return parsed, nil
+	case ReportValueTypeString:
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("Parameter %q has unsupported type %q", parameter.Name, parameter.Type)
+}
+
+// ReportColumn describes a column returned by a report
+type ReportColumn struct {
+	// Name represents the name of the column
+	Name string `json:"name"`
+	// Type represents the type of the column's values
+	Type ReportValueType `json:"type"`
+}
+
+// Report describes a named, read-only query returning typed columns. Every database backend implements the queries of all registered reports
+type Report struct {
+	// Name represents the unique name the report is run by
+	Name string `json:"name"`
+	// Title represents the human readable title of the report
+	Title string `json:"title"`
+	// Description represents a short explanation of the report's results
+	Description string `json:"description"`
+	// Parameters contains all parameters accepted by the report, in the order they are passed to the query
+	Parameters []*ReportParameter `json:"parameters"`
+	// Columns contains all columns returned by the report, in the order they are selected by the query
+	Columns []*ReportColumn `json:"columns"`
+
+	arguments func(parameters map[string]interface{}) []interface{}
+}
+
+// reports contains all registered reports
+var reports = []*Report{
+	&Report{
+		Name:        ReportCorporationMembers,
+		Title:       "Corporation members",
+		Description: "Number of users and characters per corporation",
+		Columns: []*ReportColumn{
+			&ReportColumn{Name: "corporation", Type: ReportValueTypeString},
+			&ReportColumn{Name: "ticker", Type: ReportValueTypeString},
+			&ReportColumn{Name: "users", Type: ReportValueTypeInteger},
+			&ReportColumn{Name: "characters", Type: ReportValueTypeInteger},
+		},
+	},
+	&Report{
+		Name:        ReportUsersWithoutAPIKeys,
+		Title:       "Users without API keys",
+		Description: "Users which have not added any API keys yet",
+		Columns: []*ReportColumn{
+			&ReportColumn{Name: "id", Type: ReportValueTypeInteger},
+			&ReportColumn{Name: "username", Type: ReportValueTypeString},
+			&ReportColumn{Name: "email", Type: ReportValueTypeString},
+			&ReportColumn{Name: "active", Type: ReportValueTypeBoolean},
+		},
+	},
+	&Report{
+		Name:        ReportLoginAttempts,
+		Title:       "Login attempts",
+		Description: "Number of failed and successful login attempts per day (UTC), including pruned login attempts",
+		Parameters: []*ReportParameter{
+			&ReportParameter{Name: "days", Description: "Number of days to include", Type: ReportValueTypeInteger, Default: "30", Min: 1, Max: 365},
+		},
+		Columns: []*ReportColumn{
+			&ReportColumn{Name: "day", Type: ReportValueTypeString},
+			&ReportColumn{Name: "failed", Type: ReportValueTypeInteger},
+			&ReportColumn{Name: "successful", Type: ReportValueTypeInteger},
+		},
+		arguments: func(parameters map[string]interface{}) []interface{} {
+			since := ReportLoginAttemptsSince(parameters["days"].(int64))
+
+			return []interface{}{since, since.Format("2006-01-02")}
+		},
+	},
+}
+
+// Reports returns all registered reports
+func Reports() []*Report {
+	registered := make([]*Report, len(reports))
+	copy(registered, reports)
+
+	return registered
+}
+
+// LoadReport returns the registered report with the given name, returning an error if no such report exists
+func LoadReport(name string) (*Report, error) {
+	for _, report := range reports {
+		if report.Name == name {
+			return report, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unknown report %q", name)
+}
+
+// ReportLoginAttemptsSince returns the start of the UTC day the login attempts report with the given number of days begins at
+func ReportLoginAttemptsSince(days int64) time.Time {
+	now := time.Now().UTC()
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -int(days-1))
+}
+
+// ReportResult contains the typed rows returned by running a report
+type ReportResult struct {
+	// Report represents the report which has been run
+	Report *Report
+	// Parameters contains the parsed values of all parameters the report has been run with
+	Parameters map[string]interface{}
+	// Rows contains the returned rows, holding an int64, string, bool or nil value per column
+	Rows [][]interface{}
+}
+
+// NewReportResult looks up the report with the given name and parses its parameters, returning an empty result or an error if the report is unknown or a parameter is invalid
+func NewReportResult(name string, parameters map[string]string) (*ReportResult, error) {
+	report, err := LoadReport(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReportResult{
+		Report:     report,
+		Parameters: make(map[string]interface{}),
+		Rows:       make([][]interface{}, 0),
+	}
+
+	for _, parameter := range report.Parameters {
+		value, err := parameter.Parse(parameters[parameter.Name])
+		if err != nil {
+			return nil, err
+		}
+
+		result.Parameters[parameter.Name] = value
+	}
+
+	return result, nil
+}
+
+// Arguments returns the arguments passed to the report's query
+func (result *ReportResult) Arguments() []interface{} {
+	if result.Report.arguments != nil {
+		return result.Report.arguments(result.Parameters)
+	}
+
+	args := make([]interface{}, 0, len(result.Report.Parameters))
+	for _, parameter := range result.Report.Parameters {
+		args = append(args, result.Parameters[parameter.Name])
+	}
+
+	return args
+}
+
+// Scan reads all rows into the result, converting the values to the types of the report's columns. An error is returned if the number of columns does not match or a value could not be converted
+func (result *ReportResult) Scan(rows *sql.Rows) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if len(columns) != len(result.Report.Columns) {
+		return fmt.Errorf("Report %q returned %d columns, expected %d", result.Report.Name, len(columns), len(result.Report.Columns))
+	}
+
+	for rows.Next() {
+		dest := make([]interface{}, len(result.Report.Columns))
+		for i, column := range result.Report.Columns {
+			switch column.Type {
+			case ReportValueTypeInteger:
+				dest[i] = &sql.NullInt64{}
+			case ReportValueTypeBoolean:
+				dest[i] = &sql.NullBool{}
+			default:
+				dest[i] = &sql.NullString{}
+			}
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			return err
+		}
+
+		row := make([]interface{}, len(dest))
+		for i, value := range dest {
+			switch value := value.(type) {
+			case *sql.NullInt64:
+				if value.Valid {
+					row[i] = value.Int64
+				}
+			case *sql.NullBool:
+				if value.Valid {
+					row[i] = value.Bool
+				}
+			case *sql.NullString:
+				if value.Valid {
+					row[i] = value.String
+				}
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	return rows.Err()
+}
+
+// Records returns the rows of the result with all values formatted as strings, using empty strings for nil values
+func (result *ReportResult) Records() [][]string {
+	records := make([][]string, len(result.Rows))
+
+	for i, row := range result.Rows {
+		records[i] = make([]string, len(row))
+		for j, value := range row {
+			if value != nil {
+				records[i][j] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	return records
+}
+
+// WriteCSV writes the result as CSV to the given writer, starting with a header containing the column names
+func (result *ReportResult) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(result.Report.Columns))
+	for i, column := range result.Report.Columns {
+		header[i] = column.Name
+	}
+
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	err = writer.WriteAll(result.Records())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WriteJSON writes the result as JSON to the given writer, encoding each row as an object keyed by the column names
+func (result *ReportResult) WriteJSON(w io.Writer) error {
+	rows := make([]map[string]interface{}, len(result.Rows))
+	for i, row := range result.Rows {
+		rows[i] = make(map[string]interface{})
+		for j, column := range result.Report.Columns {
+			rows[i][column.Name] = row[j]
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]interface{}{
+		"report":     result.Report.Name,
+		"parameters": result.Parameters,
+		"columns":    result.Report.Columns,
+		"rows":       rows,
+	})
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReports(t *testing.T) {
+	Convey("Looking up registered reports", t, func() {
+		Convey("All reports should be registered with unique names and at least one column", func() {
+			names := make(map[string]bool)
+
+			for _, report := range Reports() {
+				So(names[report.Name], ShouldBeFalse)
+				So(len(report.Columns), ShouldBeGreaterThan, 0)
+				names[report.Name] = true
+			}
+
+			So(names[ReportCorporationMembers], ShouldBeTrue)
+			So(names[ReportUsersWithoutAPIKeys], ShouldBeTrue)
+			So(names[ReportLoginAttempts], ShouldBeTrue)
+		})
+
+		Convey("Loading an unknown report should return an error", func() {
+			report, err := LoadReport("nonexistent")
+			So(err, ShouldNotBeNil)
+			So(report, ShouldBeNil)
+		})
+	})
+}
+
+func TestReportParameters(t *testing.T) {
+	Convey("Parsing report parameters", t, func() {
+		Convey("Missing parameters should use their default value", func() {
+			result, err := NewReportResult(ReportLoginAttempts, nil)
+			So(err, ShouldBeNil)
+			So(result.Parameters["days"], ShouldEqual, int64(30))
+		})
+
+		Convey("Passed parameters should be converted to their type", func() {
+			result, err := NewReportResult(ReportLoginAttempts, map[string]string{"days": " 7 "})
+			So(err, ShouldBeNil)
+			So(result.Parameters["days"], ShouldEqual, int64(7))
+		})
+
+		Convey("Invalid or out of range parameters should return an error", func() {
+			_, err := NewReportResult(ReportLoginAttempts, map[string]string{"days": "week"})
+			So(err, ShouldNotBeNil)
+
+			_, err = NewReportResult(ReportLoginAttempts, map[string]string{"days": "0"})
+			So(err, ShouldNotBeNil)
+
+			_, err = NewReportResult(ReportLoginAttempts, map[string]string{"days": "366"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Boolean and string parameters should be parsed", func() {
+			parameter := &ReportParameter{Name: "active", Type: ReportValueTypeBoolean, Default: "true"}
+
+			value, err := parameter.Parse("")
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, true)
+
+			_, err = parameter.Parse("maybe")
+			So(err, ShouldNotBeNil)
+
+			parameter = &ReportParameter{Name: "name", Type: ReportValueTypeString}
+
+			value, err = parameter.Parse("test")
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, "test")
+		})
+
+		Convey("The login attempts report should pass the start of its first day as arguments", func() {
+			result, err := NewReportResult(ReportLoginAttempts, map[string]string{"days": "1"})
+			So(err, ShouldBeNil)
+
+			args := result.Arguments()
+			So(len(args), ShouldEqual, 2)
+			So(args[1], ShouldEqual, time.Now().UTC().Format("2006-01-02"))
+
+			since := args[0].(time.Time)
+			So(since.Location(), ShouldEqual, time.UTC)
+			So(since.Format("2006-01-02"), ShouldEqual, time.Now().UTC().Format("2006-01-02"))
+			So(since.Hour(), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestReportResultExport(t *testing.T) {
+	Convey("Exporting a report result", t, func() {
+		result, err := NewReportResult(ReportUsersWithoutAPIKeys, nil)
+		So(err, ShouldBeNil)
+
+		result.Rows = append(result.Rows,
+			[]interface{}{int64(1), "test1", "test1@example.com", true},
+			[]interface{}{int64(2), "test,2", nil, false},
+		)
+
+		Convey("Writing CSV should include a header and quote values where required", func() {
+			var buf bytes.Buffer
+
+			err := result.WriteCSV(&buf)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, "id,username,email,active\n1,test1,test1@example.com,true\n2,\"test,2\",,false\n")
+		})
+
+		Convey("Writing JSON should key the row values by column name", func() {
+			var buf bytes.Buffer
+
+			err := result.WriteJSON(&buf)
+			So(err, ShouldBeNil)
+
+			var decoded struct {
+				Report string                   `json:"report"`
+				Rows   []map[string]interface{} `json:"rows"`
+			}
+
+			err = json.Unmarshal(buf.Bytes(), &decoded)
+			So(err, ShouldBeNil)
+			So(decoded.Report, ShouldEqual, ReportUsersWithoutAPIKeys)
+			So(len(decoded.Rows), ShouldEqual, 2)
+			So(decoded.Rows[0]["username"], ShouldEqual, "test1")
+			So(decoded.Rows[1]["email"], ShouldBeNil)
+			So(decoded.Rows[1]["active"], ShouldEqual, false)
+		})
+	})
+}
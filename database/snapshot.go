@@ -0,0 +1,70 @@
+package database
+
+import (
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// Snapshot records the IDs and versions assigned to a model and the models contained in it while saving them.
+// The backends restore a snapshot if a save fails, so the rolled back IDs and versions never end up in the caller's model
+type Snapshot struct {
+	fields []*int64
+	values []int64
+}
+
+// SnapshotUser records the IDs and versions of the given user, its accounts, characters, user roles and their roles
+func SnapshotUser(user *models.User) *Snapshot {
+	snapshot := &Snapshot{}
+	snapshot.record(&user.ID, &user.Version)
+
+	for _, account := range user.Accounts {
+		snapshot.record(&account.ID, &account.UserID)
+
+		for _, character := range account.Characters {
+			snapshot.record(&character.ID, &character.AccountID)
+		}
+	}
+
+	for _, userRole := range user.UserRoles {
+		snapshot.record(&userRole.ID, &userRole.UserID)
+		snapshot.recordRole(userRole.Role)
+	}
+
+	return snapshot
+}
+
+// SnapshotGroup records the IDs and versions of the given group, its group roles and their roles
+func SnapshotGroup(group *models.Group) *Snapshot {
+	snapshot := &Snapshot{}
+	snapshot.record(&group.ID, &group.Version)
+
+	for _, groupRole := range group.GroupRoles {
+		snapshot.record(&groupRole.ID, &groupRole.GroupID)
+		snapshot.recordRole(groupRole.Role)
+	}
+
+	return snapshot
+}
+
+// Restore reverts all recorded IDs and versions to the values they had when the snapshot was taken
+func (snapshot *Snapshot) Restore() {
+	for index, field := range snapshot.fields {
+		*field = snapshot.values[index]
+	}
+}
+
+// record stores the current values of the given fields
+func (snapshot *Snapshot) record(fields ...*int64) {
+	for _, field := range fields {
+		snapshot.fields = append(snapshot.fields, field)
+		snapshot.values = append(snapshot.values, *field)
+	}
+}
+
+// recordRole stores the ID and version of the given role, ignoring missing roles
+func (snapshot *Snapshot) recordRole(role *models.Role) {
+	if role == nil {
+		return
+	}
+
+	snapshot.record(&role.ID, &role.Version)
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSnapshot(t *testing.T) {
+	Convey("Restoring a snapshot of a user", t, func() {
+		role := &models.Role{ID: 3, Name: "ping.all", Active: true, Version: 2}
+
+		user := models.NewUser("test1", "", "test1@example.com", true, true)
+		user.Accounts = append(user.Accounts, models.NewAccount(user.ID, 1, "a", 0, true))
+		user.UserRoles = append(user.UserRoles, models.NewUserRole(user.ID, role, false, true))
+
+		snapshot := SnapshotUser(user)
+
+		user.ID = 5
+		user.Version = 1
+		user.Accounts[0].ID = 6
+		user.Accounts[0].UserID = 5
+		user.UserRoles[0].UserID = 5
+		role.Version = 3
+
+		snapshot.Restore()
+
+		Convey("The IDs and versions should be reverted", func() {
+			So(user.ID, ShouldEqual, -1)
+			So(user.Version, ShouldEqual, 0)
+			So(user.Accounts[0].ID, ShouldEqual, -1)
+			So(user.Accounts[0].UserID, ShouldEqual, -1)
+			So(user.UserRoles[0].UserID, ShouldEqual, -1)
+			So(role.Version, ShouldEqual, 2)
+		})
+	})
+
+	Convey("Restoring a snapshot of a group", t, func() {
+		group := models.NewGroup("Pilots", true)
+		group.GroupRoles = append(group.GroupRoles, models.NewGroupRole(group.ID, nil, false, true))
+
+		snapshot := SnapshotGroup(group)
+
+		group.ID = 4
+		group.Version = 1
+		group.GroupRoles[0].GroupID = 4
+
+		snapshot.Restore()
+
+		Convey("The IDs and versions should be reverted", func() {
+			So(group.ID, ShouldEqual, -1)
+			So(group.Version, ShouldEqual, 0)
+			So(group.GroupRoles[0].GroupID, ShouldEqual, -1)
+		})
+	})
+}
@@ -0,0 +1,2760 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/migration"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+
+	"github.com/jmoiron/sqlx"
+	// Blank import of the SQLite driver to use with sqlx
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	database.Register(database.TypeSQLite, func(conf *misc.Configuration) database.Connection {
+		return &DatabaseConnection{
+			Config: conf,
+		}
+	})
+}
+
+// DatabaseConnection provides an implementation of the Connection interface using a SQLite database
+type DatabaseConnection struct {
+	// Config stores the current configuration values being used
+	Config *misc.Configuration
+
+	conn *sqlx.DB
+	tx   *sqlx.Tx
+}
+
+// executor performs queries using either the database connection or the transaction currently in use, applying the configured query timeout
+type executor struct {
+	ext    sqlx.ExtContext
+	config *misc.Configuration
+}
+
+// ExecContext executes a query without returning any rows, cancelling it once the context is done or the query timeout has been reached
+func (e *executor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx, e.config)
+	defer cancel()
+
+	return e.ext.ExecContext(ctx, query, args...)
+}
+
+// GetContext scans a single row into dest, cancelling the query once the context is done or the query timeout has been reached
+func (e *executor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := database.WithQueryTimeout(ctx, e.config)
+	defer cancel()
+
+	return sqlx.GetContext(ctx, e.ext, dest, query, args...)
+}
+
+// SelectContext scans all rows into dest, cancelling the query once the context is done or the query timeout has been reached
+func (e *executor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := database.WithQueryTimeout(ctx, e.config)
+	defer cancel()
+
+	return sqlx.SelectContext(ctx, e.ext, dest, query, args...)
+}
+
+// QueryContext executes a query returning rows. As the rows are read after returning, the caller is responsible for applying the query timeout to the context
+func (e *executor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.ext.QueryContext(ctx, query, args...)
+}
+
+// groupRoleRow represents a row of the grouproles table, referencing the role by its ID
+type groupRoleRow struct {
+	ID        int64
+	GroupID   int64
+	RoleID    int64
+	AutoAdded bool
+	Granted   bool
+}
+
+// userRoleRow represents a row of the userroles table, referencing the role by its ID
+type userRoleRow struct {
+	ID         int64
+	UserID     int64
+	RoleID     int64
+	AutoAdded  bool
+	Granted    bool
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+// groupMembershipRow represents the origin and validity window of a row of the usergroups table, referencing the group by its ID
+type groupMembershipRow struct {
+	GroupID    int64
+	AutoAdded  bool
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+// Connect tries to open the SQLite database file, returning an error if the attempt failed. The bundled schema is created automatically for new database files, other outdated schemas are only migrated if configured to do so
+func (c *DatabaseConnection) Connect() error {
+	err := c.open()
+	if err != nil {
+		return err
+	}
+
+	version, err := migration.CurrentVersion(c.conn)
+	if err != nil {
+		return err
+	}
+
+	return migration.Prepare(c.conn, migrations, c.Config.DatabaseAutoMigrate || version == 0)
+}
+
+// open establishes the underlying connection to the SQLite backend if it has not been opened yet
+func (c *DatabaseConnection) open() error {
+	if c.conn != nil {
+		return nil
+	}
+
+	conn, err := sqlx.Connect("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", c.Config.DatabaseHost))
+	if err != nil {
+		return err
+	}
+
+	database.ConfigurePool(conn.DB, c.Config)
+
+	c.conn = conn
+
+	return nil
+}
+
+// Ping verifies the SQLite database is still reachable, returning an error if no connection has been established or the database could not be reached
+func (c *DatabaseConnection) Ping(ctx context.Context) error {
+	if c.conn == nil {
+		return fmt.Errorf("Not connected to the SQLite database")
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx, c.Config)
+	defer cancel()
+
+	return c.conn.PingContext(ctx)
+}
+
+// MigrateUp applies all pending schema migrations to the SQLite database, returning the resulting schema version or an error if a migration failed
+func (c *DatabaseConnection) MigrateUp() (int, error) {
+	err := c.open()
+	if err != nil {
+		return 0, err
+	}
+
+	return migration.Up(c.conn, migrations)
+}
+
+// MigrateDown reverts the most recently applied schema migration of the SQLite database, returning the resulting schema version or an error if the migration failed
+func (c *DatabaseConnection) MigrateDown() (int, error) {
+	err := c.open()
+	if err != nil {
+		return 0, err
+	}
+
+	return migration.Down(c.conn, migrations)
+}
+
+// MigrationStatus retrieves the current schema version and pending migrations of the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) MigrationStatus() (*migration.Status, error) {
+	err := c.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return migration.LoadStatus(c.conn, migrations)
+}
+
+// WithTx runs the given function within a single SQLite transaction, passing a Connection bound to it. The transaction is committed if the function returns nil and rolled back otherwise
+func (c *DatabaseConnection) WithTx(ctx context.Context, fn func(tx database.Connection) error) error {
+	return c.transaction(ctx, func(tx *DatabaseConnection) error {
+		return fn(tx)
+	})
+}
+
+// transaction runs the given function using a connection bound to a new transaction. Nested calls reuse the already running transaction
+func (c *DatabaseConnection) transaction(ctx context.Context, fn func(tx *DatabaseConnection) error) (err error) {
+	if c.tx != nil {
+		return fn(c)
+	}
+
+	tx, err := c.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	err = fn(&DatabaseConnection{
+		Config: c.Config,
+		conn:   c.conn,
+		tx:     tx,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryPage selects a single page of rows from the given table matching all conditions into dest, returning the total number of matching rows
+func (c *DatabaseConnection) queryPage(ctx context.Context, dest interface{}, table string, columns string, conditions []string, args []interface{}, criteria *database.ListCriteria, sortFields []string) (int64, error) {
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+
+	err := c.executor().GetContext(ctx, &total, "SELECT COUNT(*) FROM "+table+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	sortColumn := criteria.SortColumn(sortFields)
+
+	order := sortColumn + " " + criteria.SortDirection.String()
+	if sortColumn != "id" {
+		order += ", id ASC"
+	}
+
+	err = c.executor().SelectContext(ctx, dest, "SELECT "+columns+" FROM "+table+where+" ORDER BY "+order+" LIMIT ? OFFSET ?", append(args, criteria.PageLimit(), criteria.PageOffset())...)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// listConditions returns the conditions and arguments shared by all list queries, excluding deleted rows and matching the criteria's text filter against the given columns
+func listConditions(criteria *database.ListCriteria, filterColumns ...string) ([]string, []interface{}) {
+	conditions := []string{"deletedat IS NULL"}
+	var args []interface{}
+
+	if len(criteria.Filter) > 0 {
+		var filters []string
+
+		for _, column := range filterColumns {
+			filters = append(filters, column+" LIKE ? ESCAPE '"+database.LikeEscape+"'")
+			args = append(args, criteria.FilterPattern())
+		}
+
+		conditions = append(conditions, "("+strings.Join(filters, " OR ")+")")
+	}
+
+	switch criteria.Active {
+	case database.ActiveFilterActive:
+		conditions = append(conditions, "active=?")
+		args = append(args, true)
+	case database.ActiveFilterInactive:
+		conditions = append(conditions, "active=?")
+		args = append(args, false)
+	}
+
+	return conditions, args
+}
+
+// executor returns the transaction currently in use or the database connection if no transaction is running
+func (c *DatabaseConnection) executor() *executor {
+	if c.tx != nil {
+		return &executor{ext: c.tx, config: c.Config}
+	}
+
+	return &executor{ext: c.conn, config: c.Config}
+}
+
+// RunReport runs the registered report with the given name and parameters at the SQLite database, returning its typed rows or an error if the report is unknown, a parameter is invalid or the query failed.
+// SQLite does not support read-only transactions, reports are thus limited to their predefined SELECT statements
+func (c *DatabaseConnection) RunReport(ctx context.Context, name string, parameters map[string]string) (*database.ReportResult, error) {
+	result, err := database.NewReportResult(name, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	query, ok := reportQueries[result.Report.Name]
+	if !ok {
+		return nil, fmt.Errorf("Report %q is not supported by the SQLite database", result.Report.Name)
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx, c.Config)
+	defer cancel()
+
+	rows, err := c.executor().QueryContext(ctx, query, result.Arguments()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	err = result.Scan(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// LoadAllAccounts retrieves all accounts from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllAccounts(ctx context.Context) ([]*models.Account, error) {
+	var accounts []*models.Account
+
+	err := c.executor().SelectContext(ctx, &accounts, "SELECT id, userid, apikeyid, apivcode, apiaccessmask, active FROM accounts")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		characters, err := c.LoadAllCharactersForAccount(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		account.Characters = characters
+	}
+
+	return accounts, nil
+}
+
+// LoadAllCorporations retrieves all corporations from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCorporations(ctx context.Context) ([]*models.Corporation, error) {
+	var corporations []*models.Corporation
+
+	err := c.executor().SelectContext(ctx, &corporations, "SELECT id, name, ticker, evecorporationid, ceoid, allianceid, apikeyid, apivcode, active FROM corporations")
+	if err != nil {
+		return nil, err
+	}
+
+	return corporations, nil
+}
+
+// LoadAllAlliances retrieves all alliances from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllAlliances(ctx context.Context) ([]*models.Alliance, error) {
+	var alliances []*models.Alliance
+
+	err := c.executor().SelectContext(ctx, &alliances, "SELECT id, name, ticker, eveallianceid, executorcorpid, active FROM alliances")
+	if err != nil {
+		return nil, err
+	}
+
+	return alliances, nil
+}
+
+// LoadAllCharacters retrieves all characters from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCharacters(ctx context.Context) ([]*models.Character, error) {
+	var characters []*models.Character
+
+	err := c.executor().SelectContext(ctx, &characters, "SELECT id, accountid, corporationid, name, evecharacterid, defaultcharacter, active FROM characters")
+	if err != nil {
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+// LoadAllRoles retrieves all roles (and the roles implied by them) from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllRoles(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+
+	err := c.executor().SelectContext(ctx, &roles, "SELECT id, name, active, locked, version, applicationid, policy FROM roles WHERE deletedat IS NULL")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		err = c.loadImpliedRoles(ctx, role, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return roles, nil
+}
+
+// LoadAllGroupRoles retrieves all group roles (and their associated roles) from the database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupRoles(ctx context.Context) ([]*models.GroupRole, error) {
+	var groupRoles []*models.GroupRole
+
+	var rows []*groupRoleRow
+
+	err := c.executor().SelectContext(ctx, &rows, "SELECT id, groupid, roleid, autoadded, granted FROM grouproles WHERE groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) AND roleid IN (SELECT id FROM roles WHERE deletedat IS NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		role, err := c.LoadRole(ctx, row.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		groupRole := &models.GroupRole{
+			ID:        row.ID,
+			GroupID:   row.GroupID,
+			Role:      role,
+			AutoAdded: row.AutoAdded,
+			Granted:   row.Granted,
+		}
+
+		groupRoles = append(groupRoles, groupRole)
+	}
+
+	return groupRoles, nil
+}
+
+// LoadAllUserRoles retrieves all user roles (and their associated roles) from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllUserRoles(ctx context.Context) ([]*models.UserRole, error) {
+	var userRoles []*models.UserRole
+
+	var rows []*userRoleRow
+
+	err := c.executor().SelectContext(ctx, &rows, "SELECT id, userid, roleid, autoadded, granted, validfrom, validuntil FROM userroles WHERE userid IN (SELECT id FROM users WHERE deletedat IS NULL) AND roleid IN (SELECT id FROM roles WHERE deletedat IS NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		role, err := c.LoadRole(ctx, row.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		userRole := &models.UserRole{
+			ID:        row.ID,
+			UserID:    row.UserID,
+			Role:      role,
+			AutoAdded: row.AutoAdded,
+			Granted:   row.Granted,
+			Validity:  database.ValidityFromTimes(row.ValidFrom, row.ValidUntil),
+		}
+
+		userRoles = append(userRoles, userRole)
+	}
+
+	return userRoles, nil
+}
+
+// LoadAllRoleImplications retrieves all implications between roles not in the trash from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllRoleImplications(ctx context.Context) ([]*models.RoleImplication, error) {
+	var roleImplications []*models.RoleImplication
+
+	err := c.executor().SelectContext(ctx, &roleImplications, "SELECT id, roleid, impliedroleid FROM roleimplications WHERE roleid IN (SELECT id FROM roles WHERE deletedat IS NULL) AND impliedroleid IN (SELECT id FROM roles WHERE deletedat IS NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	return roleImplications, nil
+}
+
+// LoadAllGroupNestings retrieves all nestings between groups not in the trash from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupNestings(ctx context.Context) ([]*models.GroupNesting, error) {
+	var groupNestings []*models.GroupNesting
+
+	err := c.executor().SelectContext(ctx, &groupNestings, "SELECT id, groupid, subgroupid FROM groupnestings WHERE groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) AND subgroupid IN (SELECT id FROM groups WHERE deletedat IS NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	return groupNestings, nil
+}
+
+// LoadAllMembershipRules retrieves all membership rules of groups not in the trash from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllMembershipRules(ctx context.Context) ([]*models.MembershipRule, error) {
+	var membershipRules []*models.MembershipRule
+
+	err := c.executor().SelectContext(ctx, &membershipRules, "SELECT id, groupid, type, targetid FROM membershiprules WHERE groupid IN (SELECT id FROM groups WHERE deletedat IS NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	return membershipRules, nil
+}
+
+// LoadAllGroupManagers retrieves all group manager assignments of groups and users not in the trash from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupManagers(ctx context.Context) ([]*models.GroupManager, error) {
+	var groupManagers []*models.GroupManager
+
+	err := c.executor().SelectContext(ctx, &groupManagers, "SELECT id, groupid, userid FROM groupmanagers WHERE groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) AND userid IN (SELECT id FROM users WHERE deletedat IS NULL) ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	return groupManagers, nil
+}
+
+// LoadAllManagerActions retrieves all recorded manager actions from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllManagerActions(ctx context.Context) ([]*models.ManagerAction, error) {
+	var managerActions []*models.ManagerAction
+
+	err := c.executor().SelectContext(ctx, &managerActions, "SELECT id, groupid, actorid, userid, type, timestamp FROM manageractions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	return managerActions, nil
+}
+
+// LoadAllGroupApplications retrieves all group applications of groups and users not in the trash from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupApplications(ctx context.Context) ([]*models.GroupApplication, error) {
+	var groupApplications []*models.GroupApplication
+
+	err := c.executor().SelectContext(ctx, &groupApplications, "SELECT id, groupid, userid, status, message, comment, deciderid, createdat, decidedat FROM groupapplications WHERE groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) AND userid IN (SELECT id FROM users WHERE deletedat IS NULL) ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	return groupApplications, nil
+}
+
+// LoadPendingGroupApplications retrieves all pending group applications of groups and users not in the trash from the SQLite database, oldest first, returning an error if the query failed
+func (c *DatabaseConnection) LoadPendingGroupApplications(ctx context.Context) ([]*models.GroupApplication, error) {
+	var groupApplications []*models.GroupApplication
+
+	err := c.executor().SelectContext(ctx, &groupApplications, "SELECT id, groupid, userid, status, message, comment, deciderid, createdat, decidedat FROM groupapplications WHERE status=? AND groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) AND userid IN (SELECT id FROM users WHERE deletedat IS NULL) ORDER BY id", models.GroupApplicationStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupApplications, nil
+}
+
+// LoadAllGroups retrieves all groups (and their associated group roles and sub groups) from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroups(ctx context.Context) ([]*models.Group, error) {
+	var groups []*models.Group
+
+	err := c.executor().SelectContext(ctx, &groups, "SELECT id, name, active, version, visibility FROM groups WHERE deletedat IS NULL")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		groupRoles, err := c.LoadAllGroupRolesForGroup(ctx, group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(ctx, group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// LoadAllUsers retrieves all users (and their associates groups and user roles) from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllUsers(ctx context.Context) ([]*models.User, error) {
+	var users []*models.User
+
+	err := c.executor().SelectContext(ctx, &users, "SELECT id, username, password, email, verifiedemail, active, version FROM users WHERE deletedat IS NULL")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		accounts, err := c.LoadAllAccountsForUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		userRoles, err := c.LoadAllUserRolesForUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		groups, err := c.LoadAllGroupsForUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		groupValidities, autoAddedGroups, err := c.loadGroupMemberships(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Accounts = accounts
+		user.UserRoles = userRoles
+		user.Groups = groups
+		user.GroupValidities = groupValidities
+		user.AutoAddedGroups = autoAddedGroups
+
+		err = database.LoadDefaultAffiliation(ctx, c, user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+// LoadAllApplications retrieves all applications from the database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllApplications(ctx context.Context) ([]*models.Application, error) {
+	var applications []*models.Application
+
+	err := c.executor().SelectContext(ctx, &applications, "SELECT id, name, maintainerid, secret, callback, active, version, rolescopes FROM applications WHERE deletedat IS NULL")
+	if err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+// LoadAllLoginAttempts retrieves all login attempts from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllLoginAttempts(ctx context.Context) ([]*models.LoginAttempt, error) {
+	var loginAttempts []*models.LoginAttempt
+
+	err := c.executor().SelectContext(ctx, &loginAttempts, "SELECT id, username, remoteaddr, useragent, successful, timestamp FROM loginattempts")
+	if err != nil {
+		return nil, err
+	}
+
+	return loginAttempts, nil
+}
+
+// LoadAllLoginAttemptSummaries retrieves the daily summaries of all pruned login attempts from the SQLite database, sorted by day, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllLoginAttemptSummaries(ctx context.Context) ([]*models.LoginAttemptSummary, error) {
+	var summaries []*models.LoginAttemptSummary
+
+	err := c.executor().SelectContext(ctx, &summaries, "SELECT day, failed, successful FROM loginattemptsummaries ORDER BY day")
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// LoadAllCSRFFailures retrieves all CSRF failures from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCSRFFailures(ctx context.Context) ([]*models.CSRFFailure, error) {
+	var csrfFailures []*models.CSRFFailure
+
+	err := c.executor().SelectContext(ctx, &csrfFailures, "SELECT id, userid, request, timestamp FROM csrffailures")
+	if err != nil {
+		return nil, err
+	}
+
+	return csrfFailures, nil
+}
+
+// QueryUsers retrieves a page of users (and their associated accounts, groups and user roles) matching the given criteria as well as the total number of matches from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) QueryUsers(ctx context.Context, criteria *database.ListCriteria) ([]*models.User, int64, error) {
+	conditions, args := listConditions(criteria, "username", "email")
+
+	if criteria.GroupID > 0 {
+		conditions = append(conditions, "id IN (SELECT userid FROM usergroups WHERE active=1 AND groupid=?)")
+		args = append(args, criteria.GroupID)
+	}
+
+	if criteria.CorporationID > 0 {
+		conditions = append(conditions, "id IN (SELECT a.userid FROM accounts AS a INNER JOIN characters AS ch ON (a.id = ch.accountid) WHERE ch.corporationid=?)")
+		args = append(args, criteria.CorporationID)
+	}
+
+	var users []*models.User
+
+	total, err := c.queryPage(ctx, &users, "users", "id, username, password, email, verifiedemail, active, version", conditions, args, criteria, database.UserSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, user := range users {
+		accounts, err := c.LoadAllAccountsForUser(ctx, user.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		userRoles, err := c.LoadAllUserRolesForUser(ctx, user.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		groups, err := c.LoadAllGroupsForUser(ctx, user.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		groupValidities, autoAddedGroups, err := c.loadGroupMemberships(ctx, user.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		user.Accounts = accounts
+		user.UserRoles = userRoles
+		user.Groups = groups
+		user.GroupValidities = groupValidities
+		user.AutoAddedGroups = autoAddedGroups
+
+		err = database.LoadDefaultAffiliation(ctx, c, user)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return users, total, nil
+}
+
+// QueryGroups retrieves a page of groups (and their associated group roles and sub groups) matching the given criteria as well as the total number of matches from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) QueryGroups(ctx context.Context, criteria *database.ListCriteria) ([]*models.Group, int64, error) {
+	conditions, args := listConditions(criteria, "name")
+
+	var groups []*models.Group
+
+	total, err := c.queryPage(ctx, &groups, "groups", "id, name, active, version, visibility", conditions, args, criteria, database.GroupSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, group := range groups {
+		groupRoles, err := c.LoadAllGroupRolesForGroup(ctx, group.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(ctx, group, make(map[int64]bool))
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return groups, total, nil
+}
+
+// QueryRoles retrieves a page of roles (and the roles implied by them) matching the given criteria as well as the total number of matches from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) QueryRoles(ctx context.Context, criteria *database.ListCriteria) ([]*models.Role, int64, error) {
+	conditions, args := listConditions(criteria, "name")
+
+	var roles []*models.Role
+
+	total, err := c.queryPage(ctx, &roles, "roles", "id, name, active, locked, version, applicationid, policy", conditions, args, criteria, database.RoleSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, role := range roles {
+		err = c.loadImpliedRoles(ctx, role, make(map[int64]bool))
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return roles, total, nil
+}
+
+// QueryApplications retrieves a page of applications matching the given criteria as well as the total number of matches from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) QueryApplications(ctx context.Context, criteria *database.ListCriteria) ([]*models.Application, int64, error) {
+	conditions, args := listConditions(criteria, "name")
+
+	var applications []*models.Application
+
+	total, err := c.queryPage(ctx, &applications, "applications", "id, name, maintainerid, secret, callback, active, version, rolescopes", conditions, args, criteria, database.ApplicationSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return applications, total, nil
+}
+
+// LoadAccount retrieves the account with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	account := &models.Account{}
+
+	err := c.executor().GetContext(ctx, account, "SELECT id, userid, apikeyid, apivcode, apiaccessmask, active FROM accounts WHERE id=?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	characters, err := c.LoadAllCharactersForAccount(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	account.Characters = characters
+
+	return account, nil
+}
+
+// LoadCorporation retrieves the corporation with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadCorporation(ctx context.Context, corporationID int64) (*models.Corporation, error) {
+	corporation := &models.Corporation{}
+
+	err := c.executor().GetContext(ctx, corporation, "SELECT id, name, ticker, evecorporationid, ceoid, allianceid, apikeyid, apivcode, active FROM corporations WHERE id=?", corporationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return corporation, nil
+}
+
+// LoadCorporationFromEVECorporationID retrieves the corporation with the given EVE Online corporation ID from the database, returning an error if the query failed
+func (c *DatabaseConnection) LoadCorporationFromEVECorporationID(ctx context.Context, eveCorporationID int64) (*models.Corporation, error) {
+	corporation := &models.Corporation{}
+
+	err := c.executor().GetContext(ctx, corporation, "SELECT id, name, ticker, evecorporationid, ceoid, allianceid, apikeyid, apivcode, active FROM corporations WHERE evecorporationid=?", eveCorporationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return corporation, nil
+}
+
+// LoadCorporationNameFromID retrieves the name of the corporation with the given ID, returning an error if the query failed
+func (c *DatabaseConnection) LoadCorporationNameFromID(ctx context.Context, corporationID int64) (string, error) {
+	var corporationName string
+
+	err := c.executor().GetContext(ctx, &corporationName, "SELECT name FROM corporations WHERE id=?", corporationID)
+	if err != nil {
+		return "", err
+	}
+
+	return corporationName, nil
+}
+
+// LoadAlliance retrieves the alliance with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAlliance(ctx context.Context, allianceID int64) (*models.Alliance, error) {
+	alliance := &models.Alliance{}
+
+	err := c.executor().GetContext(ctx, alliance, "SELECT id, name, ticker, eveallianceid, executorcorpid, active FROM alliances WHERE id=?", allianceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return alliance, nil
+}
+
+// LoadAllianceFromEVEAllianceID retrieves the alliance with the given EVE Online alliance ID from the database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllianceFromEVEAllianceID(ctx context.Context, eveAllianceID int64) (*models.Alliance, error) {
+	alliance := &models.Alliance{}
+
+	err := c.executor().GetContext(ctx, alliance, "SELECT id, name, ticker, eveallianceid, executorcorpid, active FROM alliances WHERE eveallianceid=?", eveAllianceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return alliance, nil
+}
+
+// LoadCharacter retrieves the character with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadCharacter(ctx context.Context, characterID int64) (*models.Character, error) {
+	character := &models.Character{}
+
+	err := c.executor().GetContext(ctx, character, "SELECT id, accountid, corporationid, name, evecharacterid, defaultcharacter, active FROM characters WHERE id=?", characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return character, nil
+}
+
+// LoadRole retrieves the role (and all roles implied by it) with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadRole(ctx context.Context, roleID int64) (*models.Role, error) {
+	return c.loadRole(ctx, roleID, make(map[int64]bool))
+}
+
+// loadRole retrieves the role (and all roles implied by it) with the given ID, ignoring implications leading back to a role on the given path
+func (c *DatabaseConnection) loadRole(ctx context.Context, roleID int64, path map[int64]bool) (*models.Role, error) {
+	role := &models.Role{}
+
+	err := c.executor().GetContext(ctx, role, "SELECT id, name, active, locked, version, applicationid, policy FROM roles WHERE id=? AND deletedat IS NULL", roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.loadImpliedRoles(ctx, role, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// loadImpliedRoles retrieves all roles directly implied by the given role (and the roles implied by them) from the SQLite database, ignoring implications leading back to a role on the given path
+func (c *DatabaseConnection) loadImpliedRoles(ctx context.Context, role *models.Role, path map[int64]bool) error {
+	var impliedRoleIDs []int64
+
+	err := c.executor().SelectContext(ctx, &impliedRoleIDs, "SELECT impliedroleid FROM roleimplications WHERE roleid=? AND impliedroleid IN (SELECT id FROM roles WHERE deletedat IS NULL) ORDER BY impliedroleid", role.ID)
+	if err != nil {
+		return err
+	}
+
+	path[role.ID] = true
+	defer delete(path, role.ID)
+
+	for _, impliedRoleID := range impliedRoleIDs {
+		if path[impliedRoleID] {
+			continue
+		}
+
+		impliedRole, err := c.loadRole(ctx, impliedRoleID, path)
+		if err != nil {
+			return err
+		}
+
+		role.ImpliedRoles = append(role.ImpliedRoles, impliedRole)
+	}
+
+	return nil
+}
+
+// LoadGroupRole retrieves the group role (and its associated role) with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadGroupRole(ctx context.Context, groupRoleID int64) (*models.GroupRole, error) {
+	var row groupRoleRow
+
+	err := c.executor().GetContext(ctx, &row, "SELECT id, groupid, roleid, autoadded, granted FROM grouproles WHERE id=? AND roleid IN (SELECT id FROM roles WHERE deletedat IS NULL)", groupRoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := c.LoadRole(ctx, row.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRole := &models.GroupRole{
+		ID:        row.ID,
+		GroupID:   row.GroupID,
+		Role:      role,
+		AutoAdded: row.AutoAdded,
+		Granted:   row.Granted,
+	}
+
+	return groupRole, nil
+}
+
+// LoadUserRole retrieves the user role (and its associated role) with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUserRole(ctx context.Context, userRoleID int64) (*models.UserRole, error) {
+	var row userRoleRow
+
+	err := c.executor().GetContext(ctx, &row, "SELECT id, userid, roleid, autoadded, granted, validfrom, validuntil FROM userroles WHERE id=? AND roleid IN (SELECT id FROM roles WHERE deletedat IS NULL)", userRoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := c.LoadRole(ctx, row.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole := &models.UserRole{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Role:      role,
+		AutoAdded: row.AutoAdded,
+		Granted:   row.Granted,
+		Validity:  database.ValidityFromTimes(row.ValidFrom, row.ValidUntil),
+	}
+
+	return userRole, nil
+}
+
+// LoadGroup retrieves the group (and its associated group roles and sub groups) with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadGroup(ctx context.Context, groupID int64) (*models.Group, error) {
+	return c.loadGroup(ctx, groupID, make(map[int64]bool))
+}
+
+// loadGroup retrieves the group (and its associated group roles and sub groups) with the given ID, ignoring nestings leading back to a group on the given path
+func (c *DatabaseConnection) loadGroup(ctx context.Context, groupID int64, path map[int64]bool) (*models.Group, error) {
+	group := &models.Group{}
+
+	err := c.executor().GetContext(ctx, group, "SELECT id, name, active, version, visibility FROM groups WHERE id=? AND deletedat IS NULL", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRoles, err := c.LoadAllGroupRolesForGroup(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.GroupRoles = groupRoles
+
+	err = c.loadSubGroups(ctx, group, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// loadSubGroups retrieves all groups directly nested in the given group (and the groups nested in them) from the SQLite database, ignoring nestings leading back to a group on the given path
+func (c *DatabaseConnection) loadSubGroups(ctx context.Context, group *models.Group, path map[int64]bool) error {
+	var subGroupIDs []int64
+
+	err := c.executor().SelectContext(ctx, &subGroupIDs, "SELECT subgroupid FROM groupnestings WHERE groupid=? AND subgroupid IN (SELECT id FROM groups WHERE deletedat IS NULL) ORDER BY subgroupid", group.ID)
+	if err != nil {
+		return err
+	}
+
+	path[group.ID] = true
+	defer delete(path, group.ID)
+
+	for _, subGroupID := range subGroupIDs {
+		if path[subGroupID] {
+			continue
+		}
+
+		subGroup, err := c.loadGroup(ctx, subGroupID, path)
+		if err != nil {
+			return err
+		}
+
+		group.SubGroups = append(group.SubGroups, subGroup)
+	}
+
+	return nil
+}
+
+// LoadUser retrieves the user (and its associated groups and user roles) with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUser(ctx context.Context, userID int64) (*models.User, error) {
+	user := &models.User{}
+
+	err := c.executor().GetContext(ctx, user, "SELECT id, username, password, email, verifiedemail, active, version FROM users WHERE id=? AND deletedat IS NULL", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := c.LoadAllAccountsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoles, err := c.LoadAllUserRolesForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.LoadAllGroupsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupValidities, autoAddedGroups, err := c.loadGroupMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Accounts = accounts
+	user.UserRoles = userRoles
+	user.Groups = groups
+	user.GroupValidities = groupValidities
+	user.AutoAddedGroups = autoAddedGroups
+
+	err = database.LoadDefaultAffiliation(ctx, c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// LoadUserFromUsername retrieves the user (and its associated groups and user roles) with the given username from the database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUserFromUsername(ctx context.Context, username string) (*models.User, error) {
+	user := &models.User{}
+
+	err := c.executor().GetContext(ctx, user, "SELECT id, username, password, email, verifiedemail, active, version FROM users WHERE username LIKE ? AND deletedat IS NULL", username)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := c.LoadAllAccountsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoles, err := c.LoadAllUserRolesForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.LoadAllGroupsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupValidities, autoAddedGroups, err := c.loadGroupMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Accounts = accounts
+	user.UserRoles = userRoles
+	user.Groups = groups
+	user.GroupValidities = groupValidities
+	user.AutoAddedGroups = autoAddedGroups
+
+	err = database.LoadDefaultAffiliation(ctx, c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// LoadApplication retrieves the application with the given application ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadApplication(ctx context.Context, applicationID int64) (*models.Application, error) {
+	application := &models.Application{}
+
+	err := c.executor().GetContext(ctx, application, "SELECT id, name, maintainerid, secret, callback, active, version, rolescopes FROM applications WHERE id=? AND deletedat IS NULL", applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+// LoadGroupApplication retrieves the group application with the given ID from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadGroupApplication(ctx context.Context, groupApplicationID int64) (*models.GroupApplication, error) {
+	groupApplication := &models.GroupApplication{}
+
+	err := c.executor().GetContext(ctx, groupApplication, "SELECT id, groupid, userid, status, message, comment, deciderid, createdat, decidedat FROM groupapplications WHERE id=? AND groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) AND userid IN (SELECT id FROM users WHERE deletedat IS NULL)", groupApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupApplication, nil
+}
+
+// LoadAllAccountsForUser retrieves all accounts associated with the given user from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllAccountsForUser(ctx context.Context, userID int64) ([]*models.Account, error) {
+	var accounts []*models.Account
+
+	err := c.executor().SelectContext(ctx, &accounts, "SELECT id, userid, apikeyid, apivcode, apiaccessmask, active FROM accounts WHERE userid=?", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		characters, err := c.LoadAllCharactersForAccount(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		account.Characters = characters
+	}
+
+	return accounts, nil
+}
+
+// LoadAllCharactersForAccount retrieves all characters associated with the given account from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllCharactersForAccount(ctx context.Context, accountID int64) ([]*models.Character, error) {
+	var characters []*models.Character
+
+	err := c.executor().SelectContext(ctx, &characters, "SELECT id, accountid, corporationid, name, evecharacterid, defaultcharacter, active FROM characters WHERE accountid=?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+// LoadAllGroupRolesForGroup retrieves all group roles (and their associated roles) associated with the given group from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.GroupRole, error) {
+	var groupRoles []*models.GroupRole
+
+	var rows []*groupRoleRow
+
+	err := c.executor().SelectContext(ctx, &rows, "SELECT id, groupid, roleid, autoadded, granted FROM grouproles WHERE groupid=? AND roleid IN (SELECT id FROM roles WHERE deletedat IS NULL)", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		role, err := c.LoadRole(ctx, row.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		groupRole := &models.GroupRole{
+			ID:        row.ID,
+			GroupID:   row.GroupID,
+			Role:      role,
+			AutoAdded: row.AutoAdded,
+			Granted:   row.Granted,
+		}
+
+		groupRoles = append(groupRoles, groupRole)
+	}
+
+	return groupRoles, nil
+}
+
+// LoadAllMembershipRulesForGroup retrieves all membership rules associated with the given group from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllMembershipRulesForGroup(ctx context.Context, groupID int64) ([]*models.MembershipRule, error) {
+	var membershipRules []*models.MembershipRule
+
+	err := c.executor().SelectContext(ctx, &membershipRules, "SELECT id, groupid, type, targetid FROM membershiprules WHERE groupid=?", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return membershipRules, nil
+}
+
+// LoadAllGroupManagersForGroup retrieves all manager assignments of users not in the trash associated with the given group from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupManagersForGroup(ctx context.Context, groupID int64) ([]*models.GroupManager, error) {
+	var groupManagers []*models.GroupManager
+
+	err := c.executor().SelectContext(ctx, &groupManagers, "SELECT id, groupid, userid FROM groupmanagers WHERE groupid=? AND userid IN (SELECT id FROM users WHERE deletedat IS NULL) ORDER BY id", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupManagers, nil
+}
+
+// LoadAllManagerActionsForGroup retrieves all manager actions performed on the given group from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllManagerActionsForGroup(ctx context.Context, groupID int64) ([]*models.ManagerAction, error) {
+	var managerActions []*models.ManagerAction
+
+	err := c.executor().SelectContext(ctx, &managerActions, "SELECT id, groupid, actorid, userid, type, timestamp FROM manageractions WHERE groupid=? ORDER BY id", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return managerActions, nil
+}
+
+// LoadAllGroupApplicationsForUser retrieves all applications of the given user for groups not in the trash from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupApplicationsForUser(ctx context.Context, userID int64) ([]*models.GroupApplication, error) {
+	var groupApplications []*models.GroupApplication
+
+	err := c.executor().SelectContext(ctx, &groupApplications, "SELECT id, groupid, userid, status, message, comment, deciderid, createdat, decidedat FROM groupapplications WHERE userid=? AND groupid IN (SELECT id FROM groups WHERE deletedat IS NULL) ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupApplications, nil
+}
+
+// LoadAllUserRolesForUser retrieves all user roles (and their associated roles) associated with the given user from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllUserRolesForUser(ctx context.Context, userID int64) ([]*models.UserRole, error) {
+	// For whatever weird reason, only using "var userRoles []*models.UserRole" does not work in this case and throws an error...
+	var userRoles []*models.UserRole
+	userRoles = make([]*models.UserRole, 0)
+
+	var rows []*userRoleRow
+
+	err := c.executor().SelectContext(ctx, &rows, "SELECT id, userid, roleid, autoadded, granted, validfrom, validuntil FROM userroles WHERE userid=? AND roleid IN (SELECT id FROM roles WHERE deletedat IS NULL)", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		role, err := c.LoadRole(ctx, row.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		userRole := &models.UserRole{
+			ID:        row.ID,
+			UserID:    row.UserID,
+			Role:      role,
+			AutoAdded: row.AutoAdded,
+			Granted:   row.Granted,
+			Validity:  database.ValidityFromTimes(row.ValidFrom, row.ValidUntil),
+		}
+
+		userRoles = append(userRoles, userRole)
+	}
+
+	return userRoles, nil
+}
+
+// LoadAllGroupsForUser retrieves all groups (and their associated group roles and sub groups) the given user is a direct member of from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	// For whatever weird reason, only using "var groups []*models.Group" does not work in this case and throws an error...
+	var groups []*models.Group
+	groups = make([]*models.Group, 0)
+
+	err := c.executor().SelectContext(ctx, &groups, "SELECT g.id, g.name, g.active, g.version, g.visibility FROM groups AS g INNER JOIN usergroups AS ug ON (g.id = ug.groupid) WHERE g.deletedat IS NULL AND ug.active=1 AND ug.userid=? GROUP BY g.id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		groupRoles, err := c.LoadAllGroupRolesForGroup(ctx, group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(ctx, group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// loadGroupMemberships retrieves the validity windows of all time-limited group memberships as well as the group memberships added by membership rules of the given user, both indexed by the group ID.
+// No map is returned if the user has no memberships of the respective kind
+func (c *DatabaseConnection) loadGroupMemberships(ctx context.Context, userID int64) (map[int64]*models.Validity, map[int64]bool, error) {
+	var rows []*groupMembershipRow
+
+	err := c.executor().SelectContext(ctx, &rows, "SELECT groupid, autoadded, validfrom, validuntil FROM usergroups WHERE userid=? AND active=1 AND (autoadded=1 OR validfrom IS NOT NULL OR validuntil IS NOT NULL)", userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var groupValidities map[int64]*models.Validity
+	var autoAddedGroups map[int64]bool
+
+	for _, row := range rows {
+		validity := database.ValidityFromTimes(row.ValidFrom, row.ValidUntil)
+		if validity != nil {
+			if groupValidities == nil {
+				groupValidities = make(map[int64]*models.Validity)
+			}
+
+			groupValidities[row.GroupID] = validity
+		}
+
+		if row.AutoAdded {
+			if autoAddedGroups == nil {
+				autoAddedGroups = make(map[int64]bool)
+			}
+
+			autoAddedGroups[row.GroupID] = true
+		}
+	}
+
+	return groupValidities, autoAddedGroups, nil
+}
+
+// LoadAvailableGroupsForUser retrieves all available groups (and their associated group roles and sub groups) associated with the given user from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAvailableGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	// For whatever weird reason, only using "var groups []*models.Group" does not work in this case and throws an error...
+	var groups []*models.Group
+	groups = make([]*models.Group, 0)
+
+	err := c.executor().SelectContext(ctx, &groups, "SELECT g.id, g.name, g.active, g.version, g.visibility FROM groups AS g WHERE g.deletedat IS NULL AND g.id NOT IN (SELECT gi.id FROM groups AS gi INNER JOIN usergroups AS ug ON (gi.id = ug.groupid) WHERE ug.active=1 AND ug.userid=?) GROUP BY g.id ORDER BY g.name", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		groupRoles, err := c.LoadAllGroupRolesForGroup(ctx, group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(ctx, group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// LoadAllManagedGroupsForUser retrieves all groups (and their associated group roles and sub groups) managed by the given user from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllManagedGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	groups := make([]*models.Group, 0)
+
+	err := c.executor().SelectContext(ctx, &groups, "SELECT g.id, g.name, g.active, g.version, g.visibility FROM groups AS g INNER JOIN groupmanagers AS gm ON (g.id = gm.groupid) WHERE g.deletedat IS NULL AND gm.userid=? ORDER BY g.name", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		groupRoles, err := c.LoadAllGroupRolesForGroup(ctx, group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		group.GroupRoles = groupRoles
+
+		err = c.loadSubGroups(ctx, group, make(map[int64]bool))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// LoadAvailableUserRolesForUser retrieves all available user roles for the given user from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAvailableUserRolesForUser(ctx context.Context, userID int64) ([]*models.Role, error) {
+	// For whatever weird reason, only using "var roles []*models.Role" does not work in this case and throws an error...
+	var roles []*models.Role
+	roles = make([]*models.Role, 0)
+
+	err := c.executor().SelectContext(ctx, &roles, "SELECT r.id, r.name, r.active, r.locked, r.version, r.applicationid, r.policy FROM roles AS r WHERE r.deletedat IS NULL AND r.id NOT IN (SELECT ur.roleid FROM userroles AS ur WHERE ur.userid=?) GROUP BY r.id ORDER BY r.name", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// LoadAvailableGroupRolesForGroup retrieves all available group roles for the given group from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAvailableGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.Role, error) {
+	// For whatever weird reason, only using "var roles []*models.Role" does not work in this case and throws an error...
+	var roles []*models.Role
+	roles = make([]*models.Role, 0)
+
+	err := c.executor().SelectContext(ctx, &roles, "SELECT r.id, r.name, r.active, r.locked, r.version, r.applicationid, r.policy FROM roles AS r WHERE r.deletedat IS NULL AND r.id NOT IN (SELECT gr.roleid FROM grouproles AS gr WHERE gr.groupid=?) GROUP BY r.id ORDER BY r.name", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// LoadAllApplicationsForUser retrieves all applications associated with the given user from the database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllApplicationsForUser(ctx context.Context, userID int64) ([]*models.Application, error) {
+	var applications []*models.Application
+
+	err := c.executor().SelectContext(ctx, &applications, "SELECT id, name, maintainerid, secret, callback, active, version, rolescopes FROM applications WHERE maintainerid=? AND deletedat IS NULL", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+// LoadPasswordForUser retrieves the password associated with the given username from the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) LoadPasswordForUser(ctx context.Context, username string) (string, error) {
+	var password string
+
+	err := c.executor().GetContext(ctx, &password, "SELECT password FROM users WHERE username LIKE ? AND deletedat IS NULL", username)
+	if err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// QueryUserIDExists checks whether a user with the given user ID exists in the database, returning an error if the query failed
+func (c *DatabaseConnection) QueryUserIDExists(ctx context.Context, userID int64) (bool, error) {
+	var count int
+
+	err := c.executor().GetContext(ctx, &count, "SELECT COUNT(id) AS count FROM users WHERE id=? AND deletedat IS NULL", userID)
+	if err != nil {
+		return false, err
+	}
+
+	return (count > 0), nil
+}
+
+// QueryUserNameEmailExists checks whether a user with the given username or email address exists in the database, returning an error if the query failed
+func (c *DatabaseConnection) QueryUserNameEmailExists(ctx context.Context, username string, email string) (bool, error) {
+	var count int
+
+	err := c.executor().GetContext(ctx, &count, "SELECT COUNT(username) AS count FROM users WHERE username LIKE ? OR email LIKE ?", username, email)
+	if err != nil {
+		return false, err
+	}
+
+	return (count > 0), nil
+}
+
+// SaveAccount saves an account to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveAccount(ctx context.Context, account *models.Account) (*models.Account, error) {
+	if account.ID > 0 {
+		for _, character := range account.Characters {
+			char, err := c.SaveCharacter(ctx, character)
+			if err != nil {
+				return nil, err
+			}
+
+			character = char
+		}
+
+		_, err := c.executor().ExecContext(ctx, "UPDATE accounts SET userid=?, apikeyid=?, apivcode=?, apiaccessmask=?, active=? WHERE id=?", account.UserID, account.APIKeyID, account.APIvCode, account.APIAccessMask, account.Active, account.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO accounts(userid, apikeyid, apivcode, apiaccessmask, active) VALUES(?, ?, ?, ?, ?)", account.UserID, account.APIKeyID, account.APIvCode, account.APIAccessMask, account.Active)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		account.ID = lastInsertedID
+
+		for _, character := range account.Characters {
+			character.AccountID = account.ID
+
+			char, err := c.SaveCharacter(ctx, character)
+			if err != nil {
+				return nil, err
+			}
+
+			character = char
+		}
+	}
+
+	return account, nil
+}
+
+// SaveCorporation saves a corporation to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveCorporation(ctx context.Context, corporation *models.Corporation) (*models.Corporation, error) {
+	if corporation.ID > 0 {
+		_, err := c.executor().ExecContext(ctx, "UPDATE corporations SET name=?, ticker=?, evecorporationid=?, ceoid=?, allianceid=?, apikeyid=?, apivcode=?, active=? WHERE id=?", corporation.Name, corporation.Ticker, corporation.EVECorporationID, corporation.CEOID, corporation.AllianceID, corporation.APIKeyID, corporation.APIvCode, corporation.Active, corporation.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO corporations(name, ticker, evecorporationid, ceoid, allianceid, apikeyid, apivcode, active) VALUES(?, ?, ?, ?, ?, ?, ?, ?)", corporation.Name, corporation.Ticker, corporation.EVECorporationID, corporation.CEOID, corporation.AllianceID, corporation.APIKeyID, corporation.APIvCode, corporation.Active)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		corporation.ID = lastInsertedID
+	}
+
+	return corporation, nil
+}
+
+// SaveAlliance saves an alliance to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveAlliance(ctx context.Context, alliance *models.Alliance) (*models.Alliance, error) {
+	if alliance.ID > 0 {
+		_, err := c.executor().ExecContext(ctx, "UPDATE alliances SET name=?, ticker=?, eveallianceid=?, executorcorpid=?, active=? WHERE id=?", alliance.Name, alliance.Ticker, alliance.EVEAllianceID, alliance.ExecutorCorpID, alliance.Active, alliance.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO alliances(name, ticker, eveallianceid, executorcorpid, active) VALUES(?, ?, ?, ?, ?)", alliance.Name, alliance.Ticker, alliance.EVEAllianceID, alliance.ExecutorCorpID, alliance.Active)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		alliance.ID = lastInsertedID
+	}
+
+	return alliance, nil
+}
+
+// SaveCharacter saves a character to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveCharacter(ctx context.Context, character *models.Character) (*models.Character, error) {
+	if character.ID > 0 {
+		_, err := c.executor().ExecContext(ctx, "UPDATE characters SET accountid=?, corporationid=?, name=?, evecharacterid=?, defaultcharacter=?, active=? WHERE id=?", character.AccountID, character.CorporationID, character.Name, character.EVECharacterID, character.DefaultCharacter, character.Active, character.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO characters(accountid, corporationid, name, evecharacterid, defaultcharacter, active) VALUES(?, ?, ?, ?, ?, ?)", character.AccountID, character.CorporationID, character.Name, character.EVECharacterID, character.DefaultCharacter, character.Active)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		character.ID = lastInsertedID
+	}
+
+	return character, nil
+}
+
+// SaveRole saves a role to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	if role.ID > 0 {
+		resp, err := c.executor().ExecContext(ctx, "UPDATE roles SET name=?, active=?, locked=?, applicationid=?, policy=?, version=version+1 WHERE id=? AND version=?", role.Name, role.Active, role.Locked, role.ApplicationID, role.Policy, role.ID, role.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkVersion(resp, "role", role.ID, role.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		role.Version++
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO roles(name, active, locked, applicationid, policy) VALUES(?, ?, ?, ?, ?)", role.Name, role.Active, role.Locked, role.ApplicationID, role.Policy)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		role.ID = lastInsertedID
+		role.Version = 1
+	}
+
+	return role, nil
+}
+
+// SaveGroupRole saves a group role to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveGroupRole(ctx context.Context, groupRole *models.GroupRole) (*models.GroupRole, error) {
+	role, err := c.SaveRole(ctx, groupRole.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRole.Role = role
+
+	if groupRole.ID > 0 {
+		_, err = c.executor().ExecContext(ctx, "UPDATE grouproles SET groupid=?, roleid=?, autoadded=?, granted=? WHERE id=?", groupRole.GroupID, groupRole.Role.ID, groupRole.AutoAdded, groupRole.Granted, groupRole.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO grouproles(groupid, roleid, autoadded, granted) VALUES(?, ?, ?, ?)", groupRole.GroupID, groupRole.Role.ID, groupRole.AutoAdded, groupRole.Granted)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		groupRole.ID = lastInsertedID
+	}
+
+	return groupRole, nil
+}
+
+// SaveUserRole saves a user role to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveUserRole(ctx context.Context, userRole *models.UserRole) (*models.UserRole, error) {
+	role, err := c.SaveRole(ctx, userRole.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole.Role = role
+
+	validFrom, validUntil := database.ValidityTimes(userRole.Validity)
+
+	if userRole.ID > 0 {
+		_, err = c.executor().ExecContext(ctx, "UPDATE userroles SET userid=?, roleid=?, autoadded=?, granted=?, validfrom=?, validuntil=? WHERE id=?", userRole.UserID, userRole.Role.ID, userRole.AutoAdded, userRole.Granted, validFrom, validUntil, userRole.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO userroles(userid, roleid, autoadded, granted, validfrom, validuntil) VALUES(?, ?, ?, ?, ?, ?)", userRole.UserID, userRole.Role.ID, userRole.AutoAdded, userRole.Granted, validFrom, validUntil)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		userRole.ID = lastInsertedID
+	}
+
+	return userRole, nil
+}
+
+// SaveRoleImplications replaces the roles directly implied by the role with the given ID in the SQLite database, returning an error if the query failed.
+// Implications causing the role to imply itself return an *ImplicationCycleError. All queries are performed within a single transaction
+func (c *DatabaseConnection) SaveRoleImplications(ctx context.Context, roleID int64, impliedRoleIDs []int64) error {
+	return c.transaction(ctx, func(tx *DatabaseConnection) error {
+		_, err := tx.LoadRole(ctx, roleID)
+		if err != nil {
+			return err
+		}
+
+		impliedRoles := make([]*models.Role, 0, len(impliedRoleIDs))
+		seen := make(map[int64]bool)
+
+		for _, impliedRoleID := range impliedRoleIDs {
+			if seen[impliedRoleID] {
+				continue
+			}
+
+			seen[impliedRoleID] = true
+
+			impliedRole, err := tx.LoadRole(ctx, impliedRoleID)
+			if err != nil {
+				return err
+			}
+
+			impliedRoles = append(impliedRoles, impliedRole)
+		}
+
+		err = database.CheckRoleImplications(roleID, impliedRoles)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.executor().ExecContext(ctx, "DELETE FROM roleimplications WHERE roleid=?", roleID)
+		if err != nil {
+			return err
+		}
+
+		for _, impliedRole := range impliedRoles {
+			_, err = tx.executor().ExecContext(ctx, "INSERT INTO roleimplications(roleid, impliedroleid) VALUES(?, ?)", roleID, impliedRole.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.touch(ctx, "roles", roleID)
+	})
+}
+
+// SaveSubGroups replaces the groups directly nested in the group with the given ID in the SQLite database, returning an error if the query failed.
+// Nestings causing the group to contain itself return a *NestingCycleError. All queries are performed within a single transaction
+func (c *DatabaseConnection) SaveSubGroups(ctx context.Context, groupID int64, subGroupIDs []int64) error {
+	return c.transaction(ctx, func(tx *DatabaseConnection) error {
+		_, err := tx.LoadGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		subGroups := make([]*models.Group, 0, len(subGroupIDs))
+		seen := make(map[int64]bool)
+
+		for _, subGroupID := range subGroupIDs {
+			if seen[subGroupID] {
+				continue
+			}
+
+			seen[subGroupID] = true
+
+			subGroup, err := tx.LoadGroup(ctx, subGroupID)
+			if err != nil {
+				return err
+			}
+
+			subGroups = append(subGroups, subGroup)
+		}
+
+		err = database.CheckSubGroups(groupID, subGroups)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.executor().ExecContext(ctx, "DELETE FROM groupnestings WHERE groupid=?", groupID)
+		if err != nil {
+			return err
+		}
+
+		for _, subGroup := range subGroups {
+			_, err = tx.executor().ExecContext(ctx, "INSERT INTO groupnestings(groupid, subgroupid) VALUES(?, ?)", groupID, subGroup.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.touch(ctx, "groups", groupID)
+	})
+}
+
+// SaveMembershipRule saves a membership rule to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveMembershipRule(ctx context.Context, membershipRule *models.MembershipRule) (*models.MembershipRule, error) {
+	if membershipRule.ID > 0 {
+		_, err := c.executor().ExecContext(ctx, "UPDATE membershiprules SET groupid=?, type=?, targetid=? WHERE id=?", membershipRule.GroupID, membershipRule.Type, membershipRule.TargetID, membershipRule.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO membershiprules(groupid, type, targetid) VALUES(?, ?, ?)", membershipRule.GroupID, membershipRule.Type, membershipRule.TargetID)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		membershipRule.ID = lastInsertedID
+	}
+
+	return membershipRule, nil
+}
+
+// SaveGroupManager saves a group manager assignment to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveGroupManager(ctx context.Context, groupManager *models.GroupManager) (*models.GroupManager, error) {
+	if groupManager.ID > 0 {
+		_, err := c.executor().ExecContext(ctx, "UPDATE groupmanagers SET groupid=?, userid=? WHERE id=?", groupManager.GroupID, groupManager.UserID, groupManager.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO groupmanagers(groupid, userid) VALUES(?, ?)", groupManager.GroupID, groupManager.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		groupManager.ID = lastInsertedID
+	}
+
+	return groupManager, nil
+}
+
+// SaveGroup saves a group to the SQLite database, returning the updated model or an error if the query failed. All queries are performed within a single transaction,
+// leaving the IDs and versions of the given model untouched if it fails
+func (c *DatabaseConnection) SaveGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	var result *models.Group
+
+	snapshot := database.SnapshotGroup(group)
+
+	err := c.transaction(ctx, func(tx *DatabaseConnection) error {
+		var err error
+		result, err = tx.saveGroup(ctx, group)
+		return err
+	})
+	if err != nil {
+		snapshot.Restore()
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// saveGroup performs the queries required by SaveGroup, expecting to be run within a transaction
+func (c *DatabaseConnection) saveGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	if group.ID > 0 {
+		resp, err := c.executor().ExecContext(ctx, "UPDATE groups SET name=?, active=?, visibility=?, version=version+1 WHERE id=? AND version=?", group.Name, group.Active, group.Visibility, group.ID, group.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkVersion(resp, "group", group.ID, group.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		group.Version++
+
+		for _, groupRole := range group.GroupRoles {
+			role, err := c.SaveGroupRole(ctx, groupRole)
+			if err != nil {
+				return nil, err
+			}
+
+			groupRole = role
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO groups(name, active, visibility) VALUES(?, ?, ?)", group.Name, group.Active, group.Visibility)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		group.ID = lastInsertedID
+		group.Version = 1
+
+		for _, groupRole := range group.GroupRoles {
+			groupRole.GroupID = group.ID
+
+			role, err := c.SaveGroupRole(ctx, groupRole)
+			if err != nil {
+				return nil, err
+			}
+
+			groupRole = role
+		}
+	}
+
+	return group, nil
+}
+
+// SaveUser saves a user to the SQLite database, returning the updated model or an error if the query failed. All queries are performed within a single transaction,
+// leaving the IDs and versions of the given model untouched if it fails
+func (c *DatabaseConnection) SaveUser(ctx context.Context, user *models.User) (*models.User, error) {
+	var result *models.User
+
+	snapshot := database.SnapshotUser(user)
+
+	err := c.transaction(ctx, func(tx *DatabaseConnection) error {
+		var err error
+		result, err = tx.saveUser(ctx, user)
+		return err
+	})
+	if err != nil {
+		snapshot.Restore()
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// saveUser performs the queries required by SaveUser, expecting to be run within a transaction
+func (c *DatabaseConnection) saveUser(ctx context.Context, user *models.User) (*models.User, error) {
+	if user.ID > 0 {
+		resp, err := c.executor().ExecContext(ctx, "UPDATE users SET username=?, password=?, email=?, verifiedemail=?, active=?, version=version+1 WHERE id=? AND version=?", user.Username, user.Password, user.Email, user.VerifiedEmail, user.Active, user.ID, user.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkVersion(resp, "user", user.ID, user.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Version++
+
+		for _, account := range user.Accounts {
+			acc, err := c.SaveAccount(ctx, account)
+			if err != nil {
+				return nil, err
+			}
+
+			account = acc
+		}
+
+		for _, userRole := range user.UserRoles {
+			role, err := c.SaveUserRole(ctx, userRole)
+			if err != nil {
+				return nil, err
+			}
+
+			userRole = role
+		}
+
+		groups, err := c.SaveAllGroupsForUser(ctx, user.ID, user.Groups, user.GroupValidities, user.AutoAddedGroups)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Groups = groups
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO users(username, password, email, verifiedemail, active) VALUES(?, ?, ?, ?, ?)", user.Username, user.Password, user.Email, user.VerifiedEmail, user.Active)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		user.ID = lastInsertedID
+		user.Version = 1
+
+		for _, account := range user.Accounts {
+			account.UserID = user.ID
+
+			acc, err := c.SaveAccount(ctx, account)
+			if err != nil {
+				return nil, err
+			}
+
+			account = acc
+		}
+
+		for _, userRole := range user.UserRoles {
+			userRole.UserID = user.ID
+
+			role, err := c.SaveUserRole(ctx, userRole)
+			if err != nil {
+				return nil, err
+			}
+
+			userRole = role
+		}
+
+		groups, err := c.SaveAllGroupsForUser(ctx, user.ID, user.Groups, user.GroupValidities, user.AutoAddedGroups)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Groups = groups
+	}
+
+	return user, nil
+}
+
+// SaveApplication saves an application to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveApplication(ctx context.Context, application *models.Application) (*models.Application, error) {
+	if application.ID > 0 {
+		resp, err := c.executor().ExecContext(ctx, "UPDATE applications SET name=?, maintainerid=?, secret=?, callback=?, active=?, rolescopes=?, version=version+1 WHERE id=? AND version=?", application.Name, application.MaintainerID, application.Secret, application.Callback, application.Active, application.RoleScopes, application.ID, application.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkVersion(resp, "application", application.ID, application.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		application.Version++
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO applications(name, maintainerid, secret, callback, active, rolescopes) VALUES(?, ?, ?, ?, ?, ?)", application.Name, application.MaintainerID, application.Secret, application.Callback, application.Active, application.RoleScopes)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		application.ID = lastInsertedID
+		application.Version = 1
+	}
+
+	return application, nil
+}
+
+// SaveLoginAttempt saves a login attempt to the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) SaveLoginAttempt(ctx context.Context, loginAttempt *models.LoginAttempt) error {
+	if loginAttempt.Timestamp.IsZero() {
+		loginAttempt.Timestamp = time.Now()
+	}
+
+	_, err := c.executor().ExecContext(ctx, "INSERT INTO loginattempts(username, remoteaddr, useragent, successful, timestamp) VALUES(?, ?, ?, ?, ?)", loginAttempt.Username, loginAttempt.RemoteAddr, loginAttempt.UserAgent, loginAttempt.Successful, loginAttempt.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveLoginAttemptSummary adds the counts of the given summary to the stored summary of the same day in the SQLite database, creating it if required. An error is returned if the query failed
+func (c *DatabaseConnection) SaveLoginAttemptSummary(ctx context.Context, summary *models.LoginAttemptSummary) error {
+	_, err := c.executor().ExecContext(ctx, "INSERT INTO loginattemptsummaries(day, failed, successful) VALUES(?, ?, ?) ON CONFLICT(day) DO UPDATE SET failed=failed+excluded.failed, successful=successful+excluded.successful", summary.Day, summary.Failed, summary.Successful)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveCSRFFailure saves a CSRF failure to the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) SaveCSRFFailure(ctx context.Context, csrfFailure *models.CSRFFailure) error {
+	if csrfFailure.Timestamp.IsZero() {
+		csrfFailure.Timestamp = time.Now()
+	}
+
+	_, err := c.executor().ExecContext(ctx, "INSERT INTO csrffailures(userid, request, timestamp) VALUES(?, ?, ?)", csrfFailure.UserID, csrfFailure.Request, csrfFailure.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveManagerAction saves a manager action to the SQLite database, returning an error if the query failed
+func (c *DatabaseConnection) SaveManagerAction(ctx context.Context, managerAction *models.ManagerAction) error {
+	if managerAction.Timestamp.IsZero() {
+		managerAction.Timestamp = time.Now()
+	}
+
+	_, err := c.executor().ExecContext(ctx, "INSERT INTO manageractions(groupid, actorid, userid, type, timestamp) VALUES(?, ?, ?, ?, ?)", managerAction.GroupID, managerAction.ActorID, managerAction.UserID, managerAction.Type, managerAction.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveGroupApplication saves a group application to the SQLite database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveGroupApplication(ctx context.Context, groupApplication *models.GroupApplication) (*models.GroupApplication, error) {
+	if groupApplication.CreatedAt.IsZero() {
+		groupApplication.CreatedAt = time.Now()
+	}
+
+	if groupApplication.ID > 0 {
+		_, err := c.executor().ExecContext(ctx, "UPDATE groupapplications SET groupid=?, userid=?, status=?, message=?, comment=?, deciderid=?, createdat=?, decidedat=? WHERE id=?", groupApplication.GroupID, groupApplication.UserID, groupApplication.Status, groupApplication.Message, groupApplication.Comment, groupApplication.DeciderID, groupApplication.CreatedAt, groupApplication.DecidedAt, groupApplication.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.executor().ExecContext(ctx, "INSERT INTO groupapplications(groupid, userid, status, message, comment, deciderid, createdat, decidedat) VALUES(?, ?, ?, ?, ?, ?, ?, ?)", groupApplication.GroupID, groupApplication.UserID, groupApplication.Status, groupApplication.Message, groupApplication.Comment, groupApplication.DeciderID, groupApplication.CreatedAt, groupApplication.DecidedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		groupApplication.ID = lastInsertedID
+	}
+
+	return groupApplication, nil
+}
+
+// SaveAllGroupsForUser saves all group memberships for the user, limiting them to the validity windows given for their group IDs and marking the ones added by membership rules
+func (c *DatabaseConnection) SaveAllGroupsForUser(ctx context.Context, userID int64, groups []*models.Group, validities map[int64]*models.Validity, autoAdded map[int64]bool) ([]*models.Group, error) {
+	for _, group := range groups {
+		validFrom, validUntil := database.ValidityTimes(validities[group.ID])
+
+		_, err := c.executor().ExecContext(ctx, "INSERT INTO usergroups(userid, groupid, active, autoadded, validfrom, validuntil) VALUES(?, ?, ?, ?, ?, ?) ON CONFLICT(userid, groupid) DO UPDATE SET active=excluded.active, autoadded=excluded.autoadded, validfrom=excluded.validfrom, validuntil=excluded.validuntil", userID, group.ID, true, autoAdded[group.ID], validFrom, validUntil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// DeleteAccount removes an account and all associated characters from the SQLite database
+func (c *DatabaseConnection) DeleteAccount(ctx context.Context, accountID int64) error {
+	_, err := c.executor().ExecContext(ctx, "UPDATE users SET version=version+1 WHERE id IN (SELECT userid FROM accounts WHERE id=?)", accountID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM characters WHERE accountid=?", accountID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM accounts WHERE id=?", accountID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCharacter removes a character from the SQLite database
+func (c *DatabaseConnection) DeleteCharacter(ctx context.Context, characterID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM characters WHERE id=?", characterID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRole moves a role to the trash of the SQLite database, hiding it and all user and group roles associated until it is restored or purged
+func (c *DatabaseConnection) DeleteRole(ctx context.Context, roleID int64, deletedBy int64) error {
+	return c.moveToTrash(ctx, "roles", roleID, deletedBy)
+}
+
+// purgeRole permanently removes a role and all user roles, group roles and implications associated, expecting to be run within a transaction
+func (c *DatabaseConnection) purgeRole(ctx context.Context, roleID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM roleimplications WHERE roleid=? OR impliedroleid=?", roleID, roleID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM userroles WHERE roleid=?", roleID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM grouproles WHERE roleid=?", roleID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM roles WHERE id=?", roleID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroupRole removes a group role from the SQLite database
+func (c *DatabaseConnection) DeleteGroupRole(ctx context.Context, groupRoleID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM grouproles WHERE id=?", groupRoleID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUserRole removes a user role from the SQLite database
+func (c *DatabaseConnection) DeleteUserRole(ctx context.Context, userRoleID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM userroles WHERE id=?", userRoleID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMembershipRule removes a membership rule from the SQLite database. Memberships added by the rule are kept until the next reconciliation
+func (c *DatabaseConnection) DeleteMembershipRule(ctx context.Context, membershipRuleID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM membershiprules WHERE id=?", membershipRuleID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroupManager removes a group manager assignment from the SQLite database. Actions recorded for the manager are kept
+func (c *DatabaseConnection) DeleteGroupManager(ctx context.Context, groupManagerID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM groupmanagers WHERE id=?", groupManagerID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroup moves a group to the trash of the SQLite database, hiding it and all associated group memberships and roles until it is restored or purged
+func (c *DatabaseConnection) DeleteGroup(ctx context.Context, groupID int64, deletedBy int64) error {
+	return c.moveToTrash(ctx, "groups", groupID, deletedBy)
+}
+
+// purgeGroup permanently removes a group and all associated group memberships, roles, membership rules, nestings, manager assignments and group applications, expecting to be run within a transaction
+func (c *DatabaseConnection) purgeGroup(ctx context.Context, groupID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM groupnestings WHERE groupid=? OR subgroupid=?", groupID, groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM groupmanagers WHERE groupid=?", groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM groupapplications WHERE groupid=?", groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM grouproles WHERE groupid=?", groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM membershiprules WHERE groupid=?", groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM usergroups WHERE groupid=?", groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM groups WHERE id=?", groupID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUser moves a user to the trash of the SQLite database, hiding it and all associated group memberships, roles and accounts until it is restored or purged
+func (c *DatabaseConnection) DeleteUser(ctx context.Context, userID int64, deletedBy int64) error {
+	return c.moveToTrash(ctx, "users", userID, deletedBy)
+}
+
+// purgeUser permanently removes a user and all associated group memberships, manager assignments, group applications, roles and accounts, expecting to be run within a transaction.
+// The user must not maintain any applications anymore, see PurgeTrash
+func (c *DatabaseConnection) purgeUser(ctx context.Context, userID int64) error {
+	_, err := c.executor().ExecContext(ctx, "DELETE FROM usergroups WHERE userid=?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM groupmanagers WHERE userid=?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM groupapplications WHERE userid=?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM userroles WHERE userid=?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM characters WHERE accountid IN (SELECT id FROM accounts WHERE userid=?)", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM accounts WHERE userid=?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM users WHERE id=?", userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteApplication moves an application to the trash of the SQLite database, hiding it until it is restored or purged
+func (c *DatabaseConnection) DeleteApplication(ctx context.Context, appID int64, deletedBy int64) error {
+	return c.moveToTrash(ctx, "applications", appID, deletedBy)
+}
+
+// purgeApplication permanently removes an application and all roles of its namespace, including deleted ones, expecting to be run within a transaction
+func (c *DatabaseConnection) purgeApplication(ctx context.Context, appID int64) error {
+	var roleIDs []int64
+
+	err := c.executor().SelectContext(ctx, &roleIDs, "SELECT id FROM roles WHERE applicationid=?", appID)
+	if err != nil {
+		return err
+	}
+
+	for _, roleID := range roleIDs {
+		err = c.purgeRole(ctx, roleID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM applications WHERE id=?", appID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadTrash retrieves all deleted users, groups, roles and applications from the SQLite database, most recently deleted first, returning an error if the query failed
+func (c *DatabaseConnection) LoadTrash(ctx context.Context) ([]*models.TrashEntry, error) {
+	var trash []*models.TrashEntry
+
+	err := c.executor().SelectContext(ctx, &trash, "SELECT 'user' AS type, id, username AS name, deletedat, deletedby FROM users WHERE deletedat IS NOT NULL UNION ALL SELECT 'group' AS type, id, name, deletedat, deletedby FROM groups WHERE deletedat IS NOT NULL UNION ALL SELECT 'role' AS type, id, name, deletedat, deletedby FROM roles WHERE deletedat IS NOT NULL UNION ALL SELECT 'application' AS type, id, name, deletedat, deletedby FROM applications WHERE deletedat IS NOT NULL ORDER BY deletedat DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+// RestoreRole restores a deleted role including all user and group roles associated in the SQLite database, returning an error if the role is not in the trash
+func (c *DatabaseConnection) RestoreRole(ctx context.Context, roleID int64) error {
+	return c.restoreFromTrash(ctx, "roles", roleID)
+}
+
+// RestoreGroup restores a deleted group including all associated group memberships and roles in the SQLite database, returning an error if the group is not in the trash
+func (c *DatabaseConnection) RestoreGroup(ctx context.Context, groupID int64) error {
+	return c.restoreFromTrash(ctx, "groups", groupID)
+}
+
+// RestoreUser restores a deleted user including all associated group memberships, roles and accounts in the SQLite database, returning an error if the user is not in the trash
+func (c *DatabaseConnection) RestoreUser(ctx context.Context, userID int64) error {
+	return c.restoreFromTrash(ctx, "users", userID)
+}
+
+// RestoreApplication restores a deleted application in the SQLite database, returning an error if the application is not in the trash
+func (c *DatabaseConnection) RestoreApplication(ctx context.Context, appID int64) error {
+	return c.restoreFromTrash(ctx, "applications", appID)
+}
+
+// PurgeTrash permanently removes all entities deleted before the given time as well as their associations from the SQLite database, returning the number of purged entities.
+// Users still maintaining applications are kept and their applications are moved to the trash instead, so both are purged once the applications expire. All queries are performed within a single transaction
+func (c *DatabaseConnection) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+
+	err := c.transaction(ctx, func(tx *DatabaseConnection) error {
+		purgers := []struct {
+			table     string
+			condition string
+			purge     func(context.Context, int64) error
+		}{
+			{"applications", "", tx.purgeApplication},
+			{"users", " AND id NOT IN (SELECT maintainerid FROM applications)", tx.purgeUser},
+			{"groups", "", tx.purgeGroup},
+			{"roles", "", tx.purgeRole},
+		}
+
+		for _, purger := range purgers {
+			var ids []int64
+
+			err := tx.executor().SelectContext(ctx, &ids, "SELECT id FROM "+purger.table+" WHERE deletedat IS NOT NULL AND deletedat<?"+purger.condition, deletedBefore.UTC())
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				err = purger.purge(ctx, id)
+				if err != nil {
+					return err
+				}
+			}
+
+			purged += int64(len(ids))
+		}
+
+		_, err := tx.executor().ExecContext(ctx, "UPDATE applications SET deletedat=?, deletedby=(SELECT deletedby FROM users WHERE users.id=applications.maintainerid) WHERE deletedat IS NULL AND maintainerid IN (SELECT id FROM users WHERE deletedat IS NOT NULL AND deletedat<?)", time.Now().UTC(), deletedBefore.UTC())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// PruneLoginAttempts adds all login attempts made before the given time to the daily summaries and removes them from the SQLite database, returning the number of removed login attempts. All queries are performed within a single transaction
+func (c *DatabaseConnection) PruneLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	var pruned int64
+
+	err := c.transaction(ctx, func(tx *DatabaseConnection) error {
+		var summaries []*models.LoginAttemptSummary
+
+		err := tx.executor().SelectContext(ctx, &summaries, "SELECT strftime('%Y-%m-%d', timestamp) AS day, SUM(CASE WHEN successful THEN 0 ELSE 1 END) AS failed, SUM(CASE WHEN successful THEN 1 ELSE 0 END) AS successful FROM loginattempts WHERE julianday(timestamp)<julianday(?) GROUP BY 1", before.UTC())
+		if err != nil {
+			return err
+		}
+
+		for _, summary := range summaries {
+			err = tx.SaveLoginAttemptSummary(ctx, summary)
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err := tx.executor().ExecContext(ctx, "DELETE FROM loginattempts WHERE julianday(timestamp)<julianday(?)", before.UTC())
+		if err != nil {
+			return err
+		}
+
+		pruned, err = resp.RowsAffected()
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+// PruneCSRFFailures removes all CSRF failures recorded before the given time from the SQLite database, returning the number of removed CSRF failures
+func (c *DatabaseConnection) PruneCSRFFailures(ctx context.Context, before time.Time) (int64, error) {
+	resp, err := c.executor().ExecContext(ctx, "DELETE FROM csrffailures WHERE julianday(timestamp)<julianday(?)", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.RowsAffected()
+}
+
+// RemoveExpiredGrants removes all user roles and group memberships expired at the given time from the SQLite database, returning the removed grants. All queries are performed within a single transaction
+func (c *DatabaseConnection) RemoveExpiredGrants(ctx context.Context, expiredAt time.Time) ([]*models.ExpiredGrant, error) {
+	var expiredGrants []*models.ExpiredGrant
+
+	err := c.transaction(ctx, func(tx *DatabaseConnection) error {
+		err := tx.executor().SelectContext(ctx, &expiredGrants, "SELECT 'user role' AS type, userid, roleid AS targetid, validuntil FROM userroles WHERE validuntil IS NOT NULL AND julianday(validuntil)<=julianday(?) UNION ALL SELECT 'group membership' AS type, userid, groupid AS targetid, validuntil FROM usergroups WHERE validuntil IS NOT NULL AND julianday(validuntil)<=julianday(?) ORDER BY userid, validuntil", expiredAt.UTC(), expiredAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.executor().ExecContext(ctx, "DELETE FROM userroles WHERE validuntil IS NOT NULL AND julianday(validuntil)<=julianday(?)", expiredAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.executor().ExecContext(ctx, "DELETE FROM usergroups WHERE validuntil IS NOT NULL AND julianday(validuntil)<=julianday(?)", expiredAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		touched := make(map[int64]bool)
+
+		for _, expiredGrant := range expiredGrants {
+			if touched[expiredGrant.UserID] {
+				continue
+			}
+
+			err = tx.touch(ctx, "users", expiredGrant.UserID)
+			if err != nil {
+				return err
+			}
+
+			touched[expiredGrant.UserID] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expiredGrants, nil
+}
+
+// moveToTrash marks the row with the given ID in the given table as deleted by the given user
+func (c *DatabaseConnection) moveToTrash(ctx context.Context, table string, id int64, deletedBy int64) error {
+	_, err := c.executor().ExecContext(ctx, "UPDATE "+table+" SET deletedat=?, deletedby=? WHERE id=? AND deletedat IS NULL", time.Now().UTC(), deletedBy, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// restoreFromTrash removes the deletion mark of the row with the given ID in the given table, returning sql.ErrNoRows if the row was not deleted
+func (c *DatabaseConnection) restoreFromTrash(ctx context.Context, table string, id int64) error {
+	result, err := c.executor().ExecContext(ctx, "UPDATE "+table+" SET deletedat=NULL, deletedby=NULL WHERE id=? AND deletedat IS NOT NULL", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// checkVersion returns a conflict error if an update guarded by the given version of the entity didn't affect any rows
+func checkVersion(result sql.Result, entity string, id int64, version int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return database.NewConflictError(entity, id, version)
+	}
+
+	return nil
+}
+
+// touch increments the version of the row with the given ID in the given table, marking it as modified
+func (c *DatabaseConnection) touch(ctx context.Context, table string, id int64) error {
+	_, err := c.executor().ExecContext(ctx, "UPDATE "+table+" SET version=version+1 WHERE id=?", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveUserFromGroup removes a user from the given group, updates the SQLite database and returns the updated model
+func (c *DatabaseConnection) RemoveUserFromGroup(ctx context.Context, userID int64, groupID int64) (*models.User, error) {
+	user, err := c.LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM usergroups WHERE userid=? AND groupid=?", user.ID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.touch(ctx, "users", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Version++
+
+	var groups []*models.Group
+
+	for _, group := range user.Groups {
+		if group.ID != groupID {
+			groups = append(groups, group)
+		}
+	}
+
+	user.Groups = groups
+	delete(user.GroupValidities, groupID)
+	delete(user.AutoAddedGroups, groupID)
+
+	return user, nil
+}
+
+// RemoveUserRoleFromUser removes a user role from the given user, updates the database and returns the updated model
+func (c *DatabaseConnection) RemoveUserRoleFromUser(ctx context.Context, userID int64, roleID int64) (*models.User, error) {
+	user, err := c.LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM userroles WHERE userid=? AND id=?", user.ID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.touch(ctx, "users", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Version++
+
+	var userRoles []*models.UserRole
+
+	for _, userRole := range user.UserRoles {
+		if userRole.ID != roleID {
+			userRoles = append(userRoles, userRole)
+		}
+	}
+
+	user.UserRoles = userRoles
+
+	return user, nil
+}
+
+// RemoveGroupRoleFromGroup removes a group role from the given group, updates the database and returns the updated model
+func (c *DatabaseConnection) RemoveGroupRoleFromGroup(ctx context.Context, groupID int64, roleID int64) (*models.Group, error) {
+	group, err := c.LoadGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.executor().ExecContext(ctx, "DELETE FROM grouproles WHERE groupid=? AND id=?", group.ID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.touch(ctx, "groups", group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Version++
+
+	var groupRoles []*models.GroupRole
+
+	for _, groupRole := range group.GroupRoles {
+		if groupRole.ID != roleID {
+			groupRoles = append(groupRoles, groupRole)
+		}
+	}
+
+	group.GroupRoles = groupRoles
+
+	return group, nil
+}
+
+// RemoveAPIKeyFromUser removes an API key from the given user, updates the SQLite database and returns the updated model. All queries are performed within a single transaction
+func (c *DatabaseConnection) RemoveAPIKeyFromUser(ctx context.Context, user *models.User, apiKeyID int64) (*models.User, error) {
+	var result *models.User
+
+	err := c.transaction(ctx, func(tx *DatabaseConnection) error {
+		var err error
+		result, err = tx.removeAPIKeyFromUser(ctx, user, apiKeyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// removeAPIKeyFromUser performs the queries required by RemoveAPIKeyFromUser, expecting to be run within a transaction
+func (c *DatabaseConnection) removeAPIKeyFromUser(ctx context.Context, user *models.User, apiKeyID int64) (*models.User, error) {
+	for index, account := range user.Accounts {
+		if account.APIKeyID == apiKeyID {
+			for _, character := range account.Characters {
+				_, err := c.executor().ExecContext(ctx, "DELETE FROM characters WHERE id=? AND accountid=?", character.ID, account.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			_, err := c.executor().ExecContext(ctx, "DELETE FROM accounts WHERE id=? AND apikeyid=?", account.ID, apiKeyID)
+			if err != nil {
+				return nil, err
+			}
+
+			err = c.touch(ctx, "users", user.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			user.Version++
+
+			user.Accounts[index], user.Accounts[len(user.Accounts)-1], user.Accounts = user.Accounts[len(user.Accounts)-1], nil, user.Accounts[:len(user.Accounts)-1]
+
+			break
+		}
+	}
+
+	return user, nil
+}
+
+// ToggleUserRoleGranted toggles the granted state of the given user role
+func (c *DatabaseConnection) ToggleUserRoleGranted(ctx context.Context, roleID int64) (*models.UserRole, error) {
+	userRole, err := c.LoadUserRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole.Granted = !userRole.Granted
+
+	userRole, err = c.SaveUserRole(ctx, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.touch(ctx, "users", userRole.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return userRole, nil
+}
+
+// ToggleGroupRoleGranted toggles the granted state of the given group role
+func (c *DatabaseConnection) ToggleGroupRoleGranted(ctx context.Context, roleID int64) (*models.GroupRole, error) {
+	groupRole, err := c.LoadGroupRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRole.Granted = !groupRole.Granted
+
+	groupRole, err = c.SaveGroupRole(ctx, groupRole)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.touch(ctx, "groups", groupRole.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupRole, nil
+}
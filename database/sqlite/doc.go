@@ -0,0 +1,2 @@
+// Package sqlite provides the underlying connection used by the Database interface, using a SQLite database file.
+package sqlite
@@ -0,0 +1,74 @@
+-- Test data for the eveauth SQLite database
+PRAGMA foreign_keys = OFF;
+
+-- Test data for table accounts
+INSERT INTO accounts (id, userid, apikeyid, apivcode, apiaccessmask, active) VALUES
+	(1, 1, 1, 'a', 0, 1),
+	(2, 2, 2, 'b', 0, 0),
+	(3, 3, 3, 'c', 0, 1),
+	(4, 3, 4, 'd', 268435455, 1),
+	(5, 4, 5, 'e', 268435455, 0),
+	(6, 4, 6, 'f', 268435455, 0);
+
+-- Test data for table applications
+INSERT INTO applications (id, name, maintainerid, secret, callback, active) VALUES
+	(1, 'Testapp', 1, 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa', 'http://localhost/callback', 1),
+	(2, 'Apptest', 2, 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb', 'http://example.com/callback', 0);
+
+-- Test data for table characters
+INSERT INTO characters (id, accountid, corporationid, name, evecharacterid, defaultcharacter, active) VALUES
+	(1, 1, 1, 'Test Character', 1, 1, 1),
+	(2, 2, 2, 'Please Ignore', 2, 1, 1),
+	(3, 3, 1, 'Herp', 3, 1, 1),
+	(4, 3, 1, 'Derp', 4, 0, 1),
+	(5, 4, 2, 'Spai', 5, 0, 0),
+	(6, 4, 2, 'NoSpai', 6, 1, 0);
+
+-- Test data for table corporations
+INSERT INTO corporations (id, name, ticker, evecorporationid, ceoid, apikeyid, apivcode, active) VALUES
+	(1, 'Test Corp Please Ignore', 'TEST', 1, 1, 1, 'a', 1),
+	(2, 'Corp Test Ignore Please', 'CORP', 2, 2, NULL, NULL, 0);
+
+-- Test data for table grouproles
+INSERT INTO grouproles (id, groupid, roleid, autoadded, granted) VALUES
+	(1, 1, 1, 1, 1),
+	(2, 1, 3, 0, 1),
+	(3, 2, 2, 0, 0),
+	(4, 2, 4, 1, 0);
+
+-- Test data for table groups
+INSERT INTO groups (id, name, active) VALUES
+	(1, 'Test Group', 1),
+	(2, 'Dank Access', 0);
+
+-- Test data for table loginattempts
+
+-- Test data for table roles
+INSERT INTO roles (id, name, active, locked) VALUES
+	(1, 'ping.all', 1, 0),
+	(2, 'destroy.world', 0, 1),
+	(3, 'logistics.read', 1, 0),
+	(4, 'logistics.write', 1, 0);
+
+-- Test data for table usergroups
+INSERT INTO usergroups (id, userid, groupid, active) VALUES
+	(1, 1, 1, 1),
+	(2, 2, 1, 0),
+	(3, 3, 1, 1),
+	(4, 3, 2, 1),
+	(5, 4, 1, 0),
+	(6, 4, 2, 0);
+
+-- Test data for table userroles
+INSERT INTO userroles (id, userid, roleid, autoadded, granted) VALUES
+	(1, 1, 1, 0, 0),
+	(2, 3, 2, 1, 1);
+
+-- Test data for table users
+INSERT INTO users (id, username, password, email, verifiedemail, active) VALUES
+	(1, 'test1', '$2a$10$veif8VUZt7lShFhJKD0wGeY1YjCwIuWjYL0vQzlTqu8wNaYQMqzbe', 'test1@example.com', 1, 1),
+	(2, 'test2', '$2a$10$95z.WXfIreLKJ9px.3KgpOq4aXTG3DF7/5ehGYzUWALhpN6MMq/aK', 'test2@example.com', 0, 0),
+	(3, 'test3', '$2a$10$7Yxm2scdTVpEJpvZAT7tbOFA.G9JfyxtiHbr989iocX6U37C3/j4q', 'test3@example.com', 0, 1),
+	(4, 'test4', '$2a$10$WOWTgqaqLKbkb1uhYbtLnOuuYX4kXBC61GVAke7RkjiODoBpgGGzy', 'test4@example.com', 1, 0);
+
+PRAGMA foreign_keys = ON;
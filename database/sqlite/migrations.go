@@ -0,0 +1,410 @@
+package sqlite
+
+import (
+	"github.com/morpheusxaut/eveauth/database/migration"
+)
+
+// migrations stores all schema migrations for the SQLite database in the order they have to be applied
+var migrations = []*migration.Migration{
+	&migration.Migration{
+		Version:     1,
+		Description: "Create initial schema",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  username VARCHAR(32) NOT NULL COLLATE NOCASE,
+  password VARCHAR(60) NOT NULL,
+  email VARCHAR(128) NOT NULL COLLATE NOCASE,
+  verifiedemail INTEGER NOT NULL DEFAULT 0,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT username UNIQUE (username)
+)`,
+			`CREATE TABLE IF NOT EXISTS corporations (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  ticker VARCHAR(5) NOT NULL COLLATE NOCASE,
+  evecorporationid INTEGER NOT NULL,
+  ceoid INTEGER NOT NULL,
+  apikeyid INTEGER DEFAULT NULL,
+  apivcode VARCHAR(64) DEFAULT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT name UNIQUE (name),
+  CONSTRAINT ticker UNIQUE (ticker),
+  CONSTRAINT evecorporationid UNIQUE (evecorporationid),
+  CONSTRAINT apikeyid UNIQUE (apikeyid)
+)`,
+			`CREATE TABLE IF NOT EXISTS groups (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT name UNIQUE (name)
+)`,
+			`CREATE TABLE IF NOT EXISTS roles (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  active INTEGER NOT NULL DEFAULT 1,
+  locked INTEGER NOT NULL DEFAULT 0,
+  CONSTRAINT name UNIQUE (name)
+)`,
+			`CREATE TABLE IF NOT EXISTS accounts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  userid INTEGER NOT NULL REFERENCES users (id) ON UPDATE CASCADE,
+  apikeyid INTEGER NOT NULL,
+  apivcode VARCHAR(64) NOT NULL,
+  apiaccessmask INTEGER NOT NULL DEFAULT 0,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT keyid UNIQUE (apikeyid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_userapikeys_user ON accounts (userid)`,
+			`CREATE TABLE IF NOT EXISTS applications (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  maintainerid INTEGER NOT NULL REFERENCES users (id) ON UPDATE CASCADE,
+  secret VARCHAR(32) NOT NULL,
+  callback VARCHAR(128) NOT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT name UNIQUE (name),
+  CONSTRAINT secret UNIQUE (secret)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_applications_maintainer ON applications (maintainerid)`,
+			`CREATE TABLE IF NOT EXISTS characters (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  accountid INTEGER NOT NULL REFERENCES accounts (id) ON UPDATE CASCADE,
+  corporationid INTEGER NOT NULL REFERENCES corporations (id) ON UPDATE CASCADE,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  evecharacterid INTEGER NOT NULL,
+  defaultcharacter INTEGER NOT NULL DEFAULT 0,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT name UNIQUE (name),
+  CONSTRAINT evecharacterid UNIQUE (evecharacterid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_characters_account ON characters (accountid)`,
+			`CREATE INDEX IF NOT EXISTS fk_characters_corporation ON characters (corporationid)`,
+			`CREATE TABLE IF NOT EXISTS csrffailures (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  userid INTEGER NOT NULL,
+  request TEXT NOT NULL,
+  timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+			`CREATE TABLE IF NOT EXISTS grouproles (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  groupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  roleid INTEGER NOT NULL REFERENCES roles (id) ON UPDATE CASCADE,
+  autoadded INTEGER NOT NULL DEFAULT 1,
+  granted INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT groupid_roleid UNIQUE (groupid, roleid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_grouproles_group ON grouproles (groupid)`,
+			`CREATE INDEX IF NOT EXISTS fk_grouproles_role ON grouproles (roleid)`,
+			`CREATE TABLE IF NOT EXISTS loginattempts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  username VARCHAR(64) NOT NULL,
+  remoteaddr VARCHAR(64) NOT NULL,
+  useragent VARCHAR(256) NOT NULL,
+  successful INTEGER NOT NULL,
+  timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+			`CREATE TABLE IF NOT EXISTS usergroups (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  userid INTEGER NOT NULL REFERENCES users (id) ON UPDATE CASCADE,
+  groupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT userid_groupid UNIQUE (userid, groupid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_usergroups_user ON usergroups (userid)`,
+			`CREATE INDEX IF NOT EXISTS fk_usergroups_group ON usergroups (groupid)`,
+			`CREATE TABLE IF NOT EXISTS userroles (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  userid INTEGER NOT NULL REFERENCES users (id) ON UPDATE CASCADE,
+  roleid INTEGER NOT NULL REFERENCES roles (id) ON UPDATE CASCADE,
+  autoadded INTEGER NOT NULL DEFAULT 1,
+  granted INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT userid_roleid UNIQUE (userid, roleid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_userroles_user ON userroles (userid)`,
+			`CREATE INDEX IF NOT EXISTS fk_userroles_role ON userroles (roleid)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS userroles",
+			"DROP TABLE IF EXISTS usergroups",
+			"DROP TABLE IF EXISTS loginattempts",
+			"DROP TABLE IF EXISTS grouproles",
+			"DROP TABLE IF EXISTS csrffailures",
+			"DROP TABLE IF EXISTS characters",
+			"DROP TABLE IF EXISTS applications",
+			"DROP TABLE IF EXISTS accounts",
+			"DROP TABLE IF EXISTS roles",
+			"DROP TABLE IF EXISTS groups",
+			"DROP TABLE IF EXISTS corporations",
+			"DROP TABLE IF EXISTS users",
+		},
+	},
+	&migration.Migration{
+		Version:     2,
+		Description: "Add soft deletion of users, groups, roles and applications",
+		Up: []string{
+			"ALTER TABLE users ADD COLUMN deletedat DATETIME DEFAULT NULL",
+			"ALTER TABLE users ADD COLUMN deletedby INTEGER DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS users_deletedat ON users (deletedat)",
+			"ALTER TABLE groups ADD COLUMN deletedat DATETIME DEFAULT NULL",
+			"ALTER TABLE groups ADD COLUMN deletedby INTEGER DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS groups_deletedat ON groups (deletedat)",
+			"ALTER TABLE roles ADD COLUMN deletedat DATETIME DEFAULT NULL",
+			"ALTER TABLE roles ADD COLUMN deletedby INTEGER DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS roles_deletedat ON roles (deletedat)",
+			"ALTER TABLE applications ADD COLUMN deletedat DATETIME DEFAULT NULL",
+			"ALTER TABLE applications ADD COLUMN deletedby INTEGER DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS applications_deletedat ON applications (deletedat)",
+		},
+		Down: []string{
+			"DROP INDEX IF EXISTS applications_deletedat",
+			"ALTER TABLE applications DROP COLUMN deletedby",
+			"ALTER TABLE applications DROP COLUMN deletedat",
+			"DROP INDEX IF EXISTS roles_deletedat",
+			"ALTER TABLE roles DROP COLUMN deletedby",
+			"ALTER TABLE roles DROP COLUMN deletedat",
+			"DROP INDEX IF EXISTS groups_deletedat",
+			"ALTER TABLE groups DROP COLUMN deletedby",
+			"ALTER TABLE groups DROP COLUMN deletedat",
+			"DROP INDEX IF EXISTS users_deletedat",
+			"ALTER TABLE users DROP COLUMN deletedby",
+			"ALTER TABLE users DROP COLUMN deletedat",
+		},
+	},
+	&migration.Migration{
+		Version:     3,
+		Description: "Add versions of users, groups, roles and applications for optimistic concurrency control",
+		Up: []string{
+			"ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1",
+			"ALTER TABLE groups ADD COLUMN version INTEGER NOT NULL DEFAULT 1",
+			"ALTER TABLE roles ADD COLUMN version INTEGER NOT NULL DEFAULT 1",
+			"ALTER TABLE applications ADD COLUMN version INTEGER NOT NULL DEFAULT 1",
+		},
+		Down: []string{
+			"ALTER TABLE applications DROP COLUMN version",
+			"ALTER TABLE roles DROP COLUMN version",
+			"ALTER TABLE groups DROP COLUMN version",
+			"ALTER TABLE users DROP COLUMN version",
+		},
+	},
+	&migration.Migration{
+		Version:     4,
+		Description: "Add alliances and link corporations to them",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS alliances (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  ticker VARCHAR(5) NOT NULL COLLATE NOCASE,
+  eveallianceid INTEGER NOT NULL,
+  executorcorpid INTEGER NOT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT eveallianceid UNIQUE (eveallianceid)
+)`,
+			// SQLite can't drop columns used in foreign key constraints, the link is thus only enforced by the application
+			"ALTER TABLE corporations ADD COLUMN allianceid INTEGER DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS fk_corporations_alliance ON corporations (allianceid)",
+		},
+		Down: []string{
+			"DROP INDEX IF EXISTS fk_corporations_alliance",
+			"ALTER TABLE corporations DROP COLUMN allianceid",
+			"DROP TABLE IF EXISTS alliances",
+		},
+	},
+	&migration.Migration{
+		Version:     5,
+		Description: "Add daily summaries of pruned login attempts",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS loginattemptsummaries (
+  day CHAR(10) PRIMARY KEY,
+  failed INTEGER NOT NULL DEFAULT 0,
+  successful INTEGER NOT NULL DEFAULT 0
+)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS loginattemptsummaries",
+		},
+	},
+	&migration.Migration{
+		Version:     6,
+		Description: "Add role implications",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS roleimplications (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  roleid INTEGER NOT NULL REFERENCES roles (id) ON UPDATE CASCADE,
+  impliedroleid INTEGER NOT NULL REFERENCES roles (id) ON UPDATE CASCADE,
+  CONSTRAINT roleid_impliedroleid UNIQUE (roleid, impliedroleid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_roleimplications_role ON roleimplications (roleid)`,
+			`CREATE INDEX IF NOT EXISTS fk_roleimplications_impliedrole ON roleimplications (impliedroleid)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS roleimplications",
+		},
+	},
+	&migration.Migration{
+		Version:     7,
+		Description: "Add validity windows to user roles and group memberships",
+		Up: []string{
+			"ALTER TABLE userroles ADD COLUMN validfrom DATETIME DEFAULT NULL",
+			"ALTER TABLE userroles ADD COLUMN validuntil DATETIME DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS userroles_validuntil ON userroles (validuntil)",
+			"ALTER TABLE usergroups ADD COLUMN validfrom DATETIME DEFAULT NULL",
+			"ALTER TABLE usergroups ADD COLUMN validuntil DATETIME DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS usergroups_validuntil ON usergroups (validuntil)",
+		},
+		Down: []string{
+			"DROP INDEX IF EXISTS usergroups_validuntil",
+			"ALTER TABLE usergroups DROP COLUMN validuntil",
+			"ALTER TABLE usergroups DROP COLUMN validfrom",
+			"DROP INDEX IF EXISTS userroles_validuntil",
+			"ALTER TABLE userroles DROP COLUMN validuntil",
+			"ALTER TABLE userroles DROP COLUMN validfrom",
+		},
+	},
+	&migration.Migration{
+		Version:     8,
+		Description: "Add membership rules and mark automatically added group memberships",
+		Up: []string{
+			"ALTER TABLE usergroups ADD COLUMN autoadded INTEGER NOT NULL DEFAULT 0",
+			`CREATE TABLE IF NOT EXISTS membershiprules (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  groupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  type VARCHAR(32) NOT NULL,
+  targetid INTEGER NOT NULL,
+  CONSTRAINT groupid_type_targetid UNIQUE (groupid, type, targetid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_membershiprules_group ON membershiprules (groupid)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS membershiprules",
+			"ALTER TABLE usergroups DROP COLUMN autoadded",
+		},
+	},
+	&migration.Migration{
+		Version:     9,
+		Description: "Add group nestings",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS groupnestings (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  groupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  subgroupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  CONSTRAINT groupid_subgroupid UNIQUE (groupid, subgroupid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_groupnestings_group ON groupnestings (groupid)`,
+			`CREATE INDEX IF NOT EXISTS fk_groupnestings_subgroup ON groupnestings (subgroupid)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS groupnestings",
+		},
+	},
+	&migration.Migration{
+		Version:     10,
+		Description: "Add group managers and record their actions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS groupmanagers (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  groupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  userid INTEGER NOT NULL REFERENCES users (id) ON UPDATE CASCADE,
+  CONSTRAINT groupid_userid UNIQUE (groupid, userid)
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_groupmanagers_group ON groupmanagers (groupid)`,
+			`CREATE INDEX IF NOT EXISTS fk_groupmanagers_user ON groupmanagers (userid)`,
+			`CREATE TABLE IF NOT EXISTS manageractions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  groupid INTEGER NOT NULL,
+  actorid INTEGER NOT NULL,
+  userid INTEGER NOT NULL,
+  type VARCHAR(32) NOT NULL,
+  timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+			`CREATE INDEX IF NOT EXISTS manageractions_group ON manageractions (groupid)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS manageractions",
+			"DROP TABLE IF EXISTS groupmanagers",
+		},
+	},
+	&migration.Migration{
+		Version:     11,
+		Description: "Add group visibilities and applications for group memberships",
+		Up: []string{
+			"ALTER TABLE groups ADD COLUMN visibility VARCHAR(32) NOT NULL DEFAULT 'hidden'",
+			`CREATE TABLE IF NOT EXISTS groupapplications (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  groupid INTEGER NOT NULL REFERENCES groups (id) ON UPDATE CASCADE,
+  userid INTEGER NOT NULL REFERENCES users (id) ON UPDATE CASCADE,
+  status VARCHAR(32) NOT NULL DEFAULT 'pending',
+  message TEXT NOT NULL DEFAULT '',
+  comment TEXT NOT NULL DEFAULT '',
+  deciderid INTEGER NOT NULL DEFAULT -1,
+  createdat DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  decidedat DATETIME DEFAULT NULL
+)`,
+			`CREATE INDEX IF NOT EXISTS fk_groupapplications_group ON groupapplications (groupid)`,
+			`CREATE INDEX IF NOT EXISTS fk_groupapplications_user ON groupapplications (userid)`,
+			`CREATE INDEX IF NOT EXISTS groupapplications_status ON groupapplications (status)`,
+		},
+		Down: []string{
+			"DROP TABLE IF EXISTS groupapplications",
+			"ALTER TABLE groups DROP COLUMN visibility",
+		},
+	},
+	&migration.Migration{
+		Version:     12,
+		Description: "Add application namespaces to roles and role scopes to applications",
+		Up: []string{
+			"ALTER TABLE roles ADD COLUMN applicationid INTEGER DEFAULT NULL",
+			"CREATE INDEX IF NOT EXISTS fk_roles_application ON roles (applicationid)",
+			"ALTER TABLE applications ADD COLUMN rolescopes VARCHAR(1024) NOT NULL DEFAULT ''",
+		},
+		Down: []string{
+			"ALTER TABLE applications DROP COLUMN rolescopes",
+			"DROP INDEX IF EXISTS fk_roles_application",
+			"ALTER TABLE roles DROP COLUMN applicationid",
+		},
+	},
+	&migration.Migration{
+		Version:     13,
+		Description: "Add policies to roles",
+		Up: []string{
+			"ALTER TABLE roles ADD COLUMN policy VARCHAR(1024) NOT NULL DEFAULT ''",
+		},
+		Down: []string{
+			"ALTER TABLE roles DROP COLUMN policy",
+		},
+	},
+	&migration.Migration{
+		Version:     14,
+		Description: "Make role names unique within their application namespace instead of globally",
+		Up: []string{
+			// SQLite can't drop table constraints, the table is thus rebuilt. The foreign keys referencing it are only checked once the rows are back
+			"PRAGMA defer_foreign_keys=ON",
+			"CREATE TEMPORARY TABLE roles_backup AS SELECT id, name, active, locked, deletedat, deletedby, version, applicationid, policy FROM roles",
+			"CREATE TEMPORARY TABLE roles_sequence AS SELECT seq FROM sqlite_sequence WHERE name='roles'",
+			"DROP TABLE roles",
+			`CREATE TABLE roles (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL COLLATE NOCASE,
+  active INTEGER NOT NULL DEFAULT 1,
+  locked INTEGER NOT NULL DEFAULT 0,
+  deletedat DATETIME DEFAULT NULL,
+  deletedby INTEGER DEFAULT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  applicationid INTEGER DEFAULT NULL,
+  policy VARCHAR(1024) NOT NULL DEFAULT ''
+)`,
+			"INSERT INTO roles(id, name, active, locked, deletedat, deletedby, version, applicationid, policy) SELECT id, name, active, locked, deletedat, deletedby, version, applicationid, policy FROM roles_backup",
+			"DELETE FROM sqlite_sequence WHERE name='roles'",
+			"INSERT INTO sqlite_sequence(name, seq) SELECT 'roles', seq FROM roles_sequence",
+			"DROP TABLE roles_sequence",
+			"DROP TABLE roles_backup",
+			"CREATE INDEX IF NOT EXISTS roles_deletedat ON roles (deletedat)",
+			"CREATE INDEX IF NOT EXISTS fk_roles_application ON roles (applicationid)",
+			"CREATE UNIQUE INDEX IF NOT EXISTS roles_applicationid_name ON roles (COALESCE(applicationid, 0), name)",
+		},
+		Down: []string{
+			"DROP INDEX IF EXISTS roles_applicationid_name",
+			"CREATE UNIQUE INDEX IF NOT EXISTS roles_name ON roles (name)",
+		},
+	},
+}
@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"github.com/morpheusxaut/eveauth/database"
+)
+
+// reportQueries stores the SQLite queries of all registered reports, selecting the report's columns in order
+var reportQueries = map[string]string{
+	database.ReportCorporationMembers: `SELECT co.name, co.ticker, COUNT(DISTINCT u.id), COUNT(u.id)
+FROM corporations co
+LEFT JOIN characters ch ON ch.corporationid = co.id
+LEFT JOIN accounts a ON a.id = ch.accountid
+LEFT JOIN users u ON u.id = a.userid AND u.deletedat IS NULL
+GROUP BY co.id, co.name, co.ticker
+ORDER BY co.name`,
+	database.ReportUsersWithoutAPIKeys: `SELECT u.id, u.username, u.email, u.active
+FROM users u
+WHERE u.deletedat IS NULL AND NOT EXISTS (SELECT 1 FROM accounts a WHERE a.userid = u.id)
+ORDER BY u.username`,
+	database.ReportLoginAttempts: `SELECT day, SUM(failed), SUM(successful)
+FROM (
+  SELECT strftime('%Y-%m-%d', timestamp) AS day, CASE WHEN successful THEN 0 ELSE 1 END AS failed, CASE WHEN successful THEN 1 ELSE 0 END AS successful
+  FROM loginattempts
+  WHERE julianday(timestamp) >= julianday(?)
+  UNION ALL
+  SELECT day, failed, successful
+  FROM loginattemptsummaries
+  WHERE day >= ?
+) AS attempts
+GROUP BY day
+ORDER BY day`,
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/misc"
+)
+
+// WithQueryTimeout returns a copy of the given context applying the query timeout set in the configuration.
+// The returned context is not modified if no timeout has been configured, the cancel function must be called once the query has finished in any case
+func WithQueryTimeout(ctx context.Context, conf *misc.Configuration) (context.Context, context.CancelFunc) {
+	if conf == nil || conf.DatabaseQueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(conf.DatabaseQueryTimeout)*time.Millisecond)
+}
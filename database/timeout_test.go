@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morpheusxaut/eveauth/misc"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	Convey("Applying the configured query timeout to a context", t, func() {
+		Convey("A disabled timeout should not set a deadline", func() {
+			ctx, cancel := WithQueryTimeout(context.Background(), &misc.Configuration{})
+			defer cancel()
+
+			_, ok := ctx.Deadline()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("A missing configuration should not set a deadline", func() {
+			ctx, cancel := WithQueryTimeout(context.Background(), nil)
+			defer cancel()
+
+			_, ok := ctx.Deadline()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("An enabled timeout should set a deadline", func() {
+			ctx, cancel := WithQueryTimeout(context.Background(), &misc.Configuration{DatabaseQueryTimeout: 1})
+			defer cancel()
+
+			_, ok := ctx.Deadline()
+			So(ok, ShouldBeTrue)
+
+			<-ctx.Done()
+			So(ctx.Err(), ShouldEqual, context.DeadlineExceeded)
+		})
+	})
+}
@@ -4,17 +4,27 @@ package database
 type Type int
 
 const (
-	// TypeNone represents a non-persistent database backend, only storing values in memory (not implemented)
+	// TypeNone represents a non-persistent database backend, only storing values in memory
 	TypeNone Type = iota
 	// TypeMySQL represents a persistent MySQL database backend
 	TypeMySQL
+	// TypePostgreSQL represents a persistent PostgreSQL database backend
+	TypePostgreSQL
+	// TypeSQLite represents a persistent SQLite database backend, storing all values in a single file
+	TypeSQLite
 )
 
 // String returns a easily readable string representations of the given Type
 func (t Type) String() string {
 	switch t {
+	case TypeNone:
+		return "None"
 	case TypeMySQL:
 		return "MySQL"
+	case TypePostgreSQL:
+		return "PostgreSQL"
+	case TypeSQLite:
+		return "SQLite"
 	default:
 		return "Unknown"
 	}
@@ -9,15 +9,28 @@ import (
 
 func TestDatabaseTypeString(t *testing.T) {
 	Convey("Printing the string representation of the different DatabaseTypes", t, func() {
+		Convey("The DatabaseTypeNone should print \"None\"", func() {
+			So(fmt.Sprintf("%v", TypeNone), ShouldEqual, "None")
+			So(fmt.Sprintf("%v", Type(0)), ShouldEqual, "None")
+		})
+
 		Convey("The DatabaseTypeMySQL should print \"MySQL\"", func() {
 			So(fmt.Sprintf("%v", TypeMySQL), ShouldEqual, "MySQL")
 			So(fmt.Sprintf("%v", Type(1)), ShouldEqual, "MySQL")
 		})
 
+		Convey("The DatabaseTypePostgreSQL should print \"PostgreSQL\"", func() {
+			So(fmt.Sprintf("%v", TypePostgreSQL), ShouldEqual, "PostgreSQL")
+			So(fmt.Sprintf("%v", Type(2)), ShouldEqual, "PostgreSQL")
+		})
+
+		Convey("The DatabaseTypeSQLite should print \"SQLite\"", func() {
+			So(fmt.Sprintf("%v", TypeSQLite), ShouldEqual, "SQLite")
+			So(fmt.Sprintf("%v", Type(3)), ShouldEqual, "SQLite")
+		})
+
 		Convey("Any other Type should print \"Unknown\"", func() {
-			So(fmt.Sprintf("%v", Type(0)), ShouldEqual, "Unknown")
-			So(fmt.Sprintf("%v", Type(2)), ShouldEqual, "Unknown")
-			So(fmt.Sprintf("%v", Type(3)), ShouldEqual, "Unknown")
+			So(fmt.Sprintf("%v", Type(4)), ShouldEqual, "Unknown")
 			So(fmt.Sprintf("%v", Type(1337)), ShouldEqual, "Unknown")
 		})
 	})
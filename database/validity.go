@@ -0,0 +1,41 @@
+package database
+
+import (
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// ValidityTimes returns the start and expiry time of the given validity window in UTC for storing them in the database, nil for unset times
+func ValidityTimes(validity *models.Validity) (*time.Time, *time.Time) {
+	if validity == nil {
+		return nil, nil
+	}
+
+	return utcTime(validity.ValidFrom), utcTime(validity.ValidUntil)
+}
+
+// ValidityFromTimes creates a validity window from the start and expiry time stored in the database, returning nil if neither time is set
+func ValidityFromTimes(validFrom *time.Time, validUntil *time.Time) *models.Validity {
+	if validFrom == nil && validUntil == nil {
+		return nil
+	}
+
+	validity := &models.Validity{
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+	}
+
+	return validity
+}
+
+// utcTime returns a copy of the given time converted to UTC, nil if no time was given
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+
+	return &utc
+}
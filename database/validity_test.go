@@ -0,0 +1,32 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidityTimes(t *testing.T) {
+	Convey("Converting validity windows for the database", t, func() {
+		Convey("Unlimited windows should be stored without times", func() {
+			validFrom, validUntil := ValidityTimes(nil)
+			So(validFrom, ShouldBeNil)
+			So(validUntil, ShouldBeNil)
+
+			So(ValidityFromTimes(nil, nil), ShouldBeNil)
+		})
+
+		Convey("Limited windows should keep their times", func() {
+			validity := models.NewValidity(time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC), time.Hour)
+
+			validFrom, validUntil := ValidityTimes(validity)
+			So(*validFrom, ShouldResemble, *validity.ValidFrom)
+			So(*validUntil, ShouldResemble, *validity.ValidUntil)
+
+			So(ValidityFromTimes(validFrom, validUntil), ShouldResemble, validity)
+			So(ValidityFromTimes(nil, validUntil).ValidFrom, ShouldBeNil)
+		})
+	})
+}
@@ -3,11 +3,20 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/cache"
+	// Blank imports of all database backends, registering themselves for use with the database setup
+	_ "github.com/morpheusxaut/eveauth/database/memory"
+	_ "github.com/morpheusxaut/eveauth/database/mysql"
+	_ "github.com/morpheusxaut/eveauth/database/postgres"
+	_ "github.com/morpheusxaut/eveauth/database/sqlite"
 	"github.com/morpheusxaut/eveauth/mail"
 	"github.com/morpheusxaut/eveauth/misc"
 	"github.com/morpheusxaut/eveauth/session"
@@ -31,12 +40,30 @@ func main() {
 		os.Exit(2)
 	}
 
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigration(db, flag.Arg(1)))
+	}
+
 	err = db.Connect()
 	if err != nil {
 		misc.Logger.Criticalf("Failed to connect to database: [%v]", err)
 		os.Exit(2)
 	}
 
+	switch flag.Arg(0) {
+	case "export":
+		os.Exit(runExport(db, flag.Arg(1)))
+	case "import":
+		os.Exit(runImport(db, flag.Arg(1)))
+	}
+
+	var dbCache *cache.Connection
+
+	if config.DatabaseCacheTTL > 0 {
+		dbCache = cache.NewConnection(db, time.Duration(config.DatabaseCacheTTL)*time.Second)
+		db = dbCache
+	}
+
 	mailer := mail.SetupMailController(config, db)
 
 	sessionController, err := session.SetupSessionController(config, db, mailer)
@@ -53,7 +80,12 @@ func main() {
 		os.Exit(2)
 	}
 
-	controller := web.SetupController(config, db, sessionController, templates, checksums)
+	controller := web.SetupController(config, db, sessionController, mailer, templates, checksums)
+	controller.Cache = dbCache
+
+	go controller.Health.Run(context.Background())
+	go controller.Pruner.Run(context.Background())
+	go controller.Memberships.Run(context.Background())
 
 	controller.HandleRequests()
 }
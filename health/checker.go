@@ -0,0 +1,197 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/misc"
+)
+
+// DefaultTimeout represents the time a single check may take before being considered failed if no timeout has been configured
+const DefaultTimeout = 5 * time.Second
+
+// CheckFunc verifies a single backend is healthy, returning an error if it is not
+type CheckFunc func(ctx context.Context) error
+
+// Status represents the result of the latest check of a single backend
+type Status struct {
+	// Name represents the name the check has been registered with
+	Name string `json:"name"`
+	// Healthy indicates whether the latest check succeeded
+	Healthy bool `json:"healthy"`
+	// Error represents the error returned by the latest check, empty if the check succeeded
+	Error string `json:"-"`
+	// Latency represents the time the latest check took
+	Latency time.Duration `json:"latency"`
+	// CheckedAt represents the time the latest check was performed at
+	CheckedAt time.Time `json:"checkedAt"`
+	// LastHealthy represents the time the last successful check was performed at, zero if no check succeeded yet
+	LastHealthy time.Time `json:"lastHealthy"`
+}
+
+// check stores a registered check as well as its latest status
+type check struct {
+	name   string
+	fn     CheckFunc
+	status *Status
+}
+
+// Checker performs all registered checks periodically or on request, storing their latest results
+type Checker struct {
+	interval time.Duration
+	timeout  time.Duration
+
+	lock    sync.RWMutex
+	checks  []*check
+	running bool
+}
+
+// NewChecker creates a new checker performing its checks every interval if run, each limited by the given timeout (or DefaultTimeout if not positive)
+func NewChecker(interval time.Duration, timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	checker := &Checker{
+		interval: interval,
+		timeout:  timeout,
+		checks:   make([]*check, 0),
+	}
+
+	return checker
+}
+
+// Register adds a check with the given name, performed every time the checker checks all backends
+func (checker *Checker) Register(name string, fn CheckFunc) {
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+
+	checker.checks = append(checker.checks, &check{
+		name: name,
+		fn:   fn,
+	})
+}
+
+// CheckAll performs all registered checks and stores their results, returning the new status of every check
+func (checker *Checker) CheckAll(ctx context.Context) []*Status {
+	checker.lock.RLock()
+	checks := make([]*check, len(checker.checks))
+	copy(checks, checker.checks)
+	checker.lock.RUnlock()
+
+	statuses := make([]*Status, len(checks))
+
+	for i, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checker.timeout)
+
+		start := time.Now()
+		err := c.fn(checkCtx)
+
+		cancel()
+
+		status := &Status{
+			Name:      c.name,
+			Healthy:   err == nil,
+			Latency:   time.Since(start),
+			CheckedAt: start,
+		}
+
+		if err != nil {
+			status.Error = err.Error()
+		}
+
+		checker.lock.Lock()
+		if c.status != nil {
+			status.LastHealthy = c.status.LastHealthy
+
+			if c.status.Healthy && !status.Healthy {
+				misc.Logger.Warnf("Health check %q failed: [%v]", c.name, err)
+			} else if !c.status.Healthy && status.Healthy {
+				misc.Logger.Infof("Health check %q recovered", c.name)
+			}
+		} else if !status.Healthy {
+			misc.Logger.Warnf("Health check %q failed: [%v]", c.name, err)
+		}
+
+		if status.Healthy {
+			status.LastHealthy = status.CheckedAt
+		}
+
+		c.status = status
+		checker.lock.Unlock()
+
+		copied := *status
+		statuses[i] = &copied
+	}
+
+	return statuses
+}
+
+// Statuses returns the latest status of every registered check. Checks are performed first if the checker is not running periodically or a check has not been performed yet
+func (checker *Checker) Statuses(ctx context.Context) []*Status {
+	checker.lock.RLock()
+
+	statuses := make([]*Status, 0, len(checker.checks))
+	complete := checker.running
+
+	for _, c := range checker.checks {
+		if c.status == nil {
+			complete = false
+			break
+		}
+
+		status := *c.status
+		statuses = append(statuses, &status)
+	}
+
+	checker.lock.RUnlock()
+
+	if !complete {
+		return checker.CheckAll(ctx)
+	}
+
+	return statuses
+}
+
+// Healthy checks whether all of the given statuses are healthy
+func Healthy(statuses []*Status) bool {
+	for _, status := range statuses {
+		if !status.Healthy {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Run performs all checks immediately and then once every interval until the given context is done. Run returns immediately if the interval is not positive
+func (checker *Checker) Run(ctx context.Context) {
+	if checker.interval <= 0 {
+		return
+	}
+
+	checker.CheckAll(ctx)
+
+	checker.lock.Lock()
+	checker.running = true
+	checker.lock.Unlock()
+
+	defer func() {
+		checker.lock.Lock()
+		checker.running = false
+		checker.lock.Unlock()
+	}()
+
+	ticker := time.NewTicker(checker.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checker.CheckAll(ctx)
+		}
+	}
+}
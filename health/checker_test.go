@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/misc"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestChecker(t *testing.T) {
+	misc.SetupLogger(9)
+
+	ctx := context.Background()
+
+	Convey("Checking registered backends", t, func() {
+		var failure error
+		calls := 0
+
+		checker := NewChecker(0, time.Second)
+		checker.Register("healthy", func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		checker.Register("failing", func(ctx context.Context) error {
+			return failure
+		})
+
+		Convey("All checks should be performed and reported in order", func() {
+			failure = errors.New("unreachable")
+
+			statuses := checker.CheckAll(ctx)
+			So(len(statuses), ShouldEqual, 2)
+			So(statuses[0].Name, ShouldEqual, "healthy")
+			So(statuses[0].Healthy, ShouldBeTrue)
+			So(statuses[0].LastHealthy, ShouldEqual, statuses[0].CheckedAt)
+			So(statuses[1].Name, ShouldEqual, "failing")
+			So(statuses[1].Healthy, ShouldBeFalse)
+			So(statuses[1].Error, ShouldEqual, "unreachable")
+			So(statuses[1].LastHealthy.IsZero(), ShouldBeTrue)
+			So(Healthy(statuses), ShouldBeFalse)
+		})
+
+		Convey("A recovered check should keep track of its last healthy time", func() {
+			checker.CheckAll(ctx)
+
+			failure = errors.New("unreachable")
+			statuses := checker.CheckAll(ctx)
+			So(statuses[1].Healthy, ShouldBeFalse)
+			So(statuses[1].LastHealthy.IsZero(), ShouldBeFalse)
+
+			failure = nil
+			statuses = checker.CheckAll(ctx)
+			So(Healthy(statuses), ShouldBeTrue)
+		})
+
+		Convey("Checks exceeding the timeout should receive a cancelled context", func() {
+			checker = NewChecker(0, time.Millisecond)
+			checker.Register("slow", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+			statuses := checker.CheckAll(ctx)
+			So(statuses[0].Healthy, ShouldBeFalse)
+			So(statuses[0].Error, ShouldEqual, context.DeadlineExceeded.Error())
+		})
+
+		Convey("Retrieving the statuses without running periodically should perform the checks", func() {
+			checker.Statuses(ctx)
+			checker.Statuses(ctx)
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("Retrieving the statuses while running periodically should use the latest results", func() {
+			checker.interval = time.Hour
+
+			runCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+
+			go func() {
+				checker.Run(runCtx)
+				close(done)
+			}()
+
+			for {
+				checker.lock.RLock()
+				running := checker.running
+				checker.lock.RUnlock()
+
+				if running {
+					break
+				}
+
+				time.Sleep(time.Millisecond)
+			}
+
+			statuses := checker.Statuses(ctx)
+			So(len(statuses), ShouldEqual, 2)
+			So(calls, ShouldEqual, 1)
+
+			cancel()
+			<-done
+		})
+
+		Convey("Running without a positive interval should return immediately", func() {
+			checker.Run(ctx)
+			So(calls, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,2 @@
+// Package health provides periodic checks of the backends eveauth depends on, keeping track of their latest results so they can be reported by the web app.
+package health
@@ -66,6 +66,37 @@ func (controller *Controller) SendPasswordReset(username string, email string, v
 	return controller.SendEmail(email, "eveauth - Password reset", buf.String(), fmt.Sprintf("Please use the following link to reset your password: %s/login/reset/verify?email=%s&username=%s&verification=%s", controller.config.HTTPPublicURL, email, username, verification))
 }
 
+// SendGroupApplicationDecision notifies the user at the given email address about the decision made on their application to the given group
+func (controller *Controller) SendGroupApplicationDecision(username string, email string, groupName string, approved bool, comment string) error {
+	templates := template.Must(template.New("").ParseFiles("app/templates/groupapplicationdecision.html"))
+
+	data := make(map[string]interface{})
+	data["username"] = username
+	data["groupName"] = groupName
+	data["approved"] = approved
+	data["comment"] = comment
+	data["groupsLink"] = fmt.Sprintf("%s/groups", controller.config.HTTPPublicURL)
+
+	var buf bytes.Buffer
+
+	err := templates.ExecuteTemplate(&buf, "groupapplicationdecision", data)
+	if err != nil {
+		return err
+	}
+
+	decision := "denied"
+	if approved {
+		decision = "approved"
+	}
+
+	plainMessage := fmt.Sprintf("Your application to join the group %s has been %s.", groupName, decision)
+	if len(comment) > 0 {
+		plainMessage = fmt.Sprintf("%s Comment: %s", plainMessage, comment)
+	}
+
+	return controller.SendEmail(email, "eveauth - Group Application", buf.String(), plainMessage)
+}
+
 // SendEmail properly formats an email with the given data and sends it via a SMTP client
 func (controller *Controller) SendEmail(email string, subject string, message string, plainMessage string) error {
 	smtpHostname, _, err := net.SplitHostPort(controller.config.SMTPHost)
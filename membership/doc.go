@@ -0,0 +1,3 @@
+// Package membership provides the reconciliation of group memberships managed by membership rules, adding users matching any rule of a group and removing automatically added members no longer matching them.
+// Memberships added manually are never modified by the reconciliation.
+package membership
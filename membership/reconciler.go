@@ -0,0 +1,275 @@
+package membership
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+)
+
+// DefaultInterval represents the interval at which all users are reconciled if no interval has been configured
+const DefaultInterval = time.Hour
+
+// Change represents a group membership added or removed by the reconciler
+type Change struct {
+	// UserID represents the database ID of the user whose membership changed
+	UserID int64 `json:"userID"`
+	// GroupID represents the database ID of the group the membership refers to
+	GroupID int64 `json:"groupID"`
+	// Added indicates whether the membership has been added, false if it has been removed
+	Added bool `json:"added"`
+}
+
+// rules stores all membership rules indexed by the ID of their group as well as the information required to evaluate them
+type rules struct {
+	groupIDs    []int64
+	byGroup     map[int64][]*models.MembershipRule
+	allianceIDs map[int64]int64
+}
+
+// Reconciler adds and removes automatically added group memberships according to the membership rules of all groups
+type Reconciler struct {
+	database database.Connection
+	interval time.Duration
+
+	// lock prevents concurrent reconciliations from adding or removing the same membership twice
+	lock sync.Mutex
+	// trigger holds at most one pending request to reconcile all users, coalescing all requests made until Run picks it up
+	trigger chan struct{}
+}
+
+// NewReconciler creates a new reconciler using the given database connection, reconciling all users every interval (or DefaultInterval if not positive) if run
+func NewReconciler(db database.Connection, interval time.Duration) *Reconciler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	reconciler := &Reconciler{
+		database: db,
+		interval: interval,
+		trigger:  make(chan struct{}, 1),
+	}
+
+	return reconciler
+}
+
+// ReconcileAll reconciles the group memberships of all users, returning the applied changes. Users failing to be reconciled are logged and skipped,
+// an error is only returned if the membership rules could not be loaded
+func (reconciler *Reconciler) ReconcileAll(ctx context.Context) ([]*Change, error) {
+	reconciler.lock.Lock()
+	defer reconciler.lock.Unlock()
+
+	rules, err := reconciler.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := reconciler.database.LoadAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*Change, 0)
+
+	for _, user := range users {
+		userChanges, err := reconciler.reconcile(ctx, user, rules)
+		if err != nil {
+			misc.Logger.Errorf("Failed to reconcile group memberships of user #%d: [%v]", user.ID, err)
+			continue
+		}
+
+		changes = append(changes, userChanges...)
+	}
+
+	return changes, nil
+}
+
+// ReconcileUser reconciles the group memberships of the user with the given ID, returning the applied changes or an error if the reconciliation failed
+func (reconciler *Reconciler) ReconcileUser(ctx context.Context, userID int64) ([]*Change, error) {
+	reconciler.lock.Lock()
+	defer reconciler.lock.Unlock()
+
+	rules, err := reconciler.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := reconciler.database.LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciler.reconcile(ctx, user, rules)
+}
+
+// Trigger requests Run to reconcile all users as soon as possible without waiting for the result.
+// Requests made while a reconciliation is still pending are coalesced into it
+func (reconciler *Reconciler) Trigger() {
+	select {
+	case reconciler.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run reconciles all users immediately, once every interval and whenever triggered until the given context is done
+func (reconciler *Reconciler) Run(ctx context.Context) {
+	reconciler.runOnce(ctx)
+
+	ticker := time.NewTicker(reconciler.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reconciler.runOnce(ctx)
+		case <-reconciler.trigger:
+			reconciler.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce reconciles all users, logging errors instead of returning them
+func (reconciler *Reconciler) runOnce(ctx context.Context) {
+	_, err := reconciler.ReconcileAll(ctx)
+	if err != nil {
+		misc.Logger.Errorf("Failed to reconcile group memberships: [%v]", err)
+	}
+}
+
+// loadRules retrieves all membership rules as well as the alliance of every corporation from the database
+func (reconciler *Reconciler) loadRules(ctx context.Context) (*rules, error) {
+	membershipRules, err := reconciler.database.LoadAllMembershipRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	corporations, err := reconciler.database.LoadAllCorporations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &rules{
+		byGroup:     make(map[int64][]*models.MembershipRule),
+		allianceIDs: make(map[int64]int64),
+	}
+
+	for _, membershipRule := range membershipRules {
+		if _, ok := r.byGroup[membershipRule.GroupID]; !ok {
+			r.groupIDs = append(r.groupIDs, membershipRule.GroupID)
+		}
+
+		r.byGroup[membershipRule.GroupID] = append(r.byGroup[membershipRule.GroupID], membershipRule)
+	}
+
+	for _, corporation := range corporations {
+		if corporation.AllianceID.Valid {
+			r.allianceIDs[corporation.ID] = corporation.AllianceID.Int64
+		}
+	}
+
+	return r, nil
+}
+
+// matchingGroups returns the IDs of all groups the given user matches at least one membership rule of
+func (r *rules) matchingGroups(user *models.User) []int64 {
+	var groupIDs []int64
+
+	for _, groupID := range r.groupIDs {
+		for _, membershipRule := range r.byGroup[groupID] {
+			if membershipRule.Matches(user, r.allianceIDs) {
+				groupIDs = append(groupIDs, groupID)
+				break
+			}
+		}
+	}
+
+	return groupIDs
+}
+
+// reconcile adds the given user to all groups matched but not joined yet and removes all automatically added memberships of groups no longer matched.
+// All changes for the user are applied within a single transaction
+func (reconciler *Reconciler) reconcile(ctx context.Context, user *models.User, r *rules) ([]*Change, error) {
+	matchingGroupIDs := r.matchingGroups(user)
+
+	matching := make(map[int64]bool)
+	member := make(map[int64]bool)
+
+	var added []int64
+	var removed []int64
+
+	for _, groupID := range matchingGroupIDs {
+		matching[groupID] = true
+	}
+
+	for _, group := range user.Groups {
+		member[group.ID] = true
+
+		if user.IsGroupAutoAdded(group.ID) && !matching[group.ID] {
+			removed = append(removed, group.ID)
+		}
+	}
+
+	for _, groupID := range matchingGroupIDs {
+		if !member[groupID] {
+			added = append(added, groupID)
+		}
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, nil
+	}
+
+	err := reconciler.database.WithTx(ctx, func(tx database.Connection) error {
+		var err error
+
+		for _, groupID := range removed {
+			user, err = tx.RemoveUserFromGroup(ctx, user.ID, groupID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(added) == 0 {
+			return nil
+		}
+
+		if user.AutoAddedGroups == nil {
+			user.AutoAddedGroups = make(map[int64]bool)
+		}
+
+		for _, groupID := range added {
+			group, err := tx.LoadGroup(ctx, groupID)
+			if err != nil {
+				return err
+			}
+
+			user.Groups = append(user.Groups, group)
+			user.AutoAddedGroups[group.ID] = true
+		}
+
+		_, err = tx.SaveUser(ctx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*Change, 0, len(added)+len(removed))
+
+	for _, groupID := range removed {
+		misc.Logger.Infof("Removed user #%d from group #%d as no membership rule matches anymore", user.ID, groupID)
+		changes = append(changes, &Change{UserID: user.ID, GroupID: groupID, Added: false})
+	}
+
+	for _, groupID := range added {
+		misc.Logger.Infof("Added user #%d to group #%d as a membership rule matches", user.ID, groupID)
+		changes = append(changes, &Change{UserID: user.ID, GroupID: groupID, Added: true})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,271 @@
+package membership
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/database/memory"
+	"github.com/morpheusxaut/eveauth/misc"
+	"github.com/morpheusxaut/eveauth/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+// reconcilerFixture stores the entries created by createReconciler
+type reconcilerFixture struct {
+	db              *memory.DatabaseConnection
+	corporation     *models.Corporation
+	allianceCorp    *models.Corporation
+	alliance        *models.Alliance
+	corpGroup       *models.Group
+	allianceGroup   *models.Group
+	defaultGroup    *models.Group
+	manualGroup     *models.Group
+	corpMember      *models.User
+	allianceMember  *models.User
+	unrelatedMember *models.User
+}
+
+// createReconciler returns a reconciler using an in-memory database populated with a group per membership rule type and users matching them
+func createReconciler() (*Reconciler, *reconcilerFixture, error) {
+	ctx := context.Background()
+
+	f := &reconcilerFixture{
+		db: &memory.DatabaseConnection{
+			Config: &misc.Configuration{
+				DatabaseType: 0,
+				DebugLevel:   1,
+			},
+		},
+	}
+
+	err := f.db.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f.alliance, err = f.db.SaveAlliance(ctx, models.NewAlliance("Test Alliance Please Ignore", "TEST", 1, 2, true))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f.corporation, err = f.db.SaveCorporation(ctx, models.NewCorporation("Test Corp Please Ignore", "TEST", 1, 1, zero.IntFrom(0), zero.StringFrom(""), true))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allianceCorp := models.NewCorporation("Alliance Corp", "ALLY", 2, 2, zero.IntFrom(0), zero.StringFrom(""), true)
+	allianceCorp.AllianceID = zero.IntFrom(f.alliance.ID)
+
+	f.allianceCorp, err = f.db.SaveCorporation(ctx, allianceCorp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups := make([]*models.Group, 4)
+
+	for i, name := range []string{"Corporation", "Alliance", "Default", "Manual"} {
+		groups[i], err = f.db.SaveGroup(ctx, models.NewGroup(name, true))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	f.corpGroup, f.allianceGroup, f.defaultGroup, f.manualGroup = groups[0], groups[1], groups[2], groups[3]
+
+	membershipRules := []*models.MembershipRule{
+		models.NewMembershipRule(f.corpGroup.ID, models.MembershipRuleTypeCorporation, f.corporation.ID),
+		models.NewMembershipRule(f.allianceGroup.ID, models.MembershipRuleTypeAlliance, f.alliance.ID),
+		models.NewMembershipRule(f.defaultGroup.ID, models.MembershipRuleTypeDefaultCorporation, f.corporation.ID),
+	}
+
+	for _, membershipRule := range membershipRules {
+		_, err = f.db.SaveMembershipRule(ctx, membershipRule)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	corpMember := models.NewUser("corp", "password", "corp@example.com", true, true)
+	corpAccount := models.NewAccount(-1, 1, "a", 0, true)
+	corpAccount.Characters = append(corpAccount.Characters, models.NewCharacter(-1, f.allianceCorp.ID, "Main", 1, true, true), models.NewCharacter(-1, f.corporation.ID, "Alt", 2, false, true))
+	corpMember.Accounts = append(corpMember.Accounts, corpAccount)
+	corpMember.Groups = append(corpMember.Groups, f.manualGroup)
+
+	f.corpMember, err = f.db.SaveUser(ctx, corpMember)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allianceMember := models.NewUser("alliance", "password", "alliance@example.com", true, true)
+	allianceAccount := models.NewAccount(-1, 2, "b", 0, true)
+	allianceAccount.Characters = append(allianceAccount.Characters, models.NewCharacter(-1, f.corporation.ID, "Inactive", 3, false, false), models.NewCharacter(-1, f.allianceCorp.ID, "Ally", 4, true, true))
+	allianceMember.Accounts = append(allianceMember.Accounts, allianceAccount)
+	allianceMember.Groups = append(allianceMember.Groups, f.corpGroup)
+
+	f.allianceMember, err = f.db.SaveUser(ctx, allianceMember)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f.unrelatedMember, err = f.db.SaveUser(ctx, models.NewUser("unrelated", "password", "unrelated@example.com", true, true))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewReconciler(f.db, 0), f, nil
+}
+
+// groupNames returns the names of the given groups
+func groupNames(groups []*models.Group) []string {
+	names := make([]string, 0, len(groups))
+
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+
+	return names
+}
+
+func TestReconciler(t *testing.T) {
+	misc.SetupLogger(9)
+
+	ctx := context.Background()
+
+	Convey("Reconciling group memberships", t, func() {
+		reconciler, f, err := createReconciler()
+		So(err, ShouldBeNil)
+
+		Convey("Users matching a membership rule should be added to its group", func() {
+			changes, err := reconciler.ReconcileAll(ctx)
+			So(err, ShouldBeNil)
+			So(changes, ShouldResemble, []*Change{
+				{UserID: f.corpMember.ID, GroupID: f.corpGroup.ID, Added: true},
+				{UserID: f.corpMember.ID, GroupID: f.allianceGroup.ID, Added: true},
+				{UserID: f.allianceMember.ID, GroupID: f.allianceGroup.ID, Added: true},
+			})
+
+			user, err := f.db.LoadUser(ctx, f.corpMember.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Corporation", "Alliance", "Manual"})
+			So(user.IsGroupAutoAdded(f.corpGroup.ID), ShouldBeTrue)
+			So(user.IsGroupAutoAdded(f.allianceGroup.ID), ShouldBeTrue)
+			So(user.IsGroupAutoAdded(f.manualGroup.ID), ShouldBeFalse)
+
+			Convey("Reconciling again should not change anything", func() {
+				changes, err := reconciler.ReconcileAll(ctx)
+				So(err, ShouldBeNil)
+				So(changes, ShouldBeEmpty)
+			})
+		})
+
+		Convey("Manual memberships should never be touched", func() {
+			_, err := reconciler.ReconcileAll(ctx)
+			So(err, ShouldBeNil)
+
+			user, err := f.db.LoadUser(ctx, f.allianceMember.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Corporation", "Alliance"})
+			So(user.IsGroupAutoAdded(f.corpGroup.ID), ShouldBeFalse)
+		})
+
+		Convey("The default character should be checked for default corporation rules", func() {
+			user, err := f.db.LoadUser(ctx, f.corpMember.ID)
+			So(err, ShouldBeNil)
+
+			user.Accounts[0].Characters[0].DefaultCharacter = false
+			user.Accounts[0].Characters[1].DefaultCharacter = true
+
+			_, err = f.db.SaveUser(ctx, user)
+			So(err, ShouldBeNil)
+
+			changes, err := reconciler.ReconcileUser(ctx, f.corpMember.ID)
+			So(err, ShouldBeNil)
+			So(len(changes), ShouldEqual, 3)
+			So(changes[2], ShouldResemble, &Change{UserID: f.corpMember.ID, GroupID: f.defaultGroup.ID, Added: true})
+		})
+
+		Convey("Automatically added memberships should be removed once no rule matches anymore", func() {
+			_, err := reconciler.ReconcileAll(ctx)
+			So(err, ShouldBeNil)
+
+			err = f.db.DeleteAccount(ctx, f.corpMember.Accounts[0].ID)
+			So(err, ShouldBeNil)
+
+			changes, err := reconciler.ReconcileUser(ctx, f.corpMember.ID)
+			So(err, ShouldBeNil)
+			So(changes, ShouldResemble, []*Change{
+				{UserID: f.corpMember.ID, GroupID: f.corpGroup.ID, Added: false},
+				{UserID: f.corpMember.ID, GroupID: f.allianceGroup.ID, Added: false},
+			})
+
+			user, err := f.db.LoadUser(ctx, f.corpMember.ID)
+			So(err, ShouldBeNil)
+			So(groupNames(user.Groups), ShouldResemble, []string{"Manual"})
+			So(user.AutoAddedGroups, ShouldBeEmpty)
+		})
+
+		Convey("Deleting a membership rule should remove the memberships added by it on the next reconciliation", func() {
+			_, err := reconciler.ReconcileAll(ctx)
+			So(err, ShouldBeNil)
+
+			membershipRules, err := f.db.LoadAllMembershipRulesForGroup(ctx, f.allianceGroup.ID)
+			So(err, ShouldBeNil)
+			So(len(membershipRules), ShouldEqual, 1)
+
+			err = f.db.DeleteMembershipRule(ctx, membershipRules[0].ID)
+			So(err, ShouldBeNil)
+
+			changes, err := reconciler.ReconcileAll(ctx)
+			So(err, ShouldBeNil)
+			So(changes, ShouldResemble, []*Change{
+				{UserID: f.corpMember.ID, GroupID: f.allianceGroup.ID, Added: false},
+				{UserID: f.allianceMember.ID, GroupID: f.allianceGroup.ID, Added: false},
+			})
+		})
+
+		Convey("Triggering a reconciliation should coalesce pending requests and reconcile all users while running", func() {
+			reconciler.Trigger()
+			reconciler.Trigger()
+			So(len(reconciler.trigger), ShouldEqual, 1)
+
+			runCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+
+			go func() {
+				reconciler.Run(runCtx)
+				close(done)
+			}()
+
+			membershipRules, err := f.db.LoadAllMembershipRulesForGroup(ctx, f.allianceGroup.ID)
+			So(err, ShouldBeNil)
+			So(f.db.DeleteMembershipRule(ctx, membershipRules[0].ID), ShouldBeNil)
+
+			reconciler.Trigger()
+
+			var groups []*models.Group
+
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				user, err := f.db.LoadUser(ctx, f.corpMember.ID)
+				So(err, ShouldBeNil)
+
+				groups = user.Groups
+				if len(reconciler.trigger) == 0 && len(groups) == 2 {
+					break
+				}
+			}
+
+			cancel()
+			<-done
+
+			So(groupNames(groups), ShouldResemble, []string{"Corporation", "Manual"})
+		})
+
+		Convey("Reconciling an unknown user should fail", func() {
+			_, err := reconciler.ReconcileUser(ctx, 1337)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/misc"
+)
+
+// runMigration performs the given migration command (up, down or status) against the database, returning the exit code for the application
+func runMigration(db database.Connection, command string) int {
+	switch strings.ToLower(command) {
+	case "up":
+		version, err := db.MigrateUp()
+		if err != nil {
+			misc.Logger.Criticalf("Failed to apply migrations: [%v]", err)
+			return 2
+		}
+
+		misc.Logger.Infof("Database schema is now at version #%d", version)
+	case "down":
+		version, err := db.MigrateDown()
+		if err != nil {
+			misc.Logger.Criticalf("Failed to revert migration: [%v]", err)
+			return 2
+		}
+
+		misc.Logger.Infof("Database schema is now at version #%d", version)
+	case "status":
+		status, err := db.MigrationStatus()
+		if err != nil {
+			misc.Logger.Criticalf("Failed to load migration status: [%v]", err)
+			return 2
+		}
+
+		fmt.Printf("Current schema version: %d\n", status.Version)
+		fmt.Printf("Latest schema version: %d\n", status.LatestVersion)
+
+		for _, migration := range status.Pending {
+			fmt.Printf("Pending migration #%d: %s\n", migration.Version, migration.Description)
+		}
+
+		if status.Newer() {
+			fmt.Printf("Database schema is newer than supported by this release\n")
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: eveauth [options] migrate up|down|status\n")
+		return 2
+	}
+
+	return 0
+}
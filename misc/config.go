@@ -11,7 +11,7 @@ import (
 type Configuration struct {
 	// DatabaseType represents the database type to be used as a backend
 	DatabaseType int
-	// DatabaseHost represents the hostname:port of the database backend
+	// DatabaseHost represents the hostname:port of the database backend or the path to the database file when using SQLite
 	DatabaseHost string
 	// DatabaseSchema represents the schema/collection of the database backend
 	DatabaseSchema string
@@ -19,12 +19,44 @@ type Configuration struct {
 	DatabaseUser string
 	// DatabasePassword represents the password used to authenticate with the database backend
 	DatabasePassword string
+	// DatabaseAutoMigrate toggles applying pending schema migrations automatically when connecting to the database backend
+	DatabaseAutoMigrate bool
+	// DatabaseCacheTTL represents the number of seconds users, groups, roles and corporations loaded from the database backend are cached for, disabling the cache if set to 0
+	DatabaseCacheTTL int
+	// DatabaseQueryTimeout represents the number of milliseconds a single query may take before being cancelled, disabling the deadline if set to 0
+	DatabaseQueryTimeout int
+	// DatabaseMaxOpenConnections represents the maximum number of connections opened to the database backend, not limiting them if set to 0
+	DatabaseMaxOpenConnections int
+	// DatabaseMaxIdleConnections represents the maximum number of idle connections kept open to the database backend, using the driver's default if set to 0
+	DatabaseMaxIdleConnections int
+	// DatabaseConnectionLifetime represents the number of seconds a connection to the database backend is reused for, reusing connections forever if set to 0
+	DatabaseConnectionLifetime int
+	// DatabaseConnectTimeout represents the number of milliseconds establishing a connection to the MySQL or PostgreSQL backend may take, using the driver's default if set to 0
+	DatabaseConnectTimeout int
+	// DatabaseTrashRetention represents the number of days deleted users, groups, roles and applications are kept in the trash before being purged permanently, keeping them forever if set to 0
+	DatabaseTrashRetention int
+	// DatabaseLoginAttemptRetention represents the number of days login attempts are kept before being added to the daily summaries and removed, keeping them forever if set to 0
+	DatabaseLoginAttemptRetention int
+	// DatabaseCSRFFailureRetention represents the number of days CSRF failures are kept before being removed, keeping them forever if set to 0
+	DatabaseCSRFFailureRetention int
 	// RedisHost represents the hostname:port of the Redis data store
 	RedisHost string
 	// RedisPassword represents the password used to authenticate with the Redis data store
 	RedisPassword string
 	// RedisDB represents the database to select for the session store
 	RedisDB string
+	// RedisMaxIdle represents the maximum number of idle connections kept open to the Redis data store, defaulting to 3 for the web and 10 for the session store if set to 0
+	RedisMaxIdle int
+	// RedisMaxActive represents the maximum number of connections opened to the Redis data store per pool, not limiting them if set to 0
+	RedisMaxActive int
+	// RedisIdleTimeout represents the number of seconds an idle connection to the Redis data store is kept open for, defaulting to 240 if set to 0
+	RedisIdleTimeout int
+	// RedisConnectTimeout represents the number of milliseconds establishing a connection to the Redis data store may take, not limiting it if set to 0
+	RedisConnectTimeout int
+	// RedisReadTimeout represents the number of milliseconds reading a reply from the Redis data store may take, not limiting it if set to 0
+	RedisReadTimeout int
+	// RedisWriteTimeout represents the number of milliseconds sending a command to the Redis data store may take, not limiting it if set to 0
+	RedisWriteTimeout int
 	// SMTPHost represents the hostname:port of the SMTP server used for sending mails
 	SMTPHost string
 	// SMTPStartTLS indicates whether the SMTP connection should use the StartTLS command to secure communications
@@ -35,6 +67,12 @@ type Configuration struct {
 	SMTPPassword string
 	// SMTPSender represents the email address set as the sender of all outgoing emails
 	SMTPSender string
+	// HealthCheckInterval represents the number of seconds between periodic health checks of the database backend and Redis data store, checking on request only if set to 0
+	HealthCheckInterval int
+	// HealthCheckTimeout represents the number of milliseconds a single health check may take before being considered failed, defaulting to 5000 if set to 0
+	HealthCheckTimeout int
+	// MembershipReconcileInterval represents the number of minutes between reconciliations of all automatically managed group memberships, defaulting to 60 if set to 0
+	MembershipReconcileInterval int
 	// DebugLevel represents the debug level for log messages
 	DebugLevel int
 	// DebugTemplates toggles the reloading of all templates for every request
@@ -49,6 +87,8 @@ type Configuration struct {
 func LoadConfig() (*Configuration, error) {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: eveauth [options]\n")
+		fmt.Fprintf(os.Stderr, "       eveauth [options] migrate up|down|status\n")
+		fmt.Fprintf(os.Stderr, "       eveauth [options] export|import <file>\n")
 		flag.PrintDefaults()
 		os.Exit(2)
 	}
@@ -6,10 +6,17 @@ import (
 )
 
 var (
-	debugLevelFlag     = flag.Int("debug", 3, "Sets the debug level (0-9), lower number displays more messages")
-	debugTemplatesFlag = flag.Bool("templates", false, "Enables reloading of HTML templates on every request to help development")
-	httpHostFlag       = flag.String("http", "0.0.0.0:5000", "Hostname:port for the webserver to bind to")
-	configFileFlag     = flag.String("config", "config.cfg", "Path to the config file to parse")
+	debugLevelFlag            = flag.Int("debug", 3, "Sets the debug level (0-9), lower number displays more messages")
+	debugTemplatesFlag        = flag.Bool("templates", false, "Enables reloading of HTML templates on every request to help development")
+	httpHostFlag              = flag.String("http", "0.0.0.0:5000", "Hostname:port for the webserver to bind to")
+	configFileFlag            = flag.String("config", "config.cfg", "Path to the config file to parse")
+	autoMigrateFlag           = flag.Bool("automigrate", false, "Applies pending database schema migrations automatically when connecting")
+	cacheTTLFlag              = flag.Int("cachettl", 0, "Number of seconds database entries are cached for, 0 disables the cache")
+	queryTimeoutFlag          = flag.Int("querytimeout", 0, "Number of milliseconds a single database query may take before being cancelled, 0 disables the deadline")
+	trashRetentionFlag        = flag.Int("trashretention", 0, "Number of days deleted entries are kept in the trash before being purged, 0 keeps them forever")
+	loginAttemptRetentionFlag = flag.Int("loginattemptretention", 0, "Number of days login attempts are kept before being added to the daily summaries, 0 keeps them forever")
+	csrfFailureRetentionFlag  = flag.Int("csrffailureretention", 0, "Number of days CSRF failures are kept before being removed, 0 keeps them forever")
+	reconcileIntervalFlag     = flag.Int("reconcileinterval", 0, "Number of minutes between reconciliations of automatic group memberships, 0 uses the default of 60")
 )
 
 // ParseCommandlineFlags parses the command line flags used with the application
@@ -23,6 +30,27 @@ func ParseCommandlineFlags(config *Configuration) *Configuration {
 	if !strings.EqualFold(*httpHostFlag, "0.0.0.0:5000") {
 		config.HTTPHost = *httpHostFlag
 	}
+	if *autoMigrateFlag != false {
+		config.DatabaseAutoMigrate = *autoMigrateFlag
+	}
+	if *cacheTTLFlag != 0 {
+		config.DatabaseCacheTTL = *cacheTTLFlag
+	}
+	if *queryTimeoutFlag != 0 {
+		config.DatabaseQueryTimeout = *queryTimeoutFlag
+	}
+	if *trashRetentionFlag != 0 {
+		config.DatabaseTrashRetention = *trashRetentionFlag
+	}
+	if *loginAttemptRetentionFlag != 0 {
+		config.DatabaseLoginAttemptRetention = *loginAttemptRetentionFlag
+	}
+	if *csrfFailureRetentionFlag != 0 {
+		config.DatabaseCSRFFailureRetention = *csrfFailureRetentionFlag
+	}
+	if *reconcileIntervalFlag != 0 {
+		config.MembershipReconcileInterval = *reconcileIntervalFlag
+	}
 
 	return config
 }
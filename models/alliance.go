@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// Alliance represents an EVE Online alliance
+type Alliance struct {
+	// ID represents the database ID of the Alliance
+	ID int64 `json:"id"`
+	// Name represents the ingame name of the Alliance
+	Name string `json:"name"`
+	// Ticker represents the ingame ticker of the Alliance
+	Ticker string `json:"ticker"`
+	// EVEAllianceID represents the ingame alliance ID of the Alliance
+	EVEAllianceID int64 `json:"eveAllianceID"`
+	// ExecutorCorpID represents the EVE corporation ID of the executor corporation of the Alliance
+	ExecutorCorpID int64 `json:"executorCorpID"`
+	// Active indicates whether the Alliance is set as active
+	Active bool `json:"active"`
+}
+
+// AuthAlliance represents an alliance used by the authorization handler to pass required information to apps
+type AuthAlliance struct {
+	// ID represents the database ID of the Alliance
+	ID int64 `json:"id"`
+	// Name represents the ingame name of the Alliance
+	Name string `json:"name"`
+	// Ticker represents the ingame ticker of the Alliance
+	Ticker string `json:"ticker"`
+	// EVEAllianceID represents the ingame alliance ID of the Alliance
+	EVEAllianceID int64 `json:"eveAllianceID"`
+}
+
+// NewAlliance creates a new alliance with the given information
+func NewAlliance(name string, ticker string, eveAllianceID int64, executorCorpID int64, active bool) *Alliance {
+	alliance := &Alliance{
+		ID:             -1,
+		Name:           name,
+		Ticker:         ticker,
+		EVEAllianceID:  eveAllianceID,
+		ExecutorCorpID: executorCorpID,
+		Active:         active,
+	}
+
+	return alliance
+}
+
+// ToAuthAlliance converts the given alliance to an AuthAlliance, exporting only the information required by third-party apps
+func (alliance *Alliance) ToAuthAlliance() *AuthAlliance {
+	authAlliance := &AuthAlliance{
+		ID:            alliance.ID,
+		Name:          alliance.Name,
+		Ticker:        alliance.Ticker,
+		EVEAllianceID: alliance.EVEAllianceID,
+	}
+
+	return authAlliance
+}
+
+// String represents a JSON encoded representation of the alliance
+func (alliance *Alliance) String() string {
+	jsonContent, err := json.Marshal(alliance)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
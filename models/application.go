@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 )
 
 // Application represents an application registered with the auth backend
@@ -18,6 +20,10 @@ type Application struct {
 	Callback string `json:"callback"`
 	// Active indicates whether the app is set as active
 	Active bool `json:"active"`
+	// Version represents the revision of the app, incremented every time it is modified
+	Version int64 `json:"version"`
+	// RoleScopes represents the space-separated global roles the app may see. Scopes are either role names, prefixes ending in ".*" or "*" for all global roles
+	RoleScopes string `json:"roleScopes"`
 }
 
 // NewApplication creates a new application with the given information
@@ -34,6 +40,52 @@ func NewApplication(name string, maintainer int64, secret string, callback strin
 	return application
 }
 
+// ParseRoleScopes validates the given space-separated role scopes, returning them normalised or an error if a scope is malformed
+func ParseRoleScopes(roleScopes string) (string, error) {
+	scopes := strings.Fields(roleScopes)
+
+	for _, scope := range scopes {
+		if scope == "*" {
+			continue
+		}
+
+		pattern := strings.TrimSuffix(scope, ".*")
+		if len(pattern) == 0 || strings.Contains(pattern, "*") {
+			return "", fmt.Errorf("Invalid role scope %q", scope)
+		}
+	}
+
+	return strings.Join(scopes, " "), nil
+}
+
+// CanSeeRole checks whether the role should be exported to the app. Roles of the app's namespace are always visible, roles of other namespaces never.
+// Global roles are only visible if they match one of the app's role scopes
+func (application *Application) CanSeeRole(role *Role) bool {
+	if !role.IsGlobal() {
+		return role.ApplicationID.Int64 == application.ID
+	}
+
+	for _, scope := range strings.Fields(application.RoleScopes) {
+		if scope == "*" {
+			return true
+		}
+
+		if strings.HasSuffix(scope, ".*") {
+			if len(role.Name) > len(scope)-1 && strings.EqualFold(role.Name[:len(scope)-1], scope[:len(scope)-1]) {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(role.Name, scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // String represents a JSON encoded representation of the app
 func (application *Application) String() string {
 	jsonContent, err := json.Marshal(application)
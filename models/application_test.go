@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/guregu/null.v2/zero"
+)
+
+func TestApplicationRoleScopes(t *testing.T) {
+	Convey("Filtering roles exported to applications", t, func() {
+		adminUsers := &Role{ID: 1, Name: "admin.users", Active: true}
+		fleet := &Role{ID: 2, Name: "fleet", Active: true}
+		fleetCommander := &Role{ID: 3, Name: "fleet.commander", Active: true}
+		wikiEdit := &Role{ID: 4, Name: "wiki.edit", Active: true, ApplicationID: zero.IntFrom(1)}
+		forumPost := &Role{ID: 5, Name: "forum.post", Active: true, ApplicationID: zero.IntFrom(2)}
+
+		application := NewApplication("Wiki", 1, "secret", "http://localhost/callback", true)
+		application.ID = 1
+
+		user := NewUser("test1", "", "test1@example.com", true, true)
+		user.UserRoles = []*UserRole{
+			NewUserRole(user.ID, adminUsers, false, true),
+			NewUserRole(user.ID, fleet, false, true),
+			NewUserRole(user.ID, fleetCommander, false, true),
+			NewUserRole(user.ID, wikiEdit, false, true),
+			NewUserRole(user.ID, forumPost, false, true),
+		}
+
+		Convey("Applications should only see their own roles without any role scopes", func() {
+			So(application.CanSeeRole(wikiEdit), ShouldBeTrue)
+			So(application.CanSeeRole(forumPost), ShouldBeFalse)
+			So(application.CanSeeRole(adminUsers), ShouldBeFalse)
+
+			So(user.ToAuthUserForApplication(application).Roles, ShouldResemble, []string{"wiki.edit"})
+		})
+
+		Convey("Role scopes should match global role names and prefixes", func() {
+			application.RoleScopes = "fleet.*"
+			So(application.CanSeeRole(fleetCommander), ShouldBeTrue)
+			So(application.CanSeeRole(fleet), ShouldBeFalse)
+
+			application.RoleScopes = "FLEET"
+			So(application.CanSeeRole(fleet), ShouldBeTrue)
+			So(application.CanSeeRole(fleetCommander), ShouldBeFalse)
+		})
+
+		Convey("The wildcard scope should match all global roles but no other namespaces", func() {
+			application.RoleScopes = "*"
+
+			So(application.CanSeeRole(adminUsers), ShouldBeTrue)
+			So(application.CanSeeRole(forumPost), ShouldBeFalse)
+			So(len(user.ToAuthUserForApplication(application).Roles), ShouldEqual, 4)
+		})
+
+		Convey("Exporting roles without an application should not filter them", func() {
+			So(len(user.ToAuthUser().Roles), ShouldEqual, 5)
+		})
+
+		Convey("Parsing role scopes should normalise them and reject malformed scopes", func() {
+			roleScopes, err := ParseRoleScopes("  fleet.*   wiki.edit ")
+			So(err, ShouldBeNil)
+			So(roleScopes, ShouldEqual, "fleet.* wiki.edit")
+
+			_, err = ParseRoleScopes("fleet*")
+			So(err, ShouldNotBeNil)
+
+			_, err = ParseRoleScopes(".*")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
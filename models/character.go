@@ -34,6 +34,8 @@ type AuthCharacter struct {
 	EVECharacterID int64 `json:"eveCharacterID"`
 	// DefaultCharacter indicates whether the character is selected as default for the account
 	DefaultCharacter bool `json:"defaultCharacter"`
+	// Alliance represents the alliance the Character's corporation is in, nil if the corporation is not in an alliance
+	Alliance *AuthAlliance `json:"alliance,omitempty"`
 }
 
 // NewCharacter creates a new character with the given information
@@ -18,6 +18,8 @@ type Corporation struct {
 	EVECorporationID int64 `json:"eveCorporationID"`
 	// CEOID represents the EVE character ID of the CEO of the Corporation
 	CEOID int64 `json:"ceoID"`
+	// AllianceID represents the database ID of the alliance the Corporation is in, null if the Corporation is not in an alliance
+	AllianceID zero.Int `json:"allianceID"`
 	// APIKeyID represents the EVE Online API key ID for the Corporation
 	APIKeyID zero.Int `json:"apiKeyID"`
 	// APIvCode represents the EVE Online API verification code for the Corporation
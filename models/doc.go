@@ -1,2 +1,22 @@
 // Package models provides the models being used by the application to store required information.
+//
+// The status of a role for a user is decided by a single evaluator shared by User.HasRole, User.GetEffectiveRoles and Explain,
+// applying the following rules in order:
+//
+//  1. Inactive roles are ignored, they are neither granted nor denied. User roles and group memberships outside of their
+//     validity window (not yet started or already expired) are ignored as well.
+//     Inactive groups are ignored the same way whether the user joined them directly or through a group containing them,
+//     neither their group roles nor the groups nested in them apply.
+//     Members of a group are considered members of all active groups nested in it (directly or through other nested groups)
+//     for the validity window of their membership, the group roles of nested groups apply as if the user joined them directly.
+//  2. A user role decides the status of its role, overriding all group roles assigning the same role.
+//  3. Without a user role, a denying group role wins over granting group roles, regardless of the order of the groups.
+//     If several groups deny (or only grant) the role, the group with the lowest ID is reported as the decisive one.
+//  4. A role granted by a user or group role is denied instead if its policy does not hold for the user, see PolicyAttributes for the
+//     attributes available to policies.
+//  5. Without any user or group role, a role implied by a granted role is granted as well, unless its own policy does not hold.
+//     Implications are only followed through granted roles, a role denied by a user role, group role or its policy never grants
+//     the roles it implies, not even through further roles implying them. If several granted roles imply it, the implying role closest
+//     to a role granted by a user or group role is reported as the decisive one, preferring the lowest ID among equally close roles.
+//  6. Otherwise the role does not exist for the user.
 package models
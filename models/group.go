@@ -2,6 +2,19 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+)
+
+// GroupVisibility represents how users can become members of a group on their own
+type GroupVisibility string
+
+const (
+	// GroupVisibilityOpen allows any user to join the group right away
+	GroupVisibilityOpen GroupVisibility = "open"
+	// GroupVisibilityApplication allows users to apply for a membership, which has to be approved by a group manager or administrator
+	GroupVisibilityApplication GroupVisibility = "application"
+	// GroupVisibilityHidden hides the group from users, only allowing administrators and group managers to add members
+	GroupVisibilityHidden GroupVisibility = "hidden"
 )
 
 // Group represents a group of users and permissions
@@ -12,8 +25,24 @@ type Group struct {
 	Name string `json:"name"`
 	// Active indicates whether the Group is set as active
 	Active bool `json:"active"`
+	// Version represents the revision of the Group, incremented every time it is modified
+	Version int64 `json:"version"`
+	// Visibility represents how users can become members of the Group on their own
+	Visibility GroupVisibility `json:"visibility"`
 	// GroupRoles stores all the roles associated with the Group
 	GroupRoles []*GroupRole `json:"groupRoles,omitempty"`
+	// SubGroups represents the groups directly nested in the Group. Members of the Group are considered members of all its sub groups as well, inheriting their roles
+	SubGroups []*Group `json:"subGroups,omitempty"`
+}
+
+// GroupNesting represents a single edge of the group nesting graph, stating that a group contains another one
+type GroupNesting struct {
+	// ID represents the database ID of the GroupNesting
+	ID int64 `json:"id"`
+	// GroupID represents the database ID of the containing group
+	GroupID int64 `json:"groupID"`
+	// SubGroupID represents the database ID of the group being contained
+	SubGroupID int64 `json:"subGroupID"`
 }
 
 // NewGroup creates a new group with the given information
@@ -22,12 +51,28 @@ func NewGroup(name string, active bool) *Group {
 		ID:         -1,
 		Name:       name,
 		Active:     active,
+		Visibility: GroupVisibilityHidden,
 		GroupRoles: make([]*GroupRole, 0),
 	}
 
 	return group
 }
 
+// ParseGroupVisibility parses the given string as a group visibility, returning an error if the visibility is unknown
+func ParseGroupVisibility(visibility string) (GroupVisibility, error) {
+	switch GroupVisibility(visibility) {
+	case GroupVisibilityOpen, GroupVisibilityApplication, GroupVisibilityHidden:
+		return GroupVisibility(visibility), nil
+	default:
+		return "", fmt.Errorf("Unknown group visibility %q", visibility)
+	}
+}
+
+// IsListed checks whether users can see the group and join or apply to it on their own. Inactive groups are never listed
+func (group *Group) IsListed() bool {
+	return group.Active && (group.Visibility == GroupVisibilityOpen || group.Visibility == GroupVisibilityApplication)
+}
+
 // HasRole returns the RoleStatus for the provided role name
 func (group *Group) HasRole(role string) RoleStatus {
 	for _, groupRole := range group.GroupRoles {
@@ -40,6 +85,32 @@ func (group *Group) HasRole(role string) RoleStatus {
 	return RoleStatusNonExistent
 }
 
+// SubGroupClosure returns all active groups nested in the current group, directly or through other sub groups, indexed by the group ID.
+// The group itself is not part of the result
+func (group *Group) SubGroupClosure() map[int64]*Group {
+	closure := make(map[int64]*Group)
+
+	pending := append([]*Group{}, group.SubGroups...)
+
+	for len(pending) > 0 {
+		subGroup := pending[0]
+		pending = pending[1:]
+
+		if subGroup.ID == group.ID || !subGroup.Active {
+			continue
+		}
+
+		if _, ok := closure[subGroup.ID]; ok {
+			continue
+		}
+
+		closure[subGroup.ID] = subGroup
+		pending = append(pending, subGroup.SubGroups...)
+	}
+
+	return closure
+}
+
 // GetRoleCount returns the number of roles associated with the current group
 func (group *Group) GetRoleCount() int {
 	return len(group.GroupRoles)
@@ -54,3 +125,13 @@ func (group *Group) String() string {
 
 	return string(jsonContent)
 }
+
+// String represents a JSON encoded representation of the group nesting
+func (groupNesting *GroupNesting) String() string {
+	jsonContent, err := json.Marshal(groupNesting)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GroupApplicationStatus represents the state of a GroupApplication
+type GroupApplicationStatus string
+
+const (
+	// GroupApplicationStatusPending indicates the application has not been decided on yet
+	GroupApplicationStatusPending GroupApplicationStatus = "pending"
+	// GroupApplicationStatusApproved indicates the application has been approved and the applicant added to the group
+	GroupApplicationStatusApproved GroupApplicationStatus = "approved"
+	// GroupApplicationStatusDenied indicates the application has been denied
+	GroupApplicationStatusDenied GroupApplicationStatus = "denied"
+)
+
+// GroupApplication represents the request of a user to become a member of a group requiring an application
+type GroupApplication struct {
+	// ID represents the database ID of the GroupApplication
+	ID int64 `json:"id"`
+	// GroupID represents the database ID of the group applied to
+	GroupID int64 `json:"groupID"`
+	// UserID represents the database ID of the applying user
+	UserID int64 `json:"userID"`
+	// Status represents the state of the GroupApplication
+	Status GroupApplicationStatus `json:"status"`
+	// Message represents the message provided by the applicant
+	Message string `json:"message"`
+	// Comment represents the comment provided by the user deciding on the GroupApplication
+	Comment string `json:"comment"`
+	// DeciderID represents the database ID of the user who decided on the GroupApplication, -1 while pending
+	DeciderID int64 `json:"deciderID"`
+	// CreatedAt represents the time the GroupApplication was submitted at
+	CreatedAt time.Time `json:"createdAt"`
+	// DecidedAt represents the time the GroupApplication was decided on, nil while pending
+	DecidedAt *time.Time `json:"decidedAt,omitempty"`
+}
+
+// NewGroupApplication creates a new pending group application with the given information, submitted right now
+func NewGroupApplication(groupID int64, userID int64, message string) *GroupApplication {
+	groupApplication := &GroupApplication{
+		ID:        -1,
+		GroupID:   groupID,
+		UserID:    userID,
+		Status:    GroupApplicationStatusPending,
+		Message:   message,
+		DeciderID: -1,
+		CreatedAt: time.Now(),
+	}
+
+	return groupApplication
+}
+
+// IsPending checks whether the group application has not been decided on yet
+func (groupApplication *GroupApplication) IsPending() bool {
+	return groupApplication.Status == GroupApplicationStatusPending
+}
+
+// Decide marks the group application as approved or denied by the user with the given ID, storing the provided comment
+func (groupApplication *GroupApplication) Decide(deciderID int64, approved bool, comment string) {
+	now := time.Now()
+
+	groupApplication.Status = GroupApplicationStatusDenied
+	if approved {
+		groupApplication.Status = GroupApplicationStatusApproved
+	}
+
+	groupApplication.Comment = comment
+	groupApplication.DeciderID = deciderID
+	groupApplication.DecidedAt = &now
+}
+
+// String represents a JSON encoded representation of the group application
+func (groupApplication *GroupApplication) String() string {
+	jsonContent, err := json.Marshal(groupApplication)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
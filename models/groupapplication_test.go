@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGroupApplication(t *testing.T) {
+	Convey("Listing groups depending on their visibility", t, func() {
+		group := NewGroup("Test Group", true)
+
+		Convey("New groups should be hidden", func() {
+			So(group.Visibility, ShouldEqual, GroupVisibilityHidden)
+			So(group.IsListed(), ShouldBeFalse)
+		})
+
+		Convey("Open groups and groups requiring an application should be listed while active", func() {
+			group.Visibility = GroupVisibilityOpen
+			So(group.IsListed(), ShouldBeTrue)
+
+			group.Visibility = GroupVisibilityApplication
+			So(group.IsListed(), ShouldBeTrue)
+
+			group.Active = false
+			So(group.IsListed(), ShouldBeFalse)
+		})
+
+		Convey("Parsing unknown visibilities should fail", func() {
+			visibility, err := ParseGroupVisibility("application")
+			So(err, ShouldBeNil)
+			So(visibility, ShouldEqual, GroupVisibilityApplication)
+
+			_, err = ParseGroupVisibility("secret")
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Deciding on group applications", t, func() {
+		groupApplication := NewGroupApplication(1, 2, "Please")
+
+		So(groupApplication.IsPending(), ShouldBeTrue)
+		So(groupApplication.DeciderID, ShouldEqual, -1)
+		So(groupApplication.DecidedAt, ShouldBeNil)
+
+		Convey("Approving should store the decider and comment", func() {
+			groupApplication.Decide(3, true, "Welcome")
+
+			So(groupApplication.IsPending(), ShouldBeFalse)
+			So(groupApplication.Status, ShouldEqual, GroupApplicationStatusApproved)
+			So(groupApplication.DeciderID, ShouldEqual, 3)
+			So(groupApplication.Comment, ShouldEqual, "Welcome")
+			So(groupApplication.DecidedAt, ShouldNotBeNil)
+		})
+
+		Convey("Denying should mark the application as denied", func() {
+			groupApplication.Decide(3, false, "")
+
+			So(groupApplication.Status, ShouldEqual, GroupApplicationStatusDenied)
+		})
+	})
+}
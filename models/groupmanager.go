@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ManagerActionType represents the kind of change a group manager performed
+type ManagerActionType string
+
+const (
+	// ManagerActionTypeAddMember indicates a user has been added to the managed group
+	ManagerActionTypeAddMember ManagerActionType = "addMember"
+	// ManagerActionTypeRemoveMember indicates a user has been removed from the managed group
+	ManagerActionTypeRemoveMember ManagerActionType = "removeMember"
+)
+
+// GroupManager represents the assignment of a user allowed to add and remove members of a group. Managers cannot modify the group itself or its roles
+type GroupManager struct {
+	// ID represents the database ID of the GroupManager
+	ID int64 `json:"id"`
+	// GroupID represents the database ID of the managed group
+	GroupID int64 `json:"groupID"`
+	// UserID represents the database ID of the user managing the group
+	UserID int64 `json:"userID"`
+}
+
+// ManagerAction represents a change to the members of a group performed by one of its managers
+type ManagerAction struct {
+	// ID represents the database ID of the ManagerAction
+	ID int64 `json:"id"`
+	// GroupID represents the database ID of the group the action was performed on
+	GroupID int64 `json:"groupID"`
+	// ActorID represents the database ID of the manager performing the action
+	ActorID int64 `json:"actorID"`
+	// UserID represents the database ID of the user added to or removed from the group
+	UserID int64 `json:"userID"`
+	// Type represents the kind of change performed
+	Type ManagerActionType `json:"type"`
+	// Timestamp represents the time the action was performed at
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewGroupManager creates a new group manager assignment with the given information
+func NewGroupManager(groupID int64, userID int64) *GroupManager {
+	groupManager := &GroupManager{
+		ID:      -1,
+		GroupID: groupID,
+		UserID:  userID,
+	}
+
+	return groupManager
+}
+
+// NewManagerAction creates a new manager action with the given information, performed right now
+func NewManagerAction(groupID int64, actorID int64, userID int64, actionType ManagerActionType) *ManagerAction {
+	managerAction := &ManagerAction{
+		ID:        -1,
+		GroupID:   groupID,
+		ActorID:   actorID,
+		UserID:    userID,
+		Type:      actionType,
+		Timestamp: time.Now(),
+	}
+
+	return managerAction
+}
+
+// String represents a JSON encoded representation of the group manager
+func (groupManager *GroupManager) String() string {
+	jsonContent, err := json.Marshal(groupManager)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
+
+// String represents a JSON encoded representation of the manager action
+func (managerAction *ManagerAction) String() string {
+	jsonContent, err := json.Marshal(managerAction)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
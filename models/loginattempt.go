@@ -38,3 +38,13 @@ func (loginAttempt *LoginAttempt) String() string {
 
 	return string(jsonContent)
 }
+
+// LoginAttemptSummary represents the number of login attempts made on a single day, aggregated when pruning old login attempts
+type LoginAttemptSummary struct {
+	// Day represents the UTC day the login attempts were made on, formatted as YYYY-MM-DD
+	Day string `json:"day"`
+	// Failed represents the number of failed login attempts
+	Failed int64 `json:"failed"`
+	// Successful represents the number of successful login attempts
+	Successful int64 `json:"successful"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MembershipRuleType represents the condition a MembershipRule checks users against
+type MembershipRuleType string
+
+const (
+	// MembershipRuleTypeCorporation matches users with an active character in the target corporation
+	MembershipRuleTypeCorporation MembershipRuleType = "corporation"
+	// MembershipRuleTypeAlliance matches users with an active character in a corporation of the target alliance
+	MembershipRuleTypeAlliance MembershipRuleType = "alliance"
+	// MembershipRuleTypeDefaultCorporation matches users whose default character is in the target corporation
+	MembershipRuleTypeDefaultCorporation MembershipRuleType = "defaultCorporation"
+)
+
+// MembershipRule represents a condition automatically adding matching users to a group. Users are added to the group as soon as
+// one of its rules matches and removed again once none of them do, memberships added manually are never affected
+type MembershipRule struct {
+	// ID represents the database ID of the MembershipRule
+	ID int64 `json:"id"`
+	// GroupID represents the database ID of the group users are added to
+	GroupID int64 `json:"groupID"`
+	// Type represents the condition checked by the MembershipRule
+	Type MembershipRuleType `json:"type"`
+	// TargetID represents the database ID of the corporation or alliance the condition refers to
+	TargetID int64 `json:"targetID"`
+}
+
+// NewMembershipRule creates a new membership rule with the given information
+func NewMembershipRule(groupID int64, ruleType MembershipRuleType, targetID int64) *MembershipRule {
+	membershipRule := &MembershipRule{
+		ID:       -1,
+		GroupID:  groupID,
+		Type:     ruleType,
+		TargetID: targetID,
+	}
+
+	return membershipRule
+}
+
+// ParseMembershipRuleType parses the given string as a membership rule type, returning an error if the type is unknown
+func ParseMembershipRuleType(ruleType string) (MembershipRuleType, error) {
+	switch MembershipRuleType(ruleType) {
+	case MembershipRuleTypeCorporation, MembershipRuleTypeAlliance, MembershipRuleTypeDefaultCorporation:
+		return MembershipRuleType(ruleType), nil
+	default:
+		return "", fmt.Errorf("Unknown membership rule type %q", ruleType)
+	}
+}
+
+// Matches checks whether the given user fulfils the condition of the membership rule. The alliance IDs of all known corporations
+// have to be provided, indexed by the corporation ID. All rule types only consider active characters of active accounts
+func (membershipRule *MembershipRule) Matches(user *User, allianceIDs map[int64]int64) bool {
+	for _, account := range user.Accounts {
+		if !account.Active {
+			continue
+		}
+
+		for _, character := range account.Characters {
+			if !character.Active {
+				continue
+			}
+
+			switch membershipRule.Type {
+			case MembershipRuleTypeDefaultCorporation:
+				if character.DefaultCharacter && character.CorporationID == membershipRule.TargetID {
+					return true
+				}
+			case MembershipRuleTypeCorporation:
+				if character.CorporationID == membershipRule.TargetID {
+					return true
+				}
+			case MembershipRuleTypeAlliance:
+				if allianceIDs[character.CorporationID] == membershipRule.TargetID {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// String represents a JSON encoded representation of the membership rule
+func (membershipRule *MembershipRule) String() string {
+	jsonContent, err := json.Marshal(membershipRule)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMembershipRule(t *testing.T) {
+	Convey("Matching users against membership rules", t, func() {
+		user := NewUser("test", "password", "test@example.com", true, true)
+
+		account := NewAccount(user.ID, 1, "a", 0, true)
+		account.Characters = append(account.Characters, NewCharacter(account.ID, 1, "Main", 1, true, true), NewCharacter(account.ID, 2, "Alt", 2, false, true), NewCharacter(account.ID, 3, "Retired", 3, false, false))
+		user.Accounts = append(user.Accounts, account)
+
+		allianceIDs := map[int64]int64{1: 10, 2: 20, 3: 30}
+
+		Convey("Corporation rules should match any active character", func() {
+			So(NewMembershipRule(1, MembershipRuleTypeCorporation, 1).Matches(user, allianceIDs), ShouldBeTrue)
+			So(NewMembershipRule(1, MembershipRuleTypeCorporation, 2).Matches(user, allianceIDs), ShouldBeTrue)
+			So(NewMembershipRule(1, MembershipRuleTypeCorporation, 3).Matches(user, allianceIDs), ShouldBeFalse)
+		})
+
+		Convey("Alliance rules should match the alliance of any active character's corporation", func() {
+			So(NewMembershipRule(1, MembershipRuleTypeAlliance, 20).Matches(user, allianceIDs), ShouldBeTrue)
+			So(NewMembershipRule(1, MembershipRuleTypeAlliance, 30).Matches(user, allianceIDs), ShouldBeFalse)
+			So(NewMembershipRule(1, MembershipRuleTypeAlliance, 20).Matches(user, nil), ShouldBeFalse)
+		})
+
+		Convey("Default corporation rules should only match the default character", func() {
+			So(NewMembershipRule(1, MembershipRuleTypeDefaultCorporation, 1).Matches(user, allianceIDs), ShouldBeTrue)
+			So(NewMembershipRule(1, MembershipRuleTypeDefaultCorporation, 2).Matches(user, allianceIDs), ShouldBeFalse)
+		})
+
+		Convey("Characters of inactive accounts should be ignored", func() {
+			account.Active = false
+
+			So(NewMembershipRule(1, MembershipRuleTypeCorporation, 2).Matches(user, allianceIDs), ShouldBeFalse)
+			So(NewMembershipRule(1, MembershipRuleTypeAlliance, 20).Matches(user, allianceIDs), ShouldBeFalse)
+			So(NewMembershipRule(1, MembershipRuleTypeDefaultCorporation, 1).Matches(user, allianceIDs), ShouldBeFalse)
+		})
+
+		Convey("Parsing unknown rule types should fail", func() {
+			ruleType, err := ParseMembershipRuleType("alliance")
+			So(err, ShouldBeNil)
+			So(ruleType, ShouldEqual, MembershipRuleTypeAlliance)
+
+			_, err = ParseMembershipRuleType("character")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
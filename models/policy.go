@@ -0,0 +1,173 @@
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/policy"
+)
+
+// PolicyAttributes describes the attributes available to role policies. Character, corporation and alliance attributes refer to the user's
+// default character, its corporation and the corporation's alliance, evaluating to their zero values if they do not exist.
+// Group attributes contain all groups the user is considered a member of at the time of the evaluation, time attributes use UTC
+var PolicyAttributes = policy.Schema{
+	"user.id":                      policy.TypeInt,
+	"user.username":                policy.TypeString,
+	"user.email":                   policy.TypeString,
+	"user.verifiedEmail":           policy.TypeBool,
+	"user.active":                  policy.TypeBool,
+	"user.groups":                  policy.TypeStringList,
+	"user.groupIDs":                policy.TypeIntList,
+	"character.id":                 policy.TypeInt,
+	"character.name":               policy.TypeString,
+	"character.eveCharacterID":     policy.TypeInt,
+	"character.active":             policy.TypeBool,
+	"corporation.id":               policy.TypeInt,
+	"corporation.name":             policy.TypeString,
+	"corporation.ticker":           policy.TypeString,
+	"corporation.eveCorporationID": policy.TypeInt,
+	"alliance.id":                  policy.TypeInt,
+	"alliance.name":                policy.TypeString,
+	"alliance.ticker":              policy.TypeString,
+	"alliance.eveAllianceID":       policy.TypeInt,
+	"time.hour":                    policy.TypeInt,
+	"time.minute":                  policy.TypeInt,
+	"time.weekday":                 policy.TypeString,
+	"time.unix":                    policy.TypeInt,
+}
+
+// compiledRolePolicies caches the role policies compiled by compileRolePolicy by their source, storing nil for policies failing to compile.
+// Policies are only changed by administrators, keeping the number of distinct sources small
+var compiledRolePolicies = struct {
+	sync.RWMutex
+	policies map[string]*policy.Policy
+}{
+	policies: make(map[string]*policy.Policy),
+}
+
+// ParseRolePolicy verifies the syntax and types of the given role policy, returning the trimmed policy or an error describing the first problem found.
+// An empty policy is valid and makes the role unconditional
+func ParseRolePolicy(rolePolicy string) (string, error) {
+	rolePolicy = strings.TrimSpace(rolePolicy)
+	if len(rolePolicy) == 0 {
+		return "", nil
+	}
+
+	_, err := policy.Compile(rolePolicy, PolicyAttributes)
+	if err != nil {
+		return "", err
+	}
+
+	return rolePolicy, nil
+}
+
+// compileRolePolicy compiles the given role policy against PolicyAttributes, only compiling each source once and returning the cached program afterwards.
+// Returns nil if the policy fails to compile
+func compileRolePolicy(rolePolicy string) *policy.Policy {
+	compiledRolePolicies.RLock()
+	compiled, ok := compiledRolePolicies.policies[rolePolicy]
+	compiledRolePolicies.RUnlock()
+
+	if ok {
+		return compiled
+	}
+
+	compiled, err := policy.Compile(rolePolicy, PolicyAttributes)
+	if err != nil {
+		compiled = nil
+	}
+
+	compiledRolePolicies.Lock()
+	compiledRolePolicies.policies[rolePolicy] = compiled
+	compiledRolePolicies.Unlock()
+
+	return compiled
+}
+
+// policyEnvironment provides the values of all PolicyAttributes for the given user at the given time
+func policyEnvironment(user *User, at time.Time) policy.Environment {
+	groupNames := make([]string, 0)
+	groupIDs := make([]int64, 0)
+
+	for _, membership := range effectiveGroupMemberships(user, at) {
+		groupNames = append(groupNames, membership.group.Name)
+		groupIDs = append(groupIDs, membership.group.ID)
+	}
+
+	character := &Character{}
+	if defaultCharacter := user.GetDefaultCharacter(); defaultCharacter != nil {
+		character = defaultCharacter
+	}
+
+	corporation := &Corporation{}
+	if user.DefaultCorporation != nil {
+		corporation = user.DefaultCorporation
+	}
+
+	alliance := &Alliance{}
+	if user.DefaultAlliance != nil {
+		alliance = user.DefaultAlliance
+	}
+
+	at = at.UTC()
+
+	environment := policy.Environment{
+		"user.id":                      policy.IntValue(user.ID),
+		"user.username":                policy.StringValue(user.Username),
+		"user.email":                   policy.StringValue(user.Email),
+		"user.verifiedEmail":           policy.BoolValue(user.VerifiedEmail),
+		"user.active":                  policy.BoolValue(user.Active),
+		"user.groups":                  policy.StringListValue(groupNames...),
+		"user.groupIDs":                policy.IntListValue(groupIDs...),
+		"character.id":                 policy.IntValue(character.ID),
+		"character.name":               policy.StringValue(character.Name),
+		"character.eveCharacterID":     policy.IntValue(character.EVECharacterID),
+		"character.active":             policy.BoolValue(character.Active),
+		"corporation.id":               policy.IntValue(corporation.ID),
+		"corporation.name":             policy.StringValue(corporation.Name),
+		"corporation.ticker":           policy.StringValue(corporation.Ticker),
+		"corporation.eveCorporationID": policy.IntValue(corporation.EVECorporationID),
+		"alliance.id":                  policy.IntValue(alliance.ID),
+		"alliance.name":                policy.StringValue(alliance.Name),
+		"alliance.ticker":              policy.StringValue(alliance.Ticker),
+		"alliance.eveAllianceID":       policy.IntValue(alliance.EVEAllianceID),
+		"time.hour":                    policy.IntValue(int64(at.Hour())),
+		"time.minute":                  policy.IntValue(int64(at.Minute())),
+		"time.weekday":                 policy.StringValue(strings.ToLower(at.Weekday().String())),
+		"time.unix":                    policy.IntValue(at.Unix()),
+	}
+
+	return environment
+}
+
+// policyEvaluator evaluates role policies for a single user at a single point in time, providing the attribute values only once they are needed
+type policyEvaluator struct {
+	user        *User
+	at          time.Time
+	environment policy.Environment
+}
+
+// holds checks whether the policy of the given role holds, unconditional roles always hold.
+// Policies failing to compile or evaluate never hold, denying the role rather than granting it by accident
+func (evaluator *policyEvaluator) holds(role *Role) bool {
+	if len(role.Policy) == 0 {
+		return true
+	}
+
+	compiled := compileRolePolicy(role.Policy)
+	if compiled == nil {
+		return false
+	}
+
+	if evaluator.environment == nil {
+		evaluator.environment = policyEnvironment(evaluator.user, evaluator.at)
+	}
+
+	result, err := compiled.Evaluate(evaluator.environment)
+	if err != nil {
+		return false
+	}
+
+	return result
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/policy"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRolePolicy(t *testing.T) {
+	Convey("Granting roles based on policies", t, func() {
+		fc := &Role{ID: 1, Name: "fc", Active: true, Policy: `corporation.ticker == "TEST" and user.verifiedEmail`}
+		seniorFC := &Role{ID: 2, Name: "fc.senior", Active: true, ImpliedRoles: []*Role{fc}}
+		pingAll := &Role{ID: 3, Name: "ping.all", Active: true, Policy: `"Pilots" in user.groups and time.weekday != "sunday"`}
+
+		pilots := &Group{ID: 1, Name: "Pilots", Active: true, GroupRoles: []*GroupRole{NewGroupRole(1, pingAll, false, true)}}
+
+		user := NewUser("test1", "", "test1@example.com", true, true)
+
+		account := NewAccount(user.ID, 1, "a", 0, true)
+		account.Characters = append(account.Characters, NewCharacter(account.ID, 1, "Main", 1, true, true))
+		user.Accounts = append(user.Accounts, account)
+		user.DefaultCorporation = &Corporation{ID: 1, Name: "Test Corp", Ticker: "TEST"}
+
+		Convey("Roles should only be granted while their policy holds", func() {
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, fc, false, true)}
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusGranted)
+			So(user.GetEffectiveRoles(), ShouldContainKey, fc.ID)
+
+			user.VerifiedEmail = false
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusDenied)
+			So(user.GetEffectiveRoles(), ShouldBeEmpty)
+
+			explanation := Explain(user, "fc")
+			So(explanation.Source, ShouldEqual, RoleSourcePolicy)
+			So(explanation.Reason(), ShouldContainSubstring, fc.Policy)
+			So(len(explanation.Evidence), ShouldEqual, 1)
+		})
+
+		Convey("Missing default corporations should evaluate to empty attributes", func() {
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, fc, false, true)}
+			user.DefaultCorporation = nil
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusDenied)
+		})
+
+		Convey("Implied roles should be subject to their own policy", func() {
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, seniorFC, false, true)}
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusGranted)
+
+			user.DefaultCorporation.Ticker = "OTHER"
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusDenied)
+			So(user.GetEffectiveRoles(), ShouldNotContainKey, fc.ID)
+		})
+
+		Convey("Roles denied by their policy should not imply other roles", func() {
+			seniorFC.Policy = "false"
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, seniorFC, false, true)}
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusDenied)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+		})
+
+		Convey("Implied roles denied by their policy should not imply further roles", func() {
+			commander := &Role{ID: 4, Name: "fc.commander", Active: true, ImpliedRoles: []*Role{seniorFC}}
+			seniorFC.Policy = "user.verifiedEmail"
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, commander, false, true)}
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusGranted)
+
+			user.VerifiedEmail = false
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusDenied)
+			So(Explain(user, "fc.senior").Source, ShouldEqual, RoleSourcePolicy)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+			So(user.GetEffectiveRoles(), ShouldResemble, map[int64]*Role{commander.ID: commander})
+		})
+
+		Convey("Policies should be evaluated against group memberships and the time of the evaluation", func() {
+			user.Groups = []*Group{pilots}
+
+			saturday := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+
+			So(explainAt(user, "ping.all", saturday).Status, ShouldEqual, RoleStatusGranted)
+			So(explainAt(user, "ping.all", saturday.AddDate(0, 0, 1)).Status, ShouldEqual, RoleStatusDenied)
+		})
+
+		Convey("Invalid policies should never grant a role", func() {
+			fc.Policy = "user.unknown"
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, fc, false, true)}
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusDenied)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusDenied)
+		})
+
+		Convey("Role policies should only be compiled once per source", func() {
+			compiled := compileRolePolicy("user.verifiedEmail and alliance.id == 1")
+			So(compiled, ShouldNotBeNil)
+			So(compileRolePolicy("user.verifiedEmail and alliance.id == 1"), ShouldEqual, compiled)
+
+			So(compileRolePolicy("user.unknown"), ShouldBeNil)
+			So(compileRolePolicy("user.unknown"), ShouldBeNil)
+		})
+
+		Convey("The environment should provide all policy attributes with their declared types", func() {
+			environment := policyEnvironment(user, time.Now())
+
+			So(len(environment), ShouldEqual, len(PolicyAttributes))
+
+			for name, attributeType := range PolicyAttributes {
+				So(environment, ShouldContainKey, name)
+				So(environment[name].Type, ShouldEqual, attributeType)
+			}
+
+			So(environment["character.name"], ShouldResemble, policy.StringValue("Main"))
+			So(environment["alliance.id"], ShouldResemble, policy.IntValue(0))
+		})
+
+		Convey("Parsing role policies should report syntax and type errors", func() {
+			rolePolicy, err := ParseRolePolicy("  user.verifiedEmail and alliance.id == 1 ")
+			So(err, ShouldBeNil)
+			So(rolePolicy, ShouldEqual, "user.verifiedEmail and alliance.id == 1")
+
+			rolePolicy, err = ParseRolePolicy(" ")
+			So(err, ShouldBeNil)
+			So(rolePolicy, ShouldBeEmpty)
+
+			_, err = ParseRolePolicy("user.verifiedEmail and")
+			So(err, ShouldNotBeNil)
+
+			_, err = ParseRolePolicy(`alliance.id == "1"`)
+			So(err, ShouldNotBeNil)
+
+			_, err = ParseRolePolicy("user.email")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,379 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RoleSource indicates which kind of assignment decided the status of a role
+type RoleSource int
+
+const (
+	// RoleSourceNone indicates that no assignment of the role was found
+	RoleSourceNone RoleSource = iota
+	// RoleSourceUserRole indicates that a user role decided the status of the role
+	RoleSourceUserRole
+	// RoleSourceGroupRole indicates that a group role decided the status of the role
+	RoleSourceGroupRole
+	// RoleSourceImplication indicates that the role was granted by being implied by another granted role
+	RoleSourceImplication
+	// RoleSourcePolicy indicates that the role was denied as its policy does not hold, even though it has been granted or implied
+	RoleSourcePolicy
+)
+
+// String returns an easily readable string representation of the role source
+func (roleSource RoleSource) String() string {
+	switch roleSource {
+	case RoleSourceNone:
+		return "none"
+	case RoleSourceUserRole:
+		return "user role"
+	case RoleSourceGroupRole:
+		return "group role"
+	case RoleSourceImplication:
+		return "implication"
+	case RoleSourcePolicy:
+		return "policy"
+	}
+
+	return "unknown"
+}
+
+// RoleEvidence represents a single user or group role directly assigning a role, considered while evaluating the role
+type RoleEvidence struct {
+	// Source indicates whether the assignment is a user or group role
+	Source RoleSource `json:"source"`
+	// GroupID represents the database ID of the group the group role belongs to, 0 for user roles
+	GroupID int64 `json:"groupID,omitempty"`
+	// GroupName represents the name of the group the group role belongs to, empty for user roles
+	GroupName string `json:"groupName,omitempty"`
+	// ViaGroupID represents the database ID of the group the user is a member of containing the group, 0 if the user is a direct member of the group
+	ViaGroupID int64 `json:"viaGroupID,omitempty"`
+	// ViaGroupName represents the name of the group the user is a member of containing the group, empty if the user is a direct member of the group
+	ViaGroupName string `json:"viaGroupName,omitempty"`
+	// Status indicates whether the assignment grants or denies the role
+	Status RoleStatus `json:"status"`
+}
+
+// RoleExplanation describes the status of a role for a user as well as the assignment the status was decided by
+type RoleExplanation struct {
+	// RoleName represents the name of the evaluated role
+	RoleName string `json:"roleName"`
+	// Role represents the evaluated role, nil if the role does not exist for the user
+	Role *Role `json:"role,omitempty"`
+	// Status represents the resulting status of the role
+	Status RoleStatus `json:"status"`
+	// Source indicates which kind of assignment decided the status
+	Source RoleSource `json:"source"`
+	// GroupID represents the database ID of the group whose group role decided the status, 0 for other sources
+	GroupID int64 `json:"groupID,omitempty"`
+	// GroupName represents the name of the group whose group role decided the status, empty for other sources
+	GroupName string `json:"groupName,omitempty"`
+	// ViaGroupID represents the database ID of the group containing the deciding group the user is a member of, 0 for direct memberships and other sources
+	ViaGroupID int64 `json:"viaGroupID,omitempty"`
+	// ViaGroupName represents the name of the group containing the deciding group the user is a member of, empty for direct memberships and other sources
+	ViaGroupName string `json:"viaGroupName,omitempty"`
+	// ImpliedBy represents the granted role implying the role if the status was decided by an implication
+	ImpliedBy *Role `json:"impliedBy,omitempty"`
+	// Evidence contains all user and group roles directly assigning the role, including the ones being overridden
+	Evidence []*RoleEvidence `json:"evidence"`
+}
+
+// Explain evaluates the role with the given name for the given user, returning the resulting status and the assignment it was decided by
+func Explain(user *User, role string) *RoleExplanation {
+	return explainAt(user, role, time.Now())
+}
+
+// explainAt evaluates the role with the given name for the given user at the given time
+func explainAt(user *User, role string, at time.Time) *RoleExplanation {
+	for _, explanation := range evaluateRoles(user, at) {
+		if explanation.Role.IsActiveRole(role) {
+			return explanation
+		}
+	}
+
+	return &RoleExplanation{
+		RoleName: role,
+		Status:   RoleStatusNonExistent,
+		Source:   RoleSourceNone,
+		Evidence: make([]*RoleEvidence, 0),
+	}
+}
+
+// Reason returns a readable description of why the role has been granted or denied
+func (explanation *RoleExplanation) Reason() string {
+	switch explanation.Source {
+	case RoleSourceUserRole:
+		if explanation.Status == RoleStatusGranted {
+			return "Granted by a user role, which overrides all group roles"
+		}
+
+		return "Denied by a user role, which overrides all group roles"
+	case RoleSourceGroupRole:
+		if explanation.Status == RoleStatusGranted {
+			return fmt.Sprintf("Granted by group %s, no user role or group denies it", describeGroup(explanation.GroupName, explanation.ViaGroupName))
+		}
+
+		return fmt.Sprintf("Denied by group %s, which takes precedence over groups granting it", describeGroup(explanation.GroupName, explanation.ViaGroupName))
+	case RoleSourceImplication:
+		return fmt.Sprintf("Granted as it is implied by the granted role %q", explanation.ImpliedBy.Name)
+	case RoleSourcePolicy:
+		return fmt.Sprintf("Denied as the policy of the role does not hold: %s", explanation.Role.Policy)
+	}
+
+	return "No user or group role assigns the role and no granted role implies it"
+}
+
+// describeGroup returns the quoted name of the given group, mentioning the containing group if the membership has been inherited
+func describeGroup(groupName string, viaGroupName string) string {
+	if len(viaGroupName) == 0 {
+		return fmt.Sprintf("%q", groupName)
+	}
+
+	return fmt.Sprintf("%q (inherited through membership in %q)", groupName, viaGroupName)
+}
+
+// String represents a JSON encoded representation of the role explanation
+func (explanation *RoleExplanation) String() string {
+	jsonContent, err := json.Marshal(explanation)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
+
+// evaluateRoles applies the precedence rules to all roles assigned to or implied for the given user at the given time, returning the explanations indexed by the role ID
+func evaluateRoles(user *User, at time.Time) map[int64]*RoleExplanation {
+	explanations := make(map[int64]*RoleExplanation)
+
+	for _, membership := range effectiveGroupMemberships(user, at) {
+		group := membership.group
+
+		var viaGroupID int64
+		var viaGroupName string
+
+		if membership.via != nil {
+			viaGroupID = membership.via.ID
+			viaGroupName = membership.via.Name
+		}
+
+		for _, groupRole := range group.GroupRoles {
+			if !groupRole.Role.Active {
+				continue
+			}
+
+			status := roleStatusFromGranted(groupRole.Granted)
+
+			explanation := explanationForRole(explanations, groupRole.Role)
+			explanation.Evidence = append(explanation.Evidence, &RoleEvidence{
+				Source:       RoleSourceGroupRole,
+				GroupID:      group.ID,
+				GroupName:    group.Name,
+				ViaGroupID:   viaGroupID,
+				ViaGroupName: viaGroupName,
+				Status:       status,
+			})
+
+			if explanation.Source == RoleSourceNone || (explanation.Status == RoleStatusGranted && status == RoleStatusDenied) {
+				explanation.Status = status
+				explanation.Source = RoleSourceGroupRole
+				explanation.GroupID = group.ID
+				explanation.GroupName = group.Name
+				explanation.ViaGroupID = viaGroupID
+				explanation.ViaGroupName = viaGroupName
+			}
+		}
+	}
+
+	for _, userRole := range user.UserRoles {
+		if !userRole.Role.Active || !userRole.Validity.IsValidAt(at) {
+			continue
+		}
+
+		status := roleStatusFromGranted(userRole.Granted)
+
+		explanation := explanationForRole(explanations, userRole.Role)
+		explanation.Evidence = append(explanation.Evidence, &RoleEvidence{
+			Source: RoleSourceUserRole,
+			Status: status,
+		})
+
+		explanation.Status = status
+		explanation.Source = RoleSourceUserRole
+		explanation.GroupID = 0
+		explanation.GroupName = ""
+		explanation.ViaGroupID = 0
+		explanation.ViaGroupName = ""
+	}
+
+	evaluator := &policyEvaluator{user: user, at: at}
+
+	for _, explanation := range explanations {
+		if explanation.Status == RoleStatusGranted && !evaluator.holds(explanation.Role) {
+			denyByPolicy(explanation)
+		}
+	}
+
+	var level []*Role
+
+	for _, explanation := range explanations {
+		if explanation.Status == RoleStatusGranted {
+			level = append(level, explanation.Role)
+		}
+	}
+
+	// Implications are expanded one level at a time, only following roles already granted so denied roles never grant the roles they imply
+	for len(level) > 0 {
+		sort.Sort(rolesByID(level))
+
+		var next []*Role
+
+		for _, role := range level {
+			for _, impliedRole := range role.ImpliedRoles {
+				if !impliedRole.Active {
+					continue
+				}
+
+				if _, ok := explanations[impliedRole.ID]; ok {
+					continue
+				}
+
+				explanation := &RoleExplanation{
+					RoleName:  impliedRole.Name,
+					Role:      impliedRole,
+					Status:    RoleStatusGranted,
+					Source:    RoleSourceImplication,
+					ImpliedBy: role,
+					Evidence:  make([]*RoleEvidence, 0),
+				}
+
+				explanations[impliedRole.ID] = explanation
+
+				if !evaluator.holds(impliedRole) {
+					denyByPolicy(explanation)
+					continue
+				}
+
+				next = append(next, impliedRole)
+			}
+		}
+
+		level = next
+	}
+
+	return explanations
+}
+
+// groupMembership represents a group the user is considered a member of, either directly or through a group containing it
+type groupMembership struct {
+	group *Group
+	// via represents the group the user is a direct member of containing the group, nil for direct memberships
+	via *Group
+}
+
+// effectiveGroupMemberships returns all active groups the given user is considered a member of at the given time, ordered by the group ID.
+// Groups nested in several groups of the user are reported for the containing group with the lowest ID, direct memberships take precedence over inherited ones
+func effectiveGroupMemberships(user *User, at time.Time) []*groupMembership {
+	var direct []*Group
+
+	for _, group := range user.Groups {
+		if group.Active && user.GroupValidity(group.ID).IsValidAt(at) {
+			direct = append(direct, group)
+		}
+	}
+
+	sort.Sort(groupsByID(direct))
+
+	memberships := make(map[int64]*groupMembership)
+
+	for _, group := range direct {
+		memberships[group.ID] = &groupMembership{group: group}
+	}
+
+	for _, group := range direct {
+		var subGroups []*Group
+
+		for _, subGroup := range group.SubGroupClosure() {
+			subGroups = append(subGroups, subGroup)
+		}
+
+		sort.Sort(groupsByID(subGroups))
+
+		for _, subGroup := range subGroups {
+			if _, ok := memberships[subGroup.ID]; !ok {
+				memberships[subGroup.ID] = &groupMembership{group: subGroup, via: group}
+			}
+		}
+	}
+
+	groups := make([]*Group, 0, len(memberships))
+
+	for _, membership := range memberships {
+		groups = append(groups, membership.group)
+	}
+
+	sort.Sort(groupsByID(groups))
+
+	result := make([]*groupMembership, 0, len(groups))
+
+	for _, group := range groups {
+		result = append(result, memberships[group.ID])
+	}
+
+	return result
+}
+
+// explanationForRole returns the explanation stored for the given role, adding an undecided one if none exists yet
+func explanationForRole(explanations map[int64]*RoleExplanation, role *Role) *RoleExplanation {
+	explanation, ok := explanations[role.ID]
+	if !ok {
+		explanation = &RoleExplanation{
+			RoleName: role.Name,
+			Role:     role,
+			Status:   RoleStatusNonExistent,
+			Source:   RoleSourceNone,
+			Evidence: make([]*RoleEvidence, 0),
+		}
+
+		explanations[role.ID] = explanation
+	}
+
+	return explanation
+}
+
+// denyByPolicy marks the role of the given explanation as denied by its policy, discarding the assignment or implication granting it
+func denyByPolicy(explanation *RoleExplanation) {
+	explanation.Status = RoleStatusDenied
+	explanation.Source = RoleSourcePolicy
+	explanation.GroupID = 0
+	explanation.GroupName = ""
+	explanation.ViaGroupID = 0
+	explanation.ViaGroupName = ""
+	explanation.ImpliedBy = nil
+}
+
+// roleStatusFromGranted converts the granted flag of a group or user role to the matching RoleStatus
+func roleStatusFromGranted(granted bool) RoleStatus {
+	if granted {
+		return RoleStatusGranted
+	}
+
+	return RoleStatusDenied
+}
+
+// groupsByID allows sorting of groups by their ID
+type groupsByID []*Group
+
+func (g groupsByID) Len() int           { return len(g) }
+func (g groupsByID) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g groupsByID) Less(i, j int) bool { return g[i].ID < g[j].ID }
+
+// rolesByID allows sorting of roles by their ID
+type rolesByID []*Role
+
+func (r rolesByID) Len() int           { return len(r) }
+func (r rolesByID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r rolesByID) Less(i, j int) bool { return r[i].ID < r[j].ID }
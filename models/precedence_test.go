@@ -0,0 +1,177 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRolePrecedence(t *testing.T) {
+	Convey("Evaluating the roles of a user", t, func() {
+		pingAll := &Role{ID: 1, Name: "ping.all", Active: true}
+		fc := &Role{ID: 2, Name: "fc", Active: true}
+		seniorFC := &Role{ID: 3, Name: "fc.senior", Active: true, ImpliedRoles: []*Role{fc}}
+		inactive := &Role{ID: 4, Name: "destroy.world", Active: false}
+
+		granting := &Group{ID: 2, Name: "Granting", Active: true, GroupRoles: []*GroupRole{NewGroupRole(2, pingAll, false, true)}}
+		denying := &Group{ID: 1, Name: "Denying", Active: true, GroupRoles: []*GroupRole{NewGroupRole(1, pingAll, false, false)}}
+
+		user := NewUser("test1", "", "test1@example.com", true, true)
+
+		Convey("A group deny should win over a group grant regardless of the group order", func() {
+			user.Groups = []*Group{granting, denying}
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusDenied)
+
+			user.Groups = []*Group{denying, granting}
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusDenied)
+			So(user.GetEffectiveRoles(), ShouldNotContainKey, pingAll.ID)
+
+			explanation := Explain(user, "ping.all")
+			So(explanation.Source, ShouldEqual, RoleSourceGroupRole)
+			So(explanation.GroupName, ShouldEqual, "Denying")
+			So(len(explanation.Evidence), ShouldEqual, 2)
+		})
+
+		Convey("A user role should override all group roles", func() {
+			user.Groups = []*Group{granting, denying}
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, pingAll, false, true)}
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusGranted)
+			So(user.GetEffectiveRoles(), ShouldContainKey, pingAll.ID)
+			So(Explain(user, "ping.all").Source, ShouldEqual, RoleSourceUserRole)
+
+			user.Groups = []*Group{granting}
+			user.UserRoles[0].Granted = false
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusDenied)
+			So(user.GetEffectiveRoles(), ShouldBeEmpty)
+		})
+
+		Convey("Inactive roles should be ignored", func() {
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, inactive, false, true)}
+
+			So(user.HasRole("destroy.world"), ShouldEqual, RoleStatusNonExistent)
+			So(user.GetEffectiveRoles(), ShouldBeEmpty)
+		})
+
+		Convey("Implied roles should be granted unless assigned explicitly", func() {
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, seniorFC, false, true)}
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusGranted)
+			So(user.GetEffectiveRoles(), ShouldContainKey, fc.ID)
+
+			explanation := Explain(user, "fc")
+			So(explanation.Source, ShouldEqual, RoleSourceImplication)
+			So(explanation.ImpliedBy.Name, ShouldEqual, "fc.senior")
+
+			user.Groups = []*Group{{ID: 3, Name: "No FCs", Active: true, GroupRoles: []*GroupRole{NewGroupRole(3, fc, false, false)}}}
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusDenied)
+			So(user.GetEffectiveRoles(), ShouldNotContainKey, fc.ID)
+		})
+
+		Convey("Implications should not be followed through denied roles", func() {
+			commander := &Role{ID: 5, Name: "fc.commander", Active: true, ImpliedRoles: []*Role{seniorFC}}
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, commander, false, true)}
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusGranted)
+			So(Explain(user, "fc").ImpliedBy.Name, ShouldEqual, "fc.senior")
+
+			user.Groups = []*Group{{ID: 3, Name: "No senior FCs", Active: true, GroupRoles: []*GroupRole{NewGroupRole(3, seniorFC, false, false)}}}
+
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusDenied)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+			So(user.GetEffectiveRoles(), ShouldResemble, map[int64]*Role{commander.ID: commander})
+
+			user.Groups = nil
+			user.UserRoles = append(user.UserRoles, NewUserRole(user.ID, seniorFC, false, false))
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+		})
+
+		Convey("Expired or not yet valid user roles and group memberships should be ignored", func() {
+			now := time.Now()
+
+			user.Groups = []*Group{granting}
+			user.GroupValidities[granting.ID] = NewValidity(now.Add(-2*time.Hour), time.Hour)
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusNonExistent)
+			So(explainAt(user, "ping.all", now.Add(-90*time.Minute)).Status, ShouldEqual, RoleStatusGranted)
+
+			user.UserRoles = []*UserRole{NewUserRole(user.ID, seniorFC, false, true)}
+			user.UserRoles[0].Validity = NewValidity(now.Add(time.Hour), 0)
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+			So(user.GetEffectiveRoles(), ShouldBeEmpty)
+			So(explainAt(user, "fc", now.Add(2*time.Hour)).Source, ShouldEqual, RoleSourceImplication)
+		})
+
+		Convey("Members of a group should inherit the roles of all groups nested in it", func() {
+			capitals := &Group{ID: 5, Name: "Capitals", Active: true, GroupRoles: []*GroupRole{NewGroupRole(5, fc, false, true)}}
+			supers := &Group{ID: 6, Name: "Supers", Active: true, SubGroups: []*Group{capitals}, GroupRoles: []*GroupRole{NewGroupRole(6, seniorFC, false, true)}}
+			titans := &Group{ID: 7, Name: "Titans", Active: true, SubGroups: []*Group{supers, granting}}
+
+			granting.Active = false
+			user.Groups = []*Group{titans}
+
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc.senior"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusNonExistent)
+			So(user.GetEffectiveRoles(), ShouldContainKey, fc.ID)
+
+			explanation := Explain(user, "fc")
+			So(explanation.Source, ShouldEqual, RoleSourceGroupRole)
+			So(explanation.GroupName, ShouldEqual, "Capitals")
+			So(explanation.ViaGroupName, ShouldEqual, "Titans")
+			So(explanation.Reason(), ShouldContainSubstring, "Titans")
+
+			granting.Active = true
+			user.Groups = []*Group{titans, capitals}
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusGranted)
+			So(Explain(user, "fc").ViaGroupID, ShouldEqual, 0)
+
+			user.Groups = []*Group{titans, denying}
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusDenied)
+		})
+
+		Convey("Inactive groups should be ignored whether joined directly or through a group containing them", func() {
+			capitals := &Group{ID: 5, Name: "Capitals", Active: true, GroupRoles: []*GroupRole{NewGroupRole(5, fc, false, true)}}
+			denying.SubGroups = []*Group{capitals}
+			denying.Active = false
+
+			user.Groups = []*Group{denying, granting}
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+
+			titans := &Group{ID: 7, Name: "Titans", Active: true, SubGroups: []*Group{denying}}
+			user.Groups = []*Group{titans, granting}
+
+			So(user.HasRole("ping.all"), ShouldEqual, RoleStatusGranted)
+			So(user.HasRole("fc"), ShouldEqual, RoleStatusNonExistent)
+		})
+
+		Convey("Sub group closures should ignore cycles and inactive groups", func() {
+			first := &Group{ID: 8, Name: "First", Active: true}
+			second := &Group{ID: 9, Name: "Second", Active: true, SubGroups: []*Group{first}}
+			third := &Group{ID: 10, Name: "Third", Active: false, SubGroups: []*Group{first}}
+			first.SubGroups = []*Group{second, third}
+
+			closure := first.SubGroupClosure()
+			So(len(closure), ShouldEqual, 1)
+			So(closure, ShouldContainKey, second.ID)
+		})
+
+		Convey("Roles without any assignment should not exist", func() {
+			explanation := Explain(user, "ping.all")
+			So(explanation.Status, ShouldEqual, RoleStatusNonExistent)
+			So(explanation.Source, ShouldEqual, RoleSourceNone)
+			So(explanation.RoleName, ShouldEqual, "ping.all")
+			So(explanation.Reason(), ShouldNotBeEmpty)
+		})
+	})
+}
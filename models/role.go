@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"strings"
+
+	"gopkg.in/guregu/null.v2/zero"
 )
 
 // Role represents a permission/role that can be assigned to a user. Applications can require certain roles for access
@@ -15,6 +17,24 @@ type Role struct {
 	Active bool `json:"active"`
 	// Locked indicates whether the Role is locked in database and cannot be delete
 	Locked bool `json:"locked"`
+	// Version represents the revision of the Role, incremented every time it is modified
+	Version int64 `json:"version"`
+	// ApplicationID represents the database ID of the application whose namespace the Role belongs to, invalid for global roles
+	ApplicationID zero.Int `json:"applicationID"`
+	// Policy represents the grant condition of the Role written in the policy language, the Role is only granted while it holds. Empty for unconditional roles
+	Policy string `json:"policy"`
+	// ImpliedRoles represents the roles directly implied by the Role, which are granted alongside it
+	ImpliedRoles []*Role `json:"impliedRoles,omitempty"`
+}
+
+// RoleImplication represents a single edge of the role implication graph, stating that a role implies another one
+type RoleImplication struct {
+	// ID represents the database ID of the RoleImplication
+	ID int64 `json:"id"`
+	// RoleID represents the database ID of the implying role
+	RoleID int64 `json:"roleID"`
+	// ImpliedRoleID represents the database ID of the role being implied
+	ImpliedRoleID int64 `json:"impliedRoleID"`
 }
 
 // GroupRole represents a role assigned to a Group. Group permissions affect all people within the group
@@ -43,6 +63,8 @@ type UserRole struct {
 	AutoAdded bool `json:"autoAdded"`
 	// Granted indicates whether the UserRole is currently set as granted
 	Granted bool `json:"granted"`
+	// Validity represents the time window the UserRole is valid in, nil if it is valid indefinitely
+	Validity *Validity `json:"validity,omitempty"`
 }
 
 // NewRole creates a new role with the given information
@@ -88,6 +110,37 @@ func (role *Role) IsActiveRole(r string) bool {
 	return role.Active && strings.EqualFold(role.Name, r)
 }
 
+// IsGlobal checks whether the role belongs to no application namespace
+func (role *Role) IsGlobal() bool {
+	return !role.ApplicationID.Valid
+}
+
+// ImpliedRoleClosure returns all active roles implied by the current role, directly or through other implied roles, indexed by the role ID.
+// The role itself is not part of the result
+func (role *Role) ImpliedRoleClosure() map[int64]*Role {
+	closure := make(map[int64]*Role)
+
+	pending := append([]*Role{}, role.ImpliedRoles...)
+
+	for len(pending) > 0 {
+		implied := pending[0]
+		pending = pending[1:]
+
+		if implied.ID == role.ID || !implied.Active {
+			continue
+		}
+
+		if _, ok := closure[implied.ID]; ok {
+			continue
+		}
+
+		closure[implied.ID] = implied
+		pending = append(pending, implied.ImpliedRoles...)
+	}
+
+	return closure
+}
+
 // IsRole checks whether the current GroupRole has the same role name and returns the appropriate RoleStatus
 func (groupRole *GroupRole) IsRole(r string) RoleStatus {
 	if !groupRole.Role.IsActiveRole(r) {
@@ -124,6 +177,16 @@ func (role *Role) String() string {
 	return string(jsonContent)
 }
 
+// String represents a JSON encoded representation of the role implication
+func (roleImplication *RoleImplication) String() string {
+	jsonContent, err := json.Marshal(roleImplication)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
+
 // String represents a JSON encoded representation of the group role
 func (groupRole *GroupRole) String() string {
 	jsonContent, err := json.Marshal(groupRole)
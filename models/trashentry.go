@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TrashEntryType represents the type of entity a TrashEntry refers to
+type TrashEntryType string
+
+const (
+	// TrashEntryTypeUser marks a deleted user
+	TrashEntryTypeUser TrashEntryType = "user"
+	// TrashEntryTypeGroup marks a deleted group
+	TrashEntryTypeGroup TrashEntryType = "group"
+	// TrashEntryTypeRole marks a deleted role
+	TrashEntryTypeRole TrashEntryType = "role"
+	// TrashEntryTypeApplication marks a deleted application
+	TrashEntryTypeApplication TrashEntryType = "application"
+)
+
+// TrashEntry represents a deleted entity kept in the trash until it is restored or purged permanently
+type TrashEntry struct {
+	// Type represents the type of the deleted entity
+	Type TrashEntryType `json:"type"`
+	// ID represents the database ID of the deleted entity
+	ID int64 `json:"id"`
+	// Name represents the name (or username) of the deleted entity
+	Name string `json:"name"`
+	// DeletedAt represents the time the entity was deleted at
+	DeletedAt time.Time `json:"deletedAt"`
+	// DeletedBy represents the database ID of the user who deleted the entity
+	DeletedBy int64 `json:"deletedBy"`
+}
+
+// String represents a JSON encoded representation of the trash entry
+func (trashEntry *TrashEntry) String() string {
+	jsonContent, err := json.Marshal(trashEntry)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // User represents an user within the authentication system
@@ -18,12 +19,22 @@ type User struct {
 	VerifiedEmail bool `json:"verifiedEmail"`
 	// Active indicates whether the User is set as active
 	Active bool `json:"active"`
+	// Version represents the revision of the User, incremented every time it is modified
+	Version int64 `json:"version"`
 	// Accounts contains all accounts associated with the User
 	Accounts []*Account `json:"accounts,omitempty"`
 	// UserRoles contains all UserRoles associated with the User
 	UserRoles []*UserRole `json:"userRoles,omitempty"`
 	// Groups contains all Groups associated with the User
 	Groups []*Group `json:"groups,omitempty"`
+	// GroupValidities contains the validity windows of time-limited group memberships, indexed by the group ID
+	GroupValidities map[int64]*Validity `json:"groupValidities,omitempty"`
+	// AutoAddedGroups marks the group memberships added by membership rules instead of manually, indexed by the group ID
+	AutoAddedGroups map[int64]bool `json:"autoAddedGroups,omitempty"`
+	// DefaultCorporation represents the corporation of the User's default character, nil if the User has no default character or its corporation is unknown
+	DefaultCorporation *Corporation `json:"defaultCorporation,omitempty"`
+	// DefaultAlliance represents the alliance of the User's default corporation, nil if the corporation is not in an alliance or the alliance is unknown
+	DefaultAlliance *Alliance `json:"defaultAlliance,omitempty"`
 }
 
 // AuthUser represents a user used by the authorization handler to pass required information to apps
@@ -36,46 +47,42 @@ type AuthUser struct {
 	Roles []string `json:"roles,omitempty"`
 	// Characters contains all AuthCharacters for the User
 	Characters []*AuthCharacter `json:"characters,omitempty"`
+	// Alliances contains all alliances the User's characters are in
+	Alliances []*AuthAlliance `json:"alliances,omitempty"`
 }
 
 // NewUser creates a new user with the given information
 func NewUser(username string, password string, email string, verified bool, active bool) *User {
 	user := &User{
-		ID:            -1,
-		Username:      username,
-		Password:      password,
-		Email:         email,
-		VerifiedEmail: verified,
-		Active:        active,
-		Accounts:      make([]*Account, 0),
-		UserRoles:     make([]*UserRole, 0),
-		Groups:        make([]*Group, 0),
+		ID:              -1,
+		Username:        username,
+		Password:        password,
+		Email:           email,
+		VerifiedEmail:   verified,
+		Active:          active,
+		Accounts:        make([]*Account, 0),
+		UserRoles:       make([]*UserRole, 0),
+		Groups:          make([]*Group, 0),
+		GroupValidities: make(map[int64]*Validity),
+		AutoAddedGroups: make(map[int64]bool),
 	}
 
 	return user
 }
 
-// HasRole returns the RoleStatus for the provided role name
+// HasRole returns the RoleStatus for the provided role name, following the role precedence rules described in the package documentation
 func (user *User) HasRole(role string) RoleStatus {
-	roleStatus := RoleStatusNonExistent
-
-	for _, group := range user.Groups {
-		status := group.HasRole(role)
-		if status != RoleStatusNonExistent {
-			roleStatus = status
-			break
-		}
-	}
+	return Explain(user, role).Status
+}
 
-	for _, userRole := range user.UserRoles {
-		status := userRole.IsRole(role)
-		if status != RoleStatusNonExistent {
-			roleStatus = status
-			break
-		}
-	}
+// GroupValidity returns the validity window of the user's membership in the group with the given ID, nil if the membership is not time-limited
+func (user *User) GroupValidity(groupID int64) *Validity {
+	return user.GroupValidities[groupID]
+}
 
-	return roleStatus
+// IsGroupAutoAdded checks whether the user's membership in the group with the given ID has been added by a membership rule
+func (user *User) IsGroupAutoAdded(groupID int64) bool {
+	return user.AutoAddedGroups[groupID]
 }
 
 // GetCharacterCount returns the number of characters associated with the current user
@@ -123,26 +130,13 @@ func (user *User) GetDefaultCharacter() *Character {
 	return nil
 }
 
-// GetEffectiveRoles returns a map of roles as defined via granted/denied group and user roles, index by the role ID
+// GetEffectiveRoles returns a map of all granted roles, following the role precedence rules described in the package documentation, index by the role ID
 func (user *User) GetEffectiveRoles() map[int64]*Role {
 	roles := make(map[int64]*Role)
 
-	for _, group := range user.Groups {
-		for _, groupRole := range group.GroupRoles {
-			if groupRole.Granted {
-				roles[groupRole.Role.ID] = groupRole.Role
-			}
-		}
-	}
-
-	for _, userRole := range user.UserRoles {
-		if userRole.Granted {
-			roles[userRole.Role.ID] = userRole.Role
-		} else {
-			_, ok := roles[userRole.Role.ID]
-			if ok {
-				delete(roles, userRole.Role.ID)
-			}
+	for roleID, explanation := range evaluateRoles(user, time.Now()) {
+		if explanation.Status == RoleStatusGranted {
+			roles[roleID] = explanation.Role
 		}
 	}
 
@@ -151,6 +145,16 @@ func (user *User) GetEffectiveRoles() map[int64]*Role {
 
 // ToAuthUser converts the given iser to an AuthUser, exporting only the information required by third-party apps
 func (user *User) ToAuthUser() *AuthUser {
+	return user.toAuthUser(nil)
+}
+
+// ToAuthUserForApplication converts the given user to an AuthUser for the given app, only exporting the roles the app may see
+func (user *User) ToAuthUserForApplication(application *Application) *AuthUser {
+	return user.toAuthUser(application)
+}
+
+// toAuthUser converts the given user to an AuthUser, filtering the exported roles for the given app unless it is nil
+func (user *User) toAuthUser(application *Application) *AuthUser {
 	authUser := &AuthUser{
 		ID:         user.ID,
 		Username:   user.Username,
@@ -161,6 +165,10 @@ func (user *User) ToAuthUser() *AuthUser {
 	effectiveRoles := user.GetEffectiveRoles()
 
 	for _, role := range effectiveRoles {
+		if application != nil && !application.CanSeeRole(role) {
+			continue
+		}
+
 		authUser.Roles = append(authUser.Roles, role.Name)
 	}
 
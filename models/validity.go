@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Validity represents the optional time window a user role or group membership is valid in
+type Validity struct {
+	// ValidFrom represents the time the assignment starts being valid at, nil if it is valid immediately
+	ValidFrom *time.Time `json:"validFrom,omitempty"`
+	// ValidUntil represents the time the assignment expires at, nil if it never expires
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+}
+
+// ExpiredGrantType represents the kind of assignment an ExpiredGrant refers to
+type ExpiredGrantType string
+
+const (
+	// ExpiredGrantTypeUserRole marks an expired user role
+	ExpiredGrantTypeUserRole ExpiredGrantType = "user role"
+	// ExpiredGrantTypeGroupMembership marks an expired group membership
+	ExpiredGrantTypeGroupMembership ExpiredGrantType = "group membership"
+)
+
+// ExpiredGrant represents a user role or group membership removed after it expired
+type ExpiredGrant struct {
+	// Type represents the kind of the expired assignment
+	Type ExpiredGrantType `json:"type"`
+	// UserID represents the database ID of the user the assignment belonged to
+	UserID int64 `json:"userID"`
+	// TargetID represents the database ID of the role (for user roles) or group (for group memberships) assigned
+	TargetID int64 `json:"targetID"`
+	// ValidUntil represents the time the assignment expired at
+	ValidUntil time.Time `json:"validUntil"`
+}
+
+// NewValidity creates a new validity window starting at the given time and lasting for the given duration. A zero start time makes the
+// assignment valid immediately, a non-positive duration lets it never expire
+func NewValidity(validFrom time.Time, duration time.Duration) *Validity {
+	validity := &Validity{}
+
+	if !validFrom.IsZero() {
+		from := validFrom
+		validity.ValidFrom = &from
+	}
+
+	if duration > 0 {
+		if validFrom.IsZero() {
+			validFrom = time.Now()
+		}
+
+		until := validFrom.Add(duration)
+		validity.ValidUntil = &until
+	}
+
+	return validity
+}
+
+// IsLimited checks whether the validity window has a start or expiry time set
+func (validity *Validity) IsLimited() bool {
+	return validity != nil && (validity.ValidFrom != nil || validity.ValidUntil != nil)
+}
+
+// IsValidAt checks whether the validity window includes the given time. A nil validity is always valid
+func (validity *Validity) IsValidAt(t time.Time) bool {
+	if validity == nil {
+		return true
+	}
+
+	if validity.ValidFrom != nil && t.Before(*validity.ValidFrom) {
+		return false
+	}
+
+	return !validity.IsExpiredAt(t)
+}
+
+// IsExpiredAt checks whether the validity window has ended at the given time
+func (validity *Validity) IsExpiredAt(t time.Time) bool {
+	return validity != nil && validity.ValidUntil != nil && !t.Before(*validity.ValidUntil)
+}
+
+// String returns an easily readable representation of the validity window
+func (validity *Validity) String() string {
+	if !validity.IsLimited() {
+		return "unlimited"
+	}
+
+	from := "now"
+	if validity.ValidFrom != nil {
+		from = validity.ValidFrom.Format("2006-01-02 15:04")
+	}
+
+	until := "forever"
+	if validity.ValidUntil != nil {
+		until = validity.ValidUntil.Format("2006-01-02 15:04")
+	}
+
+	return fmt.Sprintf("%s until %s", from, until)
+}
+
+// String represents a JSON encoded representation of the expired grant
+func (expiredGrant *ExpiredGrant) String() string {
+	jsonContent, err := json.Marshal(expiredGrant)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
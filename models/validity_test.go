@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidity(t *testing.T) {
+	Convey("Creating a new validity window", t, func() {
+		start := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+		Convey("A nil validity should always be valid", func() {
+			var validity *Validity
+
+			So(validity.IsLimited(), ShouldBeFalse)
+			So(validity.IsValidAt(start), ShouldBeTrue)
+			So(validity.IsExpiredAt(start), ShouldBeFalse)
+			So(validity.String(), ShouldEqual, "unlimited")
+		})
+
+		Convey("A window without duration should never expire", func() {
+			validity := NewValidity(start, 0)
+
+			So(validity.IsLimited(), ShouldBeTrue)
+			So(validity.ValidUntil, ShouldBeNil)
+			So(validity.IsValidAt(start.Add(-time.Second)), ShouldBeFalse)
+			So(validity.IsValidAt(start), ShouldBeTrue)
+			So(validity.IsValidAt(start.AddDate(10, 0, 0)), ShouldBeTrue)
+		})
+
+		Convey("A window with duration should expire at its end", func() {
+			validity := NewValidity(start, 24*time.Hour)
+
+			So(validity.IsValidAt(start.Add(23*time.Hour)), ShouldBeTrue)
+			So(validity.IsValidAt(start.Add(24*time.Hour)), ShouldBeFalse)
+			So(validity.IsExpiredAt(start.Add(24*time.Hour)), ShouldBeTrue)
+			So(validity.String(), ShouldEqual, "2016-03-01 12:00 until 2016-03-02 12:00")
+		})
+
+		Convey("A window without start time should start immediately", func() {
+			validity := NewValidity(time.Time{}, time.Hour)
+
+			So(validity.ValidFrom, ShouldBeNil)
+			So(validity.IsValidAt(time.Now()), ShouldBeTrue)
+			So(validity.IsExpiredAt(time.Now().Add(2*time.Hour)), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package policy
+
+// check determines the type of the given node, returning an error describing the first type error found.
+// Attributes are resolved using the given schema
+func check(n node, schema Schema) (Type, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value.Type, nil
+	case *attributeNode:
+		t, ok := schema[n.name]
+		if !ok {
+			return TypeInvalid, newError(n.offset, "Unknown attribute %q", n.name)
+		}
+
+		return t, nil
+	case *listNode:
+		if len(n.elements) == 0 {
+			return TypeInvalid, newError(n.offset, "Empty list")
+		}
+
+		var elementType Type
+
+		for _, element := range n.elements {
+			t, err := check(element, schema)
+			if err != nil {
+				return TypeInvalid, err
+			}
+
+			if listOf(t) == TypeInvalid {
+				return TypeInvalid, newError(element.position(), "Lists may only contain integers or strings, found %s", t)
+			} else if elementType != TypeInvalid && t != elementType {
+				return TypeInvalid, newError(element.position(), "List elements must be of the same type, found %s in a list of %s", t, elementType)
+			}
+
+			elementType = t
+		}
+
+		return listOf(elementType), nil
+	case *notNode:
+		t, err := check(n.operand, schema)
+		if err != nil {
+			return TypeInvalid, err
+		}
+
+		if t != TypeBool {
+			return TypeInvalid, newError(n.offset, "Operator \"not\" expects a bool, found %s", t)
+		}
+
+		return TypeBool, nil
+	case *binaryNode:
+		left, err := check(n.left, schema)
+		if err != nil {
+			return TypeInvalid, err
+		}
+
+		right, err := check(n.right, schema)
+		if err != nil {
+			return TypeInvalid, err
+		}
+
+		switch n.operator {
+		case tokenAnd, tokenOr:
+			if left != TypeBool || right != TypeBool {
+				return TypeInvalid, newError(n.offset, "Operator %q expects bools, found %s and %s", n.symbol(), left, right)
+			}
+		case tokenEqual, tokenNotEqual:
+			if left != right || left == TypeIntList || left == TypeStringList {
+				return TypeInvalid, newError(n.offset, "Operator %q cannot compare %s with %s", n.symbol(), left, right)
+			}
+		case tokenLess, tokenLessEqual, tokenGreater, tokenGreaterEqual:
+			if left != TypeInt || right != TypeInt {
+				return TypeInvalid, newError(n.offset, "Operator %q expects ints, found %s and %s", n.symbol(), left, right)
+			}
+		case tokenIn:
+			if listOf(left) == TypeInvalid || listOf(left) != right {
+				return TypeInvalid, newError(n.offset, "Operator %q cannot search %s in %s", n.symbol(), left, right)
+			}
+		}
+
+		return TypeBool, nil
+	}
+
+	return TypeInvalid, newError(n.position(), "Unsupported expression")
+}
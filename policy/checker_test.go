@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+	Convey("Type checking policies", t, func() {
+		schema := Schema{
+			"user.verified": TypeBool,
+			"user.id":       TypeInt,
+			"user.name":     TypeString,
+			"user.groups":   TypeStringList,
+			"user.groupIDs": TypeIntList,
+		}
+
+		Convey("Well-typed policies should be accepted", func() {
+			valid := []string{
+				"true",
+				"user.verified",
+				"not user.verified",
+				"user.verified == false",
+				"user.id == 1 and user.name != \"test\"",
+				"user.id >= 1 or user.id < -1",
+				"user.id in [1, 2, user.id]",
+				`"fc" in user.groups or "admins" not in user.groups`,
+				"3 in user.groupIDs",
+				`user.name in ["a", user.name]`,
+			}
+
+			for _, source := range valid {
+				_, err := Compile(source, schema)
+				So(err, ShouldBeNil)
+			}
+		})
+
+		Convey("Type errors should be reported with their position", func() {
+			invalid := map[string]string{
+				"user.id":                        `Policy must evaluate to a bool, found int at position 1`,
+				`["a"]`:                          `Policy must evaluate to a bool, found list of string at position 1`,
+				"user.unknown":                   `Unknown attribute "user.unknown" at position 1`,
+				"user.verified and user.missing": `Unknown attribute "user.missing" at position 19`,
+				"not user.id":                    `Operator "not" expects a bool, found int at position 1`,
+				"user.verified or 1":             `Operator "or" expects bools, found bool and int at position 15`,
+				"user.id == \"1\"":               `Operator "==" cannot compare int with string at position 9`,
+				"user.groups == user.groups":     `Operator "==" cannot compare list of string with list of string at position 13`,
+				"user.name < \"b\"":              `Operator "<" expects ints, found string and string at position 11`,
+				"user.id in user.groups":         `Operator "in" cannot search int in list of string at position 9`,
+				"user.groups not in user.groups": `Operator "not in" cannot search list of string in list of string at position 13`,
+				"user.verified in [true]":        `Lists may only contain integers or strings, found bool at position 19`,
+				"user.id in [1, \"2\"]":          `List elements must be of the same type, found string in a list of int at position 16`,
+				"user.id in []":                  `Empty list at position 12`,
+				"user.id in [[1]]":               `Lists may only contain integers or strings, found list of int at position 13`,
+			}
+
+			for source, message := range invalid {
+				_, err := Compile(source, schema)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, message)
+			}
+		})
+
+		Convey("Checking should stop at syntax errors", func() {
+			_, err := Compile("user.verified and", schema)
+			So(err, ShouldNotBeNil)
+			So(err.(*Error).Position, ShouldEqual, 17)
+		})
+	})
+}
@@ -0,0 +1,21 @@
+// Package policy provides a small expression language used to express attribute-based access rules, for example granting a role only
+// while the user's default character is in a certain corporation and the user has verified their email address.
+//
+// A policy is a boolean expression following this grammar:
+//
+//	expression = or
+//	or         = and { "or" and }
+//	and        = not { "and" not }
+//	not        = "not" not | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" | "not" "in" ) operand ]
+//	operand    = integer | string | "true" | "false" | attribute | list | "(" expression ")"
+//	list       = "[" [ operand { "," operand } ] "]"
+//	attribute  = identifier { "." identifier }
+//
+// Integers are written in decimal notation and may be negative, strings are enclosed in double quotes and support the escape sequences
+// of Go string literals. Keywords are case-sensitive, comparisons cannot be chained without parentheses.
+//
+// Policies are type checked against a Schema describing the available attributes before being evaluated against an Environment providing
+// their values. Equality operators compare values of the same type, ordering operators compare integers and the in operator checks whether
+// an integer or string is contained in a list of the same type.
+package policy
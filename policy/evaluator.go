@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"fmt"
+)
+
+// evaluate computes the value of the given node using the attribute values of the given environment.
+// Logical operators short-circuit, so attributes on the skipped side are not required to be available
+func evaluate(n node, environment Environment) (Value, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+	case *attributeNode:
+		value, ok := environment[n.name]
+		if !ok {
+			return Value{}, fmt.Errorf("Attribute %q is not available", n.name)
+		}
+
+		return value, nil
+	case *listNode:
+		elements := make([]Value, len(n.elements))
+
+		for index, element := range n.elements {
+			value, err := evaluate(element, environment)
+			if err != nil {
+				return Value{}, err
+			}
+
+			elements[index] = value
+		}
+
+		return listValue(elements)
+	case *notNode:
+		operand, err := evaluateBool(n.operand, environment)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return BoolValue(!operand), nil
+	case *binaryNode:
+		if n.operator == tokenAnd || n.operator == tokenOr {
+			left, err := evaluateBool(n.left, environment)
+			if err != nil {
+				return Value{}, err
+			}
+
+			if (n.operator == tokenAnd && !left) || (n.operator == tokenOr && left) {
+				return BoolValue(left), nil
+			}
+
+			right, err := evaluateBool(n.right, environment)
+			if err != nil {
+				return Value{}, err
+			}
+
+			return BoolValue(right), nil
+		}
+
+		left, err := evaluate(n.left, environment)
+		if err != nil {
+			return Value{}, err
+		}
+
+		right, err := evaluate(n.right, environment)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return compare(n, left, right)
+	}
+
+	return Value{}, fmt.Errorf("Unsupported expression at position %d", n.position()+1)
+}
+
+// evaluateBool computes the value of the given node, returning an error if it does not evaluate to a bool
+func evaluateBool(n node, environment Environment) (bool, error) {
+	value, err := evaluate(n, environment)
+	if err != nil {
+		return false, err
+	}
+
+	if value.Type != TypeBool {
+		return false, fmt.Errorf("Expected bool at position %d, got %s", n.position()+1, value.Type)
+	}
+
+	return value.Bool, nil
+}
+
+// compare applies the comparison operator of the given node to the given operands
+func compare(n *binaryNode, left Value, right Value) (Value, error) {
+	switch n.operator {
+	case tokenEqual, tokenNotEqual:
+		equal, err := left.equals(right)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return BoolValue(equal == (n.operator == tokenEqual)), nil
+	case tokenLess, tokenLessEqual, tokenGreater, tokenGreaterEqual:
+		if left.Type != TypeInt || right.Type != TypeInt {
+			return Value{}, fmt.Errorf("Operator %q expects ints, got %s and %s", n.symbol(), left.Type, right.Type)
+		}
+
+		switch n.operator {
+		case tokenLess:
+			return BoolValue(left.Int < right.Int), nil
+		case tokenLessEqual:
+			return BoolValue(left.Int <= right.Int), nil
+		case tokenGreater:
+			return BoolValue(left.Int > right.Int), nil
+		}
+
+		return BoolValue(left.Int >= right.Int), nil
+	case tokenIn:
+		contained, err := right.contains(left)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return BoolValue(contained != n.negated), nil
+	}
+
+	return Value{}, fmt.Errorf("Unsupported operator %q", n.symbol())
+}
+
+// listValue combines the given values into a list value, returning an error if they are not all integers or all strings
+func listValue(elements []Value) (Value, error) {
+	if len(elements) == 0 {
+		return Value{}, fmt.Errorf("Empty list")
+	}
+
+	list := Value{Type: listOf(elements[0].Type)}
+	if list.Type == TypeInvalid {
+		return Value{}, fmt.Errorf("Lists may only contain integers or strings, got %s", elements[0].Type)
+	}
+
+	for _, element := range elements {
+		if listOf(element.Type) != list.Type {
+			return Value{}, fmt.Errorf("List elements must be of the same type, got %s in a %s", element.Type, list.Type)
+		}
+
+		if list.Type == TypeIntList {
+			list.Ints = append(list.Ints, element.Int)
+		} else {
+			list.Strings = append(list.Strings, element.String)
+		}
+	}
+
+	return list, nil
+}
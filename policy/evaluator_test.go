@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEvaluate(t *testing.T) {
+	Convey("Evaluating policies", t, func() {
+		schema := Schema{
+			"user.verified": TypeBool,
+			"user.id":       TypeInt,
+			"user.name":     TypeString,
+			"user.groups":   TypeStringList,
+			"user.groupIDs": TypeIntList,
+		}
+
+		environment := Environment{
+			"user.verified": BoolValue(true),
+			"user.id":       IntValue(5),
+			"user.name":     StringValue("test"),
+			"user.groups":   StringListValue("fc", "pilots"),
+			"user.groupIDs": IntListValue(1, 2),
+		}
+
+		Convey("Policies should evaluate to the expected results", func() {
+			expected := map[string]bool{
+				"true":                                   true,
+				"not true":                               false,
+				"user.verified":                          true,
+				"user.id == 5":                           true,
+				"user.id != 5":                           false,
+				"user.id < 5":                            false,
+				"user.id <= 5":                           true,
+				"user.id > -5":                           true,
+				"user.id >= 6":                           false,
+				`user.name == "test"`:                    true,
+				`user.name == "Test"`:                    false,
+				`"fc" in user.groups`:                    true,
+				`"admins" in user.groups`:                false,
+				`"admins" not in user.groups`:            true,
+				"2 in user.groupIDs":                     true,
+				"user.id in [1, 5]":                      true,
+				"user.id not in [1, 5]":                  false,
+				"user.verified and user.id == 5":         true,
+				"user.verified and user.id == 6":         false,
+				"not user.verified or user.id == 5":      true,
+				"not (user.verified or user.id == 5)":    false,
+				"false and true or true":                 true,
+				"false and (true or true)":               false,
+				`user.verified == ("fc" in user.groups)`: true,
+			}
+
+			for source, result := range expected {
+				policy, err := Compile(source, schema)
+				So(err, ShouldBeNil)
+
+				holds, err := policy.Evaluate(environment)
+				So(err, ShouldBeNil)
+				So(holds, ShouldEqual, result)
+			}
+		})
+
+		Convey("Logical operators should short-circuit", func() {
+			delete(environment, "user.name")
+
+			policy, err := Compile(`user.verified or user.name == "test"`, schema)
+			So(err, ShouldBeNil)
+
+			holds, err := policy.Evaluate(environment)
+			So(err, ShouldBeNil)
+			So(holds, ShouldBeTrue)
+
+			policy, err = Compile(`not user.verified and user.name == "test"`, schema)
+			So(err, ShouldBeNil)
+
+			holds, err = policy.Evaluate(environment)
+			So(err, ShouldBeNil)
+			So(holds, ShouldBeFalse)
+		})
+
+		Convey("Missing or mistyped attributes should fail the evaluation", func() {
+			policy, err := Compile(`user.name == "test"`, schema)
+			So(err, ShouldBeNil)
+
+			delete(environment, "user.name")
+
+			_, err = policy.Evaluate(environment)
+			So(err, ShouldNotBeNil)
+
+			environment["user.name"] = IntValue(1)
+
+			_, err = policy.Evaluate(environment)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Unchecked policies should be evaluated defensively", func() {
+			policy, err := Parse("user.id")
+			So(err, ShouldBeNil)
+
+			_, err = policy.Evaluate(environment)
+			So(err, ShouldNotBeNil)
+
+			policy, err = Parse("user.id in []")
+			So(err, ShouldBeNil)
+
+			_, err = policy.Evaluate(environment)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,215 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind indicates the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenAttribute
+	tokenInt
+	tokenString
+	tokenTrue
+	tokenFalse
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenIn
+	tokenEqual
+	tokenNotEqual
+	tokenLess
+	tokenLessEqual
+	tokenGreater
+	tokenGreaterEqual
+	tokenLeftParen
+	tokenRightParen
+	tokenLeftBracket
+	tokenRightBracket
+	tokenComma
+)
+
+// keywords maps the reserved words of the language to their token kinds
+var keywords = map[string]tokenKind{
+	"true":  tokenTrue,
+	"false": tokenFalse,
+	"and":   tokenAnd,
+	"or":    tokenOr,
+	"not":   tokenNot,
+	"in":    tokenIn,
+}
+
+// operators maps the operator and punctuation symbols of the language to their token kinds, longer symbols have to be matched first
+var operators = []struct {
+	symbol string
+	kind   tokenKind
+}{
+	{"==", tokenEqual},
+	{"!=", tokenNotEqual},
+	{"<=", tokenLessEqual},
+	{">=", tokenGreaterEqual},
+	{"<", tokenLess},
+	{">", tokenGreater},
+	{"(", tokenLeftParen},
+	{")", tokenRightParen},
+	{"[", tokenLeftBracket},
+	{"]", tokenRightBracket},
+	{",", tokenComma},
+}
+
+// token represents a single lexical token of a policy
+type token struct {
+	kind tokenKind
+	// text represents the source text of the token
+	text string
+	// position represents the byte offset of the token in the source
+	position int
+	// value represents the value of integer and string literals
+	value Value
+}
+
+// describe returns a readable description of the token used in error messages
+func (t token) describe() string {
+	if t.kind == tokenEOF {
+		return "end of policy"
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Error represents a syntax or type error found in a policy
+type Error struct {
+	// Position represents the byte offset in the source the error was found at
+	Position int
+	// Message describes the error
+	Message string
+}
+
+// Error returns a readable representation of the error, including its (one-based) position
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", err.Message, err.Position+1)
+}
+
+// newError creates a new policy error at the given position
+func newError(position int, format string, args ...interface{}) *Error {
+	return &Error{
+		Position: position,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// lex splits the given source into tokens, always ending with an EOF token
+func lex(source string) ([]token, error) {
+	var tokens []token
+
+	position := 0
+
+	for position < len(source) {
+		c := source[position]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			position++
+		case isIdentifierStart(c):
+			end := position
+			for end < len(source) && (isIdentifierPart(source[end]) || source[end] == '.') {
+				end++
+			}
+
+			text := source[position:end]
+
+			if kind, ok := keywords[text]; ok {
+				tokens = append(tokens, token{kind: kind, text: text, position: position})
+			} else {
+				for _, segment := range strings.Split(text, ".") {
+					if len(segment) == 0 || !isIdentifierStart(segment[0]) {
+						return nil, newError(position, "Invalid attribute name %q", text)
+					}
+				}
+
+				tokens = append(tokens, token{kind: tokenAttribute, text: text, position: position})
+			}
+
+			position = end
+		case isDigit(c) || (c == '-' && position+1 < len(source) && isDigit(source[position+1])):
+			end := position + 1
+			for end < len(source) && isDigit(source[end]) {
+				end++
+			}
+
+			text := source[position:end]
+
+			if end < len(source) && isIdentifierPart(source[end]) {
+				return nil, newError(position, "Invalid integer %q", source[position:end+1])
+			}
+
+			value, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				return nil, newError(position, "Integer %s out of range", text)
+			}
+
+			tokens = append(tokens, token{kind: tokenInt, text: text, position: position, value: IntValue(value)})
+			position = end
+		case c == '"':
+			end := position + 1
+			for end < len(source) && source[end] != '"' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+
+			if end >= len(source) {
+				return nil, newError(position, "Unterminated string")
+			}
+
+			text := source[position : end+1]
+
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, newError(position, "Invalid string %s", text)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: text, position: position, value: StringValue(value)})
+			position = end + 1
+		default:
+			matched := false
+
+			for _, operator := range operators {
+				if strings.HasPrefix(source[position:], operator.symbol) {
+					tokens = append(tokens, token{kind: operator.kind, text: operator.symbol, position: position})
+					position += len(operator.symbol)
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				return nil, newError(position, "Unexpected character %q", c)
+			}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, position: len(source)})
+
+	return tokens, nil
+}
+
+// isIdentifierStart checks whether the given character may start an identifier
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentifierPart checks whether the given character may be part of an identifier
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || isDigit(c)
+}
+
+// isDigit checks whether the given character is a decimal digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
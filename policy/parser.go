@@ -0,0 +1,336 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+)
+
+// node represents a single node of the syntax tree of a policy
+type node interface {
+	// position returns the byte offset of the node in the source
+	position() int
+	// String returns the canonical, fully parenthesised representation of the node
+	String() string
+}
+
+// literalNode represents an integer, string or boolean literal
+type literalNode struct {
+	offset int
+	value  Value
+}
+
+// attributeNode represents a reference to an attribute
+type attributeNode struct {
+	offset int
+	name   string
+}
+
+// listNode represents a list of operands
+type listNode struct {
+	offset   int
+	elements []node
+}
+
+// notNode represents the negation of an expression
+type notNode struct {
+	offset  int
+	operand node
+}
+
+// binaryNode represents a logical operation or comparison of two operands
+type binaryNode struct {
+	offset   int
+	operator tokenKind
+	// negated indicates whether the result of the operation is negated, used for the "not in" operator
+	negated bool
+	left    node
+	right   node
+}
+
+func (n *literalNode) position() int   { return n.offset }
+func (n *attributeNode) position() int { return n.offset }
+func (n *listNode) position() int      { return n.offset }
+func (n *notNode) position() int       { return n.offset }
+func (n *binaryNode) position() int    { return n.offset }
+
+// String returns the canonical representation of the literal
+func (n *literalNode) String() string {
+	switch n.value.Type {
+	case TypeBool:
+		return strconv.FormatBool(n.value.Bool)
+	case TypeInt:
+		return strconv.FormatInt(n.value.Int, 10)
+	}
+
+	return strconv.Quote(n.value.String)
+}
+
+// String returns the name of the referenced attribute
+func (n *attributeNode) String() string {
+	return n.name
+}
+
+// String returns the canonical representation of the list
+func (n *listNode) String() string {
+	elements := make([]string, len(n.elements))
+
+	for index, element := range n.elements {
+		elements[index] = element.String()
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// String returns the canonical representation of the negation
+func (n *notNode) String() string {
+	return "(not " + n.operand.String() + ")"
+}
+
+// String returns the canonical representation of the operation
+func (n *binaryNode) String() string {
+	return "(" + n.left.String() + " " + n.symbol() + " " + n.right.String() + ")"
+}
+
+// symbol returns the source representation of the operator
+func (n *binaryNode) symbol() string {
+	switch n.operator {
+	case tokenAnd:
+		return "and"
+	case tokenOr:
+		return "or"
+	case tokenEqual:
+		return "=="
+	case tokenNotEqual:
+		return "!="
+	case tokenLess:
+		return "<"
+	case tokenLessEqual:
+		return "<="
+	case tokenGreater:
+		return ">"
+	case tokenGreaterEqual:
+		return ">="
+	case tokenIn:
+		if n.negated {
+			return "not in"
+		}
+
+		return "in"
+	}
+
+	return "?"
+}
+
+// comparisonOperators contains the token kinds of all comparison operators
+var comparisonOperators = map[tokenKind]bool{
+	tokenEqual:        true,
+	tokenNotEqual:     true,
+	tokenLess:         true,
+	tokenLessEqual:    true,
+	tokenGreater:      true,
+	tokenGreaterEqual: true,
+	tokenIn:           true,
+}
+
+// parser implements a recursive descent parser for the policy grammar described in the package documentation
+type parser struct {
+	tokens []token
+	index  int
+}
+
+// parse parses the given source into its syntax tree, returning an error describing the first syntax error found
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	if p.peek().kind == tokenEOF {
+		return nil, newError(p.peek().position, "Empty policy")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		if comparisonOperators[next.kind] {
+			return nil, newError(next.position, "Comparisons cannot be chained, unexpected %s", next.describe())
+		}
+
+		return nil, newError(next.position, "Unexpected %s", next.describe())
+	}
+
+	return root, nil
+}
+
+// peek returns the current token without consuming it
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+// next consumes and returns the current token
+func (p *parser) next() token {
+	t := p.tokens[p.index]
+
+	if t.kind != tokenEOF {
+		p.index++
+	}
+
+	return t
+}
+
+// expect consumes the current token if it is of the given kind, returning an error otherwise
+func (p *parser) expect(kind tokenKind, description string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, newError(t.position, "Expected %s, found %s", description, t.describe())
+	}
+
+	return t, nil
+}
+
+// parseOr parses a sequence of expressions joined by the or operator
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		operator := p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{offset: operator.position, operator: tokenOr, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses a sequence of expressions joined by the and operator
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		operator := p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{offset: operator.position, operator: tokenAnd, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseNot parses an optionally negated comparison
+func (p *parser) parseNot() (node, error) {
+	if p.peek().kind == tokenNot {
+		operator := p.next()
+
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{offset: operator.position, operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+// parseComparison parses an operand, optionally compared to a second operand
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := p.peek()
+	negated := false
+
+	if operator.kind == tokenNot && p.tokens[p.index+1].kind == tokenIn {
+		p.next()
+		negated = true
+	} else if !comparisonOperators[operator.kind] {
+		return left, nil
+	}
+
+	kind := p.next().kind
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return &binaryNode{offset: operator.position, operator: kind, negated: negated, left: left, right: right}, nil
+}
+
+// parseOperand parses a literal, attribute reference, list or parenthesised expression
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenInt, tokenString:
+		return &literalNode{offset: t.position, value: t.value}, nil
+	case tokenTrue, tokenFalse:
+		return &literalNode{offset: t.position, value: BoolValue(t.kind == tokenTrue)}, nil
+	case tokenAttribute:
+		return &attributeNode{offset: t.position, name: t.text}, nil
+	case tokenLeftBracket:
+		return p.parseList(t)
+	case tokenLeftParen:
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(tokenRightParen, "\")\"")
+		if err != nil {
+			return nil, err
+		}
+
+		return expression, nil
+	}
+
+	return nil, newError(t.position, "Expected operand, found %s", t.describe())
+}
+
+// parseList parses the elements of a list following the given opening bracket
+func (p *parser) parseList(open token) (node, error) {
+	list := &listNode{offset: open.position}
+
+	if p.peek().kind == tokenRightBracket {
+		p.next()
+
+		return list, nil
+	}
+
+	for {
+		element, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		list.elements = append(list.elements, element)
+
+		t := p.next()
+		if t.kind == tokenRightBracket {
+			return list, nil
+		} else if t.kind != tokenComma {
+			return nil, newError(t.position, "Expected \",\" or \"]\", found %s", t.describe())
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Parsing policies", t, func() {
+		Convey("Literals and attributes should be parsed as operands", func() {
+			valid := map[string]string{
+				"true":                      "true",
+				"false":                     "false",
+				"42":                        "42",
+				"-7":                        "-7",
+				`"Some Corp"`:               `"Some Corp"`,
+				`"quote \" and \\ escaped"`: `"quote \" and \\ escaped"`,
+				"user.verifiedEmail":        "user.verifiedEmail",
+				"corporation.eve_id2":       "corporation.eve_id2",
+				"[1, 2, 3]":                 "[1, 2, 3]",
+				`["a","b"]`:                 `["a", "b"]`,
+				"[]":                        "[]",
+				"((true))":                  "true",
+			}
+
+			for source, expected := range valid {
+				policy, err := Parse(source)
+				So(err, ShouldBeNil)
+				So(policy.String(), ShouldEqual, expected)
+				So(policy.Source(), ShouldEqual, source)
+			}
+		})
+
+		Convey("Comparisons should bind tighter than logical operators", func() {
+			valid := map[string]string{
+				"a == 1":                       "(a == 1)",
+				"a != 1":                       "(a != 1)",
+				"a < 1 and b <= 2":             "((a < 1) and (b <= 2))",
+				"a > 1 or b >= 2":              "((a > 1) or (b >= 2))",
+				`a in ["x", "y"]`:              `(a in ["x", "y"])`,
+				"a not in b":                   "(a not in b)",
+				"not a in b":                   "(not (a in b))",
+				"not a not in b":               "(not (a not in b))",
+				"(a==1)and(b)":                 "((a == 1) and b)",
+				"a == -1":                      "(a == -1)",
+				"user.groups == user.groupIDs": "(user.groups == user.groupIDs)",
+			}
+
+			for source, expected := range valid {
+				policy, err := Parse(source)
+				So(err, ShouldBeNil)
+				So(policy.String(), ShouldEqual, expected)
+			}
+		})
+
+		Convey("Logical operators should follow their precedence and associate to the left", func() {
+			valid := map[string]string{
+				"a or b and c":        "(a or (b and c))",
+				"a and b or c":        "((a and b) or c)",
+				"a and b and c":       "((a and b) and c)",
+				"a or b or c":         "((a or b) or c)",
+				"not a and b":         "((not a) and b)",
+				"not not a":           "(not (not a))",
+				"not (a or b)":        "(not (a or b))",
+				"(a or b) and c":      "((a or b) and c)",
+				"a and (b or not c)":  "(a and (b or (not c)))",
+				"\ta\n\tor\r\n  b\t ": "(a or b)",
+			}
+
+			for source, expected := range valid {
+				policy, err := Parse(source)
+				So(err, ShouldBeNil)
+				So(policy.String(), ShouldEqual, expected)
+			}
+		})
+
+		Convey("Keywords should be case-sensitive", func() {
+			policy, err := Parse("AND")
+			So(err, ShouldBeNil)
+			So(policy.String(), ShouldEqual, "AND")
+
+			_, err = Parse("a AND b")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Invalid syntax should be reported with its position", func() {
+			invalid := map[string]int{
+				"":                     1,
+				"   ":                  4,
+				"a and":                6,
+				"or a":                 1,
+				"a b":                  3,
+				"(a":                   3,
+				"a)":                   2,
+				"[1, 2":                6,
+				"[1 2]":                4,
+				"[1,]":                 4,
+				"a == 1 == 2":          8,
+				"a < b < c":            7,
+				"a not b":              3,
+				"a = 1":                3,
+				"a & b":                3,
+				"!a":                   1,
+				`"unterminated`:        1,
+				`"bad \q escape"`:      1,
+				"user..id":             1,
+				"user.":                1,
+				"user.1":               1,
+				"12abc":                1,
+				"99999999999999999999": 1,
+			}
+
+			for source, position := range invalid {
+				_, err := Parse(source)
+				So(err, ShouldNotBeNil)
+				So(err, ShouldHaveSameTypeAs, &Error{})
+				So(err.(*Error).Position+1, ShouldEqual, position)
+			}
+		})
+
+		Convey("Errors should describe the problem and its position", func() {
+			_, err := Parse("a == 1 == 2")
+			So(err.Error(), ShouldEqual, `Comparisons cannot be chained, unexpected "==" at position 8`)
+
+			_, err = Parse("a and")
+			So(err.Error(), ShouldEqual, "Expected operand, found end of policy at position 6")
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"fmt"
+)
+
+// Policy represents a parsed policy expression
+type Policy struct {
+	source string
+	root   node
+}
+
+// Parse parses the given source, returning an error describing the first syntax error found
+func Parse(source string) (*Policy, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		source: source,
+		root:   root,
+	}
+
+	return policy, nil
+}
+
+// Compile parses the given source and type checks it against the given schema, returning an error describing the first syntax or type error found
+func Compile(source string, schema Schema) (*Policy, error) {
+	policy, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Check(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// Check verifies the policy only references attributes of the given schema, applies all operators to operands of matching types and evaluates to a bool
+func (policy *Policy) Check(schema Schema) error {
+	t, err := check(policy.root, schema)
+	if err != nil {
+		return err
+	}
+
+	if t != TypeBool {
+		return newError(policy.root.position(), "Policy must evaluate to a bool, found %s", t)
+	}
+
+	return nil
+}
+
+// Evaluate evaluates the policy using the attribute values of the given environment, returning an error if an attribute is not available or has an unexpected type
+func (policy *Policy) Evaluate(environment Environment) (bool, error) {
+	value, err := evaluate(policy.root, environment)
+	if err != nil {
+		return false, err
+	}
+
+	if value.Type != TypeBool {
+		return false, fmt.Errorf("Policy evaluated to %s instead of a bool", value.Type)
+	}
+
+	return value.Bool, nil
+}
+
+// Source returns the source the policy has been parsed from
+func (policy *Policy) Source() string {
+	return policy.source
+}
+
+// String returns the canonical, fully parenthesised representation of the policy
+func (policy *Policy) String() string {
+	return policy.root.String()
+}
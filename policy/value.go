@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"fmt"
+)
+
+// Type represents the type of an attribute or expression
+type Type int
+
+const (
+	// TypeInvalid represents an unknown or invalid type
+	TypeInvalid Type = iota
+	// TypeBool represents boolean values
+	TypeBool
+	// TypeInt represents integer values
+	TypeInt
+	// TypeString represents string values
+	TypeString
+	// TypeIntList represents lists of integers
+	TypeIntList
+	// TypeStringList represents lists of strings
+	TypeStringList
+)
+
+// String returns an easily readable string representation of the type
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeString:
+		return "string"
+	case TypeIntList:
+		return "list of int"
+	case TypeStringList:
+		return "list of string"
+	}
+
+	return "invalid"
+}
+
+// listOf returns the list type containing elements of the given type, TypeInvalid if no such list type exists
+func listOf(t Type) Type {
+	switch t {
+	case TypeInt:
+		return TypeIntList
+	case TypeString:
+		return TypeStringList
+	}
+
+	return TypeInvalid
+}
+
+// Value represents the value of an attribute or evaluated expression, only the field matching its type is set
+type Value struct {
+	// Type represents the type of the Value
+	Type Type
+	// Bool represents the value of boolean Values
+	Bool bool
+	// Int represents the value of integer Values
+	Int int64
+	// String represents the value of string Values
+	String string
+	// Ints contains the elements of integer list Values
+	Ints []int64
+	// Strings contains the elements of string list Values
+	Strings []string
+}
+
+// BoolValue creates a new boolean value
+func BoolValue(value bool) Value {
+	return Value{Type: TypeBool, Bool: value}
+}
+
+// IntValue creates a new integer value
+func IntValue(value int64) Value {
+	return Value{Type: TypeInt, Int: value}
+}
+
+// StringValue creates a new string value
+func StringValue(value string) Value {
+	return Value{Type: TypeString, String: value}
+}
+
+// IntListValue creates a new list value containing the given integers
+func IntListValue(values ...int64) Value {
+	return Value{Type: TypeIntList, Ints: values}
+}
+
+// StringListValue creates a new list value containing the given strings
+func StringListValue(values ...string) Value {
+	return Value{Type: TypeStringList, Strings: values}
+}
+
+// equals checks whether the value equals the given value of the same scalar type
+func (value Value) equals(other Value) (bool, error) {
+	if value.Type != other.Type {
+		return false, fmt.Errorf("Cannot compare %s with %s", value.Type, other.Type)
+	}
+
+	switch value.Type {
+	case TypeBool:
+		return value.Bool == other.Bool, nil
+	case TypeInt:
+		return value.Int == other.Int, nil
+	case TypeString:
+		return value.String == other.String, nil
+	}
+
+	return false, fmt.Errorf("Cannot compare values of type %s", value.Type)
+}
+
+// contains checks whether the list value contains the given element
+func (value Value) contains(element Value) (bool, error) {
+	if listOf(element.Type) != value.Type {
+		return false, fmt.Errorf("Cannot search %s in %s", element.Type, value.Type)
+	}
+
+	if value.Type == TypeIntList {
+		for _, entry := range value.Ints {
+			if entry == element.Int {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	for _, entry := range value.Strings {
+		if entry == element.String {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Schema describes the attributes available to policies, mapping the attribute names to their types
+type Schema map[string]Type
+
+// Environment provides the values of the attributes policies are evaluated against, indexed by the attribute names
+type Environment map[string]Value
@@ -0,0 +1,2 @@
+// Package retention provides periodic pruning of entries which have been kept for longer than their configured retention period, keeping track of the latest results so they can be reported by the web app.
+package retention
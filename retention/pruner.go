@@ -0,0 +1,171 @@
+package retention
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/misc"
+)
+
+// DefaultInterval represents the interval at which all tasks are run if no interval has been configured
+const DefaultInterval = time.Hour
+
+// PruneFunc removes all entries created before the given time, returning the number of removed entries or an error if pruning failed
+type PruneFunc func(ctx context.Context, before time.Time) (int64, error)
+
+// Status represents the result of the latest run of a single task
+type Status struct {
+	// Name represents the name the task has been registered with
+	Name string `json:"name"`
+	// Retention represents the time entries are kept for before being pruned, 0 for sweeps removing expired entries immediately
+	Retention time.Duration `json:"retention"`
+	// LastRun represents the time the latest run was started at, zero if the task has not been run yet
+	LastRun time.Time `json:"lastRun"`
+	// Removed represents the number of entries removed by the latest run
+	Removed int64 `json:"removed"`
+	// TotalRemoved represents the number of entries removed by all runs since the pruner has been created
+	TotalRemoved int64 `json:"totalRemoved"`
+	// Error represents the error returned by the latest run, empty if the run succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// RetentionDays returns the retention period of the task in full days
+func (status *Status) RetentionDays() int64 {
+	return int64(status.Retention / (24 * time.Hour))
+}
+
+// task stores a registered task as well as its latest status
+type task struct {
+	fn     PruneFunc
+	status *Status
+}
+
+// Pruner runs all registered tasks periodically or on request, storing their latest results
+type Pruner struct {
+	interval time.Duration
+
+	lock  sync.RWMutex
+	tasks []*task
+}
+
+// NewPruner creates a new pruner running its tasks every interval (or DefaultInterval if not positive) if run
+func NewPruner(interval time.Duration) *Pruner {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	pruner := &Pruner{
+		interval: interval,
+		tasks:    make([]*task, 0),
+	}
+
+	return pruner
+}
+
+// Register adds a task with the given name, removing all entries older than the given retention period every time the pruner runs.
+// Tasks without a positive retention period are ignored, keeping their entries forever
+func (pruner *Pruner) Register(name string, retention time.Duration, fn PruneFunc) {
+	if retention <= 0 {
+		return
+	}
+
+	pruner.add(name, retention, fn)
+}
+
+// RegisterSweep adds a task with the given name, removing all entries which have expired by the time the pruner runs
+func (pruner *Pruner) RegisterSweep(name string, fn PruneFunc) {
+	pruner.add(name, 0, fn)
+}
+
+// add appends a task with the given name and retention period to the registered tasks
+func (pruner *Pruner) add(name string, retention time.Duration, fn PruneFunc) {
+	pruner.lock.Lock()
+	defer pruner.lock.Unlock()
+
+	pruner.tasks = append(pruner.tasks, &task{
+		fn: fn,
+		status: &Status{
+			Name:      name,
+			Retention: retention,
+		},
+	})
+}
+
+// PruneAll runs all registered tasks once and stores their results, returning the new status of every task
+func (pruner *Pruner) PruneAll(ctx context.Context) []*Status {
+	pruner.lock.RLock()
+	tasks := make([]*task, len(pruner.tasks))
+	copy(tasks, pruner.tasks)
+	pruner.lock.RUnlock()
+
+	statuses := make([]*Status, len(tasks))
+
+	for i, t := range tasks {
+		start := time.Now()
+
+		removed, err := t.fn(ctx, start.Add(-t.status.Retention))
+		if err != nil {
+			misc.Logger.Errorf("Failed to prune %s: [%v]", t.status.Name, err)
+		} else if removed > 0 {
+			misc.Logger.Infof("Pruned %d entries from %s", removed, t.status.Name)
+		}
+
+		pruner.lock.Lock()
+		t.status.LastRun = start
+		t.status.Removed = removed
+		t.status.TotalRemoved += removed
+		t.status.Error = ""
+
+		if err != nil {
+			t.status.Error = err.Error()
+		}
+
+		status := *t.status
+		pruner.lock.Unlock()
+
+		statuses[i] = &status
+	}
+
+	return statuses
+}
+
+// Statuses returns the latest status of every registered task
+func (pruner *Pruner) Statuses() []*Status {
+	pruner.lock.RLock()
+	defer pruner.lock.RUnlock()
+
+	statuses := make([]*Status, len(pruner.tasks))
+
+	for i, t := range pruner.tasks {
+		status := *t.status
+		statuses[i] = &status
+	}
+
+	return statuses
+}
+
+// Run runs all tasks immediately and then once every interval until the given context is done. Run returns immediately if no tasks have been registered
+func (pruner *Pruner) Run(ctx context.Context) {
+	pruner.lock.RLock()
+	empty := len(pruner.tasks) == 0
+	pruner.lock.RUnlock()
+
+	if empty {
+		return
+	}
+
+	pruner.PruneAll(ctx)
+
+	ticker := time.NewTicker(pruner.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruner.PruneAll(ctx)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morpheusxaut/eveauth/misc"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPruner(t *testing.T) {
+	misc.SetupLogger(9)
+
+	ctx := context.Background()
+
+	Convey("Pruning registered tasks", t, func() {
+		var failure error
+		var cutoff time.Time
+
+		pruner := NewPruner(0)
+		pruner.Register("entries", 48*time.Hour, func(ctx context.Context, before time.Time) (int64, error) {
+			cutoff = before
+			return 3, nil
+		})
+		pruner.Register("failing", time.Hour, func(ctx context.Context, before time.Time) (int64, error) {
+			return 0, failure
+		})
+		pruner.Register("disabled", 0, func(ctx context.Context, before time.Time) (int64, error) {
+			return 1, nil
+		})
+
+		Convey("Tasks without a retention period should be ignored", func() {
+			statuses := pruner.Statuses()
+			So(len(statuses), ShouldEqual, 2)
+			So(statuses[0].Name, ShouldEqual, "entries")
+			So(statuses[0].RetentionDays(), ShouldEqual, 2)
+			So(statuses[0].LastRun.IsZero(), ShouldBeTrue)
+		})
+
+		Convey("All tasks should be run with the time their retention period started at", func() {
+			failure = errors.New("locked")
+
+			statuses := pruner.PruneAll(ctx)
+			So(len(statuses), ShouldEqual, 2)
+			So(statuses[0].Removed, ShouldEqual, 3)
+			So(statuses[0].Error, ShouldBeEmpty)
+			So(statuses[0].LastRun.Sub(cutoff), ShouldEqual, 48*time.Hour)
+			So(statuses[1].Removed, ShouldEqual, 0)
+			So(statuses[1].Error, ShouldEqual, "locked")
+		})
+
+		Convey("The number of removed entries should be summed up across runs", func() {
+			pruner.PruneAll(ctx)
+
+			failure = nil
+			pruner.PruneAll(ctx)
+
+			statuses := pruner.Statuses()
+			So(statuses[0].Removed, ShouldEqual, 3)
+			So(statuses[0].TotalRemoved, ShouldEqual, 6)
+			So(statuses[1].Error, ShouldBeEmpty)
+		})
+
+		Convey("Sweeps should be registered without retention period and run with the current time", func() {
+			var expiredAt time.Time
+
+			pruner.RegisterSweep("expired", func(ctx context.Context, before time.Time) (int64, error) {
+				expiredAt = before
+				return 2, nil
+			})
+
+			statuses := pruner.PruneAll(ctx)
+			So(len(statuses), ShouldEqual, 3)
+			So(statuses[2].Name, ShouldEqual, "expired")
+			So(statuses[2].Removed, ShouldEqual, 2)
+			So(statuses[2].RetentionDays(), ShouldEqual, 0)
+			So(statuses[2].LastRun, ShouldResemble, expiredAt)
+		})
+
+		Convey("Running should prune immediately and stop once the context is done", func() {
+			runCtx, cancel := context.WithCancel(ctx)
+
+			done := make(chan struct{})
+			go func() {
+				pruner.Run(runCtx)
+				close(done)
+			}()
+
+			for pruner.Statuses()[0].LastRun.IsZero() {
+				time.Sleep(time.Millisecond)
+			}
+
+			cancel()
+			<-done
+
+			So(pruner.Statuses()[0].TotalRemoved, ShouldEqual, 3)
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"database/sql"
 	"encoding/gob"
 	"encoding/json"
@@ -16,6 +17,7 @@ import (
 	"github.com/morpheusxaut/eveauth/models"
 
 	"github.com/boj/redistore"
+	"github.com/garyburd/redigo/redis"
 	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 	"golang.org/x/crypto/bcrypt"
@@ -38,11 +40,49 @@ func SetupSessionController(conf *misc.Configuration, db database.Connection, ma
 		mail:     mailer,
 	}
 
-	store, err := redistore.NewRediStoreWithDB(10, "tcp", controller.config.RedisHost, controller.config.RedisPassword, controller.config.RedisDB, securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32))
+	maxIdle := 10
+	if controller.config.RedisMaxIdle > 0 {
+		maxIdle = controller.config.RedisMaxIdle
+	}
+
+	store, err := redistore.NewRediStoreWithDB(maxIdle, "tcp", controller.config.RedisHost, controller.config.RedisPassword, controller.config.RedisDB, securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32))
 	if err != nil {
 		return nil, err
 	}
 
+	store.Pool.MaxActive = controller.config.RedisMaxActive
+	if controller.config.RedisIdleTimeout > 0 {
+		store.Pool.IdleTimeout = time.Duration(controller.config.RedisIdleTimeout) * time.Second
+	}
+
+	store.Pool.Dial = func() (redis.Conn, error) {
+		c, err := redis.Dial("tcp", conf.RedisHost,
+			redis.DialConnectTimeout(time.Duration(conf.RedisConnectTimeout)*time.Millisecond),
+			redis.DialReadTimeout(time.Duration(conf.RedisReadTimeout)*time.Millisecond),
+			redis.DialWriteTimeout(time.Duration(conf.RedisWriteTimeout)*time.Millisecond))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(conf.RedisPassword) > 0 {
+			_, err = c.Do("AUTH", conf.RedisPassword)
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+
+		if len(conf.RedisDB) > 0 {
+			_, err = c.Do("SELECT", conf.RedisDB)
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+
+		return c, nil
+	}
+
 	controller.store = store
 
 	controller.store.Options = &sessions.Options{
@@ -186,13 +226,13 @@ func (controller *Controller) GetLoginRedirect(w http.ResponseWriter, r *http.Re
 
 // Authenticate validates the given username and password against the database and creates a new session with timestamp if successful
 func (controller *Controller) Authenticate(w http.ResponseWriter, r *http.Request, username string, password string) misc.AuthStatus {
-	storedPassword, err := controller.database.LoadPasswordForUser(username)
+	storedPassword, err := controller.database.LoadPasswordForUser(r.Context(), username)
 
 	err = bcrypt.CompareHashAndPassword([]byte(storedPassword), []byte(password))
 
 	loginAttempt := models.NewLoginAttempt(username, r.RemoteAddr, r.UserAgent(), (err == nil))
 
-	logErr := controller.database.SaveLoginAttempt(loginAttempt)
+	logErr := controller.database.SaveLoginAttempt(r.Context(), loginAttempt)
 	if logErr != nil {
 		misc.Logger.Warnf("Failed to log authentication attempt: [%v]", logErr)
 	}
@@ -205,7 +245,7 @@ func (controller *Controller) Authenticate(w http.ResponseWriter, r *http.Reques
 		return misc.AuthStatusError
 	}
 
-	user, err := controller.database.LoadUserFromUsername(username)
+	user, err := controller.database.LoadUserFromUsername(r.Context(), username)
 	if err != nil {
 		misc.Logger.Tracef("Failed to load user from database: [%v]", err)
 		return misc.AuthStatusError
@@ -285,7 +325,7 @@ func (controller *Controller) SendEmailVerification(w http.ResponseWriter, r *ht
 
 // ResendEmailVerification resends an email with a verification link to the given address
 func (controller *Controller) ResendEmailVerification(w http.ResponseWriter, r *http.Request, username string, email string) error {
-	user, err := controller.database.LoadUserFromUsername(username)
+	user, err := controller.database.LoadUserFromUsername(r.Context(), username)
 	if err != nil {
 		return err
 	}
@@ -322,7 +362,7 @@ func (controller *Controller) VerifyEmail(w http.ResponseWriter, r *http.Request
 		return fmt.Errorf("Failed to verify email address")
 	}
 
-	user, err := controller.GetUser(r)
+	user, err := controller.loadUser(r)
 	if err != nil {
 		return err
 	}
@@ -339,7 +379,7 @@ func (controller *Controller) VerifyEmail(w http.ResponseWriter, r *http.Request
 
 // SendPasswordReset sends an email with a verification link to reset a user's password to the given address
 func (controller *Controller) SendPasswordReset(w http.ResponseWriter, r *http.Request, username string, email string) error {
-	user, err := controller.database.LoadUserFromUsername(username)
+	user, err := controller.database.LoadUserFromUsername(r.Context(), username)
 	if err != nil {
 		return err
 	}
@@ -364,7 +404,7 @@ func (controller *Controller) SendPasswordReset(w http.ResponseWriter, r *http.R
 
 // VerifyPasswordReset checks the given code and changes the user's password if the request is valid
 func (controller *Controller) VerifyPasswordReset(w http.ResponseWriter, r *http.Request, email string, username string, verification string, password string) error {
-	user, err := controller.database.LoadUserFromUsername(username)
+	user, err := controller.database.LoadUserFromUsername(r.Context(), username)
 	if err != nil {
 		return err
 	}
@@ -403,9 +443,9 @@ func (controller *Controller) VerifyPasswordReset(w http.ResponseWriter, r *http
 func (controller *Controller) SaveAPIKey(w http.ResponseWriter, r *http.Request, apiKeyID string, apivCode string) error {
 	dataSession, _ := controller.store.Get(r, "eveauthData")
 
-	user, ok := dataSession.Values["user"].(*models.User)
-	if !ok {
-		return fmt.Errorf("Failed to retrieve user from data session")
+	user, err := controller.loadUser(r)
+	if err != nil {
+		return err
 	}
 
 	keyID, err := strconv.ParseInt(apiKeyID, 10, 64)
@@ -430,7 +470,7 @@ func (controller *Controller) SaveAPIKey(w http.ResponseWriter, r *http.Request,
 	}
 
 	for _, accountChar := range accountCharacters {
-		corporation, err := controller.database.LoadCorporationFromEVECorporationID(accountChar.CorporationID)
+		corporation, err := controller.database.LoadCorporationFromEVECorporationID(r.Context(), accountChar.CorporationID)
 		if err == sql.ErrNoRows {
 			misc.Logger.Tracef("No corporation with ID %d found, fetching corporation sheet...", accountChar.CorporationID)
 
@@ -441,7 +481,16 @@ func (controller *Controller) SaveAPIKey(w http.ResponseWriter, r *http.Request,
 
 			corporation = models.NewCorporation(corporationSheet.Name, corporationSheet.Ticker, accountChar.CorporationID, corporationSheet.CEOID, zero.NewInt(0, false), zero.NewString("", false), true)
 
-			corporation, err = controller.database.SaveCorporation(corporation)
+			if corporationSheet.AllianceID > 0 {
+				alliance, err := controller.loadOrCreateAlliance(r.Context(), corporationSheet.AllianceID, corporationSheet.AllianceName)
+				if err != nil {
+					return err
+				}
+
+				corporation.AllianceID = zero.IntFrom(alliance.ID)
+			}
+
+			corporation, err = controller.database.SaveCorporation(r.Context(), corporation)
 			if err != nil {
 				return err
 			}
@@ -456,7 +505,7 @@ func (controller *Controller) SaveAPIKey(w http.ResponseWriter, r *http.Request,
 
 	user.Accounts = append(user.Accounts, account)
 
-	user, err = controller.database.SaveUser(user)
+	user, err = controller.database.SaveUser(r.Context(), user)
 	if err != nil {
 		return err
 	}
@@ -466,13 +515,26 @@ func (controller *Controller) SaveAPIKey(w http.ResponseWriter, r *http.Request,
 	return sessions.Save(r, w)
 }
 
+// loadOrCreateAlliance retrieves the alliance with the given EVE Online alliance ID, creating it if it has not been stored yet.
+// The corporation sheet does not provide the alliance's ticker and executor corporation, new alliances are thus saved without them
+func (controller *Controller) loadOrCreateAlliance(ctx context.Context, eveAllianceID int64, name string) (*models.Alliance, error) {
+	alliance, err := controller.database.LoadAllianceFromEVEAllianceID(ctx, eveAllianceID)
+	if err == sql.ErrNoRows {
+		misc.Logger.Tracef("No alliance with ID %d found, creating alliance...", eveAllianceID)
+
+		return controller.database.SaveAlliance(ctx, models.NewAlliance(name, "", eveAllianceID, 0, true))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return alliance, nil
+}
+
 // DeleteAPIKey removes the given API key from the user and database
 func (controller *Controller) DeleteAPIKey(w http.ResponseWriter, r *http.Request, apiKeyID string) error {
-	dataSession, _ := controller.store.Get(r, "eveauthData")
-
-	user, ok := dataSession.Values["user"].(*models.User)
-	if !ok {
-		return fmt.Errorf("Failed to retrieve user from data session")
+	user, err := controller.loadUser(r)
+	if err != nil {
+		return err
 	}
 
 	keyID, err := strconv.ParseInt(apiKeyID, 10, 64)
@@ -480,7 +542,7 @@ func (controller *Controller) DeleteAPIKey(w http.ResponseWriter, r *http.Reques
 		return err
 	}
 
-	user, err = controller.database.RemoveAPIKeyFromUser(user, keyID)
+	user, err = controller.database.RemoveAPIKeyFromUser(r.Context(), user, keyID)
 	if err != nil {
 		return err
 	}
@@ -509,7 +571,7 @@ func (controller *Controller) GetUser(r *http.Request) (*models.User, error) {
 		}
 
 		var err error
-		user, err = controller.database.LoadUser(userID)
+		user, err = controller.database.LoadUser(r.Context(), userID)
 		if err != nil {
 			return nil, err
 		}
@@ -518,9 +580,19 @@ func (controller *Controller) GetUser(r *http.Request) (*models.User, error) {
 	return user, nil
 }
 
+// loadUser retrieves the current state of the session's user from the database, preventing outdated session data from overwriting changes made in the meantime
+func (controller *Controller) loadUser(r *http.Request) (*models.User, error) {
+	user, err := controller.GetUser(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.database.LoadUser(r.Context(), user.ID)
+}
+
 // SetUser saves the given user object to the database and updates the data session reference
 func (controller *Controller) SetUser(w http.ResponseWriter, r *http.Request, user *models.User) (*models.User, error) {
-	user, err := controller.database.SaveUser(user)
+	user, err := controller.database.SaveUser(r.Context(), user)
 	if err != nil {
 		return nil, err
 	}
@@ -534,7 +606,7 @@ func (controller *Controller) SetUser(w http.ResponseWriter, r *http.Request, us
 
 // UpdateUser updates the current user's settings with the new given values
 func (controller *Controller) UpdateUser(w http.ResponseWriter, r *http.Request, email string, oldPassword string, newPassword string) (*models.User, error) {
-	user, err := controller.GetUser(r)
+	user, err := controller.loadUser(r)
 	if err != nil {
 		return nil, err
 	}
@@ -596,7 +668,7 @@ func (controller *Controller) SetDefaultCharacter(w http.ResponseWriter, r *http
 		return err
 	}
 
-	user, err := controller.GetUser(r)
+	user, err := controller.loadUser(r)
 	if err != nil {
 		return err
 	}
@@ -633,7 +705,7 @@ func (controller *Controller) HasUserRole(r *http.Request, role string) bool {
 	return roleStatus == models.RoleStatusGranted
 }
 
-// EncodeUserPermissions encodes the user's current permissions in a JSON struct and returns the encrypted payload
+// EncodeUserPermissions encodes the user's current permissions visible to the given app in a JSON struct and returns the encrypted payload
 func (controller *Controller) EncodeUserPermissions(r *http.Request, application *models.Application) (string, error) {
 	dataSession, _ := controller.store.Get(r, "eveauthData")
 
@@ -642,7 +714,10 @@ func (controller *Controller) EncodeUserPermissions(r *http.Request, application
 		return "", fmt.Errorf("Failed to retrieve user from data session")
 	}
 
-	authUser := user.ToAuthUser()
+	authUser, err := database.LoadAuthUserForApplication(r.Context(), controller.database, user, application)
+	if err != nil {
+		return "", err
+	}
 
 	payload, err := json.Marshal(authUser)
 	if err != nil {
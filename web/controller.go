@@ -1,51 +1,77 @@
 package web
 
 import (
+	"context"
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/database/cache"
+	"github.com/morpheusxaut/eveauth/health"
+	"github.com/morpheusxaut/eveauth/mail"
+	"github.com/morpheusxaut/eveauth/membership"
 	"github.com/morpheusxaut/eveauth/misc"
 	"github.com/morpheusxaut/eveauth/models"
+	"github.com/morpheusxaut/eveauth/retention"
 	"github.com/morpheusxaut/eveauth/session"
 
 	"github.com/garyburd/redigo/redis"
 	"github.com/gorilla/mux"
+	"gopkg.in/guregu/null.v2/zero"
 )
 
 // Controller provides functionality for handling web requests and accessing session and backend data
 type Controller struct {
-	Config    *misc.Configuration
-	Database  database.Connection
-	Session   *session.Controller
-	Templates *Templates
-	Checksums *AssetChecksums
-	RedisPool *redis.Pool
+	Config      *misc.Configuration
+	Database    database.Connection
+	Cache       *cache.Connection
+	Session     *session.Controller
+	Mail        *mail.Controller
+	Templates   *Templates
+	Checksums   *AssetChecksums
+	RedisPool   *redis.Pool
+	Health      *health.Checker
+	Pruner      *retention.Pruner
+	Memberships *membership.Reconciler
 
 	router *mux.Router
 }
 
 // SetupController prepares the web controller and initialises the router and handled routes
-func SetupController(config *misc.Configuration, db database.Connection, sessions *session.Controller, templates *Templates, checksums *AssetChecksums) *Controller {
+func SetupController(config *misc.Configuration, db database.Connection, sessions *session.Controller, mailer *mail.Controller, templates *Templates, checksums *AssetChecksums) *Controller {
 	controller := &Controller{
 		Config:    config,
 		Database:  db,
 		Session:   sessions,
+		Mail:      mailer,
 		Templates: templates,
 		Checksums: checksums,
 		router:    mux.NewRouter().StrictSlash(true),
 	}
 
+	maxIdle := 3
+	if config.RedisMaxIdle > 0 {
+		maxIdle = config.RedisMaxIdle
+	}
+
+	idleTimeout := 240 * time.Second
+	if config.RedisIdleTimeout > 0 {
+		idleTimeout = time.Duration(config.RedisIdleTimeout) * time.Second
+	}
+
 	controller.RedisPool = &redis.Pool{
-		MaxIdle:     3,
-		IdleTimeout: 240 * time.Second,
+		MaxIdle:     maxIdle,
+		MaxActive:   config.RedisMaxActive,
+		IdleTimeout: idleTimeout,
 		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", config.RedisHost)
+			c, err := redis.DialTimeout("tcp", config.RedisHost, time.Duration(config.RedisConnectTimeout)*time.Millisecond, time.Duration(config.RedisReadTimeout)*time.Millisecond, time.Duration(config.RedisWriteTimeout)*time.Millisecond)
 			if err != nil {
 				return nil, err
 			}
@@ -74,6 +100,18 @@ func SetupController(config *misc.Configuration, db database.Connection, session
 		},
 	}
 
+	controller.Health = health.NewChecker(time.Duration(config.HealthCheckInterval)*time.Second, time.Duration(config.HealthCheckTimeout)*time.Millisecond)
+	controller.Health.Register("database", db.Ping)
+	controller.Health.Register("redis", controller.PingRedis)
+
+	controller.Pruner = retention.NewPruner(retention.DefaultInterval)
+	controller.Pruner.Register("trash", time.Duration(config.DatabaseTrashRetention)*24*time.Hour, db.PurgeTrash)
+	controller.Pruner.Register("login attempts", time.Duration(config.DatabaseLoginAttemptRetention)*24*time.Hour, db.PruneLoginAttempts)
+	controller.Pruner.Register("CSRF failures", time.Duration(config.DatabaseCSRFFailureRetention)*24*time.Hour, db.PruneCSRFFailures)
+	controller.Pruner.RegisterSweep("expired grants", controller.SweepExpiredGrants)
+
+	controller.Memberships = membership.NewReconciler(db, time.Duration(config.MembershipReconcileInterval)*time.Minute)
+
 	routes := SetupRoutes(controller)
 
 	for _, route := range routes {
@@ -120,7 +158,7 @@ func (controller *Controller) ServeHTTP(inner http.Handler, name string) http.Ha
 
 			csrfFailure := models.NewCSRFFailure(userID, r)
 
-			err = controller.Database.SaveCSRFFailure(csrfFailure)
+			err = controller.Database.SaveCSRFFailure(r.Context(), csrfFailure)
 			if err != nil {
 				misc.Logger.Errorf("Failed to save CSRF failure: [%v]", err)
 			}
@@ -150,6 +188,21 @@ func (controller *Controller) HandleRequests() {
 	misc.Logger.Criticalf("Received error while listening for HTTP requests: [%v]", err)
 }
 
+// PingRedis verifies the Redis data store is still reachable, returning an error if no connection could be established or the PING command failed
+func (controller *Controller) PingRedis(ctx context.Context) error {
+	c := controller.RedisPool.Get()
+	defer c.Close()
+
+	err := c.Err()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do("PING")
+
+	return err
+}
+
 // SetAuthorizationToken stores a temporary authorization token for the given user and app
 func (controller *Controller) SetAuthorizationToken(userID int64, appID int64, token string) error {
 	c := controller.RedisPool.Get()
@@ -181,16 +234,19 @@ func (controller *Controller) GetAuthorizationToken(userID int64, appID int64) (
 	return token, nil
 }
 
-// EncryptUserPermissions retrieves the data for the given user and app and encrypted the user's permissions using the app secret
-func (controller *Controller) EncryptUserPermissions(userID int64, appID int64) (string, error) {
-	user, err := controller.Database.LoadUser(userID)
+// EncryptUserPermissions retrieves the data for the given user and app and encrypted the user's permissions visible to the app using the app secret
+func (controller *Controller) EncryptUserPermissions(ctx context.Context, userID int64, appID int64) (string, error) {
+	user, err := controller.Database.LoadUser(ctx, userID)
 	if err != nil {
 		return "", err
 	}
 
-	authUser := user.ToAuthUser()
+	application, err := controller.Database.LoadApplication(ctx, appID)
+	if err != nil {
+		return "", err
+	}
 
-	application, err := controller.Database.LoadApplication(appID)
+	authUser, err := database.LoadAuthUserForApplication(ctx, controller.Database, user, application)
 	if err != nil {
 		return "", err
 	}
@@ -208,21 +264,38 @@ func (controller *Controller) EncryptUserPermissions(userID int64, appID int64)
 	return base64.URLEncoding.EncodeToString([]byte(encryptedPayload)), nil
 }
 
-// AddGroupToUser adds the group with the given ID to the user
-func (controller *Controller) AddGroupToUser(userID int64, groupID int64) error {
-	user, err := controller.Database.LoadUser(userID)
+// AddGroupToUser adds the group with the given ID to the user, letting the membership expire after the given duration if it is positive.
+// Returns an error if the user already is a member of the group or a *database.ConflictError if the user has been modified since the given version was loaded
+func (controller *Controller) AddGroupToUser(ctx context.Context, userID int64, version int64, groupID int64, duration time.Duration) error {
+	user, err := controller.Database.LoadUser(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	group, err := controller.Database.LoadGroup(groupID)
+	for _, group := range user.Groups {
+		if group.ID == groupID {
+			return fmt.Errorf("User #%d already is a member of group #%d", user.ID, groupID)
+		}
+	}
+
+	user.Version = version
+
+	group, err := controller.Database.LoadGroup(ctx, groupID)
 	if err != nil {
 		return err
 	}
 
 	user.Groups = append(user.Groups, group)
 
-	_, err = controller.Database.SaveUser(user)
+	if duration > 0 {
+		if user.GroupValidities == nil {
+			user.GroupValidities = make(map[int64]*models.Validity)
+		}
+
+		user.GroupValidities[group.ID] = models.NewValidity(time.Time{}, duration)
+	}
+
+	_, err = controller.Database.SaveUser(ctx, user)
 	if err != nil {
 		return err
 	}
@@ -230,38 +303,119 @@ func (controller *Controller) AddGroupToUser(userID int64, groupID int64) error
 	return nil
 }
 
-// AddUserRoleToUser adds the role with the given ID to the user
-func (controller *Controller) AddUserRoleToUser(userID int64, roleID int64, roleGranted bool) error {
-	user, err := controller.Database.LoadUser(userID)
+// AddUserRoleToUser adds the role with the given ID to the user, letting the user role expire after the given duration if it is positive.
+// Returns a *database.ConflictError if the user has been modified since the given version was loaded
+func (controller *Controller) AddUserRoleToUser(ctx context.Context, userID int64, version int64, roleID int64, roleGranted bool, duration time.Duration) error {
+	user, err := controller.Database.LoadUser(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	role, err := controller.Database.LoadRole(roleID)
+	user.Version = version
+
+	role, err := controller.Database.LoadRole(ctx, roleID)
 	if err != nil {
 		return err
 	}
 
 	userRole := models.NewUserRole(user.ID, role, false, roleGranted)
 
+	if duration > 0 {
+		userRole.Validity = models.NewValidity(time.Time{}, duration)
+	}
+
 	user.UserRoles = append(user.UserRoles, userRole)
 
-	_, err = controller.Database.SaveUser(user)
+	_, err = controller.Database.SaveUser(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SweepExpiredGrants removes all user roles and group memberships expired at the given time, logging every removed grant and returning their number
+func (controller *Controller) SweepExpiredGrants(ctx context.Context, expiredAt time.Time) (int64, error) {
+	expiredGrants, err := controller.Database.RemoveExpiredGrants(ctx, expiredAt)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, expiredGrant := range expiredGrants {
+		misc.Logger.Infof("Removed expired %s #%d of user #%d, valid until %s", expiredGrant.Type, expiredGrant.TargetID, expiredGrant.UserID, expiredGrant.ValidUntil.Format(time.RFC3339))
+	}
+
+	return int64(len(expiredGrants)), nil
+}
+
+// ReconcileUserMemberships adds and removes the automatically managed group memberships of the user with the given ID, logging errors instead of returning them
+// as the triggering change has already been applied
+func (controller *Controller) ReconcileUserMemberships(ctx context.Context, userID int64) {
+	_, err := controller.Memberships.ReconcileUser(ctx, userID)
+	if err != nil {
+		misc.Logger.Errorf("Failed to reconcile group memberships of user #%d: [%v]", userID, err)
+	}
+}
+
+// AddMembershipRuleToGroup adds a membership rule of the given type and target to the group, triggering the reconciliation of all users in the background afterwards.
+// Returns a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) AddMembershipRuleToGroup(ctx context.Context, groupID int64, version int64, ruleType models.MembershipRuleType, targetID int64) error {
+	err := controller.UpdateGroup(ctx, groupID, version, func(tx database.Connection, group *models.Group) error {
+		_, err := tx.SaveMembershipRule(ctx, models.NewMembershipRule(group.ID, ruleType, targetID))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	controller.Memberships.Trigger()
+
+	return nil
+}
+
+// RemoveMembershipRuleFromGroup removes the membership rule with the given ID from the group, triggering the reconciliation of all users in the background afterwards.
+// Returns a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) RemoveMembershipRuleFromGroup(ctx context.Context, groupID int64, version int64, membershipRuleID int64) error {
+	err := controller.UpdateGroup(ctx, groupID, version, func(tx database.Connection, _ *models.Group) error {
+		membershipRules, err := tx.LoadAllMembershipRulesForGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		found := false
+
+		for _, membershipRule := range membershipRules {
+			if membershipRule.ID == membershipRuleID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return sql.ErrNoRows
+		}
+
+		return tx.DeleteMembershipRule(ctx, membershipRuleID)
+	})
 	if err != nil {
 		return err
 	}
 
+	controller.Memberships.Trigger()
+
 	return nil
 }
 
-// AddGroupRoleToGroup adds the role with the given ID to the group
-func (controller *Controller) AddGroupRoleToGroup(groupID int64, roleID int64, roleGranted bool) error {
-	group, err := controller.Database.LoadGroup(groupID)
+// AddGroupRoleToGroup adds the role with the given ID to the group, returning a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) AddGroupRoleToGroup(ctx context.Context, groupID int64, version int64, roleID int64, roleGranted bool) error {
+	group, err := controller.Database.LoadGroup(ctx, groupID)
 	if err != nil {
 		return err
 	}
 
-	role, err := controller.Database.LoadRole(roleID)
+	group.Version = version
+
+	role, err := controller.Database.LoadRole(ctx, roleID)
 	if err != nil {
 		return err
 	}
@@ -270,7 +424,7 @@ func (controller *Controller) AddGroupRoleToGroup(groupID int64, roleID int64, r
 
 	group.GroupRoles = append(group.GroupRoles, groupRole)
 
-	_, err = controller.Database.SaveGroup(group)
+	_, err = controller.Database.SaveGroup(ctx, group)
 	if err != nil {
 		return err
 	}
@@ -278,12 +432,73 @@ func (controller *Controller) AddGroupRoleToGroup(groupID int64, roleID int64, r
 	return nil
 }
 
+// UpdateUser runs the given modification of the user with the given ID within a single transaction, passing the user loaded within it.
+// The user is saved with the given version before the modification runs, so the conditional update returns a *database.ConflictError
+// if the user has been modified since that version was loaded and holds off concurrent modifications until the transaction ends
+func (controller *Controller) UpdateUser(ctx context.Context, userID int64, version int64, modify func(tx database.Connection, user *models.User) error) error {
+	return controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		user, err := tx.LoadUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		user.Version = version
+
+		user, err = tx.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return modify(tx, user)
+	})
+}
+
+// UpdateGroup runs the given modification of the group with the given ID within a single transaction, passing the group loaded within it.
+// The group is saved with the given version first, see UpdateUser
+func (controller *Controller) UpdateGroup(ctx context.Context, groupID int64, version int64, modify func(tx database.Connection, group *models.Group) error) error {
+	return controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		group, err := tx.LoadGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		group.Version = version
+
+		group, err = tx.SaveGroup(ctx, group)
+		if err != nil {
+			return err
+		}
+
+		return modify(tx, group)
+	})
+}
+
+// UpdateRole runs the given modification of the role with the given ID within a single transaction, passing the role loaded within it.
+// The role is saved with the given version first, see UpdateUser
+func (controller *Controller) UpdateRole(ctx context.Context, roleID int64, version int64, modify func(tx database.Connection, role *models.Role) error) error {
+	return controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		role, err := tx.LoadRole(ctx, roleID)
+		if err != nil {
+			return err
+		}
+
+		role.Version = version
+
+		role, err = tx.SaveRole(ctx, role)
+		if err != nil {
+			return err
+		}
+
+		return modify(tx, role)
+	})
+}
+
 // CreateNewGroup creates a new group, saves it to the database and returns the updated model
-func (controller *Controller) CreateNewGroup(groupName string) (*models.Group, error) {
+func (controller *Controller) CreateNewGroup(ctx context.Context, groupName string) (*models.Group, error) {
 	group := models.NewGroup(groupName, true)
 
 	var err error
-	group, err = controller.Database.SaveGroup(group)
+	group, err = controller.Database.SaveGroup(ctx, group)
 	if err != nil {
 		return nil, err
 	}
@@ -291,12 +506,26 @@ func (controller *Controller) CreateNewGroup(groupName string) (*models.Group, e
 	return group, nil
 }
 
+// SetGroupVisibility changes the visibility of the group with the given ID, returning a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) SetGroupVisibility(ctx context.Context, groupID int64, version int64, visibility models.GroupVisibility) error {
+	group, err := controller.Database.LoadGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	group.Version = version
+	group.Visibility = visibility
+
+	_, err = controller.Database.SaveGroup(ctx, group)
+	return err
+}
+
 // CreateNewRole creates a new role, saves it to the database and returns the updated model
-func (controller *Controller) CreateNewRole(roleName string, roleLocked bool) (*models.Role, error) {
+func (controller *Controller) CreateNewRole(ctx context.Context, roleName string, roleLocked bool) (*models.Role, error) {
 	role := models.NewRole(roleName, true, roleLocked)
 
 	var err error
-	role, err = controller.Database.SaveRole(role)
+	role, err = controller.Database.SaveRole(ctx, role)
 	if err != nil {
 		return nil, err
 	}
@@ -304,14 +533,587 @@ func (controller *Controller) CreateNewRole(roleName string, roleLocked bool) (*
 	return role, nil
 }
 
+// SetRoleApplication moves the role with the given ID into the namespace of the application with the given ID, turning it into a global role if the application ID is not positive.
+// Returns a *database.ConflictError if the role has been modified since the given version was loaded
+func (controller *Controller) SetRoleApplication(ctx context.Context, roleID int64, version int64, applicationID int64) error {
+	role, err := controller.Database.LoadRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	role.Version = version
+	role.ApplicationID = zero.Int{}
+
+	if applicationID > 0 {
+		application, err := controller.Database.LoadApplication(ctx, applicationID)
+		if err != nil {
+			return err
+		}
+
+		role.ApplicationID = zero.IntFrom(application.ID)
+	}
+
+	_, err = controller.Database.SaveRole(ctx, role)
+	return err
+}
+
+// SetRolePolicy sets the policy of the role with the given ID, expecting the policy to be verified by models.ParseRolePolicy already.
+// Returns a *database.ConflictError if the role has been modified since the given version was loaded
+func (controller *Controller) SetRolePolicy(ctx context.Context, roleID int64, version int64, rolePolicy string) error {
+	role, err := controller.Database.LoadRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	role.Version = version
+	role.Policy = rolePolicy
+
+	_, err = controller.Database.SaveRole(ctx, role)
+	return err
+}
+
+// AddImpliedRole adds the role with the given implied role ID to the roles implied by the role with the given ID.
+// Returns a *database.ConflictError if the role has been modified since the given version was loaded
+func (controller *Controller) AddImpliedRole(ctx context.Context, roleID int64, version int64, impliedRoleID int64) error {
+	return controller.UpdateRole(ctx, roleID, version, func(tx database.Connection, role *models.Role) error {
+		impliedRoleIDs := []int64{impliedRoleID}
+
+		for _, impliedRole := range role.ImpliedRoles {
+			impliedRoleIDs = append(impliedRoleIDs, impliedRole.ID)
+		}
+
+		return tx.SaveRoleImplications(ctx, roleID, impliedRoleIDs)
+	})
+}
+
+// RemoveImpliedRole removes the role with the given implied role ID from the roles implied by the role with the given ID.
+// Returns a *database.ConflictError if the role has been modified since the given version was loaded
+func (controller *Controller) RemoveImpliedRole(ctx context.Context, roleID int64, version int64, impliedRoleID int64) error {
+	return controller.UpdateRole(ctx, roleID, version, func(tx database.Connection, role *models.Role) error {
+		impliedRoleIDs := make([]int64, 0)
+
+		for _, impliedRole := range role.ImpliedRoles {
+			if impliedRole.ID != impliedRoleID {
+				impliedRoleIDs = append(impliedRoleIDs, impliedRole.ID)
+			}
+		}
+
+		return tx.SaveRoleImplications(ctx, roleID, impliedRoleIDs)
+	})
+}
+
+// AddSubGroup adds the group with the given sub group ID to the groups nested in the group with the given ID.
+// Returns a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) AddSubGroup(ctx context.Context, groupID int64, version int64, subGroupID int64) error {
+	return controller.UpdateGroup(ctx, groupID, version, func(tx database.Connection, group *models.Group) error {
+		subGroupIDs := []int64{subGroupID}
+
+		for _, subGroup := range group.SubGroups {
+			subGroupIDs = append(subGroupIDs, subGroup.ID)
+		}
+
+		return tx.SaveSubGroups(ctx, groupID, subGroupIDs)
+	})
+}
+
+// RemoveSubGroup removes the group with the given sub group ID from the groups nested in the group with the given ID.
+// Returns a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) RemoveSubGroup(ctx context.Context, groupID int64, version int64, subGroupID int64) error {
+	return controller.UpdateGroup(ctx, groupID, version, func(tx database.Connection, group *models.Group) error {
+		subGroupIDs := make([]int64, 0)
+
+		for _, subGroup := range group.SubGroups {
+			if subGroup.ID != subGroupID {
+				subGroupIDs = append(subGroupIDs, subGroup.ID)
+			}
+		}
+
+		return tx.SaveSubGroups(ctx, groupID, subGroupIDs)
+	})
+}
+
+// LoadAvailableSubGroupsForGroup retrieves all groups which are not yet directly nested in the given group, excluding the group itself
+func (controller *Controller) LoadAvailableSubGroupsForGroup(ctx context.Context, group *models.Group) ([]*models.Group, error) {
+	groups, err := controller.Database.LoadAllGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := map[int64]bool{group.ID: true}
+	for _, subGroup := range group.SubGroups {
+		nested[subGroup.ID] = true
+	}
+
+	availableGroups := make([]*models.Group, 0)
+
+	for _, g := range groups {
+		if !nested[g.ID] {
+			availableGroups = append(availableGroups, g)
+		}
+	}
+
+	return availableGroups, nil
+}
+
+// ManagedGroup bundles a group managed by a user with its direct members and the actions performed by its managers
+type ManagedGroup struct {
+	// Group represents the managed group
+	Group *models.Group
+	// Members contains the direct members of the group, sorted by their username
+	Members []*models.User
+	// Actions contains all actions performed by the managers of the group, newest first
+	Actions []*models.ManagerAction
+}
+
+// AddGroupManager allows the user with the given username to manage the members of the group with the given ID.
+// Returns a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) AddGroupManager(ctx context.Context, groupID int64, version int64, username string) error {
+	return controller.UpdateGroup(ctx, groupID, version, func(tx database.Connection, group *models.Group) error {
+		user, err := tx.LoadUserFromUsername(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.SaveGroupManager(ctx, models.NewGroupManager(group.ID, user.ID))
+		return err
+	})
+}
+
+// RemoveGroupManager removes the group manager assignment with the given ID from the group with the given ID.
+// Returns a *database.ConflictError if the group has been modified since the given version was loaded
+func (controller *Controller) RemoveGroupManager(ctx context.Context, groupID int64, version int64, groupManagerID int64) error {
+	return controller.UpdateGroup(ctx, groupID, version, func(tx database.Connection, _ *models.Group) error {
+		groupManagers, err := tx.LoadAllGroupManagersForGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		found := false
+
+		for _, groupManager := range groupManagers {
+			if groupManager.ID == groupManagerID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return sql.ErrNoRows
+		}
+
+		return tx.DeleteGroupManager(ctx, groupManagerID)
+	})
+}
+
+// IsGroupManager checks whether the user with the given ID manages the group with the given ID
+func (controller *Controller) IsGroupManager(ctx context.Context, groupID int64, userID int64) (bool, error) {
+	groupManagers, err := controller.Database.LoadAllGroupManagersForGroup(ctx, groupID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, groupManager := range groupManagers {
+		if groupManager.UserID == userID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// LoadGroupManagersForGroup retrieves all manager assignments of the group with the given ID
+func (controller *Controller) LoadGroupManagersForGroup(ctx context.Context, groupID int64) ([]*models.GroupManager, error) {
+	groupManagers, err := controller.Database.LoadAllGroupManagersForGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupManagers, nil
+}
+
+// LoadManagerActionsForGroup retrieves all actions performed by the managers of the group with the given ID, newest first
+func (controller *Controller) LoadManagerActionsForGroup(ctx context.Context, groupID int64) ([]*models.ManagerAction, error) {
+	managerActions, err := controller.Database.LoadAllManagerActionsForGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(managerActions)-1; i < j; i, j = i+1, j-1 {
+		managerActions[i], managerActions[j] = managerActions[j], managerActions[i]
+	}
+
+	return managerActions, nil
+}
+
+// LoadManagedGroupsForUser retrieves all groups managed by the user with the given ID as well as their direct members and recorded manager actions
+func (controller *Controller) LoadManagedGroupsForUser(ctx context.Context, userID int64) ([]*ManagedGroup, error) {
+	groups, err := controller.Database.LoadAllManagedGroupsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	managedGroups := make([]*ManagedGroup, 0, len(groups))
+
+	for _, group := range groups {
+		criteria := database.NewListCriteria()
+		criteria.GroupID = group.ID
+		criteria.SortField = "username"
+		criteria.Limit = database.MaxListLimit
+
+		members, _, err := controller.Database.QueryUsers(ctx, criteria)
+		if err != nil {
+			return nil, err
+		}
+
+		managerActions, err := controller.LoadManagerActionsForGroup(ctx, group.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		managedGroups = append(managedGroups, &ManagedGroup{
+			Group:   group,
+			Members: members,
+			Actions: managerActions,
+		})
+	}
+
+	return managedGroups, nil
+}
+
+// AddMemberToManagedGroup adds the user with the given username to the group with the given ID on behalf of the manager with the given ID, recording the action.
+// The caller is expected to verify the manager is allowed to manage the group
+func (controller *Controller) AddMemberToManagedGroup(ctx context.Context, managerID int64, groupID int64, username string) error {
+	var userID int64
+
+	err := controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		user, err := tx.LoadUserFromUsername(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		for _, group := range user.Groups {
+			if group.ID == groupID {
+				return fmt.Errorf("User #%d already is a member of group #%d", user.ID, groupID)
+			}
+		}
+
+		group, err := tx.LoadGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		user.Groups = append(user.Groups, group)
+
+		user, err = tx.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		userID = user.ID
+
+		return tx.SaveManagerAction(ctx, models.NewManagerAction(groupID, managerID, user.ID, models.ManagerActionTypeAddMember))
+	})
+	if err != nil {
+		return err
+	}
+
+	misc.Logger.Infof("Manager #%d added user #%d to group #%d", managerID, userID, groupID)
+
+	return nil
+}
+
+// RemoveMemberFromManagedGroup removes the user with the given ID from the group with the given ID on behalf of the manager with the given ID, recording the action.
+// The caller is expected to verify the manager is allowed to manage the group
+func (controller *Controller) RemoveMemberFromManagedGroup(ctx context.Context, managerID int64, groupID int64, userID int64) error {
+	err := controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		_, err := tx.RemoveUserFromGroup(ctx, userID, groupID)
+		if err != nil {
+			return err
+		}
+
+		return tx.SaveManagerAction(ctx, models.NewManagerAction(groupID, managerID, userID, models.ManagerActionTypeRemoveMember))
+	})
+	if err != nil {
+		return err
+	}
+
+	misc.Logger.Infof("Manager #%d removed user #%d from group #%d", managerID, userID, groupID)
+
+	return nil
+}
+
+// GroupApplicationEntry represents a pending group application as shown in the application queue
+type GroupApplicationEntry struct {
+	// Application contains the pending group application
+	Application *models.GroupApplication
+	// Group contains the group applied to
+	Group *models.Group
+	// Applicant contains the user who submitted the application
+	Applicant *models.User
+}
+
+// LoadJoinableGroupsForUser retrieves all listed groups the user with the given ID is not a direct member of yet
+func (controller *Controller) LoadJoinableGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	user, err := controller.Database.LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := controller.Database.LoadAllGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	member := make(map[int64]bool)
+	for _, group := range user.Groups {
+		member[group.ID] = true
+	}
+
+	joinableGroups := make([]*models.Group, 0)
+
+	for _, group := range groups {
+		if group.IsListed() && !member[group.ID] {
+			joinableGroups = append(joinableGroups, group)
+		}
+	}
+
+	return joinableGroups, nil
+}
+
+// LoadGroupApplicationsForUser retrieves all applications submitted by the user with the given ID, newest first
+func (controller *Controller) LoadGroupApplicationsForUser(ctx context.Context, userID int64) ([]*models.GroupApplication, error) {
+	groupApplications, err := controller.Database.LoadAllGroupApplicationsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(groupApplications)-1; i < j; i, j = i+1, j-1 {
+		groupApplications[i], groupApplications[j] = groupApplications[j], groupApplications[i]
+	}
+
+	return groupApplications, nil
+}
+
+// JoinGroup adds the user with the given ID to the open group with the given ID
+func (controller *Controller) JoinGroup(ctx context.Context, userID int64, groupID int64) error {
+	err := controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		group, err := tx.LoadGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		if !group.IsListed() || group.Visibility != models.GroupVisibilityOpen {
+			return fmt.Errorf("Group #%d cannot be joined without an application", groupID)
+		}
+
+		user, err := tx.LoadUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, userGroup := range user.Groups {
+			if userGroup.ID == groupID {
+				return fmt.Errorf("User #%d already is a member of group #%d", userID, groupID)
+			}
+		}
+
+		user.Groups = append(user.Groups, group)
+
+		_, err = tx.SaveUser(ctx, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	misc.Logger.Infof("User #%d joined group #%d", userID, groupID)
+
+	return nil
+}
+
+// ApplyToGroup submits an application of the user with the given ID for the group with the given ID, which must require an application.
+// Users can only have one pending application per group
+func (controller *Controller) ApplyToGroup(ctx context.Context, userID int64, groupID int64, message string) error {
+	group, err := controller.Database.LoadGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	if !group.IsListed() || group.Visibility != models.GroupVisibilityApplication {
+		return fmt.Errorf("Group #%d does not accept applications", groupID)
+	}
+
+	user, err := controller.Database.LoadUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, userGroup := range user.Groups {
+		if userGroup.ID == groupID {
+			return fmt.Errorf("User #%d already is a member of group #%d", userID, groupID)
+		}
+	}
+
+	groupApplications, err := controller.Database.LoadAllGroupApplicationsForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, groupApplication := range groupApplications {
+		if groupApplication.GroupID == groupID && groupApplication.IsPending() {
+			return fmt.Errorf("User #%d already has a pending application for group #%d", userID, groupID)
+		}
+	}
+
+	groupApplication, err := controller.Database.SaveGroupApplication(ctx, models.NewGroupApplication(groupID, userID, message))
+	if err != nil {
+		return err
+	}
+
+	misc.Logger.Infof("User #%d applied to group #%d with application #%d", userID, groupID, groupApplication.ID)
+
+	return nil
+}
+
+// LoadGroupApplicationQueue retrieves all pending applications the user with the given ID can decide on, oldest first.
+// Administrators can decide on all applications, other users only on those of groups they manage
+func (controller *Controller) LoadGroupApplicationQueue(ctx context.Context, userID int64, admin bool) ([]*GroupApplicationEntry, error) {
+	groupApplications, err := controller.Database.LoadPendingGroupApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[int64]bool)
+
+	if !admin {
+		managedGroups, err := controller.Database.LoadAllManagedGroupsForUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range managedGroups {
+			managed[group.ID] = true
+		}
+	}
+
+	entries := make([]*GroupApplicationEntry, 0)
+
+	for _, groupApplication := range groupApplications {
+		if !admin && !managed[groupApplication.GroupID] {
+			continue
+		}
+
+		group, err := controller.Database.LoadGroup(ctx, groupApplication.GroupID)
+		if err != nil {
+			return nil, err
+		}
+
+		applicant, err := controller.Database.LoadUser(ctx, groupApplication.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &GroupApplicationEntry{
+			Application: groupApplication,
+			Group:       group,
+			Applicant:   applicant,
+		})
+	}
+
+	return entries, nil
+}
+
+// LoadGroupApplication retrieves the group application with the given ID
+func (controller *Controller) LoadGroupApplication(ctx context.Context, groupApplicationID int64) (*models.GroupApplication, error) {
+	groupApplication, err := controller.Database.LoadGroupApplication(ctx, groupApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupApplication, nil
+}
+
+// DecideGroupApplication approves or denies the pending group application with the given ID on behalf of the user with the given ID, adding the applicant to the group if approved.
+// Decisions by group managers are recorded as manager actions. The applicant is notified via email, failing to send it is only logged.
+// The caller is expected to verify the user is allowed to decide on the application
+func (controller *Controller) DecideGroupApplication(ctx context.Context, deciderID int64, groupApplicationID int64, approved bool, comment string, recordAction bool) error {
+	var applicant *models.User
+	var group *models.Group
+
+	err := controller.Database.WithTx(ctx, func(tx database.Connection) error {
+		groupApplication, err := tx.LoadGroupApplication(ctx, groupApplicationID)
+		if err != nil {
+			return err
+		}
+
+		if !groupApplication.IsPending() {
+			return fmt.Errorf("Group application #%d has already been decided on", groupApplicationID)
+		}
+
+		group, err = tx.LoadGroup(ctx, groupApplication.GroupID)
+		if err != nil {
+			return err
+		}
+
+		applicant, err = tx.LoadUser(ctx, groupApplication.UserID)
+		if err != nil {
+			return err
+		}
+
+		groupApplication.Decide(deciderID, approved, comment)
+
+		_, err = tx.SaveGroupApplication(ctx, groupApplication)
+		if err != nil {
+			return err
+		}
+
+		if !approved {
+			return nil
+		}
+
+		for _, userGroup := range applicant.Groups {
+			if userGroup.ID == group.ID {
+				return nil
+			}
+		}
+
+		applicant.Groups = append(applicant.Groups, group)
+
+		applicant, err = tx.SaveUser(ctx, applicant)
+		if err != nil {
+			return err
+		}
+
+		if !recordAction {
+			return nil
+		}
+
+		return tx.SaveManagerAction(ctx, models.NewManagerAction(group.ID, deciderID, applicant.ID, models.ManagerActionTypeAddMember))
+	})
+	if err != nil {
+		return err
+	}
+
+	misc.Logger.Infof("User #%d decided on group application #%d for group #%d, approved: %t", deciderID, groupApplicationID, group.ID, approved)
+
+	if controller.Mail != nil {
+		err = controller.Mail.SendGroupApplicationDecision(applicant.Username, applicant.Email, group.Name, approved, comment)
+		if err != nil {
+			misc.Logger.Warnf("Failed to send group application decision to user #%d: [%v]", applicant.ID, err)
+		}
+	}
+
+	return nil
+}
+
 // VerifyApplication verifies the application to be authorized to perform requests to the auth backend
-func (controller *Controller) VerifyApplication(appID string, callback string, auth string) (*models.Application, error) {
+func (controller *Controller) VerifyApplication(ctx context.Context, appID string, callback string, auth string) (*models.Application, error) {
 	applicationID, err := strconv.ParseInt(appID, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	application, err := controller.Database.LoadApplication(applicationID)
+	application, err := controller.Database.LoadApplication(ctx, applicationID)
 	if err != nil {
 		return nil, err
 	}
@@ -325,9 +1127,39 @@ func (controller *Controller) VerifyApplication(appID string, callback string, a
 	return application, nil
 }
 
+// LoadAllCorporations retrieves all currently known corporations
+func (controller *Controller) LoadAllCorporations(ctx context.Context) ([]*models.Corporation, error) {
+	corporations, err := controller.Database.LoadAllCorporations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return corporations, nil
+}
+
+// LoadAllAlliances retrieves all currently known alliances
+func (controller *Controller) LoadAllAlliances(ctx context.Context) ([]*models.Alliance, error) {
+	alliances, err := controller.Database.LoadAllAlliances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return alliances, nil
+}
+
+// LoadMembershipRulesForGroup retrieves all membership rules of the group with the given ID
+func (controller *Controller) LoadMembershipRulesForGroup(ctx context.Context, groupID int64) ([]*models.MembershipRule, error) {
+	membershipRules, err := controller.Database.LoadAllMembershipRulesForGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return membershipRules, nil
+}
+
 // LoadAllUsers retrieves all currently registered users
-func (controller *Controller) LoadAllUsers() ([]*models.User, error) {
-	users, err := controller.Database.LoadAllUsers()
+func (controller *Controller) LoadAllUsers(ctx context.Context) ([]*models.User, error) {
+	users, err := controller.Database.LoadAllUsers(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -335,9 +1167,19 @@ func (controller *Controller) LoadAllUsers() ([]*models.User, error) {
 	return users, nil
 }
 
+// QueryUsers retrieves a page of users matching the given criteria as well as the total number of matching users
+func (controller *Controller) QueryUsers(ctx context.Context, criteria *database.ListCriteria) ([]*models.User, int64, error) {
+	users, total, err := controller.Database.QueryUsers(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
 // LoadUserFromUserID retrieves a user with the given user ID
-func (controller *Controller) LoadUserFromUserID(userID int64) (*models.User, error) {
-	user, err := controller.Database.LoadUser(userID)
+func (controller *Controller) LoadUserFromUserID(ctx context.Context, userID int64) (*models.User, error) {
+	user, err := controller.Database.LoadUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -346,8 +1188,8 @@ func (controller *Controller) LoadUserFromUserID(userID int64) (*models.User, er
 }
 
 // LoadAllGroups retrieves all currently existing groups
-func (controller *Controller) LoadAllGroups() ([]*models.Group, error) {
-	groups, err := controller.Database.LoadAllGroups()
+func (controller *Controller) LoadAllGroups(ctx context.Context) ([]*models.Group, error) {
+	groups, err := controller.Database.LoadAllGroups(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -355,9 +1197,19 @@ func (controller *Controller) LoadAllGroups() ([]*models.Group, error) {
 	return groups, nil
 }
 
+// QueryGroups retrieves a page of groups matching the given criteria as well as the total number of matching groups
+func (controller *Controller) QueryGroups(ctx context.Context, criteria *database.ListCriteria) ([]*models.Group, int64, error) {
+	groups, total, err := controller.Database.QueryGroups(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
 // LoadGroupFromGroupID retrieves a group with the given group ID
-func (controller *Controller) LoadGroupFromGroupID(groupID int64) (*models.Group, error) {
-	group, err := controller.Database.LoadGroup(groupID)
+func (controller *Controller) LoadGroupFromGroupID(ctx context.Context, groupID int64) (*models.Group, error) {
+	group, err := controller.Database.LoadGroup(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
@@ -366,8 +1218,8 @@ func (controller *Controller) LoadGroupFromGroupID(groupID int64) (*models.Group
 }
 
 // LoadAvailableGroupsForUser retrieves all groups the user can be added to
-func (controller *Controller) LoadAvailableGroupsForUser(userID int64) ([]*models.Group, error) {
-	availableGroups, err := controller.Database.LoadAvailableGroupsForUser(userID)
+func (controller *Controller) LoadAvailableGroupsForUser(ctx context.Context, userID int64) ([]*models.Group, error) {
+	availableGroups, err := controller.Database.LoadAvailableGroupsForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -376,8 +1228,8 @@ func (controller *Controller) LoadAvailableGroupsForUser(userID int64) ([]*model
 }
 
 // LoadAllRoles retrieves all currently existing roles
-func (controller *Controller) LoadAllRoles() ([]*models.Role, error) {
-	roles, err := controller.Database.LoadAllRoles()
+func (controller *Controller) LoadAllRoles(ctx context.Context) ([]*models.Role, error) {
+	roles, err := controller.Database.LoadAllRoles(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -385,9 +1237,19 @@ func (controller *Controller) LoadAllRoles() ([]*models.Role, error) {
 	return roles, nil
 }
 
+// QueryRoles retrieves a page of roles matching the given criteria as well as the total number of matching roles
+func (controller *Controller) QueryRoles(ctx context.Context, criteria *database.ListCriteria) ([]*models.Role, int64, error) {
+	roles, total, err := controller.Database.QueryRoles(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return roles, total, nil
+}
+
 // LoadAvailableUserRolesForUser retrieves all roles the user can be assigned
-func (controller *Controller) LoadAvailableUserRolesForUser(userID int64) ([]*models.Role, error) {
-	availableUserRoles, err := controller.Database.LoadAvailableUserRolesForUser(userID)
+func (controller *Controller) LoadAvailableUserRolesForUser(ctx context.Context, userID int64) ([]*models.Role, error) {
+	availableUserRoles, err := controller.Database.LoadAvailableUserRolesForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -396,8 +1258,8 @@ func (controller *Controller) LoadAvailableUserRolesForUser(userID int64) ([]*mo
 }
 
 // LoadAvailableGroupRolesForGroup retrieves all roles the group can be assigned
-func (controller *Controller) LoadAvailableGroupRolesForGroup(groupID int64) ([]*models.Role, error) {
-	availableGroupRoles, err := controller.Database.LoadAvailableGroupRolesForGroup(groupID)
+func (controller *Controller) LoadAvailableGroupRolesForGroup(ctx context.Context, groupID int64) ([]*models.Role, error) {
+	availableGroupRoles, err := controller.Database.LoadAvailableGroupRolesForGroup(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
@@ -406,11 +1268,57 @@ func (controller *Controller) LoadAvailableGroupRolesForGroup(groupID int64) ([]
 }
 
 // QueryCorporationName queries the database for the name of the corporation with the given ID
-func (controller *Controller) QueryCorporationName(corporationID int64) (string, error) {
-	corporationName, err := controller.Database.LoadCorporationNameFromID(corporationID)
+func (controller *Controller) QueryCorporationName(ctx context.Context, corporationID int64) (string, error) {
+	corporationName, err := controller.Database.LoadCorporationNameFromID(ctx, corporationID)
 	if err != nil {
 		return "", err
 	}
 
 	return corporationName, nil
 }
+
+// LoadTrash retrieves all deleted entities currently kept in the trash
+func (controller *Controller) LoadTrash(ctx context.Context) ([]*models.TrashEntry, error) {
+	trash, err := controller.Database.LoadTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+// RunReport runs the registered report with the given name, reading the values of its parameters from the given form values
+func (controller *Controller) RunReport(ctx context.Context, name string, values url.Values) (*database.ReportResult, error) {
+	report, err := database.LoadReport(name)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := make(map[string]string)
+	for _, parameter := range report.Parameters {
+		parameters[parameter.Name] = values.Get(parameter.Name)
+	}
+
+	result, err := controller.Database.RunReport(ctx, report.Name, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RestoreFromTrash restores the deleted entity of the given type and ID as well as all of its relations
+func (controller *Controller) RestoreFromTrash(ctx context.Context, entryType models.TrashEntryType, id int64) error {
+	switch entryType {
+	case models.TrashEntryTypeUser:
+		return controller.Database.RestoreUser(ctx, id)
+	case models.TrashEntryTypeGroup:
+		return controller.Database.RestoreGroup(ctx, id)
+	case models.TrashEntryTypeRole:
+		return controller.Database.RestoreRole(ctx, id)
+	case models.TrashEntryTypeApplication:
+		return controller.Database.RestoreApplication(ctx, id)
+	}
+
+	return fmt.Errorf("Unknown trash entry type %q", entryType)
+}
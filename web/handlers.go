@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/morpheusxaut/eveauth/database"
+	"github.com/morpheusxaut/eveauth/health"
 	"github.com/morpheusxaut/eveauth/misc"
 	"github.com/morpheusxaut/eveauth/models"
 
@@ -578,7 +580,7 @@ func (controller *Controller) AuthorizeGetHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	application, err := controller.VerifyApplication(app, callback, auth)
+	application, err := controller.VerifyApplication(r.Context(), app, callback, auth)
 	if err != nil {
 		misc.Logger.Tracef("Failed to verify app authentication: [%v]", err)
 
@@ -704,7 +706,7 @@ func (controller *Controller) PermissionsGetHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	encryptedPayload, err := controller.EncryptUserPermissions(userID, appID)
+	encryptedPayload, err := controller.EncryptUserPermissions(r.Context(), userID, appID)
 	if err != nil {
 		misc.Logger.Tracef("Failed to encode user permissions: [%v]", err)
 
@@ -942,6 +944,11 @@ func (controller *Controller) SettingsAccountsPutHandler(w http.ResponseWriter,
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
+
+		user, err := controller.Session.GetUser(r)
+		if err == nil {
+			controller.ReconcileUserMemberships(r.Context(), user.ID)
+		}
 	case "apikeydelete":
 		err = controller.Session.DeleteAPIKey(w, r, apiKeyID)
 		if err != nil {
@@ -953,6 +960,11 @@ func (controller *Controller) SettingsAccountsPutHandler(w http.ResponseWriter,
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
+
+		user, err := controller.Session.GetUser(r)
+		if err == nil {
+			controller.ReconcileUserMemberships(r.Context(), user.ID)
+		}
 	}
 
 	response["status"] = 1
@@ -1053,6 +1065,11 @@ func (controller *Controller) SettingsCharactersPutHandler(w http.ResponseWriter
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
+
+		user, err := controller.Session.GetUser(r)
+		if err == nil {
+			controller.ReconcileUserMemberships(r.Context(), user.ID)
+		}
 	}
 
 	response["status"] = 1
@@ -1105,7 +1122,7 @@ func (controller *Controller) SettingsApplicationsGetHandler(w http.ResponseWrit
 		return
 	}
 
-	applications, err := controller.Database.LoadAllApplicationsForUser(user.ID)
+	applications, err := controller.Database.LoadAllApplicationsForUser(r.Context(), user.ID)
 	if err != nil {
 		misc.Logger.Tracef("Failed to load applications: [%v]", err)
 
@@ -1206,7 +1223,7 @@ func (controller *Controller) SettingsApplicationsPostHandler(w http.ResponseWri
 
 		application := models.NewApplication(name, user.ID, misc.GenerateRandomString(32), callback, true)
 
-		_, err = controller.Database.SaveApplication(application)
+		_, err = controller.Database.SaveApplication(r.Context(), application)
 		if err != nil {
 			misc.Logger.Tracef("Failed to save application: [%v]", err)
 
@@ -1284,7 +1301,18 @@ func (controller *Controller) SettingsApplicationsPutHandler(w http.ResponseWrit
 
 	switch strings.ToLower(command) {
 	case "settingsapplicationsdelete":
-		err = controller.Database.DeleteApplication(applicationID)
+		user, err := controller.Session.GetUser(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to load user, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.Database.DeleteApplication(r.Context(), applicationID, user.ID)
 		if err != nil {
 			misc.Logger.Tracef("Failed to delete application: [%v]", err)
 
@@ -1314,7 +1342,18 @@ func (controller *Controller) SettingsApplicationsPutHandler(w http.ResponseWrit
 			return
 		}
 
-		application, err := controller.Database.LoadApplication(applicationID)
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		application, err := controller.Database.LoadApplication(r.Context(), applicationID)
 		if err != nil {
 			misc.Logger.Tracef("Failed to load application: [%v]", err)
 
@@ -1346,11 +1385,27 @@ func (controller *Controller) SettingsApplicationsPutHandler(w http.ResponseWrit
 			return
 		}
 
+		roleScopes, err := models.ParseRoleScopes(r.FormValue("settingsApplicationsEditApplicationRoleScopes"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role scopes: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Invalid role scopes, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		application.Version = version
 		application.Name = name
 		application.Callback = callback
+		application.RoleScopes = roleScopes
 
-		_, err = controller.Database.SaveApplication(application)
-		if err != nil {
+		_, err = controller.Database.SaveApplication(r.Context(), application)
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
 			misc.Logger.Tracef("Failed to save application: [%v]", err)
 
 			response["status"] = 1
@@ -1366,7 +1421,18 @@ func (controller *Controller) SettingsApplicationsPutHandler(w http.ResponseWrit
 		controller.SendJSONResponse(w, r, response)
 		return
 	case "settingsapplicationseditapplicationresetsecret":
-		application, err := controller.Database.LoadApplication(applicationID)
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		application, err := controller.Database.LoadApplication(r.Context(), applicationID)
 		if err != nil {
 			misc.Logger.Tracef("Failed to load application: [%v]", err)
 
@@ -1398,10 +1464,14 @@ func (controller *Controller) SettingsApplicationsPutHandler(w http.ResponseWrit
 			return
 		}
 
+		application.Version = version
 		application.Secret = misc.GenerateRandomString(32)
 
-		_, err = controller.Database.SaveApplication(application)
-		if err != nil {
+		_, err = controller.Database.SaveApplication(r.Context(), application)
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
 			misc.Logger.Tracef("Failed to save application: [%v]", err)
 
 			response["status"] = 1
@@ -1424,18 +1494,18 @@ func (controller *Controller) SettingsApplicationsPutHandler(w http.ResponseWrit
 	controller.SendJSONResponse(w, r, response)
 }
 
-// AdminUsersGetHandler allows administrators to modify users and assign new groups and roles
-func (controller *Controller) AdminUsersGetHandler(w http.ResponseWriter, r *http.Request) {
+// ManagedGroupsGetHandler provides group managers with an overview of the members of the groups they manage
+func (controller *Controller) ManagedGroupsGetHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
-	response["pageType"] = 6
-	response["pageTitle"] = "User Administration"
+	response["pageType"] = 7
+	response["pageTitle"] = "Managed Groups"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, "/admin/users")
+		err := controller.Session.SetLoginRedirect(w, r, "/managedgroups")
 		if err != nil {
 			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
 
@@ -1447,46 +1517,47 @@ func (controller *Controller) AdminUsersGetHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.users") {
-		misc.Logger.Traceln("Unauthorized access to user administration")
+	user, err := controller.Session.GetUser(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "You don't have access to this page!"
+		response["result"] = "Failed to load user, please try again!"
 
-		controller.SendResponse(w, r, "index", response)
+		controller.SendResponse(w, r, "managedgroups", response)
 		return
 	}
 
-	users, err := controller.LoadAllUsers()
+	managedGroups, err := controller.LoadManagedGroupsForUser(r.Context(), user.ID)
 	if err != nil {
-		misc.Logger.Tracef("Failed to load all users: [%v]")
+		misc.Logger.Tracef("Failed to load managed groups: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to retrieve all user details, please try again!"
+		response["result"] = "Failed to retrieve managed groups, please try again!"
 
-		controller.SendResponse(w, r, "adminusers", response)
+		controller.SendResponse(w, r, "managedgroups", response)
 		return
 	}
 
-	response["users"] = users
+	response["managedGroups"] = managedGroups
 	response["status"] = 0
 	response["result"] = nil
 
-	controller.SendResponse(w, r, "adminusers", response)
+	controller.SendResponse(w, r, "managedgroups", response)
 }
 
-// AdminUsersPostHandler updates the user and adds new roles and groups
-func (controller *Controller) AdminUsersPostHandler(w http.ResponseWriter, r *http.Request) {
+// ManagedGroupsPostHandler handles requests of group managers adding members to the groups they manage
+func (controller *Controller) ManagedGroupsPostHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
-	response["pageType"] = 6
-	response["pageTitle"] = "User Administration"
+	response["pageType"] = 7
+	response["pageTitle"] = "Managed Groups"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, "/admin/users")
+		err := controller.Session.SetLoginRedirect(w, r, "/managedgroups")
 		if err != nil {
 			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
 
@@ -1498,16 +1569,6 @@ func (controller *Controller) AdminUsersPostHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.users") {
-		misc.Logger.Traceln("Unauthorized access to user administration")
-
-		response["status"] = 1
-		response["result"] = "You don't have access to this page!"
-
-		controller.SendResponse(w, r, "adminusers", response)
-		return
-	}
-
 	err := r.ParseForm()
 	if err != nil {
 		misc.Logger.Tracef("Failed to parse form: [%v]", err)
@@ -1515,126 +1576,93 @@ func (controller *Controller) AdminUsersPostHandler(w http.ResponseWriter, r *ht
 		response["status"] = 1
 		response["result"] = "Failed to parse form, please try again!"
 
-		controller.SendResponse(w, r, "adminusers", response)
+		controller.SendResponse(w, r, "managedgroups", response)
 		return
 	}
 
 	command := r.FormValue("command")
-	userID, err := strconv.ParseInt(r.FormValue("userID"), 10, 64)
-	if err != nil {
-		misc.Logger.Tracef("Failed to parse user ID: [%v]", err)
-
-		response["status"] = 1
-		response["result"] = "Failed to parse user ID, please try again!"
-
-		controller.SendResponse(w, r, "adminusers", response)
-		return
-	}
-
 	if len(command) == 0 {
 		misc.Logger.Traceln("Received empty command")
 
 		response["status"] = 1
 		response["result"] = "Empty command, please try again!"
 
-		controller.SendResponse(w, r, "adminusers", response)
+		controller.SendResponse(w, r, "managedgroups", response)
 		return
 	}
 
-	switch strings.ToLower(command) {
-	case "adminuserdetailsaddgroup":
-		group := r.FormValue("adminUserDetailsAddGroupGroup")
-
-		if len(group) == 0 {
-			misc.Logger.Traceln("Received empty group ID")
-
-			response["status"] = 1
-			response["result"] = "Empty group, please try again!"
-
-			controller.SendResponse(w, r, "adminusers", response)
-			return
-		}
-
-		groupID, err := strconv.ParseInt(group, 10, 64)
-		if err != nil {
-			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
-
-			response["status"] = 1
-			response["result"] = "Failed to parse group ID, please try again!"
+	groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
 
-			controller.SendResponse(w, r, "adminusers", response)
-			return
-		}
+		response["status"] = 1
+		response["result"] = "Failed to parse group ID, please try again!"
 
-		err = controller.AddGroupToUser(userID, groupID)
-		if err != nil {
-			misc.Logger.Tracef("Failed to add group to user: [%v]", err)
+		controller.SendResponse(w, r, "managedgroups", response)
+		return
+	}
 
-			response["status"] = 1
-			response["result"] = "Failed to add group to user, please try again!"
+	user, err := controller.Session.GetUser(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
 
-			controller.SendResponse(w, r, "adminusers", response)
-			return
-		}
+		response["status"] = 1
+		response["result"] = "Failed to load user, please try again!"
 
-		controller.SendRedirect(w, r, fmt.Sprintf("/admin/user/%d", userID), http.StatusSeeOther)
+		controller.SendResponse(w, r, "managedgroups", response)
 		return
-	case "adminuserdetailsadduserrole":
-		role := r.FormValue("adminUserDetailsAddUserRoleRole")
-		granted := r.FormValue("adminUserDetailsAddUserRoleGranted")
+	}
 
-		if len(role) == 0 {
-			misc.Logger.Traceln("Received empty role")
+	manager, err := controller.IsGroupManager(r.Context(), groupID, user.ID)
+	if err != nil || !manager {
+		misc.Logger.Tracef("Unauthorized access to managed group #%d by user #%d: [%v]", groupID, user.ID, err)
 
-			response["status"] = 1
-			response["result"] = "Empty role, please try again!"
+		response["status"] = 1
+		response["result"] = "You don't manage this group!"
 
-			controller.SendResponse(w, r, "adminusers", response)
-			return
-		}
+		controller.SendResponse(w, r, "managedgroups", response)
+		return
+	}
 
-		roleID, err := strconv.ParseInt(role, 10, 64)
-		if err != nil {
-			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+	switch strings.ToLower(command) {
+	case "managedgroupsaddmember":
+		username := r.FormValue("managedGroupsAddMemberUsername")
+		if len(username) == 0 {
+			misc.Logger.Traceln("Received empty username")
 
 			response["status"] = 1
-			response["result"] = "Failed to parse role ID, please try again!"
+			response["result"] = "Empty username, please try again!"
 
-			controller.SendResponse(w, r, "adminusers", response)
+			controller.SendResponse(w, r, "managedgroups", response)
 			return
 		}
 
-		roleGranted := false
-		if len(granted) > 0 && strings.EqualFold(granted, "on") {
-			roleGranted = true
-		}
-
-		err = controller.AddUserRoleToUser(userID, roleID, roleGranted)
+		err = controller.AddMemberToManagedGroup(r.Context(), user.ID, groupID, username)
 		if err != nil {
-			misc.Logger.Tracef("Failed to add user role to user: [%v]", err)
+			misc.Logger.Tracef("Failed to add member to managed group: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to add role to user, please try again!"
+			response["result"] = "Failed to add member to group, please try again!"
 
-			controller.SendResponse(w, r, "adminusers", response)
+			controller.SendResponse(w, r, "managedgroups", response)
 			return
 		}
 
-		controller.SendRedirect(w, r, fmt.Sprintf("/admin/user/%d", userID), http.StatusSeeOther)
+		controller.SendRedirect(w, r, "/managedgroups", http.StatusSeeOther)
 		return
 	}
 
 	response["status"] = 1
 	response["result"] = fmt.Sprintf("Unknown command %q", command)
 
-	controller.SendResponse(w, r, "adminusers", response)
+	controller.SendResponse(w, r, "managedgroups", response)
 }
 
-// AdminUsersPutHandler updates the user and removes roles and groups
-func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *http.Request) {
+// ManagedGroupsPutHandler handles AJAX requests of group managers removing members from the groups they manage
+func (controller *Controller) ManagedGroupsPutHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
-	response["pageType"] = 6
-	response["pageTitle"] = "User Administration"
+	response["pageType"] = 7
+	response["pageTitle"] = "Managed Groups"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
@@ -1645,68 +1673,80 @@ func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.users") {
-		misc.Logger.Traceln("Unauthorized access to user administration")
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "You don't have access to this page!"
+		response["result"] = "Failed to parse form, please try again!"
 
 		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
-	err := r.ParseForm()
+	command := r.FormValue("command")
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
 	if err != nil {
-		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+		misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to parse form, please try again!"
+		response["result"] = "Failed to parse group ID, please try again!"
 
 		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
-	command := r.FormValue("command")
-	userID, err := strconv.ParseInt(r.FormValue("userID"), 10, 64)
+	user, err := controller.Session.GetUser(r)
 	if err != nil {
-		misc.Logger.Tracef("Failed to parse user ID: [%v]", err)
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to parse user ID, please try again!"
+		response["result"] = "Failed to load user, please try again!"
 
 		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
-	if len(command) == 0 {
-		misc.Logger.Traceln("Received empty command")
+	manager, err := controller.IsGroupManager(r.Context(), groupID, user.ID)
+	if err != nil || !manager {
+		misc.Logger.Tracef("Unauthorized access to managed group #%d by user #%d: [%v]", groupID, user.ID, err)
 
 		response["status"] = 1
-		response["result"] = "Empty command, please try again!"
+		response["result"] = "You don't manage this group!"
 
 		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
 	switch strings.ToLower(command) {
-	case "adminuserdetailsgroupdelete":
-		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+	case "managedgroupsremovemember":
+		userID, err := strconv.ParseInt(r.FormValue("userID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+			misc.Logger.Tracef("Failed to parse user ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to parse group ID, please try again!"
+			response["result"] = "Failed to parse user ID, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
 
-		_, err = controller.Database.RemoveUserFromGroup(userID, groupID)
+		err = controller.RemoveMemberFromManagedGroup(r.Context(), user.ID, groupID, userID)
 		if err != nil {
-			misc.Logger.Tracef("Failed to remove user from group: [%v]", err)
+			misc.Logger.Tracef("Failed to remove member from managed group: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to remove user from group, please try again!"
+			response["result"] = "Failed to remove member from group, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
@@ -1717,52 +1757,1522 @@ func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *htt
 
 		controller.SendJSONResponse(w, r, response)
 		return
-	case "adminuserdetailsroledelete":
-		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
-		if err != nil {
-			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
-
-			response["status"] = 1
-			response["result"] = "Failed to parse role ID, please try again!"
+	}
 
-			controller.SendJSONResponse(w, r, response)
-			return
-		}
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
 
-		_, err = controller.Database.RemoveUserRoleFromUser(userID, roleID)
-		if err != nil {
-			misc.Logger.Tracef("Failed to remove role from user: [%v]", err)
+	controller.SendJSONResponse(w, r, response)
+}
 
-			response["status"] = 1
-			response["result"] = "Failed to remove role from user, please try again!"
+// GroupsGetHandler displays the groups the current user can join or apply to as well as their submitted applications
+func (controller *Controller) GroupsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 8
+	response["pageTitle"] = "Groups"
 
-			controller.SendJSONResponse(w, r, response)
-			return
-		}
+	loggedIn := controller.Session.IsLoggedIn(w, r)
 
-		response["status"] = 0
-		response["result"] = nil
+	response["loggedIn"] = loggedIn
 
-		controller.SendJSONResponse(w, r, response)
-		return
-	case "adminuserdetailsroletogglegranted":
-		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/groups")
 		if err != nil {
-			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
-
-			response["status"] = 1
-			response["result"] = "Failed to parse role ID, please try again!"
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
 
-			controller.SendJSONResponse(w, r, response)
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
 			return
 		}
 
-		_, err = controller.Database.ToggleUserRoleGranted(roleID)
-		if err != nil {
-			misc.Logger.Tracef("Failed to toggle user role granted: [%v]", err)
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
 
-			response["status"] = 1
-			response["result"] = "Failed to toggle user role, please try again!"
+	user, err := controller.Session.GetUser(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to load user, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	groups, err := controller.LoadJoinableGroupsForUser(r.Context(), user.ID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load joinable groups: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve groups, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	groupApplications, err := controller.LoadGroupApplicationsForUser(r.Context(), user.ID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load group applications: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve group applications, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	response["groups"] = groups
+	response["groupApplications"] = groupApplications
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "groups", response)
+}
+
+// GroupsPostHandler handles requests of users joining open groups or applying to groups requiring an application
+func (controller *Controller) GroupsPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 8
+	response["pageTitle"] = "Groups"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/groups")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse form, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	command := r.FormValue("command")
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse group ID, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	user, err := controller.Session.GetUser(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to load user, please try again!"
+
+		controller.SendResponse(w, r, "groups", response)
+		return
+	}
+
+	switch strings.ToLower(command) {
+	case "groupsjoin":
+		err = controller.JoinGroup(r.Context(), user.ID, groupID)
+		if err != nil {
+			misc.Logger.Tracef("Failed to join group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to join group, please try again!"
+
+			controller.SendResponse(w, r, "groups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/groups", http.StatusSeeOther)
+		return
+	case "groupsapply":
+		err = controller.ApplyToGroup(r.Context(), user.ID, groupID, r.FormValue("groupsApplyMessage"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to apply to group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to apply to group, please try again!"
+
+			controller.SendResponse(w, r, "groups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/groups", http.StatusSeeOther)
+		return
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
+
+	controller.SendResponse(w, r, "groups", response)
+}
+
+// GroupApplicationsGetHandler displays the pending applications for the groups the current user manages, or all pending applications for group administrators
+func (controller *Controller) GroupApplicationsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 9
+	response["pageTitle"] = "Group Applications"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/groups/applications")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	user, err := controller.Session.GetUser(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to load user, please try again!"
+
+		controller.SendResponse(w, r, "groupapplications", response)
+		return
+	}
+
+	groupApplications, err := controller.LoadGroupApplicationQueue(r.Context(), user.ID, controller.Session.HasUserRole(r, "admin.groups"))
+	if err != nil {
+		misc.Logger.Tracef("Failed to load group application queue: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve group applications, please try again!"
+
+		controller.SendResponse(w, r, "groupapplications", response)
+		return
+	}
+
+	response["groupApplications"] = groupApplications
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "groupapplications", response)
+}
+
+// GroupApplicationsPostHandler handles requests of group managers and administrators approving or denying group applications
+func (controller *Controller) GroupApplicationsPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 9
+	response["pageTitle"] = "Group Applications"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/groups/applications")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse form, please try again!"
+
+		controller.SendResponse(w, r, "groupapplications", response)
+		return
+	}
+
+	command := r.FormValue("command")
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendResponse(w, r, "groupapplications", response)
+		return
+	}
+
+	user, err := controller.Session.GetUser(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to load user, please try again!"
+
+		controller.SendResponse(w, r, "groupapplications", response)
+		return
+	}
+
+	switch strings.ToLower(command) {
+	case "groupapplicationsdecide":
+		groupApplicationID, err := strconv.ParseInt(r.FormValue("groupApplicationID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group application ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group application ID, please try again!"
+
+			controller.SendResponse(w, r, "groupapplications", response)
+			return
+		}
+
+		var approved bool
+
+		switch strings.ToLower(r.FormValue("decision")) {
+		case "approve":
+			approved = true
+		case "deny":
+			approved = false
+		default:
+			misc.Logger.Tracef("Received unknown decision %q", r.FormValue("decision"))
+
+			response["status"] = 1
+			response["result"] = "Unknown decision, please try again!"
+
+			controller.SendResponse(w, r, "groupapplications", response)
+			return
+		}
+
+		groupApplication, err := controller.LoadGroupApplication(r.Context(), groupApplicationID)
+		if err != nil {
+			misc.Logger.Tracef("Failed to load group application: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to load group application, please try again!"
+
+			controller.SendResponse(w, r, "groupapplications", response)
+			return
+		}
+
+		manager, err := controller.IsGroupManager(r.Context(), groupApplication.GroupID, user.ID)
+		if err != nil {
+			misc.Logger.Tracef("Failed to check group manager: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to check group manager, please try again!"
+
+			controller.SendResponse(w, r, "groupapplications", response)
+			return
+		}
+
+		if !manager && !controller.Session.HasUserRole(r, "admin.groups") {
+			misc.Logger.Tracef("Unauthorized decision on group application #%d by user #%d", groupApplicationID, user.ID)
+
+			response["status"] = 1
+			response["result"] = "You are not allowed to decide on this application!"
+
+			controller.SendResponse(w, r, "groupapplications", response)
+			return
+		}
+
+		err = controller.DecideGroupApplication(r.Context(), user.ID, groupApplicationID, approved, r.FormValue("groupApplicationsComment"), manager)
+		if err != nil {
+			misc.Logger.Tracef("Failed to decide on group application: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to decide on group application, please try again!"
+
+			controller.SendResponse(w, r, "groupapplications", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/groups/applications", http.StatusSeeOther)
+		return
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
+
+	controller.SendResponse(w, r, "groupapplications", response)
+}
+
+// AdminUsersGetHandler allows administrators to modify users and assign new groups and roles
+func (controller *Controller) AdminUsersGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "User Administration"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/users")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.users") {
+		misc.Logger.Traceln("Unauthorized access to user administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendResponse(w, r, "index", response)
+		return
+	}
+
+	criteria := ParseListCriteria(r)
+
+	response["pagination"] = NewPagination("/admin/users", criteria, 0)
+
+	filterGroups, err := controller.LoadAllGroups(r.Context())
+	if err != nil {
+		misc.Logger.Tracef("Failed to load all groups: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve groups, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	filterCorporations, err := controller.LoadAllCorporations(r.Context())
+	if err != nil {
+		misc.Logger.Tracef("Failed to load all corporations: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve corporations, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	response["filterGroups"] = filterGroups
+	response["filterCorporations"] = filterCorporations
+
+	users, total, err := controller.QueryUsers(r.Context(), criteria)
+	if err != nil {
+		misc.Logger.Tracef("Failed to query users: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve all user details, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	response["users"] = users
+	response["pagination"] = NewPagination("/admin/users", criteria, total)
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "adminusers", response)
+}
+
+// AdminUsersPostHandler updates the user and adds new roles and groups
+func (controller *Controller) AdminUsersPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "User Administration"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/users")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.users") {
+		misc.Logger.Traceln("Unauthorized access to user administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse form, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	command := r.FormValue("command")
+	userID, err := strconv.ParseInt(r.FormValue("userID"), 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse user ID: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse user ID, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	version, err := ParseVersion(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse version, please try again!"
+
+		controller.SendResponse(w, r, "adminusers", response)
+		return
+	}
+
+	switch strings.ToLower(command) {
+	case "adminuserdetailsaddgroup":
+		group := r.FormValue("adminUserDetailsAddGroupGroup")
+
+		if len(group) == 0 {
+			misc.Logger.Traceln("Received empty group ID")
+
+			response["status"] = 1
+			response["result"] = "Empty group, please try again!"
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		groupID, err := strconv.ParseInt(group, 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		duration, err := ParseGrantDuration(r.FormValue("adminUserDetailsAddGroupDuration"), r.FormValue("adminUserDetailsAddGroupDurationUnit"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse membership duration: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Invalid membership duration, please try again!"
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		err = controller.AddGroupToUser(r.Context(), userID, version, groupID, duration)
+		if err != nil {
+			misc.Logger.Tracef("Failed to add group to user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add group to user, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/user/%d", userID), http.StatusSeeOther)
+		return
+	case "adminuserdetailsadduserrole":
+		role := r.FormValue("adminUserDetailsAddUserRoleRole")
+		granted := r.FormValue("adminUserDetailsAddUserRoleGranted")
+
+		if len(role) == 0 {
+			misc.Logger.Traceln("Received empty role")
+
+			response["status"] = 1
+			response["result"] = "Empty role, please try again!"
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		roleID, err := strconv.ParseInt(role, 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		roleGranted := false
+		if len(granted) > 0 && strings.EqualFold(granted, "on") {
+			roleGranted = true
+		}
+
+		duration, err := ParseGrantDuration(r.FormValue("adminUserDetailsAddUserRoleDuration"), r.FormValue("adminUserDetailsAddUserRoleDurationUnit"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse user role duration: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Invalid role duration, please try again!"
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		err = controller.AddUserRoleToUser(r.Context(), userID, version, roleID, roleGranted, duration)
+		if err != nil {
+			misc.Logger.Tracef("Failed to add user role to user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add role to user, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "adminusers", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/user/%d", userID), http.StatusSeeOther)
+		return
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
+
+	controller.SendResponse(w, r, "adminusers", response)
+}
+
+// AdminUsersPutHandler updates the user and removes roles and groups
+func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "User Administration"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Not logged in"))
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.users") {
+		misc.Logger.Traceln("Unauthorized access to user administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse form, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	command := r.FormValue("command")
+	userID, err := strconv.ParseInt(r.FormValue("userID"), 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse user ID: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse user ID, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	version, err := ParseVersion(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse version, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	switch strings.ToLower(command) {
+	case "adminuserdetailsgroupdelete":
+		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateUser(r.Context(), userID, version, func(tx database.Connection, _ *models.User) error {
+			_, err := tx.RemoveUserFromGroup(r.Context(), userID, groupID)
+			return err
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove user from group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to remove user from group, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "adminuserdetailsroledelete":
+		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateUser(r.Context(), userID, version, func(tx database.Connection, _ *models.User) error {
+			_, err := tx.RemoveUserRoleFromUser(r.Context(), userID, roleID)
+			return err
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove role from user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to remove role from user, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "adminuserdetailsroletogglegranted":
+		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateUser(r.Context(), userID, version, func(tx database.Connection, _ *models.User) error {
+			_, err := tx.ToggleUserRoleGranted(r.Context(), roleID)
+			return err
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to toggle user role granted: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to toggle user role, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "adminuserdetailsaccountdelete":
+		accountID, err := strconv.ParseInt(r.FormValue("accountID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse account ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse account ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateUser(r.Context(), userID, version, func(tx database.Connection, _ *models.User) error {
+			return tx.DeleteAccount(r.Context(), accountID)
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to delete account: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to delete account, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		controller.ReconcileUserMemberships(r.Context(), userID)
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "adminusersdelete":
+		user, err := controller.Session.GetUser(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to load user, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateUser(r.Context(), userID, version, func(tx database.Connection, _ *models.User) error {
+			return tx.DeleteUser(r.Context(), userID, user.ID)
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to delete user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to delete user, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// AdminUserDetailsGetHandler allows administrators to view details of a user
+func (controller *Controller) AdminUserDetailsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "User Details"
+
+	vars := mux.Vars(r)
+	userID, err := strconv.ParseInt(vars["userid"], 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse userID: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse user ID, please try again!"
+
+		controller.SendResponse(w, r, "adminuserdetails", response)
+		return
+	}
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, fmt.Sprintf("/admin/user/%d", userID))
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.users") {
+		misc.Logger.Traceln("Unauthorized access to user administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendResponse(w, r, "index", response)
+		return
+	}
+
+	user, err := controller.LoadUserFromUserID(r.Context(), userID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve user details, please try again!"
+
+		controller.SendResponse(w, r, "adminuserdetails", response)
+		return
+	}
+
+	response["user"] = user
+
+	availableGroups, err := controller.LoadAvailableGroupsForUser(r.Context(), user.ID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load available groups: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve available user groups, please try again!"
+
+		controller.SendResponse(w, r, "adminuserdetails", response)
+		return
+	}
+
+	response["availableGroups"] = availableGroups
+
+	availableUserRoles, err := controller.LoadAvailableUserRolesForUser(r.Context(), user.ID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load available user roles: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve available user roles, please try again!"
+
+		controller.SendResponse(w, r, "adminuserdetails", response)
+		return
+	}
+
+	response["availableUserRoles"] = availableUserRoles
+
+	explainRole := r.FormValue("explain")
+	if len(explainRole) > 0 {
+		response["explainRole"] = explainRole
+		response["explanation"] = models.Explain(user, explainRole)
+	}
+
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "adminuserdetails", response)
+}
+
+// AdminGroupsGetHandler allows administrators to modify groups and assign new roles
+func (controller *Controller) AdminGroupsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "Group Administration"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/groups")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.groups") {
+		misc.Logger.Traceln("Unauthorized access to group administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendResponse(w, r, "index", response)
+		return
+	}
+
+	criteria := ParseListCriteria(r)
+
+	response["pagination"] = NewPagination("/admin/groups", criteria, 0)
+
+	groups, total, err := controller.QueryGroups(r.Context(), criteria)
+	if err != nil {
+		misc.Logger.Tracef("Failed to query groups: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve groups, please try again!"
+
+		controller.SendResponse(w, r, "admingroups", response)
+		return
+	}
+
+	response["groups"] = groups
+	response["pagination"] = NewPagination("/admin/groups", criteria, total)
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "admingroups", response)
+}
+
+// AdminGroupsPostHandler allows creation of new groups and adds roles to existing ones
+func (controller *Controller) AdminGroupsPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "Group Administration"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/groups")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.groups") {
+		misc.Logger.Traceln("Unauthorized access to group administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendResponse(w, r, "admingroups", response)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse form, please try again!"
+
+		controller.SendResponse(w, r, "admingroups", response)
+		return
+	}
+
+	command := r.FormValue("command")
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendResponse(w, r, "admingroups", response)
+		return
+	}
+
+	switch strings.ToLower(command) {
+	case "admingroupsadd":
+		groupName := r.FormValue("adminGroupsAddGroupName")
+
+		if len(groupName) == 0 {
+			misc.Logger.Traceln("Received empty group name")
+
+			response["status"] = 1
+			response["result"] = "Empty group name, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		group, err := controller.CreateNewGroup(r.Context(), groupName)
+		if err != nil {
+			misc.Logger.Tracef("Failed to create new group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to create new group, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", group.ID), http.StatusSeeOther)
+		return
+	case "admingroupdetailsaddgrouprole":
+		role := r.FormValue("adminGroupDetailsAddGroupRoleRole")
+		granted := r.FormValue("adminGroupDetailsAddGroupRoleGranted")
+		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		if len(role) == 0 {
+			misc.Logger.Traceln("Received empty role")
+
+			response["status"] = 1
+			response["result"] = "Empty role, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		roleID, err := strconv.ParseInt(role, 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		roleGranted := false
+		if len(granted) > 0 && strings.EqualFold(granted, "on") {
+			roleGranted = true
+		}
+
+		err = controller.AddGroupRoleToGroup(r.Context(), groupID, version, roleID, roleGranted)
+		if err != nil {
+			misc.Logger.Tracef("Failed to add group role to group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add group role to group, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID), http.StatusSeeOther)
+		return
+	case "admingroupdetailsaddmembershiprule":
+		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		ruleType, err := models.ParseMembershipRuleType(r.FormValue("adminGroupDetailsAddMembershipRuleType"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse membership rule type: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Invalid membership rule type, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		target := r.FormValue("adminGroupDetailsAddMembershipRuleCorporation")
+		if ruleType == models.MembershipRuleTypeAlliance {
+			target = r.FormValue("adminGroupDetailsAddMembershipRuleAlliance")
+		}
+
+		targetID, err := strconv.ParseInt(target, 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse membership rule target ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse corporation or alliance ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		err = controller.AddMembershipRuleToGroup(r.Context(), groupID, version, ruleType, targetID)
+		if err != nil {
+			misc.Logger.Tracef("Failed to add membership rule to group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add membership rule to group, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID), http.StatusSeeOther)
+		return
+	case "admingroupdetailsaddsubgroup":
+		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		subGroupID, err := strconv.ParseInt(r.FormValue("adminGroupDetailsAddSubGroupGroup"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse sub group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse sub group ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		err = controller.AddSubGroup(r.Context(), groupID, version, subGroupID)
+		if database.IsNestingCycle(err) {
+			misc.Logger.Tracef("Rejected cyclic group nesting: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "The group cannot contain the selected group as it would end up containing itself!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to add sub group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add sub group, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID), http.StatusSeeOther)
+		return
+	case "admingroupdetailsaddmanager":
+		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		username := r.FormValue("adminGroupDetailsAddManagerUsername")
+		if len(username) == 0 {
+			misc.Logger.Traceln("Received empty username")
+
+			response["status"] = 1
+			response["result"] = "Empty username, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		err = controller.AddGroupManager(r.Context(), groupID, version, username)
+		if err != nil {
+			misc.Logger.Tracef("Failed to add group manager: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add group manager, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID), http.StatusSeeOther)
+		return
+	case "admingroupdetailssetvisibility":
+		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse group ID, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		visibility, err := models.ParseGroupVisibility(r.FormValue("adminGroupDetailsSetVisibilityVisibility"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse group visibility: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Invalid group visibility, please try again!"
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		err = controller.SetGroupVisibility(r.Context(), groupID, version, visibility)
+		if err != nil {
+			misc.Logger.Tracef("Failed to set group visibility: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to set group visibility, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "admingroups", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID), http.StatusSeeOther)
+		return
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
+
+	controller.SendResponse(w, r, "admingroups", response)
+}
+
+// AdminGroupsPutHandler updates a groups and removes existing roles
+func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "Group Administration"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Not logged in"))
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.groups") {
+		misc.Logger.Traceln("Unauthorized access to group administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse form, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	command := r.FormValue("command")
+	groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse group ID, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	if len(command) == 0 {
+		misc.Logger.Traceln("Received empty command")
+
+		response["status"] = 1
+		response["result"] = "Empty command, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	version, err := ParseVersion(r)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse version, please try again!"
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	switch strings.ToLower(command) {
+	case "admingroupdetailsroledelete":
+		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateGroup(r.Context(), groupID, version, func(tx database.Connection, _ *models.Group) error {
+			_, err := tx.RemoveGroupRoleFromGroup(r.Context(), groupID, roleID)
+			return err
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove role from group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to remove role from group, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
@@ -1773,24 +3283,92 @@ func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *htt
 
 		controller.SendJSONResponse(w, r, response)
 		return
-	case "adminuserdetailsaccountdelete":
-		accountID, err := strconv.ParseInt(r.FormValue("accountID"), 10, 64)
+	case "admingroupdetailsroletogglegranted":
+		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to parse account ID: [%v]", err)
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to parse account ID, please try again!"
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateGroup(r.Context(), groupID, version, func(tx database.Connection, _ *models.Group) error {
+			_, err := tx.ToggleGroupRoleGranted(r.Context(), roleID)
+			return err
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to toggle group role granted: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to toggle group role, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "admingroupdetailsmembershipruledelete":
+		membershipRuleID, err := strconv.ParseInt(r.FormValue("membershipRuleID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse membership rule ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse membership rule ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.RemoveMembershipRuleFromGroup(r.Context(), groupID, version, membershipRuleID)
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove membership rule from group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to remove membership rule from group, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
 
-		err = controller.Database.DeleteAccount(accountID)
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "admingroupdetailssubgroupdelete":
+		subGroupID, err := strconv.ParseInt(r.FormValue("subGroupID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to delete account: [%v]", err)
+			misc.Logger.Tracef("Failed to parse sub group ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to delete account, please try again!"
+			response["result"] = "Failed to parse sub group ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.RemoveSubGroup(r.Context(), groupID, version, subGroupID)
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove sub group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to remove sub group, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
@@ -1801,13 +3379,60 @@ func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *htt
 
 		controller.SendJSONResponse(w, r, response)
 		return
-	case "adminusersdelete":
-		err = controller.Database.DeleteUser(userID)
+	case "admingroupdetailsmanagerdelete":
+		groupManagerID, err := strconv.ParseInt(r.FormValue("groupManagerID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to delete user: [%v]", err)
+			misc.Logger.Tracef("Failed to parse group manager ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to delete user, please try again!"
+			response["result"] = "Failed to parse group manager ID, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.RemoveGroupManager(r.Context(), groupID, version, groupManagerID)
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove group manager: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to remove group manager, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
+		return
+	case "admingroupsdelete":
+		user, err := controller.Session.GetUser(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to load user: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to load user, please try again!"
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+
+		err = controller.UpdateGroup(r.Context(), groupID, version, func(tx database.Connection, _ *models.Group) error {
+			return tx.DeleteGroup(r.Context(), groupID, user.ID)
+		})
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to delete group: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to delete group, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
@@ -1816,121 +3441,191 @@ func (controller *Controller) AdminUsersPutHandler(w http.ResponseWriter, r *htt
 		response["status"] = 0
 		response["result"] = nil
 
-		controller.SendJSONResponse(w, r, response)
+		controller.SendJSONResponse(w, r, response)
+		return
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Sprintf("Unknown command %q", command)
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// AdminGroupDetailsGetHandler allows administrators to view details of a group
+func (controller *Controller) AdminGroupDetailsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "Group Details"
+
+	vars := mux.Vars(r)
+	groupID, err := strconv.ParseInt(vars["groupid"], 10, 64)
+	if err != nil {
+		misc.Logger.Tracef("Failed to parse groupID: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to parse group ID, please try again!"
+
+		controller.SendResponse(w, r, "admingroupdetails", response)
+		return
+	}
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID))
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.HasUserRole(r, "admin.groups") {
+		misc.Logger.Traceln("Unauthorized access to group administration")
+
+		response["status"] = 1
+		response["result"] = "You don't have access to this page!"
+
+		controller.SendResponse(w, r, "index", response)
+		return
+	}
+
+	group, err := controller.LoadGroupFromGroupID(r.Context(), groupID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load group: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve group details, please try again!"
+
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	response["status"] = 1
-	response["result"] = fmt.Sprintf("Unknown command %q", command)
-
-	controller.SendJSONResponse(w, r, response)
-}
-
-// AdminUserDetailsGetHandler allows administrators to view details of a user
-func (controller *Controller) AdminUserDetailsGetHandler(w http.ResponseWriter, r *http.Request) {
-	response := make(map[string]interface{})
-	response["pageType"] = 6
-	response["pageTitle"] = "User Details"
+	response["group"] = group
 
-	vars := mux.Vars(r)
-	userID, err := strconv.ParseInt(vars["userid"], 10, 64)
+	availableGroupRoles, err := controller.LoadAvailableGroupRolesForGroup(r.Context(), group.ID)
 	if err != nil {
-		misc.Logger.Tracef("Failed to parse userID: [%v]", err)
+		misc.Logger.Tracef("Failed to load available group roles: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to parse user ID, please try again!"
+		response["result"] = "Failed to retrieve available group roles, please try again!"
 
-		controller.SendResponse(w, r, "adminuserdetails", response)
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	loggedIn := controller.Session.IsLoggedIn(w, r)
+	membershipRules, err := controller.LoadMembershipRulesForGroup(r.Context(), group.ID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load membership rules: [%v]", err)
 
-	response["loggedIn"] = loggedIn
+		response["status"] = 1
+		response["result"] = "Failed to retrieve membership rules, please try again!"
 
-	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, fmt.Sprintf("/admin/user/%d", userID))
-		if err != nil {
-			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+		controller.SendResponse(w, r, "admingroupdetails", response)
+		return
+	}
 
-			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
-			return
-		}
+	availableSubGroups, err := controller.LoadAvailableSubGroupsForGroup(r.Context(), group)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load available sub groups: [%v]", err)
 
-		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		response["status"] = 1
+		response["result"] = "Failed to retrieve available sub groups, please try again!"
+
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.users") {
-		misc.Logger.Traceln("Unauthorized access to user administration")
+	groupManagers, err := controller.LoadGroupManagersForGroup(r.Context(), group.ID)
+	if err != nil {
+		misc.Logger.Tracef("Failed to load group managers: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "You don't have access to this page!"
+		response["result"] = "Failed to retrieve group managers, please try again!"
 
-		controller.SendResponse(w, r, "index", response)
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	user, err := controller.LoadUserFromUserID(userID)
+	managerActions, err := controller.LoadManagerActionsForGroup(r.Context(), group.ID)
 	if err != nil {
-		misc.Logger.Tracef("Failed to load user: [%v]", err)
+		misc.Logger.Tracef("Failed to load manager actions: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to retrieve user details, please try again!"
+		response["result"] = "Failed to retrieve manager actions, please try again!"
 
-		controller.SendResponse(w, r, "adminuserdetails", response)
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	response["user"] = user
-
-	availableGroups, err := controller.LoadAvailableGroupsForUser(user.ID)
+	corporations, err := controller.LoadAllCorporations(r.Context())
 	if err != nil {
-		misc.Logger.Tracef("Failed to load available groups: [%v]", err)
+		misc.Logger.Tracef("Failed to load corporations: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to retrieve available user groups, please try again!"
+		response["result"] = "Failed to retrieve corporations, please try again!"
 
-		controller.SendResponse(w, r, "adminuserdetails", response)
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	response["availableGroups"] = availableGroups
-
-	availableUserRoles, err := controller.LoadAvailableUserRolesForUser(user.ID)
+	alliances, err := controller.LoadAllAlliances(r.Context())
 	if err != nil {
-		misc.Logger.Tracef("Failed to load available user roles: [%v]", err)
+		misc.Logger.Tracef("Failed to load alliances: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to retrieve available user roles, please try again!"
+		response["result"] = "Failed to retrieve alliances, please try again!"
 
-		controller.SendResponse(w, r, "adminuserdetails", response)
+		controller.SendResponse(w, r, "admingroupdetails", response)
 		return
 	}
 
-	response["availableUserRoles"] = availableUserRoles
+	corporationNames := make(map[int64]string)
+	for _, corporation := range corporations {
+		corporationNames[corporation.ID] = corporation.Name
+	}
+
+	allianceNames := make(map[int64]string)
+	for _, alliance := range alliances {
+		allianceNames[alliance.ID] = alliance.Name
+	}
+
+	response["availableGroupRoles"] = availableGroupRoles
+	response["membershipRules"] = membershipRules
+	response["availableSubGroups"] = availableSubGroups
+	response["nestedGroups"] = group.SubGroupClosure()
+	response["groupManagers"] = groupManagers
+	response["managerActions"] = managerActions
+	response["corporations"] = corporations
+	response["corporationNames"] = corporationNames
+	response["alliances"] = alliances
+	response["allianceNames"] = allianceNames
 	response["status"] = 0
 	response["result"] = nil
 
-	controller.SendResponse(w, r, "adminuserdetails", response)
+	controller.SendResponse(w, r, "admingroupdetails", response)
 }
 
-// AdminGroupsGetHandler allows administrators to modify groups and assign new roles
-func (controller *Controller) AdminGroupsGetHandler(w http.ResponseWriter, r *http.Request) {
+// AdminRolesGetHandler allows administrators to modify roles
+func (controller *Controller) AdminRolesGetHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
 	response["pageType"] = 6
-	response["pageTitle"] = "Group Administration"
+	response["pageTitle"] = "Role Administration"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, "/admin/groups")
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/roles")
 		if err != nil {
 			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
-
-			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to set login redirect"))
+			controller.SendRawError(w, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -1938,8 +3633,8 @@ func (controller *Controller) AdminGroupsGetHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.groups") {
-		misc.Logger.Traceln("Unauthorized access to group administration")
+	if !controller.Session.HasUserRole(r, "admin.roles") {
+		misc.Logger.Traceln("Unauthorized access to role administration")
 
 		response["status"] = 1
 		response["result"] = "You don't have access to this page!"
@@ -1948,36 +3643,66 @@ func (controller *Controller) AdminGroupsGetHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	groups, err := controller.LoadAllGroups()
+	criteria := ParseListCriteria(r)
+
+	response["pagination"] = NewPagination("/admin/roles", criteria, 0)
+
+	roles, total, err := controller.QueryRoles(r.Context(), criteria)
 	if err != nil {
-		misc.Logger.Tracef("Failed to load all groups: [%v]")
+		misc.Logger.Tracef("Failed to query roles: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to retrieve groups, please try again!"
+		response["result"] = "Failed to retrieve roles, please try again!"
 
-		controller.SendResponse(w, r, "admingroups", response)
+		controller.SendResponse(w, r, "adminroles", response)
 		return
 	}
 
-	response["groups"] = groups
+	allRoles, err := controller.LoadAllRoles(r.Context())
+	if err != nil {
+		misc.Logger.Tracef("Failed to load all roles: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve roles, please try again!"
+
+		controller.SendResponse(w, r, "adminroles", response)
+		return
+	}
+
+	applications, err := controller.Database.LoadAllApplications(r.Context())
+	if err != nil {
+		misc.Logger.Tracef("Failed to load all applications: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = "Failed to retrieve applications, please try again!"
+
+		controller.SendResponse(w, r, "adminroles", response)
+		return
+	}
+
+	response["roles"] = roles
+	response["allRoles"] = allRoles
+	response["applications"] = applications
+	response["policyAttributes"] = models.PolicyAttributes
+	response["pagination"] = NewPagination("/admin/roles", criteria, total)
 	response["status"] = 0
 	response["result"] = nil
 
-	controller.SendResponse(w, r, "admingroups", response)
+	controller.SendResponse(w, r, "adminroles", response)
 }
 
-// AdminGroupsPostHandler allows creation of new groups and adds roles to existing ones
-func (controller *Controller) AdminGroupsPostHandler(w http.ResponseWriter, r *http.Request) {
+// AdminRolesPostHandler allows creation of new roles, adding implied roles, moving roles into application namespaces and setting role policies
+func (controller *Controller) AdminRolesPostHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
 	response["pageType"] = 6
-	response["pageTitle"] = "Group Administration"
+	response["pageTitle"] = "Role Administration"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, "/admin/groups")
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/roles")
 		if err != nil {
 			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
 			controller.SendRawError(w, http.StatusInternalServerError, err)
@@ -1988,13 +3713,13 @@ func (controller *Controller) AdminGroupsPostHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.groups") {
-		misc.Logger.Traceln("Unauthorized access to group administration")
+	if !controller.Session.HasUserRole(r, "admin.roles") {
+		misc.Logger.Traceln("Unauthorized access to role administration")
 
 		response["status"] = 1
 		response["result"] = "You don't have access to this page!"
 
-		controller.SendResponse(w, r, "admingroups", response)
+		controller.SendResponse(w, r, "adminroles", response)
 		return
 	}
 
@@ -2005,7 +3730,7 @@ func (controller *Controller) AdminGroupsPostHandler(w http.ResponseWriter, r *h
 		response["status"] = 1
 		response["result"] = "Failed to parse form, please try again!"
 
-		controller.SendResponse(w, r, "admingroups", response)
+		controller.SendResponse(w, r, "adminroles", response)
 		return
 	}
 
@@ -2016,103 +3741,214 @@ func (controller *Controller) AdminGroupsPostHandler(w http.ResponseWriter, r *h
 		response["status"] = 1
 		response["result"] = "Empty command, please try again!"
 
-		controller.SendResponse(w, r, "admingroups", response)
+		controller.SendResponse(w, r, "adminroles", response)
 		return
 	}
 
 	switch strings.ToLower(command) {
-	case "admingroupsadd":
-		groupName := r.FormValue("adminGroupsAddGroupName")
+	case "adminrolesadd":
+		roleName := r.FormValue("adminRolesAddRoleName")
+		locked := r.FormValue("adminRolesAddLocked")
 
-		if len(groupName) == 0 {
-			misc.Logger.Traceln("Received empty group name")
+		if len(roleName) == 0 {
+			misc.Logger.Traceln("Received empty role name")
 
 			response["status"] = 1
-			response["result"] = "Empty group name, please try again!"
+			response["result"] = "Empty role name, please try again!"
 
-			controller.SendResponse(w, r, "admingroups", response)
+			controller.SendResponse(w, r, "adminroles", response)
 			return
 		}
 
-		group, err := controller.CreateNewGroup(groupName)
+		roleLocked := false
+		if len(locked) > 0 && strings.EqualFold(locked, "on") {
+			roleLocked = true
+		}
+
+		_, err := controller.CreateNewRole(r.Context(), roleName, roleLocked)
 		if err != nil {
-			misc.Logger.Tracef("Failed to create new group: [%v]", err)
+			misc.Logger.Tracef("Failed to create new role: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to create new group, please try again!"
+			response["result"] = "Failed to create new role, please try again!"
 
-			controller.SendResponse(w, r, "admingroups", response)
+			controller.SendResponse(w, r, "adminroles", response)
 			return
 		}
 
-		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", group.ID), http.StatusSeeOther)
+		controller.SendRedirect(w, r, "/admin/roles", http.StatusSeeOther)
 		return
-	case "admingroupdetailsaddgrouprole":
-		role := r.FormValue("adminGroupDetailsAddGroupRoleRole")
-		granted := r.FormValue("adminGroupDetailsAddGroupRoleGranted")
-		groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+	case "adminrolesimply":
+		roleID, err := strconv.ParseInt(r.FormValue("adminRolesImplyRoleID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to parse group ID, please try again!"
+			response["result"] = "Failed to parse role ID, please try again!"
 
-			controller.SendResponse(w, r, "admingroups", response)
+			controller.SendResponse(w, r, "adminroles", response)
 			return
 		}
 
-		if len(role) == 0 {
-			misc.Logger.Traceln("Received empty role")
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Empty role, please try again!"
+			response["result"] = "Failed to parse version, please try again!"
 
-			controller.SendResponse(w, r, "admingroups", response)
+			controller.SendResponse(w, r, "adminroles", response)
 			return
 		}
 
-		roleID, err := strconv.ParseInt(role, 10, 64)
+		impliedRoleID, err := strconv.ParseInt(r.FormValue("adminRolesImplyImpliedRoleID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse implied role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse implied role ID, please try again!"
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		err = controller.AddImpliedRole(r.Context(), roleID, version, impliedRoleID)
+		if database.IsImplicationCycle(err) {
+			misc.Logger.Tracef("Rejected cyclic role implication: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "The role cannot imply the selected role as it would end up implying itself!"
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to add implied role: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to add implied role, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/admin/roles", http.StatusSeeOther)
+		return
+	case "adminrolessetapplication":
+		roleID, err := strconv.ParseInt(r.FormValue("adminRolesSetApplicationRoleID"), 10, 64)
 		if err != nil {
 			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
 
 			response["status"] = 1
 			response["result"] = "Failed to parse role ID, please try again!"
 
-			controller.SendResponse(w, r, "admingroups", response)
+			controller.SendResponse(w, r, "adminroles", response)
 			return
 		}
 
-		roleGranted := false
-		if len(granted) > 0 && strings.EqualFold(granted, "on") {
-			roleGranted = true
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
 		}
 
-		err = controller.AddGroupRoleToGroup(groupID, roleID, roleGranted)
+		applicationID, err := strconv.ParseInt(r.FormValue("adminRolesSetApplicationApplicationID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to add group role to group: [%v]", err)
+			misc.Logger.Tracef("Failed to parse application ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to add group role to group, please try again!"
+			response["result"] = "Failed to parse application ID, please try again!"
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		err = controller.SetRoleApplication(r.Context(), roleID, version, applicationID)
+		if err != nil {
+			misc.Logger.Tracef("Failed to set application of role: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to set application of role, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/admin/roles", http.StatusSeeOther)
+		return
+	case "adminrolessetpolicy":
+		roleID, err := strconv.ParseInt(r.FormValue("adminRolesSetPolicyRoleID"), 10, 64)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse role ID, please try again!"
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		version, err := ParseVersion(r)
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to parse version, please try again!"
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		rolePolicy, err := models.ParseRolePolicy(r.FormValue("adminRolesSetPolicyPolicy"))
+		if err != nil {
+			misc.Logger.Tracef("Failed to parse role policy: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = fmt.Sprintf("Invalid policy: %v", err)
+
+			controller.SendResponse(w, r, "adminroles", response)
+			return
+		}
+
+		err = controller.SetRolePolicy(r.Context(), roleID, version, rolePolicy)
+		if err != nil {
+			misc.Logger.Tracef("Failed to set policy of role: [%v]", err)
+
+			response["status"] = 1
+			response["result"] = "Failed to set policy of role, please try again!"
+			if database.IsConflict(err) {
+				response["result"] = conflictResult
+			}
 
-			controller.SendResponse(w, r, "admingroups", response)
+			controller.SendResponse(w, r, "adminroles", response)
 			return
 		}
 
-		controller.SendRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID), http.StatusSeeOther)
+		controller.SendRedirect(w, r, "/admin/roles", http.StatusSeeOther)
 		return
 	}
 
 	response["status"] = 1
 	response["result"] = fmt.Sprintf("Unknown command %q", command)
 
-	controller.SendResponse(w, r, "admingroups", response)
+	controller.SendResponse(w, r, "adminroles", response)
 }
 
-// AdminGroupsPutHandler updates a groups and removes existing roles
-func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *http.Request) {
+// AdminRolesPutHandler allows removal of existing roles and implied roles
+func (controller *Controller) AdminRolesPutHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
 	response["pageType"] = 6
-	response["pageTitle"] = "Group Administration"
+	response["pageTitle"] = "Role Administration"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
@@ -2123,8 +3959,8 @@ func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.groups") {
-		misc.Logger.Traceln("Unauthorized access to group administration")
+	if !controller.Session.HasUserRole(r, "admin.roles") {
+		misc.Logger.Traceln("Unauthorized access to role administration")
 
 		response["status"] = 1
 		response["result"] = "You don't have access to this page!"
@@ -2145,12 +3981,12 @@ func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *ht
 	}
 
 	command := r.FormValue("command")
-	groupID, err := strconv.ParseInt(r.FormValue("groupID"), 10, 64)
+	roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
 	if err != nil {
-		misc.Logger.Tracef("Failed to parse group ID: [%v]", err)
+		misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to parse group ID, please try again!"
+		response["result"] = "Failed to parse role ID, please try again!"
 
 		controller.SendJSONResponse(w, r, response)
 		return
@@ -2167,24 +4003,24 @@ func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *ht
 	}
 
 	switch strings.ToLower(command) {
-	case "admingroupdetailsroledelete":
-		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+	case "adminrolesdelete":
+		user, err := controller.Session.GetUser(r)
 		if err != nil {
-			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+			misc.Logger.Tracef("Failed to load user: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to parse role ID, please try again!"
+			response["result"] = "Failed to load user, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
 
-		_, err = controller.Database.RemoveGroupRoleFromGroup(groupID, roleID)
+		err = controller.Database.DeleteRole(r.Context(), roleID, user.ID)
 		if err != nil {
-			misc.Logger.Tracef("Failed to remove role from group: [%v]", err)
+			misc.Logger.Tracef("Failed to delete role: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to remove role from group, please try again!"
+			response["result"] = "Failed to delete role, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
@@ -2195,41 +4031,38 @@ func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *ht
 
 		controller.SendJSONResponse(w, r, response)
 		return
-	case "admingroupdetailsroletogglegranted":
-		roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+	case "adminrolesremoveimplied":
+		version, err := ParseVersion(r)
 		if err != nil {
-			misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+			misc.Logger.Tracef("Failed to parse version: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to parse role ID, please try again!"
+			response["result"] = "Failed to parse version, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
 
-		_, err = controller.Database.ToggleGroupRoleGranted(roleID)
+		impliedRoleID, err := strconv.ParseInt(r.FormValue("impliedRoleID"), 10, 64)
 		if err != nil {
-			misc.Logger.Tracef("Failed to toggle group role granted: [%v]", err)
+			misc.Logger.Tracef("Failed to parse implied role ID: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to toggle group role, please try again!"
+			response["result"] = "Failed to parse implied role ID, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
 		}
 
-		response["status"] = 0
-		response["result"] = nil
-
-		controller.SendJSONResponse(w, r, response)
-		return
-	case "admingroupsdelete":
-		err = controller.Database.DeleteGroup(groupID)
-		if err != nil {
-			misc.Logger.Tracef("Failed to delete group: [%v]", err)
+		err = controller.RemoveImpliedRole(r.Context(), roleID, version, impliedRoleID)
+		if database.IsConflict(err) {
+			controller.SendConflictResponse(w, r, response, err)
+			return
+		} else if err != nil {
+			misc.Logger.Tracef("Failed to remove implied role: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to delete group, please try again!"
+			response["result"] = "Failed to remove implied role, please try again!"
 
 			controller.SendJSONResponse(w, r, response)
 			return
@@ -2248,30 +4081,18 @@ func (controller *Controller) AdminGroupsPutHandler(w http.ResponseWriter, r *ht
 	controller.SendJSONResponse(w, r, response)
 }
 
-// AdminGroupDetailsGetHandler allows administrators to view details of a group
-func (controller *Controller) AdminGroupDetailsGetHandler(w http.ResponseWriter, r *http.Request) {
+// AdminTrashGetHandler allows administrators to view deleted users, groups, roles and applications
+func (controller *Controller) AdminTrashGetHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
 	response["pageType"] = 6
-	response["pageTitle"] = "Group Details"
-
-	vars := mux.Vars(r)
-	groupID, err := strconv.ParseInt(vars["groupid"], 10, 64)
-	if err != nil {
-		misc.Logger.Tracef("Failed to parse groupID: [%v]", err)
-
-		response["status"] = 1
-		response["result"] = "Failed to parse group ID, please try again!"
-
-		controller.SendResponse(w, r, "admingroupdetails", response)
-		return
-	}
+	response["pageTitle"] = "Trash"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, fmt.Sprintf("/admin/group/%d", groupID))
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/trash")
 		if err != nil {
 			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
 			controller.SendRawError(w, http.StatusInternalServerError, err)
@@ -2282,8 +4103,8 @@ func (controller *Controller) AdminGroupDetailsGetHandler(w http.ResponseWriter,
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.groups") {
-		misc.Logger.Traceln("Unauthorized access to group administration")
+	if !controller.Session.HasUserRole(r, "admin.trash") {
+		misc.Logger.Traceln("Unauthorized access to trash")
 
 		response["status"] = 1
 		response["result"] = "You don't have access to this page!"
@@ -2292,264 +4113,217 @@ func (controller *Controller) AdminGroupDetailsGetHandler(w http.ResponseWriter,
 		return
 	}
 
-	group, err := controller.LoadGroupFromGroupID(groupID)
-	if err != nil {
-		misc.Logger.Tracef("Failed to load group: [%v]", err)
-
-		response["status"] = 1
-		response["result"] = "Failed to retrieve group details, please try again!"
-
-		controller.SendResponse(w, r, "admingroupdetails", response)
-		return
-	}
-
-	response["group"] = group
+	response["trashRetention"] = controller.Config.DatabaseTrashRetention
 
-	availableGroupRoles, err := controller.LoadAvailableGroupRolesForGroup(group.ID)
+	trash, err := controller.LoadTrash(r.Context())
 	if err != nil {
-		misc.Logger.Tracef("Failed to load available group roles: [%v]", err)
+		misc.Logger.Tracef("Failed to load trash: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to retrieve available group roles, please try again!"
+		response["result"] = "Failed to retrieve trash, please try again!"
 
-		controller.SendResponse(w, r, "admingroupdetails", response)
+		controller.SendResponse(w, r, "admintrash", response)
 		return
 	}
 
-	response["availableGroupRoles"] = availableGroupRoles
+	response["trash"] = trash
 	response["status"] = 0
 	response["result"] = nil
 
-	controller.SendResponse(w, r, "admingroupdetails", response)
+	controller.SendResponse(w, r, "admintrash", response)
 }
 
-// AdminRolesGetHandler allows administrators to modify roles
-func (controller *Controller) AdminRolesGetHandler(w http.ResponseWriter, r *http.Request) {
+// AdminTrashPutHandler allows restoring deleted entities from the trash
+func (controller *Controller) AdminTrashPutHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
 	response["pageType"] = 6
-	response["pageTitle"] = "Role Administration"
+	response["pageTitle"] = "Trash"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, "/admin/roles")
-		if err != nil {
-			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
-			controller.SendRawError(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Not logged in"))
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.roles") {
-		misc.Logger.Traceln("Unauthorized access to role administration")
+	if !controller.Session.HasUserRole(r, "admin.trash") {
+		misc.Logger.Traceln("Unauthorized access to trash")
 
 		response["status"] = 1
 		response["result"] = "You don't have access to this page!"
 
-		controller.SendResponse(w, r, "index", response)
+		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
-	roles, err := controller.LoadAllRoles()
+	err := r.ParseForm()
 	if err != nil {
-		misc.Logger.Tracef("Failed to load all roles: [%v]")
-
-		response["status"] = 1
-		response["result"] = "Failed to retrieve roles, please try again!"
-
-		controller.SendResponse(w, r, "adminroles", response)
-		return
-	}
-
-	response["roles"] = roles
-	response["status"] = 0
-	response["result"] = nil
-
-	controller.SendResponse(w, r, "adminroles", response)
-}
-
-// AdminRolesPostHandler allows creation of new roles
-func (controller *Controller) AdminRolesPostHandler(w http.ResponseWriter, r *http.Request) {
-	response := make(map[string]interface{})
-	response["pageType"] = 6
-	response["pageTitle"] = "Role Administration"
-
-	loggedIn := controller.Session.IsLoggedIn(w, r)
-
-	response["loggedIn"] = loggedIn
-
-	if !loggedIn {
-		err := controller.Session.SetLoginRedirect(w, r, "/admin/roles")
-		if err != nil {
-			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
-			controller.SendRawError(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
-		return
-	}
-
-	if !controller.Session.HasUserRole(r, "admin.roles") {
-		misc.Logger.Traceln("Unauthorized access to role administration")
+		misc.Logger.Tracef("Failed to parse form: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "You don't have access to this page!"
+		response["result"] = "Failed to parse form, please try again!"
 
-		controller.SendResponse(w, r, "adminroles", response)
+		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
-	err := r.ParseForm()
+	command := r.FormValue("command")
+	entryType := models.TrashEntryType(r.FormValue("entryType"))
+	entryID, err := strconv.ParseInt(r.FormValue("entryID"), 10, 64)
 	if err != nil {
-		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+		misc.Logger.Tracef("Failed to parse entry ID: [%v]", err)
 
 		response["status"] = 1
-		response["result"] = "Failed to parse form, please try again!"
+		response["result"] = "Failed to parse entry ID, please try again!"
 
-		controller.SendResponse(w, r, "adminroles", response)
+		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
-	command := r.FormValue("command")
 	if len(command) == 0 {
 		misc.Logger.Traceln("Received empty command")
 
 		response["status"] = 1
 		response["result"] = "Empty command, please try again!"
 
-		controller.SendResponse(w, r, "adminroles", response)
+		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
 	switch strings.ToLower(command) {
-	case "adminrolesadd":
-		roleName := r.FormValue("adminRolesAddRoleName")
-		locked := r.FormValue("adminRolesAddLocked")
-
-		if len(roleName) == 0 {
-			misc.Logger.Traceln("Received empty role name")
-
-			response["status"] = 1
-			response["result"] = "Empty role name, please try again!"
-
-			controller.SendResponse(w, r, "adminroles", response)
-			return
-		}
-
-		roleLocked := false
-		if len(locked) > 0 && strings.EqualFold(locked, "on") {
-			roleLocked = true
-		}
-
-		_, err := controller.CreateNewRole(roleName, roleLocked)
+	case "admintrashrestore":
+		err = controller.RestoreFromTrash(r.Context(), entryType, entryID)
 		if err != nil {
-			misc.Logger.Tracef("Failed to create new role: [%v]", err)
+			misc.Logger.Tracef("Failed to restore entry from trash: [%v]", err)
 
 			response["status"] = 1
-			response["result"] = "Failed to create new role, please try again!"
+			response["result"] = "Failed to restore entry, please try again!"
 
-			controller.SendResponse(w, r, "adminroles", response)
+			controller.SendJSONResponse(w, r, response)
 			return
 		}
 
-		controller.SendRedirect(w, r, "/admin/roles", http.StatusSeeOther)
+		response["status"] = 0
+		response["result"] = nil
+
+		controller.SendJSONResponse(w, r, response)
 		return
 	}
 
 	response["status"] = 1
 	response["result"] = fmt.Sprintf("Unknown command %q", command)
 
-	controller.SendResponse(w, r, "adminroles", response)
+	controller.SendJSONResponse(w, r, response)
 }
 
-// AdminRolesPutHandler allows removal of existing roles
-func (controller *Controller) AdminRolesPutHandler(w http.ResponseWriter, r *http.Request) {
+// AdminReportsGetHandler allows administrators to view and run the registered reports
+func (controller *Controller) AdminReportsGetHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
 	response["pageType"] = 6
-	response["pageTitle"] = "Role Administration"
+	response["pageTitle"] = "Reports"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
 	response["loggedIn"] = loggedIn
 
 	if !loggedIn {
-		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Not logged in"))
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/reports")
+		if err != nil {
+			misc.Logger.Tracef("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		controller.SendRedirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
-	if !controller.Session.HasUserRole(r, "admin.roles") {
-		misc.Logger.Traceln("Unauthorized access to role administration")
+	if !controller.Session.HasUserRole(r, "admin.reports") {
+		misc.Logger.Traceln("Unauthorized access to reports")
 
 		response["status"] = 1
 		response["result"] = "You don't have access to this page!"
 
-		controller.SendJSONResponse(w, r, response)
+		controller.SendResponse(w, r, "index", response)
 		return
 	}
 
-	err := r.ParseForm()
-	if err != nil {
-		misc.Logger.Tracef("Failed to parse form: [%v]", err)
+	response["reports"] = database.Reports()
+	response["retention"] = controller.Pruner.Statuses()
 
-		response["status"] = 1
-		response["result"] = "Failed to parse form, please try again!"
+	name := r.FormValue("report")
+	if len(name) > 0 {
+		result, err := controller.RunReport(r.Context(), name, r.URL.Query())
+		if err != nil {
+			misc.Logger.Tracef("Failed to run report: [%v]", err)
 
-		controller.SendJSONResponse(w, r, response)
-		return
-	}
+			response["status"] = 1
+			response["result"] = fmt.Sprintf("Failed to run report: %v", err)
 
-	command := r.FormValue("command")
-	roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
-	if err != nil {
-		misc.Logger.Tracef("Failed to parse role ID: [%v]", err)
+			controller.SendResponse(w, r, "adminreports", response)
+			return
+		}
 
-		response["status"] = 1
-		response["result"] = "Failed to parse role ID, please try again!"
+		query := r.URL.Query()
 
-		controller.SendJSONResponse(w, r, response)
-		return
+		query.Set("format", "csv")
+		response["reportCSVURL"] = "/admin/reports/export?" + query.Encode()
+
+		query.Set("format", "json")
+		response["reportJSONURL"] = "/admin/reports/export?" + query.Encode()
+
+		response["report"] = result
+		response["reportRecords"] = result.Records()
 	}
 
-	if len(command) == 0 {
-		misc.Logger.Traceln("Received empty command")
+	response["status"] = 0
+	response["result"] = nil
 
-		response["status"] = 1
-		response["result"] = "Empty command, please try again!"
+	controller.SendResponse(w, r, "adminreports", response)
+}
 
-		controller.SendJSONResponse(w, r, response)
+// AdminReportsExportGetHandler allows administrators to download the results of a report as CSV or JSON
+func (controller *Controller) AdminReportsExportGetHandler(w http.ResponseWriter, r *http.Request) {
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Not logged in"))
 		return
 	}
 
-	switch strings.ToLower(command) {
-	case "adminrolesdelete":
-		err = controller.Database.DeleteRole(roleID)
-		if err != nil {
-			misc.Logger.Tracef("Failed to delete role: [%v]", err)
+	if !controller.Session.HasUserRole(r, "admin.reports") {
+		misc.Logger.Traceln("Unauthorized access to reports")
+		controller.SendRawError(w, http.StatusForbidden, fmt.Errorf("Access denied"))
+		return
+	}
 
-			response["status"] = 1
-			response["result"] = "Failed to delete role, please try again!"
+	result, err := controller.RunReport(r.Context(), r.FormValue("report"), r.URL.Query())
+	if err != nil {
+		misc.Logger.Tracef("Failed to run report: [%v]", err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
 
-			controller.SendJSONResponse(w, r, response)
-			return
-		}
+	controller.SendReport(w, r, result, r.FormValue("format"))
+}
 
-		response["status"] = 0
-		response["result"] = nil
+// HealthGetHandler reports the latest health checks of the database backend and Redis data store, responding with 503 if any of them failed.
+// The hit and miss counters of the database cache are included if caching is enabled.
+// The handler does not require a session, allowing load balancers and monitoring to query it
+func (controller *Controller) HealthGetHandler(w http.ResponseWriter, r *http.Request) {
+	statuses := controller.Health.Statuses(r.Context())
 
-		controller.SendJSONResponse(w, r, response)
-		return
-	}
+	response := make(map[string]interface{})
+	response["healthy"] = health.Healthy(statuses)
+	response["checks"] = statuses
 
-	response["status"] = 1
-	response["result"] = fmt.Sprintf("Unknown command %q", command)
+	if controller.Cache != nil {
+		response["cache"] = controller.Cache.Statistics()
+	}
 
-	controller.SendJSONResponse(w, r, response)
+	controller.SendHealthResponse(w, response)
 }
 
 // LegalGetHandler displays some legal information as well as copyright disclaimers and contact info
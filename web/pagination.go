@@ -0,0 +1,168 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/morpheusxaut/eveauth/database"
+)
+
+// Pagination provides the filter, sort and paging information of a list query to the templates
+type Pagination struct {
+	// Criteria stores the criteria used to retrieve the current page
+	Criteria *database.ListCriteria
+	// Total represents the total number of entries matching the criteria
+	Total int64
+	// Path stores the URL path the listing is available at
+	Path string
+}
+
+// ParseListCriteria parses the filter, sort and paging options of a list query from the request's URL query
+func ParseListCriteria(r *http.Request) *database.ListCriteria {
+	criteria := database.NewListCriteria()
+	query := r.URL.Query()
+
+	criteria.Filter = strings.TrimSpace(query.Get("filter"))
+
+	switch strings.ToLower(query.Get("active")) {
+	case "active":
+		criteria.Active = database.ActiveFilterActive
+	case "inactive":
+		criteria.Active = database.ActiveFilterInactive
+	}
+
+	groupID, err := strconv.ParseInt(query.Get("group"), 10, 64)
+	if err == nil {
+		criteria.GroupID = groupID
+	}
+
+	corporationID, err := strconv.ParseInt(query.Get("corporation"), 10, 64)
+	if err == nil {
+		criteria.CorporationID = corporationID
+	}
+
+	if len(query.Get("sort")) > 0 {
+		criteria.SortField = strings.ToLower(query.Get("sort"))
+	}
+
+	if strings.EqualFold(query.Get("direction"), "desc") {
+		criteria.SortDirection = database.SortDescending
+	}
+
+	limit, err := strconv.ParseInt(query.Get("limit"), 10, 64)
+	if err == nil {
+		criteria.Limit = limit
+	}
+
+	criteria.Limit = criteria.PageLimit()
+
+	page, err := strconv.ParseInt(query.Get("page"), 10, 64)
+	if err == nil && page > 1 {
+		criteria.Offset = (page - 1) * criteria.Limit
+	}
+
+	return criteria
+}
+
+// NewPagination creates new pagination information for the given criteria and total number of entries
+func NewPagination(path string, criteria *database.ListCriteria, total int64) *Pagination {
+	pagination := &Pagination{
+		Criteria: criteria,
+		Total:    total,
+		Path:     path,
+	}
+
+	return pagination
+}
+
+// Page returns the number of the current page, starting at 1
+func (pagination *Pagination) Page() int64 {
+	return pagination.Criteria.PageOffset()/pagination.Criteria.PageLimit() + 1
+}
+
+// PageCount returns the total number of pages available, always returning at least 1
+func (pagination *Pagination) PageCount() int64 {
+	limit := pagination.Criteria.PageLimit()
+
+	pageCount := (pagination.Total + limit - 1) / limit
+	if pageCount < 1 {
+		return 1
+	}
+
+	return pageCount
+}
+
+// HasPrevious checks whether a page before the current one is available
+func (pagination *Pagination) HasPrevious() bool {
+	return pagination.Page() > 1
+}
+
+// HasNext checks whether a page after the current one is available
+func (pagination *Pagination) HasNext() bool {
+	return pagination.Page() < pagination.PageCount()
+}
+
+// PreviousURL returns the URL of the previous page, keeping all filter and sort options
+func (pagination *Pagination) PreviousURL() string {
+	return pagination.url(pagination.Page()-1, pagination.Criteria.SortField, pagination.Criteria.SortDirection)
+}
+
+// NextURL returns the URL of the next page, keeping all filter and sort options
+func (pagination *Pagination) NextURL() string {
+	return pagination.url(pagination.Page()+1, pagination.Criteria.SortField, pagination.Criteria.SortDirection)
+}
+
+// SortURL returns the URL of the first page sorted by the given field, reversing the direction if the listing is already sorted by it
+func (pagination *Pagination) SortURL(field string) string {
+	direction := database.SortAscending
+	if strings.EqualFold(pagination.Criteria.SortField, field) && pagination.Criteria.SortDirection == database.SortAscending {
+		direction = database.SortDescending
+	}
+
+	return pagination.url(1, field, direction)
+}
+
+// IsActiveFilter checks whether the listing is filtered by the given active state ("all", "active" or "inactive")
+func (pagination *Pagination) IsActiveFilter(active string) bool {
+	switch active {
+	case "active":
+		return pagination.Criteria.Active == database.ActiveFilterActive
+	case "inactive":
+		return pagination.Criteria.Active == database.ActiveFilterInactive
+	default:
+		return pagination.Criteria.Active == database.ActiveFilterAll
+	}
+}
+
+// url builds the URL of the given page using the provided sort options and the current filters
+func (pagination *Pagination) url(page int64, sortField string, direction database.SortDirection) string {
+	values := url.Values{}
+
+	if len(pagination.Criteria.Filter) > 0 {
+		values.Set("filter", pagination.Criteria.Filter)
+	}
+
+	switch pagination.Criteria.Active {
+	case database.ActiveFilterActive:
+		values.Set("active", "active")
+	case database.ActiveFilterInactive:
+		values.Set("active", "inactive")
+	}
+
+	if pagination.Criteria.GroupID > 0 {
+		values.Set("group", strconv.FormatInt(pagination.Criteria.GroupID, 10))
+	}
+
+	if pagination.Criteria.CorporationID > 0 {
+		values.Set("corporation", strconv.FormatInt(pagination.Criteria.CorporationID, 10))
+	}
+
+	values.Set("sort", sortField)
+	values.Set("direction", strings.ToLower(direction.String()))
+	values.Set("limit", strconv.FormatInt(pagination.Criteria.PageLimit(), 10))
+	values.Set("page", strconv.FormatInt(page, 10))
+
+	return pagination.Path + "?" + values.Encode()
+}
@@ -163,6 +163,48 @@ func SetupRoutes(controller *Controller) []Route {
 			Pattern:     "/settings/applications",
 			HandlerFunc: controller.SettingsApplicationsPutHandler,
 		},
+		Route{
+			Name:        "ManagedGroupsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/managedgroups",
+			HandlerFunc: controller.ManagedGroupsGetHandler,
+		},
+		Route{
+			Name:        "ManagedGroupsPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/managedgroups",
+			HandlerFunc: controller.ManagedGroupsPostHandler,
+		},
+		Route{
+			Name:        "ManagedGroupsPut",
+			Methods:     []string{"PUT"},
+			Pattern:     "/managedgroups",
+			HandlerFunc: controller.ManagedGroupsPutHandler,
+		},
+		Route{
+			Name:        "GroupsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/groups",
+			HandlerFunc: controller.GroupsGetHandler,
+		},
+		Route{
+			Name:        "GroupsPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/groups",
+			HandlerFunc: controller.GroupsPostHandler,
+		},
+		Route{
+			Name:        "GroupApplicationsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/groups/applications",
+			HandlerFunc: controller.GroupApplicationsGetHandler,
+		},
+		Route{
+			Name:        "GroupApplicationsPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/groups/applications",
+			HandlerFunc: controller.GroupApplicationsPostHandler,
+		},
 		Route{
 			Name:        "AdminUsersGet",
 			Methods:     []string{"GET"},
@@ -229,6 +271,36 @@ func SetupRoutes(controller *Controller) []Route {
 			Pattern:     "/admin/roles",
 			HandlerFunc: controller.AdminRolesPutHandler,
 		},
+		Route{
+			Name:        "AdminTrashGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/admin/trash",
+			HandlerFunc: controller.AdminTrashGetHandler,
+		},
+		Route{
+			Name:        "AdminTrashPut",
+			Methods:     []string{"PUT"},
+			Pattern:     "/admin/trash",
+			HandlerFunc: controller.AdminTrashPutHandler,
+		},
+		Route{
+			Name:        "AdminReportsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/admin/reports",
+			HandlerFunc: controller.AdminReportsGetHandler,
+		},
+		Route{
+			Name:        "AdminReportsExportGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/admin/reports/export",
+			HandlerFunc: controller.AdminReportsExportGetHandler,
+		},
+		Route{
+			Name:        "HealthGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/health",
+			HandlerFunc: controller.HealthGetHandler,
+		},
 		Route{
 			Name:        "LegalGet",
 			Methods:     []string{"GET"},
@@ -43,7 +43,11 @@ func (templates *Templates) TemplateFunctions(r *http.Request) template.FuncMap
 	return template.FuncMap{
 		"IsResultNil":          func(res interface{}) bool { return templates.IsResultNil(res) },
 		"HasUserRole":          func(role string) bool { return templates.HasUserRole(r, role) },
-		"QueryCorporationName": func(i int64) string { return templates.QueryCorporationName(i) },
+		"QueryCorporationName": func(i int64) string { return templates.QueryCorporationName(r, i) },
+		"QueryUsername":        func(i int64) string { return templates.QueryUsername(r, i) },
+		"QueryGroupName":       func(i int64) string { return templates.QueryGroupName(r, i) },
+		"QueryApplicationName": func(i int64) string { return templates.QueryApplicationName(r, i) },
+		"IsGroupManager":       func() bool { return templates.IsGroupManager(r) },
 	}
 }
 
@@ -58,11 +62,56 @@ func (templates *Templates) HasUserRole(r *http.Request, role string) bool {
 }
 
 // QueryCorporationName queries the database for the name of the corporation with the given ID
-func (templates *Templates) QueryCorporationName(corporationID int64) string {
-	corporation, err := templates.database.LoadCorporation(corporationID)
+func (templates *Templates) QueryCorporationName(r *http.Request, corporationID int64) string {
+	corporation, err := templates.database.LoadCorporation(r.Context(), corporationID)
 	if err != nil {
 		return fmt.Sprintf("#%d", corporationID)
 	}
 
 	return corporation.Name
 }
+
+// QueryUsername queries the database for the username of the user with the given ID
+func (templates *Templates) QueryUsername(r *http.Request, userID int64) string {
+	user, err := templates.database.LoadUser(r.Context(), userID)
+	if err != nil {
+		return fmt.Sprintf("#%d", userID)
+	}
+
+	return user.Username
+}
+
+// QueryGroupName queries the database for the name of the group with the given ID
+func (templates *Templates) QueryGroupName(r *http.Request, groupID int64) string {
+	group, err := templates.database.LoadGroup(r.Context(), groupID)
+	if err != nil {
+		return fmt.Sprintf("#%d", groupID)
+	}
+
+	return group.Name
+}
+
+// QueryApplicationName queries the database for the name of the application with the given ID
+func (templates *Templates) QueryApplicationName(r *http.Request, applicationID int64) string {
+	application, err := templates.database.LoadApplication(r.Context(), applicationID)
+	if err != nil {
+		return fmt.Sprintf("#%d", applicationID)
+	}
+
+	return application.Name
+}
+
+// IsGroupManager checks whether the current user manages at least one group
+func (templates *Templates) IsGroupManager(r *http.Request) bool {
+	user, err := templates.session.GetUser(r)
+	if err != nil {
+		return false
+	}
+
+	groups, err := templates.database.LoadAllManagedGroupsForUser(r.Context(), user.ID)
+	if err != nil {
+		return false
+	}
+
+	return len(groups) > 0
+}
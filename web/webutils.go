@@ -1,14 +1,21 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/morpheusxaut/eveauth/database"
 	"github.com/morpheusxaut/eveauth/misc"
 )
 
+// conflictResult is displayed to users trying to modify an entry which has been changed by someone else in the meantime
+const conflictResult = "This entry has been modified by someone else in the meantime, please reload the page and try again!"
+
 // SendResponse sends a response to the client by executing the templates and appending the asset checksum data
 func (controller *Controller) SendResponse(w http.ResponseWriter, r *http.Request, template string, response map[string]interface{}) {
 	csrfToken := controller.Session.GetCSRFToken(w, r)
@@ -67,6 +74,117 @@ func (controller *Controller) SendJSONResponse(w http.ResponseWriter, r *http.Re
 	w.Write(responseContent)
 }
 
+// SendConflictResponse informs the client about an entry modified by someone else in the meantime, allowing it to reload the current state
+func (controller *Controller) SendConflictResponse(w http.ResponseWriter, r *http.Request, response map[string]interface{}, err error) {
+	misc.Logger.Tracef("Detected conflicting modification: [%v]", err)
+
+	response["status"] = 4
+	response["result"] = conflictResult
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// ParseVersion parses the entity version sent along with the request, used to detect modifications made since the client loaded the entity.
+// Returns an error if no version was provided
+func ParseVersion(r *http.Request) (int64, error) {
+	version := r.FormValue("version")
+	if len(version) == 0 {
+		return 0, fmt.Errorf("Missing version")
+	}
+
+	return strconv.ParseInt(version, 10, 64)
+}
+
+// ParseGrantDuration parses the optional duration of a time-limited group membership or user role from the given amount and unit (either "hours" or "days"),
+// returning 0 if no amount was provided
+func ParseGrantDuration(amount string, unit string) (time.Duration, error) {
+	if len(amount) == 0 {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if value <= 0 {
+		return 0, fmt.Errorf("Invalid duration %d", value)
+	}
+
+	switch strings.ToLower(unit) {
+	case "hours":
+		return time.Duration(value) * time.Hour, nil
+	case "", "days":
+		return time.Duration(value) * 24 * time.Hour, nil
+	}
+
+	return 0, fmt.Errorf("Unknown duration unit %q", unit)
+}
+
+// SendReport sends the given report result as a file download, encoded in the given format (either "csv" or "json")
+func (controller *Controller) SendReport(w http.ResponseWriter, r *http.Request, result *database.ReportResult, format string) {
+	var content bytes.Buffer
+	var contentType string
+	var err error
+
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+		err = result.WriteCSV(&content)
+	case "json":
+		contentType = "application/json"
+		err = result.WriteJSON(&content)
+	default:
+		controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("Unsupported export format %q", format))
+		return
+	}
+
+	if err != nil {
+		misc.Logger.Warnf("Failed to encode report: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.%s", result.Report.Name, time.Now().UTC().Format("20060102"), format)
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-XSS-Protection", "1; mode=block")
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Content-Length", strconv.Itoa(content.Len()))
+
+	w.WriteHeader(http.StatusOK)
+
+	w.Write(content.Bytes())
+}
+
+// SendHealthResponse sends the given health report as JSON without accessing the session, using 503 as status code if the report is unhealthy
+func (controller *Controller) SendHealthResponse(w http.ResponseWriter, response map[string]interface{}) {
+	responseContent, err := json.Marshal(response)
+	if err != nil {
+		misc.Logger.Warnf("Failed to marshal health response: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if healthy, ok := response["healthy"].(bool); !ok || !healthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-XSS-Protection", "1; mode=block")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Length", strconv.Itoa(len(responseContent)))
+
+	w.WriteHeader(statusCode)
+
+	w.Write(responseContent)
+}
+
 // SendRedirect sends a redirect to the given URL using the provided status code
 func (controller *Controller) SendRedirect(w http.ResponseWriter, r *http.Request, redirect string, status int) {
 	w.Header().Set("X-Content-Type-Options", "nosniff")